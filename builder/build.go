@@ -4,10 +4,13 @@
 package builder
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
 	"strings"
@@ -22,8 +25,27 @@ import (
 // Can also be passed as a build arg hence needs to be accessed from commands
 const AdditionalPackageBuildArg = "ADDITIONAL_PACKAGE"
 
-// BuildImage construct Docker image from function parameters
-func BuildImage(image string, handler string, functionName string, language string, nocache bool, squash bool, shrinkwrap bool, buildArgMap map[string]string, buildOptions []string, tagMode schema.BuildFormat, buildLabelMap map[string]string, quietBuild bool, copyExtraPaths []string) error {
+// quiet stops the progress of each build being printed, see SetQuiet
+var quiet bool
+
+// SetQuiet stops the progress of each build being printed, the errors and
+// the images which were built are still printed
+func SetQuiet(q bool) {
+	quiet = q
+}
+
+// progress is where the progress of a build is written
+func progress() io.Writer {
+	if quiet {
+		return ioutil.Discard
+	}
+	return os.Stdout
+}
+
+// BuildImage construct Docker image from function parameters, the image is
+// the full name to tag it with, as given by schema.BuildImageName. When a
+// buildContext is given, its files are copied into the build with the handler.
+func BuildImage(imageName string, handler string, functionName string, language string, nocache bool, squash bool, shrinkwrap bool, buildArgMap map[string]string, buildOptions []string, buildLabelMap map[string]string, quietBuild bool, copyExtraPaths []string, buildContext string, platforms []string, push bool, cacheFrom []string, outputPrefix string) error {
 
 	if stack.IsValidTemplate(language) {
 		pathToTemplateYAML := fmt.Sprintf("./template/%s/template.yml", language)
@@ -36,19 +58,18 @@ func BuildImage(image string, handler string, functionName string, language stri
 			return fmt.Errorf("error reading language template: %s", err.Error())
 		}
 
-		branch, version, err := GetImageTagValues(tagMode)
-		if err != nil {
-			return err
-		}
-
-		imageName := schema.BuildImageName(tagMode, image, version, branch)
-
 		if err := ensureHandlerPath(handler); err != nil {
 			return fmt.Errorf("building %s, %s is an invalid path", imageName, handler)
 		}
 
-		tempPath, buildErr := createBuildContext(functionName, handler, language, isLanguageTemplate(language), langTemplate.HandlerFolder, copyExtraPaths)
-		fmt.Printf("Building: %s with %s template. Please wait..\n", imageName, language)
+		if len(buildContext) > 0 {
+			if err := handlerInContext(handler, buildContext); err != nil {
+				return fmt.Errorf("building %s, %s", imageName, err)
+			}
+		}
+
+		tempPath, buildErr := createBuildContext(functionName, handler, language, isLanguageTemplate(language), langTemplate.HandlerFolder, copyExtraPaths, buildContext)
+		fmt.Fprintf(progress(), "Building: %s with %s template. Please wait..\n", imageName, language)
 		if buildErr != nil {
 			return buildErr
 		}
@@ -74,28 +95,41 @@ func BuildImage(image string, handler string, functionName string, language stri
 			BuildArgMap:      buildArgMap,
 			BuildOptPackages: buildOptPackages,
 			BuildLabelMap:    buildLabelMap,
+			Platforms:        platforms,
+			Push:             push,
+			CacheFrom:        cacheFrom,
+			SBOMAttest:       sbomAttest && len(platforms) > 0 && push,
 		}
 
-		command, args := getDockerBuildCommand(dockerBuildVal)
-
-		task := v1execute.ExecTask{
-			Cwd:         tempPath,
-			Command:     command,
-			Args:        args,
-			StreamStdio: !quietBuild,
+		// buildx reads the cache from the registry itself
+		if len(platforms) == 0 {
+			pullCacheImages(cacheFrom, quietBuild)
 		}
 
-		res, err := task.Execute()
+		command, args := getDockerBuildCommand(dockerBuildVal)
 
+		res, err := execBuild(tempPath, command, args, quietBuild, outputPrefix)
 		if err != nil {
 			return err
 		}
 
 		if res.ExitCode != 0 {
-			return fmt.Errorf("[%s] received non-zero exit code from build, error: %s", functionName, res.Stderr)
+			return fmt.Errorf("received non-zero exit code from build, error: %s", res.Stderr)
+		}
+
+		if push {
+			fmt.Printf("Image: %s built and pushed for %s.\n", imageName, strings.Join(platforms, ","))
+		} else {
+			fmt.Printf("Image: %s built.\n", imageName)
 		}
 
-		fmt.Printf("Image: %s built.\n", imageName)
+		if dockerBuildVal.SBOMAttest {
+			fmt.Printf("SBOM: attached to %s.\n", imageName)
+		} else if len(sbomFormat) > 0 {
+			if err := writeSBOM(imageName, functionName, push); err != nil {
+				return err
+			}
+		}
 
 	} else {
 		return fmt.Errorf("language template: %s not supported, build a custom Dockerfile", language)
@@ -104,31 +138,71 @@ func BuildImage(image string, handler string, functionName string, language stri
 	return nil
 }
 
-// GetImageTagValues returns the image tag format and component information determined via GIT
-func GetImageTagValues(tagType schema.BuildFormat) (branch, version string, err error) {
+// execBuild runs the build command, when outputPrefix is given each line of
+// its output is written with the prefix in front
+func execBuild(cwd string, command string, args []string, quietBuild bool, outputPrefix string) (v1execute.ExecResult, error) {
+	if quietBuild || len(outputPrefix) == 0 {
+		task := v1execute.ExecTask{
+			Cwd:         cwd,
+			Command:     command,
+			Args:        args,
+			StreamStdio: !quietBuild,
+		}
+
+		return task.Execute()
+	}
+
+	stdout := newPrefixWriter(os.Stdout, outputPrefix)
+	stderr := newPrefixWriter(os.Stderr, outputPrefix)
+	var stderrBuff bytes.Buffer
+
+	cmd := exec.Command(command, args...)
+	cmd.Dir = cwd
+	cmd.Stdout = stdout
+	cmd.Stderr = io.MultiWriter(stderr, &stderrBuff)
+
+	runErr := cmd.Run()
+	stdout.Flush()
+	stderr.Flush()
+
+	res := v1execute.ExecResult{Stderr: stderrBuff.String()}
+	if runErr != nil {
+		exitErr, ok := runErr.(*exec.ExitError)
+		if !ok {
+			return res, runErr
+		}
+		res.ExitCode = exitErr.ExitCode()
+	}
+
+	return res, nil
+}
+
+// GetImageTagValues returns the image tag format and component information
+// determined via GIT from the repo holding dir
+func GetImageTagValues(tagType schema.BuildFormat, dir string) (branch, version string, err error) {
 	switch tagType {
 	case schema.SHAFormat:
-		version = vcs.GetGitSHA()
+		version = vcs.GetGitSHA(dir)
 		if len(version) == 0 {
 			err = fmt.Errorf("cannot tag image with Git SHA as this is not a Git repository")
 			return
 		}
 	case schema.BranchAndSHAFormat:
-		branch = vcs.GetGitBranch()
+		branch = vcs.GetGitBranch(dir)
 		if len(branch) == 0 {
 			err = fmt.Errorf("cannot tag image with Git branch and SHA as this is not a Git repository")
 			return
 
 		}
 
-		version = vcs.GetGitSHA()
+		version = vcs.GetGitSHA(dir)
 		if len(version) == 0 {
 			err = fmt.Errorf("cannot tag image with Git SHA as this is not a Git repository")
 			return
 
 		}
 	case schema.DescribeFormat:
-		version = vcs.GetGitDescribe()
+		version = vcs.GetGitDescribe(dir)
 		if len(version) == 0 {
 			err = fmt.Errorf("cannot tag image with Git Tag and SHA as this is not a Git repository")
 			return
@@ -141,8 +215,29 @@ func GetImageTagValues(tagType schema.BuildFormat) (branch, version string, err
 func getDockerBuildCommand(build dockerBuild) (string, []string) {
 	flagSlice := buildFlagSlice(build.NoCache, build.Squash, build.HTTPProxy, build.HTTPSProxy, build.BuildArgMap, build.BuildOptPackages, build.BuildLabelMap)
 	args := []string{"build"}
+	if len(build.Platforms) > 0 {
+		args = []string{"buildx", "build", "--platform", strings.Join(build.Platforms, ",")}
+	}
 	args = append(args, flagSlice...)
-	args = append(args, "-t", build.Image, ".")
+	for _, image := range build.CacheFrom {
+		args = append(args, "--cache-from", image)
+	}
+	args = append(args, "-t", build.Image)
+
+	if build.SBOMAttest {
+		args = append(args, "--attest", "type=sbom")
+	}
+
+	// buildx can only load an image for a single platform into the local
+	// image cache, an image for several platforms has to be pushed
+	if len(build.Platforms) > 0 {
+		if build.Push {
+			args = append(args, "--push")
+		} else {
+			args = append(args, "--load")
+		}
+	}
+	args = append(args, ".")
 
 	command := "docker"
 
@@ -159,14 +254,58 @@ type dockerBuild struct {
 	BuildArgMap      map[string]string
 	BuildOptPackages []string
 	BuildLabelMap    map[string]string
+	Platforms        []string
+	Push             bool
+	CacheFrom        []string
+	SBOMAttest       bool
+}
+
+// pullCacheImages pulls the images given to --cache-from, as docker build
+// only uses a cache image which is in the local image cache. The build goes
+// on without an image which can't be pulled, such as on the first build.
+func pullCacheImages(images []string, quietBuild bool) {
+	for _, image := range images {
+		fmt.Fprintf(progress(), "Pulling cache image: %s\n", image)
+
+		task := v1execute.ExecTask{
+			Command:     "docker",
+			Args:        []string{"pull", image},
+			StreamStdio: !quietBuild,
+		}
+
+		res, err := task.Execute()
+		if err != nil || res.ExitCode != 0 {
+			fmt.Printf("Warning: unable to pull cache image %s, building without it.\n", image)
+		}
+	}
+}
+
+// CheckBuildx gives an error explaining how to set up docker buildx when it
+// is not available
+func CheckBuildx() error {
+	task := v1execute.ExecTask{
+		Command: "docker",
+		Args:    []string{"buildx", "version"},
+	}
+
+	res, err := task.Execute()
+	if err != nil || res.ExitCode != 0 {
+		return fmt.Errorf(`docker buildx is needed to build for --platforms, but is not available.
+Install Docker 19.03 or newer, set DOCKER_CLI_EXPERIMENTAL=enabled and create a
+builder which can build for other platforms with:
+  docker buildx create --use
+See https://docs.docker.com/buildx/working-with-buildx/`)
+	}
+
+	return nil
 }
 
 const defaultHandlerFolder = "function"
 
 // createBuildContext creates temporary build folder to perform a Docker build with language template
-func createBuildContext(functionName string, handler string, language string, useFunction bool, handlerFolder string, copyExtraPaths []string) (string, error) {
+func createBuildContext(functionName string, handler string, language string, useFunction bool, handlerFolder string, copyExtraPaths []string, buildContext string) (string, error) {
 	tempPath := fmt.Sprintf("./build/%s/", functionName)
-	fmt.Printf("Clearing temporary build folder: %s\n", tempPath)
+	fmt.Fprintf(progress(), "Clearing temporary build folder: %s\n", tempPath)
 
 	clearErr := os.RemoveAll(tempPath)
 	if clearErr != nil {
@@ -184,7 +323,7 @@ func createBuildContext(functionName string, handler string, language string, us
 		}
 	}
 
-	fmt.Printf("Preparing: %s %s\n", handler+"/", functionPath)
+	fmt.Fprintf(progress(), "Preparing: %s %s\n", handler+"/", functionPath)
 
 	mkdirErr := os.MkdirAll(functionPath, 0700)
 	if mkdirErr != nil {
@@ -200,6 +339,15 @@ func createBuildContext(functionName string, handler string, language string, us
 		}
 	}
 
+	// The files around the handler keep their path from the build context,
+	// the handler is copied over the top of them
+	if len(buildContext) > 0 {
+		fmt.Fprintf(progress(), "Preparing: %s %s\n", buildContext+"/", functionPath)
+		if err := copyBuildContext(buildContext, handler, functionPath); err != nil {
+			return tempPath, err
+		}
+	}
+
 	// Overlay in user-function
 	// CopyFiles(handler, functionPath)
 	infos, readErr := ioutil.ReadDir(handler)
@@ -211,7 +359,7 @@ func createBuildContext(functionName string, handler string, language string, us
 	for _, info := range infos {
 		switch info.Name() {
 		case "build", "template":
-			fmt.Printf("Skipping \"%s\" folder\n", info.Name())
+			fmt.Fprintf(progress(), "Skipping \"%s\" folder\n", info.Name())
 			continue
 		default:
 			copyErr := CopyFiles(
@@ -271,17 +419,88 @@ func pathInScope(path string, scope string) (string, error) {
 	return "", fmt.Errorf("forbidden path appears to be outside of the build context: %s (%s)", path, abs)
 }
 
+// handlerInContext gives an error when the handler is not in the build context
+func handlerInContext(handler string, buildContext string) error {
+	contextAbs, err := filepath.Abs(filepath.FromSlash(buildContext))
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(contextAbs); err != nil || !info.IsDir() {
+		return fmt.Errorf("the build context %s is not a folder", buildContext)
+	}
+
+	handlerAbs, err := filepath.Abs(filepath.FromSlash(handler))
+	if err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(contextAbs, handlerAbs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("the handler %s is outside of the build context %s", handler, buildContext)
+	}
+	return nil
+}
+
+// copyBuildContext copies the build context into dest, leaving out the
+// handler, which is copied on its own, and the build and template folders
+func copyBuildContext(buildContext string, handler string, dest string) error {
+	handlerAbs, err := filepath.Abs(filepath.FromSlash(handler))
+	if err != nil {
+		return err
+	}
+
+	var copyDir func(src string, dest string, top bool) error
+	copyDir = func(src string, dest string, top bool) error {
+		infos, err := ioutil.ReadDir(src)
+		if err != nil {
+			return err
+		}
+
+		for _, info := range infos {
+			if top && (info.Name() == "build" || info.Name() == "template") {
+				fmt.Fprintf(progress(), "Skipping \"%s\" folder\n", info.Name())
+				continue
+			}
+
+			srcPath := filepath.Join(src, info.Name())
+			srcAbs, err := filepath.Abs(srcPath)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case srcAbs == handlerAbs:
+				continue
+			case info.IsDir() && strings.HasPrefix(handlerAbs, srcAbs+string(filepath.Separator)):
+				if err := os.MkdirAll(filepath.Join(dest, info.Name()), info.Mode()); err != nil {
+					return err
+				}
+				if err := copyDir(srcPath, filepath.Join(dest, info.Name()), false); err != nil {
+					return err
+				}
+			default:
+				if err := CopyFiles(srcPath, filepath.Join(dest, info.Name())); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	return copyDir(filepath.Clean(buildContext), dest, true)
+}
+
 // appears to be unused???
 func dockerBuildFolder(functionName string, handler string, language string) string {
 	tempPath := fmt.Sprintf("./build/%s/", functionName)
-	fmt.Printf("Clearing temporary build folder: %s\n", tempPath)
+	fmt.Fprintf(progress(), "Clearing temporary build folder: %s\n", tempPath)
 
 	clearErr := os.RemoveAll(tempPath)
 	if clearErr != nil {
 		fmt.Printf("Error clearing temporary build folder: %s\n", tempPath)
 	}
 
-	fmt.Printf("Preparing: %s %s\n", handler+"/", tempPath)
+	fmt.Fprintf(progress(), "Preparing: %s %s\n", handler+"/", tempPath)
 
 	// Both Dockerfile and dockerfile are accepted
 	if language == "Dockerfile" {
@@ -297,7 +516,7 @@ func dockerBuildFolder(functionName string, handler string, language string) str
 	for _, info := range infos {
 		switch info.Name() {
 		case "build", "template":
-			fmt.Printf("Skipping \"%s\" folder\n", info.Name())
+			fmt.Fprintf(progress(), "Skipping \"%s\" folder\n", info.Name())
 			continue
 		default:
 			copyErr := CopyFiles(
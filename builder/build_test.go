@@ -2,6 +2,8 @@ package builder
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -114,6 +116,55 @@ func Test_getDockerBuildCommand_WithProxies(t *testing.T) {
 	}
 }
 
+func Test_getDockerBuildCommand_WithPlatforms(t *testing.T) {
+	cases := []struct {
+		platforms []string
+		push      bool
+		want      string
+	}{
+		{platforms: []string{"linux/arm64"}, want: "buildx build --platform linux/arm64 -t imagename:latest --load ."},
+		{platforms: []string{"linux/amd64", "linux/arm64"}, push: true, want: "buildx build --platform linux/amd64,linux/arm64 --no-cache -t imagename:latest --push ."},
+	}
+
+	for _, c := range cases {
+		dockerBuildVal := dockerBuild{
+			Image:       "imagename:latest",
+			NoCache:     c.push,
+			BuildArgMap: make(map[string]string),
+			Platforms:   c.platforms,
+			Push:        c.push,
+		}
+
+		command, args := getDockerBuildCommand(dockerBuildVal)
+
+		joined := strings.Join(args, " ")
+		if joined != c.want {
+			t.Errorf("getDockerBuildCommand want: \"%s\", got: \"%s\"", c.want, joined)
+		}
+
+		if command != "docker" {
+			t.Errorf("getDockerBuildCommand want command: \"docker\", got: \"%s\"", command)
+		}
+	}
+}
+
+func Test_getDockerBuildCommand_WithCacheFrom(t *testing.T) {
+	dockerBuildVal := dockerBuild{
+		Image:       "imagename:latest",
+		BuildArgMap: make(map[string]string),
+		CacheFrom:   []string{"registry/imagename:latest", "registry/base:latest"},
+	}
+
+	want := "build --cache-from registry/imagename:latest --cache-from registry/base:latest -t imagename:latest ."
+
+	_, args := getDockerBuildCommand(dockerBuildVal)
+
+	joined := strings.Join(args, " ")
+	if joined != want {
+		t.Errorf("getDockerBuildCommand want: \"%s\", got: \"%s\"", want, joined)
+	}
+}
+
 func Test_getDockerBuildCommand_WithBuildArg(t *testing.T) {
 	dockerBuildVal := dockerBuild{
 		Image:   "imagename:latest",
@@ -536,3 +587,75 @@ func Test_pathInScope(t *testing.T) {
 		})
 	}
 }
+
+func Test_handlerInContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "faas-cli-context")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	services := filepath.Join(dir, "services")
+	if err := os.MkdirAll(filepath.Join(services, "api", "fn"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handlerInContext(filepath.Join(services, "api", "fn"), services); err != nil {
+		t.Errorf("want a handler in the context to be accepted, got %s", err)
+	}
+	if err := handlerInContext(services, services); err != nil {
+		t.Errorf("want the context as the handler to be accepted, got %s", err)
+	}
+
+	want := fmt.Sprintf("the handler %s is outside of the build context %s", dir, services)
+	if err := handlerInContext(dir, services); err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+	if err := handlerInContext(services+"-other", services); err == nil {
+		t.Error("want an error for a folder next to the context with the same prefix")
+	}
+	if err := handlerInContext(services, filepath.Join(dir, "missing")); err == nil {
+		t.Error("want an error when the context does not exist")
+	}
+}
+
+func Test_copyBuildContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "faas-cli-context")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := []string{
+		"services/vendor/lib/lib.go",
+		"services/api/shared.go",
+		"services/api/fn/handler.go",
+		"services/build/fn/Dockerfile",
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(file), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dest := filepath.Join(dir, "out")
+	services := filepath.Join(dir, "services")
+	if err := copyBuildContext(services, filepath.Join(services, "api", "fn"), dest); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{"vendor/lib/lib.go", "api/shared.go"} {
+		if _, err := os.Stat(filepath.Join(dest, filepath.FromSlash(file))); err != nil {
+			t.Errorf("want %s to be copied, got %s", file, err)
+		}
+	}
+	for _, file := range []string{"api/fn", "build"} {
+		if _, err := os.Stat(filepath.Join(dest, filepath.FromSlash(file))); err == nil {
+			t.Errorf("want %s not to be copied", file)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	v1execute "github.com/alexellis/go-execute/pkg/v1"
+)
+
+// ImageDigest gives the image pinned to the digest it was pushed with, such
+// as repo@sha256:..., which docker records once the image is pushed
+func ImageDigest(image string) (string, error) {
+	task := v1execute.ExecTask{
+		Command: "docker",
+		Args:    []string{"image", "inspect", "--format", "{{json .RepoDigests}}", image},
+	}
+
+	res, err := task.Execute()
+	if err != nil {
+		return "", err
+	}
+	if res.ExitCode != 0 {
+		return "", fmt.Errorf("unable to inspect %s: %s", image, strings.TrimSpace(res.Stderr))
+	}
+
+	var repoDigests []string
+	if err := json.Unmarshal([]byte(res.Stdout), &repoDigests); err != nil {
+		return "", fmt.Errorf("unable to read the digests of %s: %s", image, err)
+	}
+	return pinnedImage(image, repoDigests)
+}
+
+// pinnedImage picks the digest of the image's repository out of the
+// RepoDigests of a local image, which has one for each registry it was
+// pushed to or pulled from
+func pinnedImage(image string, repoDigests []string) (string, error) {
+	repository := imageRepository(image)
+	for _, repoDigest := range repoDigests {
+		if i := strings.Index(repoDigest, "@"); i > -1 && imageRepository(repoDigest[:i]) == repository {
+			return repoDigest, nil
+		}
+	}
+	return "", fmt.Errorf("no digest was found for %s, it must be pushed first", image)
+}
+
+// imageRepository gives the image without its tag or digest, with the
+// docker.io prefixes which docker leaves out of RepoDigests removed
+func imageRepository(image string) string {
+	if i := strings.Index(image, "@"); i > -1 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	for _, prefix := range []string{"docker.io/", "index.docker.io/"} {
+		image = strings.TrimPrefix(image, prefix)
+	}
+	return strings.TrimPrefix(image, "library/")
+}
@@ -0,0 +1,42 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package builder
+
+import "testing"
+
+func Test_pinnedImage(t *testing.T) {
+	repoDigests := []string{
+		"registry.example.com:5000/team/figlet@sha256:1111",
+		"alexellis/figlet@sha256:2222",
+		"nginx@sha256:3333",
+	}
+
+	cases := []struct {
+		image string
+		want  string
+	}{
+		{"alexellis/figlet:latest", "alexellis/figlet@sha256:2222"},
+		{"docker.io/alexellis/figlet:0.1", "alexellis/figlet@sha256:2222"},
+		{"registry.example.com:5000/team/figlet:latest", "registry.example.com:5000/team/figlet@sha256:1111"},
+		{"registry.example.com:5000/team/figlet", "registry.example.com:5000/team/figlet@sha256:1111"},
+		{"docker.io/library/nginx:1.19", "nginx@sha256:3333"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.image, func(t *testing.T) {
+			got, err := pinnedImage(c.image, repoDigests)
+			if err != nil {
+				t.Fatalf("want no error, got %s", err)
+			}
+			if got != c.want {
+				t.Errorf("want %s, got %s", c.want, got)
+			}
+		})
+	}
+
+	_, err := pinnedImage("alexellis/nodeinfo:latest", repoDigests)
+	if err == nil || err.Error() != "no digest was found for alexellis/nodeinfo:latest, it must be pushed first" {
+		t.Errorf("want an error for an image which wasn't pushed, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package builder
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_prefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := newPrefixWriter(&out, "[fn1] ")
+
+	w.Write([]byte("Step 1/2 : FROM alpine\nStep 2/2"))
+	w.Write([]byte(" : RUN true\nSuccess"))
+
+	want := "[fn1] Step 1/2 : FROM alpine\n[fn1] Step 2/2 : RUN true\n"
+	if out.String() != want {
+		t.Errorf("want %q, got %q", want, out.String())
+	}
+
+	w.Flush()
+
+	want += "[fn1] Success\n"
+	if out.String() != want {
+		t.Errorf("want %q after Flush, got %q", want, out.String())
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package builder
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	v1execute "github.com/alexellis/go-execute/pkg/v1"
+)
+
+// SBOMFolder is where the SBOM of each image is written
+const SBOMFolder = "./build/sbom/"
+
+// DefaultSBOMFormat is the format of the SBOM when none is given
+const DefaultSBOMFormat = "spdx-json"
+
+// sbomExtensions gives the file extension for each format syft can write
+var sbomExtensions = map[string]string{
+	"spdx-json":      "spdx.json",
+	"cyclonedx-json": "cdx.json",
+}
+
+// sbomFormat is the format of the SBOM written after each build, no SBOM is
+// written when it is empty, see SetSBOM
+var sbomFormat string
+
+// sbomAttest is true when docker buildx attaches the SBOM to the image as
+// it is pushed, in place of running syft
+var sbomAttest bool
+
+// SetSBOM writes an SBOM in the format after each image is built, or has
+// docker buildx attach one to each image it pushes when attest is true
+func SetSBOM(format string, attest bool) {
+	sbomFormat = format
+	sbomAttest = attest
+}
+
+// SBOMFormats gives the formats which can be given to SetSBOM
+func SBOMFormats() []string {
+	formats := make([]string, 0, len(sbomExtensions))
+	for format := range sbomExtensions {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// CheckSBOM gives whether the SBOM should be attached by docker buildx,
+// which is only used when syft is not installed and buildx pushes the image,
+// or an error explaining how to install syft when neither can be used
+func CheckSBOM(format string, platforms []string, push bool) (bool, error) {
+	if _, ok := sbomExtensions[format]; !ok {
+		return false, fmt.Errorf("the SBOM format %s is not supported, use one of: %v", format, SBOMFormats())
+	}
+
+	if _, err := exec.LookPath("syft"); err == nil {
+		return false, nil
+	}
+
+	// buildkit writes its SBOM attestation in SPDX
+	if len(platforms) > 0 && push && format == DefaultSBOMFormat {
+		return true, nil
+	}
+
+	return false, fmt.Errorf(`syft is needed to write an SBOM with --sbom, but it was not found in the PATH.
+Install it from https://github.com/anchore/syft#installation, or run
+"faas-cli up --platforms" with the default --sbom-format to have docker buildx
+attach an SPDX SBOM to each image as it is pushed`)
+}
+
+// SBOMPath gives the file the SBOM of a function is written to
+func SBOMPath(functionName string) string {
+	return filepath.Join(SBOMFolder, fmt.Sprintf("%s.%s", functionName, sbomExtensions[sbomFormat]))
+}
+
+// writeSBOM runs syft against the image which was built, an image which
+// buildx pushed is read from the registry as it is not in the local cache
+func writeSBOM(imageName string, functionName string, pushed bool) error {
+	source := "docker:" + imageName
+	if pushed {
+		source = "registry:" + imageName
+	}
+
+	if err := os.MkdirAll(SBOMFolder, 0700); err != nil {
+		return err
+	}
+
+	path := SBOMPath(functionName)
+	task := v1execute.ExecTask{
+		Command: "syft",
+		Args:    []string{source, "-o", fmt.Sprintf("%s=%s", sbomFormat, path)},
+	}
+
+	res, err := task.Execute()
+	if err != nil {
+		return fmt.Errorf("unable to write the SBOM of %s: %s", imageName, err)
+	}
+	if res.ExitCode != 0 {
+		return fmt.Errorf("unable to write the SBOM of %s: %s", imageName, res.Stderr)
+	}
+
+	fmt.Printf("SBOM: %s written to %s.\n", imageName, path)
+	return nil
+}
@@ -0,0 +1,60 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_getDockerBuildCommand_WithSBOMAttest(t *testing.T) {
+	dockerBuildVal := dockerBuild{
+		Image:       "imagename:latest",
+		BuildArgMap: make(map[string]string),
+		Platforms:   []string{"linux/amd64", "linux/arm64"},
+		Push:        true,
+		SBOMAttest:  true,
+	}
+
+	want := "buildx build --platform linux/amd64,linux/arm64 -t imagename:latest --attest type=sbom --push ."
+
+	_, args := getDockerBuildCommand(dockerBuildVal)
+
+	joined := strings.Join(args, " ")
+	if joined != want {
+		t.Errorf("getDockerBuildCommand want: \"%s\", got: \"%s\"", want, joined)
+	}
+}
+
+func Test_CheckSBOM(t *testing.T) {
+	if _, err := CheckSBOM("xml", nil, false); err == nil || !strings.Contains(err.Error(), "the SBOM format xml is not supported") {
+		t.Errorf("want an error for an unknown format, got %v", err)
+	}
+
+	// Hide syft, if it is installed
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", "")
+	defer os.Setenv("PATH", path)
+
+	if _, err := CheckSBOM(DefaultSBOMFormat, nil, false); err == nil || !strings.Contains(err.Error(), "syft is needed") {
+		t.Errorf("want an error explaining how to install syft, got %v", err)
+	}
+
+	attest, err := CheckSBOM(DefaultSBOMFormat, []string{"linux/amd64"}, true)
+	if err != nil || !attest {
+		t.Errorf("want buildx to attach the SBOM when pushing, got %t, %v", attest, err)
+	}
+
+	if _, err := CheckSBOM("cyclonedx-json", []string{"linux/amd64"}, true); err == nil {
+		t.Error("want an error as buildx only writes SPDX")
+	}
+}
+
+func Test_SBOMPath(t *testing.T) {
+	defer SetSBOM("", false)
+
+	SetSBOM("cyclonedx-json", false)
+	if got, want := SBOMPath("figlet"), filepath.Join("build", "sbom", "figlet.cdx.json"); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
@@ -4,24 +4,33 @@
 package commands
 
 import (
-	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"html"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/proxy"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+// browserEnvironment overrides the command used to open the browser for auth
+const browserEnvironment = "FAAS_BROWSER"
+
 var (
 	scope         string
 	authURL       string
@@ -32,6 +41,14 @@ var (
 	grant         string
 	clientSecret  string
 	redirectHost  string
+	noSave        bool
+	tokenURL      string
+	strictPort    bool
+	deviceCodeURL string
+	authOutput    string
+	browser       string
+	authTimeout   time.Duration
+	callbackTLS   bool
 )
 
 func init() {
@@ -39,13 +56,21 @@ func init() {
 	authCmd.Flags().StringVar(&authURL, "auth-url", "", "OAuth2 Authorize URL i.e. http://idp/oauth/authorize")
 	authCmd.Flags().StringVar(&clientID, "client-id", "", "OAuth2 client_id")
 	authCmd.Flags().IntVar(&listenPort, "listen-port", 31111, "OAuth2 local port for receiving cookie")
+	authCmd.Flags().BoolVar(&strictPort, "strict-port", false, "Fail instead of picking a free port when --listen-port is in use")
 	authCmd.Flags().StringVar(&audience, "audience", "", "OAuth2 audience")
 	authCmd.Flags().BoolVar(&launchBrowser, "launch-browser", true, "Launch browser for OAuth2 redirect")
+	authCmd.Flags().StringVar(&browser, "browser", "", "Command to launch the browser with, %s is replaced by the URL i.e. \"wslview %s\", overrides "+browserEnvironment)
+	authCmd.Flags().BoolVar(&callbackTLS, "callback-tls", false, "Serve the OAuth2 callback over https with a temporary self-signed certificate, the https loopback redirect URI must still be registered with your IdP")
 	authCmd.Flags().StringVar(&redirectHost, "redirect-host", "http://127.0.0.1", "Host for OAuth2 redirection in the implicit flow including URL scheme")
 
 	authCmd.Flags().StringVar(&scope, "scope", "openid profile", "scope for OAuth2 flow - i.e. \"openid profile\"")
-	authCmd.Flags().StringVar(&grant, "grant", "implicit", "grant for OAuth2 flow - either implicit, implicit-id or client_credentials")
-	authCmd.Flags().StringVar(&clientSecret, "client-secret", "", "OAuth2 client_secret, for use with client_credentials grant")
+	authCmd.Flags().StringVar(&grant, "grant", "implicit", "grant for OAuth2 flow - either implicit, implicit-id, authorization_code, device_code or client_credentials")
+	authCmd.Flags().StringVar(&tokenURL, "token-url", "", "OAuth2 Token URL i.e. http://idp/oauth/token, for use with authorization_code, device_code and client_credentials grants")
+	authCmd.Flags().StringVar(&deviceCodeURL, "device-code-url", "", "OAuth2 Device Authorization URL i.e. http://idp/oauth/device/code, for use with device_code grant")
+	authCmd.Flags().StringVar(&clientSecret, "client-secret", "", "OAuth2 client_secret, for use with client_credentials grant, or set "+clientSecretEnvironment)
+	authCmd.Flags().DurationVar(&authTimeout, "callback-timeout", 2*time.Minute, "Timeout for the browser login to complete for the implicit and authorization_code grants")
+	authCmd.Flags().BoolVar(&noSave, "no-save", false, "Print the token without saving it to the config file")
+	authCmd.Flags().StringVarP(&authOutput, "output", "o", "", "Output format for the token, leave blank for text or use json")
 
 	faasCmd.AddCommand(authCmd)
 }
@@ -53,21 +78,51 @@ func init() {
 var authCmd = &cobra.Command{
 	Use: `auth --auth-url AUTH_URL | --client-id CLIENT_ID --scope SCOPE
   [--audience AUDIENCE]
-  [--launch-browser LAUNCH_BROWSER]
+  [--launch-browser LAUNCH_BROWSER] [--browser BROWSER]
+  [--listen-port PORT] [--strict-port] [--callback-tls]
   [--client-secret]
-  [--grant GRANT]`,
+  [--grant GRANT]
+  [--token-url TOKEN_URL]
+  [--device-code-url DEVICE_CODE_URL]
+  [--no-save] [--callback-timeout TIMEOUT]
+  [--output json]`,
 	Short: "Obtain a token for your OpenFaaS gateway",
 	Long:  "Authenticate to an OpenFaaS gateway using OAuth2.",
 	Example: `  faas-cli auth --client-id my-id --auth-url https://tenant.auth0.com/authorize --scope "oidc profile" --audience my-id
-  faas-cli auth --grant=client_credentials --client-id=id --client-secret=secret --auth-url=https://tenant.auth0.com/token`,
+  faas-cli auth --grant=authorization_code --client-id=my-id --auth-url=https://tenant.auth0.com/authorize --token-url=https://tenant.auth0.com/oauth/token
+  faas-cli auth --grant=device_code --client-id=my-id --device-code-url=https://tenant.auth0.com/oauth/device/code --token-url=https://tenant.auth0.com/oauth/token
+  TOKEN=$(faas-cli auth --client-id my-id --auth-url https://tenant.auth0.com/authorize --output json | jq -r .access_token)
+  faas-cli auth --grant=client_credentials --client-id=id --client-secret=secret --token-url=https://tenant.auth0.com/oauth/token`,
 	RunE:    runAuth,
 	PreRunE: preRunAuth,
 }
 
 func preRunAuth(cmd *cobra.Command, args []string) error {
-	return checkValues(authURL,
+	if len(authOutput) > 0 && authOutput != "json" {
+		return fmt.Errorf("--output must be blank or json, not: %s", authOutput)
+	}
+
+	if grant == "device_code" {
+		return checkDeviceValues(deviceCodeURL, tokenURL, clientID)
+	}
+
+	if grant == "client_credentials" {
+		if len(clientSecret) == 0 {
+			clientSecret = os.Getenv(clientSecretEnvironment)
+		}
+		return checkClientCredentialsValues(tokenURL, clientID, clientSecret)
+	}
+
+	if err := checkValues(authURL,
 		clientID,
-	)
+	); err != nil {
+		return err
+	}
+
+	if grant == "authorization_code" {
+		return checkTokenURL(tokenURL, grant)
+	}
+	return nil
 }
 
 func checkValues(authURL, clientID string) error {
@@ -91,11 +146,28 @@ func checkValues(authURL, clientID string) error {
 	return nil
 }
 
+func checkTokenURL(tokenURL, grant string) error {
+	if len(tokenURL) == 0 {
+		return fmt.Errorf("--token-url is required for the %s grant", grant)
+	}
+
+	u, err := url.Parse(tokenURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("--token-url is an invalid URL: %s", tokenURL)
+	}
+
+	return nil
+}
+
 func runAuth(cmd *cobra.Command, args []string) error {
 	if grant == "implicit" {
 		return authImplicit("token")
 	} else if grant == "implicit-id" {
 		return authImplicit("id_token")
+	} else if grant == "authorization_code" {
+		return authCode()
+	} else if grant == "device_code" {
+		return authDeviceCode()
 	} else if grant == "client_credentials" {
 		return authClientCredentials()
 	}
@@ -104,63 +176,145 @@ func runAuth(cmd *cobra.Command, args []string) error {
 
 func authImplicit(grant string) error {
 
-	context, cancel := context.WithCancel(context.TODO())
-	defer cancel()
+	tokenKey := "access_token"
+	if grant == "id_token" {
+		tokenKey = "id_token"
+	}
 
-	server := &http.Server{
-		Addr:           fmt.Sprintf(":%d", listenPort),
-		ReadTimeout:    5 * time.Second,
-		WriteTimeout:   5 * time.Second,
-		MaxHeaderBytes: 1 << 20, // Max header of 1MB
-		Handler:        http.HandlerFunc(makeCallbackHandler(cancel)),
+	state, err := makeRandomValue()
+	if err != nil {
+		return errors.Wrap(err, "unable to generate state")
 	}
 
-	go func() {
-		fmt.Printf("Starting local token server on port %d\n", listenPort)
-		if err := server.ListenAndServe(); err != nil {
-			panic(err)
-		}
+	nonce, err := makeRandomValue()
+	if err != nil {
+		return errors.Wrap(err, "unable to generate nonce")
+	}
 
-		select {
-		case <-context.Done():
-			break
-		}
-	}()
+	results := make(chan callbackResult, 1)
+
+	tlsConfig, err := makeCallbackTLSConfig(callbackTLS)
+	if err != nil {
+		return err
+	}
 
-	defer server.Shutdown(context)
+	server, port, err := startCallbackServer(listenPort, strictPort, tlsConfig, makeCallbackHandler(tokenKey, state, nonce, results), results)
+	if err != nil {
+		return err
+	}
+	defer server.Shutdown(context.Background())
 
 	q := url.Values{}
 	q.Add("client_id", clientID)
 
-	q.Add("state", fmt.Sprintf("%d", time.Now().UnixNano()))
-	q.Add("nonce", fmt.Sprintf("%d", time.Now().UnixNano()))
+	q.Add("state", state)
+	q.Add("nonce", nonce)
 	q.Add("response_type", grant)
 	q.Add("scope", scope)
 	q.Add("&response_mode", "fragment")
 	q.Add("audience", audience)
 
-	uri, err := makeRedirectURI(redirectHost, listenPort)
+	uri, err := makeRedirectURI(callbackRedirectHost(redirectHost, callbackTLS), port)
 	if err != nil {
 		return err
 	}
 
 	q.Add("redirect_uri", uri.String())
 
-	authURLVal, _ := url.Parse(authURL)
-	authURLVal.RawQuery = q.Encode()
+	if err := openAuthURL(authURL, q); err != nil {
+		return err
+	}
+
+	result, err := waitForCallback(results, authTimeout)
+	if err != nil {
+		return err
+	}
+
+	if result.err != nil {
+		return result.err
+	}
+
+	if len(result.value) == 0 {
+		return fmt.Errorf("unable to detect a valid %s in URL fragment. Check your credentials or contact your administrator", tokenKey)
+	}
+
+	return saveAuthToken(AuthResult{
+		AccessToken: result.value,
+		TokenType:   result.tokenType,
+		ExpiresIn:   result.expiresIn,
+		Gateway:     gateway,
+	})
+}
+
+// startCallbackServer binds to the given port and serves handler to receive
+// the browser redirect from the IdP. When the port is in use, a free port is
+// picked by the OS unless strict is set, so the bound port is returned.
+// When tlsConfig is not nil the callback is served over https.
+// Errors from serving are sent to results.
+func startCallbackServer(port int, strict bool, tlsConfig *tls.Config, handler http.HandlerFunc, results chan<- callbackResult) (*http.Server, int, error) {
+	server := &http.Server{
+		ReadTimeout:    5 * time.Second,
+		WriteTimeout:   5 * time.Second,
+		MaxHeaderBytes: 1 << 20, // Max header of 1MB
+		Handler:        handler,
+		TLSConfig:      tlsConfig,
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil && isAddrInUse(err) && !strict {
+		fmt.Fprintf(authInfo(), "Port %d is in use, picking a free port instead\n", port)
+		listener, err = net.Listen("tcp", ":0")
+	}
 
-	browserBase := authURLVal
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to start local token server on port %d: %s", port, err.Error())
+	}
+
+	boundPort := listener.Addr().(*net.TCPAddr).Port
+
+	go func() {
+		fmt.Fprintf(authInfo(), "Starting local token server on port %d\n", boundPort)
+		var err error
+		if tlsConfig != nil {
+			err = server.ServeTLS(listener, "", "")
+		} else {
+			err = server.Serve(listener)
+		}
+
+		if err != nil && err != http.ErrServerClosed {
+			sendCallbackResult(results, callbackResult{err: fmt.Errorf("local token server failed: %s", err.Error())})
+		}
+	}()
+
+	return server, boundPort, nil
+}
+
+// isAddrInUse returns true when a listen error was caused by the port being bound already
+func isAddrInUse(err error) bool {
+	if opErr, ok := err.(*net.OpError); ok {
+		if sysErr, ok := opErr.Err.(*os.SyscallError); ok {
+			return sysErr.Err == syscall.EADDRINUSE
+		}
+	}
+	return false
+}
+
+// openAuthURL adds the query to the authorization URL and prints it, then
+// launches the browser unless --launch-browser=false was given.
+func openAuthURL(authURL string, q url.Values) error {
+	authURLVal, err := url.Parse(authURL)
+	if err != nil {
+		return err
+	}
+	authURLVal.RawQuery = q.Encode()
 
-	fmt.Printf("Launching browser: %s\n", browserBase)
+	fmt.Fprintf(authInfo(), "Launching browser: %s\n", authURLVal)
 	if launchBrowser {
-		err := launchURL(browserBase.String())
+		err := launchURL(authURLVal.String())
 		if err != nil {
 			return errors.Wrap(err, "unable to launch browser")
 		}
 	}
-
-	<-context.Done()
-
 	return nil
 }
 
@@ -178,56 +332,143 @@ func makeRedirectURI(host string, port int) (*url.URL, error) {
 	return res, err
 }
 
-func authClientCredentials() error {
+// AuthResult is printed to stdout after a successful auth with --output json
+type AuthResult struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Gateway     string `json:"gateway"`
+}
 
-	body := ClientCredentialsReq{
-		ClientID:     clientID,
-		ClientSecret: clientSecret,
-		Audience:     audience,
-		GrantType:    grant,
+// saveAuthToken stores the token for the gateway in the config file, unless
+// --no-save was given, and then prints an example of how to use it, or the
+// result as JSON for --output json.
+func saveAuthToken(result AuthResult) error {
+	if !noSave {
+		if err := config.UpdateAuthConfig(result.Gateway, result.AccessToken, config.Oauth2AuthType); err != nil {
+			return fmt.Errorf("error while saving authentication token: %s", err.Error())
+		}
+		fmt.Fprintln(authInfo(), "credentials saved for", result.Gateway)
 	}
 
-	bodyBytes, marshalErr := json.Marshal(body)
-	if marshalErr != nil {
-		return errors.Wrapf(marshalErr, "unable to unmarshal %s", string(bodyBytes))
+	if authOutput == "json" {
+		return printAuthResultJSON(result, time.Now())
 	}
 
-	buf := bytes.NewBuffer(bodyBytes)
-	req, _ := http.NewRequest(http.MethodPost, authURL, buf)
-	req.Header.Set("Content-Type", "application/json")
-	res, err := http.DefaultClient.Do(req)
+	// The example is the only place the token is printed when it isn't saved
+	if !quietOutput || noSave {
+		printExampleTokenUsage(result.Gateway, result.AccessToken)
+	}
 
-	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("cannot POST to %s", authURL))
+	if msg := tokenExpiryMessage(result.AccessToken, time.Now()); len(msg) > 0 {
+		fmt.Fprintln(authInfo(), msg)
 	}
-	if res.Body != nil {
-		defer res.Body.Close()
+	return nil
+}
 
-		tokenData, _ := ioutil.ReadAll(res.Body)
+// printAuthResultJSON fills in the token_type and expires_in when the IdP
+// left them out, using the exp claim for JWTs.
+func printAuthResultJSON(result AuthResult, now time.Time) error {
+	if len(result.TokenType) == 0 {
+		result.TokenType = "Bearer"
+	}
 
-		if res.StatusCode != http.StatusOK {
-			return fmt.Errorf("cannot authenticate, code: %d.\nResponse: %s", res.StatusCode, string(tokenData))
-		}
-		token := ClientCredentialsToken{}
-		tokenErr := json.Unmarshal(tokenData, &token)
-		if tokenErr != nil {
-			return errors.Wrapf(tokenErr, "unable to unmarshal token: %s", string(tokenData))
+	if result.ExpiresIn == 0 {
+		if expiry, err := tokenExpiry(result.AccessToken); err == nil && expiry.After(now) {
+			result.ExpiresIn = int(expiry.Sub(now).Seconds())
 		}
+	}
 
-		if err := config.UpdateAuthConfig(gateway, token.AccessToken, config.Oauth2AuthType); err != nil {
-			return err
-		}
-		fmt.Println("credentials saved for", gateway)
-		printExampleTokenUsage(gateway, token.AccessToken)
+	out, err := json.Marshal(result)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal auth result")
 	}
 
+	fmt.Println(string(out))
 	return nil
 }
 
-// launchURL opens a URL with the default browser for Linux, MacOS or Windows.
+// authInfo is where informational messages are written, stderr is used for
+// --output json so that stdout can be parsed, and they are discarded with
+// --quiet.
+func authInfo() io.Writer {
+	if quietOutput {
+		return ioutil.Discard
+	}
+	if authOutput == "json" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// authHTTPClient is used to exchange tokens with the identity provider, with
+// the client certificate and CAs given by --tls-cert, --tls-ca and --ca-bundle
+func authHTTPClient() *http.Client {
+	client := &http.Client{}
+	if tlsConfig := proxy.TLSClientConfig(false); tlsConfig != nil {
+		client.Transport = &http.Transport{
+			Proxy:           proxy.HTTPProxy,
+			TLSClientConfig: tlsConfig,
+		}
+	}
+	return client
+}
+
+// launchURL opens a URL with the command from --browser or FAAS_BROWSER, or
+// the default browser for Linux, MacOS or Windows when neither is set.
 func launchURL(serverURL string) error {
 	ctx := context.Background()
 	var command *exec.Cmd
+
+	if template := getBrowserCommand(browser, os.Getenv(browserEnvironment)); len(template) > 0 {
+		var err error
+		if command, err = makeBrowserCommand(ctx, template, serverURL); err != nil {
+			return err
+		}
+	} else {
+		command = makeDefaultBrowserCommand(ctx, serverURL)
+	}
+
+	command.Stdout = os.Stdout
+	command.Stdin = os.Stdin
+	command.Stderr = os.Stderr
+	return command.Run()
+}
+
+// getBrowserCommand gives the --browser flag priority over the environment variable
+func getBrowserCommand(flagValue, envValue string) string {
+	if len(flagValue) > 0 {
+		return flagValue
+	}
+	return envValue
+}
+
+// makeBrowserCommand runs the template without a shell with %s replaced by the
+// URL, the URL is appended as the last argument when there is no %s.
+func makeBrowserCommand(ctx context.Context, template, serverURL string) (*exec.Cmd, error) {
+	fields := strings.Fields(template)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("the browser command is empty, check --browser or %s", browserEnvironment)
+	}
+
+	if !strings.Contains(template, "%s") {
+		fields = append(fields, serverURL)
+	}
+
+	for i, field := range fields {
+		fields[i] = strings.Replace(field, "%s", serverURL, -1)
+	}
+
+	name, err := exec.LookPath(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("the browser command %q was not found in PATH, check --browser or %s", fields[0], browserEnvironment)
+	}
+
+	return exec.CommandContext(ctx, name, fields[1:]...), nil
+}
+
+func makeDefaultBrowserCommand(ctx context.Context, serverURL string) *exec.Cmd {
+	var command *exec.Cmd
 	switch runtime.GOOS {
 	case "linux":
 		command = exec.CommandContext(ctx, "sh", "-c", fmt.Sprintf(`xdg-open "%s"`, serverURL))
@@ -237,10 +478,7 @@ func launchURL(serverURL string) error {
 		escaped := strings.Replace(serverURL, "&", "^&", -1)
 		command = exec.CommandContext(ctx, "cmd", "/c", fmt.Sprintf(`start %s`, escaped))
 	}
-	command.Stdout = os.Stdout
-	command.Stdin = os.Stdin
-	command.Stderr = os.Stderr
-	return command.Run()
+	return command
 }
 
 func printExampleTokenUsage(gateway, token string) {
@@ -254,30 +492,64 @@ func printExampleTokenUsage(gateway, token string) {
 
 }
 
-func makeCallbackHandler(cancel context.CancelFunc) func(w http.ResponseWriter, r *http.Request) {
+// callbackResult is sent back from the callback handler, holding either the
+// value read from the redirect or the reason it was rejected.
+type callbackResult struct {
+	value     string
+	tokenType string
+	expiresIn int
+	err       error
+}
+
+// waitForCallback blocks until the first callback is received, or returns an
+// error once timeout has passed. The caller shuts down the local server.
+func waitForCallback(results <-chan callbackResult, timeout time.Duration) (callbackResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	select {
+	case result := <-results:
+		return result, nil
+	case <-ctx.Done():
+		return callbackResult{}, fmt.Errorf("authentication timed out after %s", timeout)
+	}
+}
+
+// sendCallbackResult only accepts the first callback, any later requests are dropped
+func sendCallbackResult(results chan<- callbackResult, result callbackResult) {
+	select {
+	case results <- result:
+	default:
+	}
+}
+
+// makeCallbackHandler serves the page which captures the URL fragment from the
+// browser redirect, then sends the value of tokenKey back over the results channel.
+// The state, and the nonce of any id_token, must match the values sent to the IdP.
+func makeCallbackHandler(tokenKey, state, nonce string, results chan<- callbackResult) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		if v := r.URL.Query().Get("fragment"); len(v) > 0 {
 			q, err := url.ParseQuery(v)
+
+			expiresIn, _ := strconv.Atoi(q.Get("expires_in"))
+			result := callbackResult{value: q.Get(tokenKey), tokenType: q.Get("token_type"), expiresIn: expiresIn}
 			if err != nil {
-				panic(errors.Wrap(err, "unable to parse fragment response from browser redirect"))
+				result = callbackResult{err: errors.Wrap(err, "unable to parse fragment response from browser redirect")}
+			} else if q.Get("state") != state {
+				result = callbackResult{err: fmt.Errorf("the state returned in the redirect did not match, the token was rejected")}
+			} else if idToken := q.Get("id_token"); len(idToken) > 0 {
+				if err := checkNonce(idToken, nonce); err != nil {
+					result = callbackResult{err: err}
+				}
 			}
 
-			// log.Println("QueryString:", q)
-
-			key := "id_token"
-			if token := q.Get(key); len(token) > 0 {
-
-				if err := config.UpdateAuthConfig(gateway, token, config.Oauth2AuthType); err != nil {
-					fmt.Printf("error while saving authentication token: %s", err.Error())
-				}
-				fmt.Println("credentials saved for", gateway)
-				printExampleTokenUsage(gateway, token)
-			} else {
-				fmt.Printf("Unable to detect a valid %s in URL fragment. Check your credentials or contact your administrator.\n", key)
+			if result.err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(buildErrorPage(result.err)))
 			}
 
-			cancel()
+			sendCallbackResult(results, result)
 			return
 		}
 
@@ -298,6 +570,8 @@ func buildCaptureFragment() string {
 	xhttp.onreadystatechange = function() {
 		if (this.readyState == 4 && this.status == 200) {
 			console.log(xhttp.responseText)
+		} else if (this.readyState == 4) {
+			document.documentElement.innerHTML = xhttp.responseText
 		}
 	};
 	xhttp.open("GET", "/oauth2/callback?fragment="+document.location.hash.slice(1), true);
@@ -310,16 +584,14 @@ func buildCaptureFragment() string {
 </html>`
 }
 
-type ClientCredentialsReq struct {
-	ClientID     string `json:"client_id"`
-	ClientSecret string `json:"client_secret"`
-	Audience     string `json:"audience"`
-	GrantType    string `json:"grant_type"`
-}
-
-type ClientCredentialsToken struct {
-	AccessToken string `json:"access_token"`
-	Scope       string `json:"scope"`
-	ExpiresIn   int    `json:"expires_in"`
-	TokenType   string `json:"token_type"`
+func buildErrorPage(err error) string {
+	return fmt.Sprintf(`
+<html>
+<head>
+<title>OpenFaaS CLI Authorization flow</title>
+</head>
+<body>
+ Authorization flow failed: %s
+</body>
+</html>`, html.EscapeString(err.Error()))
 }
@@ -0,0 +1,60 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// clientSecretEnvironment is read for the client_secret when --client-secret is not given
+const clientSecretEnvironment = "FAAS_CLIENT_SECRET"
+
+func checkClientCredentialsValues(tokenURL, clientID, clientSecret string) error {
+	if err := checkTokenURL(tokenURL, "client_credentials"); err != nil {
+		return err
+	}
+
+	if len(clientID) == 0 {
+		return fmt.Errorf("--client-id is required")
+	}
+
+	if len(clientSecret) == 0 {
+		return fmt.Errorf("--client-secret or %s is required for the client_credentials grant", clientSecretEnvironment)
+	}
+
+	return nil
+}
+
+// authClientCredentials obtains a token for a service account without any user
+// interaction, so no local server or browser is started.
+func authClientCredentials() error {
+	token, err := requestClientCredentialsToken(context.Background(), tokenURL, clientID, clientSecret, audience, scope)
+	if err != nil {
+		return err
+	}
+
+	return saveAuthToken(AuthResult{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		ExpiresIn:   token.ExpiresIn,
+		Gateway:     gateway,
+	})
+}
+
+func requestClientCredentialsToken(ctx context.Context, tokenURL, clientID, clientSecret, audience, scope string) (AuthorizationCodeToken, error) {
+	form := url.Values{}
+	form.Add("grant_type", "client_credentials")
+	form.Add("client_id", clientID)
+	form.Add("client_secret", clientSecret)
+	if len(audience) > 0 {
+		form.Add("audience", audience)
+	}
+	if len(scope) > 0 {
+		form.Add("scope", scope)
+	}
+
+	return postTokenForm(ctx, tokenURL, form)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_requestClientCredentialsToken(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/x-www-form-urlencoded" {
+			t.Errorf("Content-Type: want form encoded, got %q", got)
+		}
+
+		r.ParseForm()
+		want := map[string]string{
+			"grant_type":    "client_credentials",
+			"client_id":     "my-id",
+			"client_secret": "my-secret",
+			"audience":      "my-api",
+			"scope":         "functions:read",
+		}
+		for key, value := range want {
+			if got := r.PostForm.Get(key); got != value {
+				t.Errorf("%s: want %q, got %q", key, value, got)
+			}
+		}
+
+		json.NewEncoder(w).Encode(AuthorizationCodeToken{AccessToken: "access", TokenType: "Bearer", ExpiresIn: 3600})
+	}))
+	defer s.Close()
+
+	token, err := requestClientCredentialsToken(context.Background(), s.URL, "my-id", "my-secret", "my-api", "functions:read")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.AccessToken != "access" || token.ExpiresIn != 3600 {
+		t.Errorf("unexpected token: %+v", token)
+	}
+}
+
+func Test_checkClientCredentialsValues(t *testing.T) {
+	testCases := []struct {
+		title        string
+		tokenURL     string
+		clientID     string
+		clientSecret string
+		wantErr      string
+	}{
+		{title: "valid", tokenURL: "https://idp/oauth/token", clientID: "id", clientSecret: "secret"},
+		{title: "missing token URL", clientID: "id", clientSecret: "secret", wantErr: "--token-url is required for the client_credentials grant"},
+		{title: "missing client ID", tokenURL: "https://idp/oauth/token", clientSecret: "secret", wantErr: "--client-id is required"},
+		{title: "missing secret", tokenURL: "https://idp/oauth/token", clientID: "id", wantErr: "--client-secret or FAAS_CLIENT_SECRET is required for the client_credentials grant"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.title, func(t *testing.T) {
+			err := checkClientCredentialsValues(testCase.tokenURL, testCase.clientID, testCase.clientSecret)
+
+			if len(testCase.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("want no error, got %s", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != testCase.wantErr {
+				t.Errorf("want error %q, got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
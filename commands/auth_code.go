@@ -0,0 +1,239 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/pkg/errors"
+)
+
+// randomValueBytes gives a 43 character value once base64url encoded, which is
+// the minimum length for a code_verifier allowed by RFC 7636
+const randomValueBytes = 32
+
+// AuthorizationCodeToken is returned by the token endpoint when exchanging a code
+type AuthorizationCodeToken struct {
+	AccessToken  string `json:"access_token"`
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+	ExpiresIn    int    `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+}
+
+// authCode runs the authorization code flow with a PKCE code challenge, the
+// code received on the callback is exchanged for a token at --token-url
+func authCode() error {
+	verifier, err := makeRandomValue()
+	if err != nil {
+		return errors.Wrap(err, "unable to generate code_verifier")
+	}
+
+	state, err := makeRandomValue()
+	if err != nil {
+		return errors.Wrap(err, "unable to generate state")
+	}
+
+	results := make(chan callbackResult, 1)
+
+	tlsConfig, err := makeCallbackTLSConfig(callbackTLS)
+	if err != nil {
+		return err
+	}
+
+	server, port, err := startCallbackServer(listenPort, strictPort, tlsConfig, makeCodeCallbackHandler(state, results), results)
+	if err != nil {
+		return err
+	}
+	defer server.Shutdown(context.Background())
+
+	uri, err := makeRedirectURI(callbackRedirectHost(redirectHost, callbackTLS), port)
+	if err != nil {
+		return err
+	}
+
+	q := url.Values{}
+	q.Add("client_id", clientID)
+	q.Add("state", state)
+	q.Add("response_type", "code")
+	q.Add("scope", scope)
+	q.Add("audience", audience)
+	q.Add("redirect_uri", uri.String())
+	q.Add("code_challenge", makeCodeChallenge(verifier))
+	q.Add("code_challenge_method", "S256")
+
+	if err := openAuthURL(authURL, q); err != nil {
+		return err
+	}
+
+	result, err := waitForCallback(results, authTimeout)
+	if err != nil {
+		return err
+	}
+
+	if result.err != nil {
+		return result.err
+	}
+
+	code := result.value
+	if len(code) == 0 {
+		return fmt.Errorf("unable to detect a valid code in the redirect. Check your credentials or contact your administrator")
+	}
+
+	token, err := exchangeAuthCode(tokenURL, clientID, code, uri.String(), verifier)
+	if err != nil {
+		return err
+	}
+
+	return saveTokenWithRefresh(token)
+}
+
+// saveTokenWithRefresh saves the access token and any refresh token, along
+// with the --token-url and --client-id needed to redeem it later.
+func saveTokenWithRefresh(token AuthorizationCodeToken) error {
+	if err := saveAuthToken(AuthResult{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		ExpiresIn:   token.ExpiresIn,
+		Gateway:     gateway,
+	}); err != nil {
+		return err
+	}
+
+	if !noSave && len(token.RefreshToken) > 0 {
+		if err := config.UpdateRefreshToken(gateway, token.RefreshToken, tokenURL, clientID); err != nil {
+			return fmt.Errorf("error while saving refresh token: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// makeRandomValue generates a random value made up of unreserved characters for
+// use as a code_verifier, state or nonce
+func makeRandomValue() (string, error) {
+	b := make([]byte, randomValueBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// makeCodeChallenge derives the S256 code_challenge for a code_verifier
+func makeCodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// makeCodeCallbackHandler sends the code from the query-string of the browser
+// redirect back over the results channel, as long as the state matches.
+func makeCodeCallbackHandler(state string, results chan<- callbackResult) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			defer r.Body.Close()
+		}
+
+		if r.URL.Path != "/oauth/callback" {
+			http.NotFound(w, r)
+			return
+		}
+
+		q := r.URL.Query()
+		if q.Get("state") != state {
+			err := fmt.Errorf("the state returned in the redirect did not match, the code was rejected")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(buildErrorPage(err)))
+			sendCallbackResult(results, callbackResult{err: err})
+			return
+		}
+
+		sendCallbackResult(results, callbackResult{value: q.Get("code")})
+		w.Write([]byte(buildCompletePage()))
+	}
+}
+
+// exchangeAuthCode swaps the code for a token at the token endpoint of the IdP
+func exchangeAuthCode(tokenURL, clientID, code, redirectURI, codeVerifier string) (AuthorizationCodeToken, error) {
+	form := url.Values{}
+	form.Add("grant_type", "authorization_code")
+	form.Add("client_id", clientID)
+	form.Add("code", code)
+	form.Add("redirect_uri", redirectURI)
+	form.Add("code_verifier", codeVerifier)
+
+	return postTokenForm(context.Background(), tokenURL, form)
+}
+
+// postTokenForm POSTs the form to the token endpoint and parses the token in the response
+func postTokenForm(ctx context.Context, tokenURL string, form url.Values) (AuthorizationCodeToken, error) {
+	token := AuthorizationCodeToken{}
+
+	req, err := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return token, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := authHTTPClient().Do(req)
+	if err != nil {
+		return token, errors.Wrap(err, fmt.Sprintf("cannot POST to %s", tokenURL))
+	}
+
+	if res.Body != nil {
+		defer res.Body.Close()
+	}
+
+	tokenData, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		tokenErr := &tokenEndpointError{StatusCode: res.StatusCode, Body: string(tokenData)}
+		json.Unmarshal(tokenData, tokenErr)
+		return token, tokenErr
+	}
+
+	if err := json.Unmarshal(tokenData, &token); err != nil {
+		return token, errors.Wrapf(err, "unable to unmarshal token: %s", string(tokenData))
+	}
+
+	if len(token.AccessToken) == 0 {
+		return token, fmt.Errorf("no access_token found in response from %s", tokenURL)
+	}
+
+	return token, nil
+}
+
+// tokenEndpointError is returned when the token endpoint responds with an error,
+// Code holds the OAuth2 error code from the body when one was given.
+type tokenEndpointError struct {
+	StatusCode  int    `json:"-"`
+	Body        string `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *tokenEndpointError) Error() string {
+	return fmt.Sprintf("cannot obtain token, code: %d.\nResponse: %s", e.StatusCode, e.Body)
+}
+
+func buildCompletePage() string {
+	return `
+<html>
+<head>
+<title>OpenFaaS CLI Authorization flow</title>
+</head>
+<body>
+ Authorization flow complete. Please close this browser window.
+</body>
+</html>`
+}
@@ -0,0 +1,113 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func Test_makeRandomValue(t *testing.T) {
+	verifier, err := makeRandomValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unreserved := regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+	if !unreserved.MatchString(verifier) {
+		t.Errorf("code_verifier %q is not 43-128 unreserved characters", verifier)
+	}
+}
+
+func Test_makeCodeChallenge(t *testing.T) {
+	// Example from RFC 7636 Appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	got := makeCodeChallenge(verifier)
+	if got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func Test_makeCodeCallbackHandler_SendsCode(t *testing.T) {
+	results := make(chan callbackResult, 1)
+	handler := makeCodeCallbackHandler("1", results)
+
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/oauth/callback?code=abc&state=1", nil))
+
+	select {
+	case got := <-results:
+		if got.value != "abc" {
+			t.Errorf("want %q, got %q", "abc", got.value)
+		}
+	default:
+		t.Fatal("no code was sent by the callback handler")
+	}
+}
+
+func Test_makeCodeCallbackHandler_RejectsMismatchedState(t *testing.T) {
+	results := make(chan callbackResult, 1)
+	handler := makeCodeCallbackHandler("1", results)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/oauth/callback?code=abc&state=2", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("want status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	got := <-results
+	if got.err == nil || got.value == "abc" {
+		t.Errorf("code with mismatched state must not be accepted")
+	}
+}
+
+func Test_exchangeAuthCode(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]string{
+			"grant_type":    "authorization_code",
+			"client_id":     "my-id",
+			"code":          "abc",
+			"redirect_uri":  "http://127.0.0.1:31111/oauth/callback",
+			"code_verifier": "verifier",
+		}
+		for k, v := range want {
+			if got := r.PostForm.Get(k); got != v {
+				t.Errorf("%s: want %q, got %q", k, v, got)
+			}
+		}
+
+		json.NewEncoder(w).Encode(AuthorizationCodeToken{AccessToken: "token"})
+	}))
+	defer s.Close()
+
+	token, err := exchangeAuthCode(s.URL, "my-id", "abc", "http://127.0.0.1:31111/oauth/callback", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.AccessToken != "token" {
+		t.Errorf("want %q, got %q", "token", token.AccessToken)
+	}
+}
+
+func Test_exchangeAuthCode_BadStatus(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer s.Close()
+
+	_, err := exchangeAuthCode(s.URL, "my-id", "abc", "http://127.0.0.1:31111/oauth/callback", "verifier")
+	if err == nil {
+		t.Fatal("want error for non-200 status")
+	}
+}
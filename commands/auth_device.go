@@ -0,0 +1,172 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	// defaultDeviceInterval is used when the IdP does not give a polling interval, as per RFC 8628
+	defaultDeviceInterval = 5 * time.Second
+	// slowDownIncrement is added to the polling interval on each slow_down response
+	slowDownIncrement = 5 * time.Second
+)
+
+var deviceSleep = time.Sleep
+
+// DeviceCodeResponse is returned by the device authorization endpoint
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+func checkDeviceValues(deviceCodeURL, tokenURL, clientID string) error {
+	if len(deviceCodeURL) == 0 {
+		return fmt.Errorf("--device-code-url is required for the device_code grant")
+	}
+
+	u, err := url.Parse(deviceCodeURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("--device-code-url is an invalid URL: %s", deviceCodeURL)
+	}
+
+	if err := checkTokenURL(tokenURL, "device_code"); err != nil {
+		return err
+	}
+
+	if len(clientID) == 0 {
+		return fmt.Errorf("--client-id is required")
+	}
+
+	return nil
+}
+
+// authDeviceCode runs the device authorization grant from RFC 8628, the user
+// approves the request on another device whilst the token endpoint is polled.
+func authDeviceCode() error {
+	ctx := context.Background()
+
+	device, err := requestDeviceCode(ctx, deviceCodeURL, clientID, scope, audience)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(authInfo(), "Visit %s and enter the code: %s\n", device.VerificationURI, device.UserCode)
+	if len(device.VerificationURIComplete) > 0 {
+		fmt.Fprintf(authInfo(), "Or open: %s\n", device.VerificationURIComplete)
+	}
+
+	token, err := pollDeviceToken(ctx, tokenURL, clientID, device)
+	if err != nil {
+		return err
+	}
+
+	return saveTokenWithRefresh(token)
+}
+
+// requestDeviceCode starts the flow by requesting a device and user code
+func requestDeviceCode(ctx context.Context, deviceCodeURL, clientID, scope, audience string) (DeviceCodeResponse, error) {
+	device := DeviceCodeResponse{}
+
+	form := url.Values{}
+	form.Add("client_id", clientID)
+	form.Add("scope", scope)
+	if len(audience) > 0 {
+		form.Add("audience", audience)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, deviceCodeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return device, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := authHTTPClient().Do(req)
+	if err != nil {
+		return device, errors.Wrap(err, fmt.Sprintf("cannot POST to %s", deviceCodeURL))
+	}
+
+	if res.Body != nil {
+		defer res.Body.Close()
+	}
+
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		return device, fmt.Errorf("cannot request device code, code: %d.\nResponse: %s", res.StatusCode, string(body))
+	}
+
+	if err := json.Unmarshal(body, &device); err != nil {
+		return device, errors.Wrapf(err, "unable to unmarshal device code: %s", string(body))
+	}
+
+	if len(device.DeviceCode) == 0 {
+		return device, fmt.Errorf("no device_code found in response from %s", deviceCodeURL)
+	}
+
+	return device, nil
+}
+
+// pollDeviceToken polls the token endpoint at the interval given by the IdP
+// until the user approves the request, or the device code expires.
+func pollDeviceToken(ctx context.Context, tokenURL, clientID string, device DeviceCodeResponse) (AuthorizationCodeToken, error) {
+	interval := defaultDeviceInterval
+	if device.Interval > 0 {
+		interval = time.Duration(device.Interval) * time.Second
+	}
+
+	expiresIn := time.Duration(device.ExpiresIn) * time.Second
+	deadline := time.Now().Add(expiresIn)
+
+	form := url.Values{}
+	form.Add("grant_type", deviceCodeGrantType)
+	form.Add("device_code", device.DeviceCode)
+	form.Add("client_id", clientID)
+
+	for {
+		deviceSleep(interval)
+
+		if device.ExpiresIn > 0 && time.Now().After(deadline) {
+			return AuthorizationCodeToken{}, fmt.Errorf("the device code expired after %s, run \"faas-cli auth\" again", expiresIn)
+		}
+
+		token, err := postTokenForm(ctx, tokenURL, form)
+		if err == nil {
+			return token, nil
+		}
+
+		tokenErr, ok := err.(*tokenEndpointError)
+		if !ok {
+			return token, err
+		}
+
+		switch tokenErr.Code {
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += slowDownIncrement
+		case "expired_token":
+			return token, fmt.Errorf("the device code expired after %s, run \"faas-cli auth\" again", expiresIn)
+		case "access_denied":
+			return token, fmt.Errorf("the authorization request was denied")
+		default:
+			return token, err
+		}
+	}
+}
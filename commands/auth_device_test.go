@@ -0,0 +1,143 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openfaas/faas-cli/proxy"
+)
+
+func Test_requestDeviceCode(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got := r.PostForm.Get("client_id"); got != "my-id" {
+			t.Errorf("client_id: want %q, got %q", "my-id", got)
+		}
+		json.NewEncoder(w).Encode(DeviceCodeResponse{
+			DeviceCode:      "device",
+			UserCode:        "ABCD-EFGH",
+			VerificationURI: "https://idp/activate",
+			ExpiresIn:       600,
+			Interval:        5,
+		})
+	}))
+	defer s.Close()
+
+	device, err := requestDeviceCode(context.Background(), s.URL, "my-id", "openid", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if device.UserCode != "ABCD-EFGH" || device.DeviceCode != "device" {
+		t.Errorf("unexpected device code response: %+v", device)
+	}
+}
+
+func Test_requestDeviceCode_ThroughProxy(t *testing.T) {
+	var proxiedHost string
+	stubProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.URL.Host
+		json.NewEncoder(w).Encode(DeviceCodeResponse{DeviceCode: "device", UserCode: "ABCD-EFGH"})
+	}))
+	defer stubProxy.Close()
+
+	if err := proxy.SetHTTPProxy(stubProxy.URL); err != nil {
+		t.Fatal(err)
+	}
+	defer proxy.SetHTTPProxy("")
+
+	if _, err := requestDeviceCode(context.Background(), "http://idp.example.com/device", "my-id", "openid", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if proxiedHost != "idp.example.com" {
+		t.Errorf("want the device code request to go through the proxy, got host %q", proxiedHost)
+	}
+}
+
+func Test_pollDeviceToken_PendingThenSlowDownThenToken(t *testing.T) {
+	var slept []time.Duration
+	deviceSleep = func(d time.Duration) { slept = append(slept, d) }
+	defer func() { deviceSleep = time.Sleep }()
+
+	responses := []string{"authorization_pending", "slow_down", ""}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got := r.PostForm.Get("grant_type"); got != deviceCodeGrantType {
+			t.Errorf("grant_type: want %q, got %q", deviceCodeGrantType, got)
+		}
+
+		code := responses[0]
+		responses = responses[1:]
+		if len(code) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": code})
+			return
+		}
+		json.NewEncoder(w).Encode(AuthorizationCodeToken{AccessToken: "token"})
+	}))
+	defer s.Close()
+
+	device := DeviceCodeResponse{DeviceCode: "device", ExpiresIn: 600, Interval: 1}
+	token, err := pollDeviceToken(context.Background(), s.URL, "my-id", device)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.AccessToken != "token" {
+		t.Errorf("want %q, got %q", "token", token.AccessToken)
+	}
+
+	want := []time.Duration{time.Second, time.Second, 6 * time.Second}
+	if len(slept) != len(want) {
+		t.Fatalf("want %d polls, got %d", len(want), len(slept))
+	}
+	for i := range want {
+		if slept[i] != want[i] {
+			t.Errorf("poll %d: want interval %s, got %s", i, want[i], slept[i])
+		}
+	}
+}
+
+func Test_pollDeviceToken_Expired(t *testing.T) {
+	deviceSleep = func(d time.Duration) {}
+	defer func() { deviceSleep = time.Sleep }()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "expired_token"})
+	}))
+	defer s.Close()
+
+	device := DeviceCodeResponse{DeviceCode: "device", ExpiresIn: 600, Interval: 1}
+	_, err := pollDeviceToken(context.Background(), s.URL, "my-id", device)
+	if err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("want expiry error, got %v", err)
+	}
+}
+
+func Test_checkDeviceValues(t *testing.T) {
+	if err := checkDeviceValues("", "https://idp/token", "id"); err == nil {
+		t.Errorf("want error for missing --device-code-url")
+	}
+
+	if err := checkDeviceValues("https://idp/device", "", "id"); err == nil {
+		t.Errorf("want error for missing --token-url")
+	}
+
+	if err := checkDeviceValues("https://idp/device", "https://idp/token", ""); err == nil {
+		t.Errorf("want error for missing --client-id")
+	}
+
+	if err := checkDeviceValues("https://idp/device", "https://idp/token", "id"); err != nil {
+		t.Errorf("want no error, got %s", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// decodeJWTPayload unmarshals the claims of a JWT into v, the signature is
+// not verified as the token is only issued to the CLI by the IdP.
+func decodeJWTPayload(token string, v interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return fmt.Errorf("unable to decode JWT payload: %s", err.Error())
+	}
+
+	if err := json.Unmarshal(payload, v); err != nil {
+		return fmt.Errorf("unable to parse JWT payload: %s", err.Error())
+	}
+
+	return nil
+}
+
+// tokenExpiry reads the exp claim from the payload of a JWT, an error is
+// returned for opaque tokens.
+func tokenExpiry(token string) (time.Time, error) {
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+
+	if err := decodeJWTPayload(token, &claims); err != nil {
+		return time.Time{}, err
+	}
+
+	if claims.Exp == 0 {
+		return time.Time{}, fmt.Errorf("no exp claim found in JWT")
+	}
+
+	return time.Unix(claims.Exp, 0), nil
+}
+
+// checkNonce verifies the nonce claim of an id_token matches the one sent to the IdP
+func checkNonce(idToken, nonce string) error {
+	claims := struct {
+		Nonce string `json:"nonce"`
+	}{}
+
+	if err := decodeJWTPayload(idToken, &claims); err != nil {
+		return err
+	}
+
+	if claims.Nonce != nonce {
+		return fmt.Errorf("the nonce in the id_token did not match, the token was rejected")
+	}
+
+	return nil
+}
+
+// tokenExpiryMessage describes when a JWT expires in local time and how long it
+// remains valid for, opaque tokens give an empty message.
+func tokenExpiryMessage(token string, now time.Time) string {
+	expiry, err := tokenExpiry(token)
+	if err != nil {
+		return ""
+	}
+
+	if !expiry.After(now) {
+		return fmt.Sprintf("Token expired at %s", expiry.Local().Format(time.RFC1123))
+	}
+
+	return fmt.Sprintf("Token expires at %s, token valid for %s", expiry.Local().Format(time.RFC1123), formatValidity(expiry.Sub(now)))
+}
+
+// formatValidity prints a duration to the minute, or to the second when under a minute
+func formatValidity(d time.Duration) string {
+	if d < time.Minute {
+		return d.Truncate(time.Second).String()
+	}
+	return strings.TrimSuffix(d.Truncate(time.Minute).String(), "0s")
+}
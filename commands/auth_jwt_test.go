@@ -0,0 +1,96 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func makeTestJWTWithClaims(claims map[string]interface{}) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	payloadBytes, _ := json.Marshal(claims)
+	payload := base64.RawURLEncoding.EncodeToString(payloadBytes)
+	return header + "." + payload + ".signature"
+}
+
+func makeTestJWT(exp time.Time) string {
+	return makeTestJWTWithClaims(map[string]interface{}{"sub": "user", "exp": exp.Unix()})
+}
+
+func Test_tokenExpiry(t *testing.T) {
+	want := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	got, err := tokenExpiry(makeTestJWT(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(want) {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func Test_tokenExpiry_OpaqueToken(t *testing.T) {
+	if _, err := tokenExpiry("opaque-token"); err == nil {
+		t.Fatal("want error for opaque token")
+	}
+}
+
+func Test_checkNonce(t *testing.T) {
+	idToken := makeTestJWTWithClaims(map[string]interface{}{"nonce": "abc"})
+
+	if err := checkNonce(idToken, "abc"); err != nil {
+		t.Errorf("want no error for matching nonce, got %s", err)
+	}
+
+	if err := checkNonce(idToken, "xyz"); err == nil {
+		t.Errorf("want error for mismatched nonce")
+	}
+}
+
+func Test_tokenExpiryMessage(t *testing.T) {
+	now := time.Now()
+	exp := now.Add(11*time.Hour + 59*time.Minute + 30*time.Second)
+
+	got := tokenExpiryMessage(makeTestJWT(exp), now)
+	if !strings.HasSuffix(got, "token valid for 11h59m") {
+		t.Errorf("want validity of 11h59m, got %q", got)
+	}
+}
+
+func Test_tokenExpiryMessage_Expired(t *testing.T) {
+	now := time.Now()
+
+	got := tokenExpiryMessage(makeTestJWT(now.Add(-time.Minute)), now)
+	if !strings.HasPrefix(got, "Token expired at") {
+		t.Errorf("want expired message, got %q", got)
+	}
+}
+
+func Test_tokenExpiryMessage_OpaqueToken(t *testing.T) {
+	if got := tokenExpiryMessage("opaque-token", time.Now()); got != "" {
+		t.Errorf("want no message for opaque token, got %q", got)
+	}
+}
+
+func Test_formatValidity(t *testing.T) {
+	testCases := []struct {
+		duration time.Duration
+		want     string
+	}{
+		{duration: 30 * time.Second, want: "30s"},
+		{duration: time.Hour, want: "1h0m"},
+		{duration: 90*time.Minute + 10*time.Second, want: "1h30m"},
+	}
+
+	for _, testCase := range testCases {
+		if got := formatValidity(testCase.duration); got != testCase.want {
+			t.Errorf("want %q, got %q", testCase.want, got)
+		}
+	}
+}
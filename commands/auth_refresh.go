@@ -0,0 +1,106 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/openfaas/faas-cli/config"
+)
+
+// refreshWindow is how close to its expiry a token is renewed
+const refreshWindow = 60 * time.Second
+
+// refreshAccessToken redeems a refresh token for a new access token
+func refreshAccessToken(ctx context.Context, tokenURL, clientID, refreshToken string) (AuthorizationCodeToken, error) {
+	form := url.Values{}
+	form.Add("grant_type", "refresh_token")
+	form.Add("client_id", clientID)
+	form.Add("refresh_token", refreshToken)
+
+	return postTokenForm(ctx, tokenURL, form)
+}
+
+// refreshIfExpiring returns the saved token for the gateway, renewing it first
+// when it is about to expire and a refresh token is available. When the
+// refresh fails the saved token is returned and the user is asked to run auth.
+func refreshIfExpiring(gateway string, authConfig config.AuthConfig) string {
+	if len(authConfig.RefreshToken) == 0 || len(authConfig.TokenURL) == 0 {
+		return authConfig.Token
+	}
+
+	expiry, err := tokenExpiry(authConfig.Token)
+	if err != nil || time.Until(expiry) > refreshWindow {
+		return authConfig.Token
+	}
+
+	token, err := renewToken(gateway, authConfig)
+	if err != nil {
+		return authConfig.Token
+	}
+	return token
+}
+
+// renewToken redeems the refresh token of the auth config and saves the new
+// tokens for the gateway, the user is asked to run auth when that fails
+func renewToken(gateway string, authConfig config.AuthConfig) (string, error) {
+	token, err := refreshAccessToken(context.Background(), authConfig.TokenURL, authConfig.ClientID, authConfig.RefreshToken)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to refresh the token for %s, run \"faas-cli auth\" to authenticate again. %s\n", gateway, err.Error())
+		return "", err
+	}
+
+	refreshToken := authConfig.RefreshToken
+	if len(token.RefreshToken) > 0 {
+		refreshToken = token.RefreshToken
+	}
+
+	if err := config.UpdateAuthConfig(gateway, token.AccessToken, config.Oauth2AuthType); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to save the refreshed token for %s: %s\n", gateway, err.Error())
+		return token.AccessToken, nil
+	}
+
+	if err := config.UpdateRefreshToken(gateway, refreshToken, authConfig.TokenURL, authConfig.ClientID); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to save the refresh token for %s: %s\n", gateway, err.Error())
+	}
+
+	return token.AccessToken, nil
+}
+
+// OAuth2Token is the bearer token saved by "faas-cli auth", which is renewed
+// with the saved refresh token when the gateway refuses it
+type OAuth2Token struct {
+	BearerToken
+	gateway    string
+	authConfig config.AuthConfig
+}
+
+// Refresh implements proxy.RefreshAuth, the refresh token is read again as
+// it may have been replaced since the token was loaded
+func (t *OAuth2Token) Refresh() error {
+	if len(t.authConfig.TokenURL) == 0 {
+		return fmt.Errorf("no token URL saved for %s", t.gateway)
+	}
+
+	refreshToken, err := config.GetRefreshToken(t.gateway)
+	if err != nil {
+		return err
+	}
+
+	authConfig := t.authConfig
+	authConfig.RefreshToken = refreshToken
+
+	token, err := renewToken(t.gateway, authConfig)
+	if err != nil {
+		return err
+	}
+
+	t.token = token
+	t.authConfig.RefreshToken = refreshToken
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/proxy"
+)
+
+func Test_refreshAccessToken(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got := r.PostForm.Get("grant_type"); got != "refresh_token" {
+			t.Errorf("grant_type: want %q, got %q", "refresh_token", got)
+		}
+		if got := r.PostForm.Get("refresh_token"); got != "refresh" {
+			t.Errorf("refresh_token: want %q, got %q", "refresh", got)
+		}
+		if got := r.PostForm.Get("client_id"); got != "my-id" {
+			t.Errorf("client_id: want %q, got %q", "my-id", got)
+		}
+		json.NewEncoder(w).Encode(AuthorizationCodeToken{AccessToken: "new-token"})
+	}))
+	defer s.Close()
+
+	token, err := refreshAccessToken(context.Background(), s.URL, "my-id", "refresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.AccessToken != "new-token" {
+		t.Errorf("want %q, got %q", "new-token", token.AccessToken)
+	}
+}
+
+func Test_refreshIfExpiring(t *testing.T) {
+	config.DefaultDir, _ = ioutil.TempDir("", "faas-cli-refresh-test")
+	config.DefaultFile = "config.yml"
+
+	refreshed := makeTestJWT(time.Now().Add(time.Hour))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(AuthorizationCodeToken{AccessToken: refreshed, RefreshToken: "refresh2"})
+	}))
+	defer s.Close()
+
+	gatewayURL := "http://openfaas.test"
+	expiring := makeTestJWT(time.Now().Add(30 * time.Second))
+	config.UpdateAuthConfig(gatewayURL, expiring, config.Oauth2AuthType)
+	config.UpdateRefreshToken(gatewayURL, "refresh", s.URL, "my-id")
+
+	authConfig, _ := config.LookupAuthConfig(gatewayURL)
+	got := refreshIfExpiring(gatewayURL, authConfig)
+	if got != refreshed {
+		t.Fatalf("want refreshed token %q, got %q", refreshed, got)
+	}
+
+	authConfig, _ = config.LookupAuthConfig(gatewayURL)
+	if authConfig.Token != refreshed {
+		t.Errorf("want saved token %q, got %q", refreshed, authConfig.Token)
+	}
+	if authConfig.RefreshToken != "refresh2" {
+		t.Errorf("want saved refresh token %q, got %q", "refresh2", authConfig.RefreshToken)
+	}
+}
+
+func Test_refreshIfExpiring_ValidTokenIsKept(t *testing.T) {
+	valid := makeTestJWT(time.Now().Add(time.Hour))
+	authConfig := config.AuthConfig{
+		Gateway:      "http://openfaas.test",
+		Auth:         config.Oauth2AuthType,
+		Token:        valid,
+		RefreshToken: "refresh",
+		TokenURL:     "http://127.0.0.1:1/token",
+	}
+
+	if got := refreshIfExpiring(authConfig.Gateway, authConfig); got != valid {
+		t.Errorf("want %q, got %q", valid, got)
+	}
+}
+
+func Test_NewCLIAuth_RefreshesOnUnauthorized(t *testing.T) {
+	config.DefaultDir, _ = ioutil.TempDir("", "faas-cli-refresh-test")
+	config.DefaultFile = "config.yml"
+	defer os.RemoveAll(config.DefaultDir)
+
+	refreshed := makeTestJWT(time.Now().Add(time.Hour))
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got := r.PostForm.Get("refresh_token"); got != "refresh" {
+			t.Errorf("refresh_token: want %q, got %q", "refresh", got)
+		}
+		json.NewEncoder(w).Encode(AuthorizationCodeToken{AccessToken: refreshed, RefreshToken: "refresh2"})
+	}))
+	defer tokenServer.Close()
+
+	gatewayServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+refreshed {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer gatewayServer.Close()
+
+	// The token has not expired, but the gateway no longer accepts it
+	revoked := makeTestJWT(time.Now().Add(2 * time.Hour))
+	config.UpdateAuthConfig(gatewayServer.URL, revoked, config.Oauth2AuthType)
+	config.UpdateRefreshToken(gatewayServer.URL, "refresh", tokenServer.URL, "my-id")
+
+	client := proxy.NewClient(NewCLIAuth("", gatewayServer.URL), gatewayServer.URL, nil, &commandTimeout)
+	if _, err := client.ListFunctions(context.Background(), ""); err != nil {
+		t.Fatalf("want the request to succeed after a refresh, got %s", err)
+	}
+
+	authConfig, _ := config.LookupAuthConfig(gatewayServer.URL)
+	if authConfig.Token != refreshed {
+		t.Errorf("want saved token %q, got %q", refreshed, authConfig.Token)
+	}
+	if authConfig.RefreshToken != "refresh2" {
+		t.Errorf("want saved refresh token %q, got %q", "refresh2", authConfig.RefreshToken)
+	}
+}
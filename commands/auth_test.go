@@ -4,7 +4,19 @@
 package commands
 
 import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/test"
 )
 
 func Test_auth(t *testing.T) {
@@ -90,3 +102,305 @@ func Test_makeRedirectURI_NoSchemeIsInvalid(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func Test_makeCallbackHandler_SendsToken(t *testing.T) {
+	results := make(chan callbackResult, 1)
+	handler := makeCallbackHandler("access_token", "state1", "nonce1", results)
+
+	fragment := url.Values{}
+	fragment.Add("access_token", "my-token")
+	fragment.Add("token_type", "Bearer")
+	fragment.Add("state", "state1")
+
+	req := httptest.NewRequest(http.MethodGet, "/oauth2/callback?fragment="+url.QueryEscape(fragment.Encode()), nil)
+	handler(httptest.NewRecorder(), req)
+
+	select {
+	case got := <-results:
+		if got.err != nil {
+			t.Fatalf("want no error, got %s", got.err)
+		}
+		want := "my-token"
+		if got.value != want {
+			t.Errorf("want %q, got %q", want, got.value)
+		}
+	default:
+		t.Fatal("no token was sent by the callback handler")
+	}
+}
+
+func Test_makeCallbackHandler_RejectsMismatchedState(t *testing.T) {
+	results := make(chan callbackResult, 1)
+	handler := makeCallbackHandler("access_token", "state1", "nonce1", results)
+
+	fragment := url.Values{}
+	fragment.Add("access_token", "injected-token")
+	fragment.Add("state", "attacker")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/oauth2/callback?fragment="+url.QueryEscape(fragment.Encode()), nil)
+	handler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("want status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	got := <-results
+	if got.err == nil {
+		t.Fatal("want error for mismatched state")
+	}
+	if got.value == "injected-token" {
+		t.Errorf("token with mismatched state must not be accepted")
+	}
+}
+
+func Test_makeCallbackHandler_RejectsMismatchedNonce(t *testing.T) {
+	results := make(chan callbackResult, 1)
+	handler := makeCallbackHandler("id_token", "state1", "nonce1", results)
+
+	fragment := url.Values{}
+	fragment.Add("id_token", makeTestJWTWithClaims(map[string]interface{}{"nonce": "attacker"}))
+	fragment.Add("state", "state1")
+
+	req := httptest.NewRequest(http.MethodGet, "/oauth2/callback?fragment="+url.QueryEscape(fragment.Encode()), nil)
+	handler(httptest.NewRecorder(), req)
+
+	got := <-results
+	if got.err == nil {
+		t.Fatal("want error for mismatched nonce")
+	}
+}
+
+func Test_makeCallbackHandler_ServesCapturePageWithoutFragment(t *testing.T) {
+	results := make(chan callbackResult, 1)
+	handler := makeCallbackHandler("access_token", "state1", "nonce1", results)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/oauth/callback", nil))
+
+	if !strings.Contains(rec.Body.String(), "OpenFaaS CLI Authorization flow") {
+		t.Errorf("want capture page, got %q", rec.Body.String())
+	}
+
+	if len(results) != 0 {
+		t.Errorf("want no token to be sent, got %d", len(results))
+	}
+}
+
+func Test_checkTokenURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		tokenURL string
+		wantErr  string
+	}{
+		{
+			name:     "Missing token-url",
+			tokenURL: "",
+			wantErr:  "--token-url is required for the authorization_code grant",
+		},
+		{
+			name:     "Invalid token-url",
+			tokenURL: "xyz",
+			wantErr:  "--token-url is an invalid URL: xyz",
+		},
+		{
+			name:     "Valid token-url",
+			tokenURL: "https://idp/oauth/token",
+			wantErr:  "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := checkTokenURL(testCase.tokenURL, "authorization_code")
+			gotErr := ""
+			if err != nil {
+				gotErr = err.Error()
+			}
+
+			if testCase.wantErr != gotErr {
+				t.Errorf("want %q, got %q", testCase.wantErr, gotErr)
+			}
+		})
+	}
+}
+
+func Test_makeCallbackHandler_MalformedFragment(t *testing.T) {
+	results := make(chan callbackResult, 1)
+	handler := makeCallbackHandler("access_token", "state1", "nonce1", results)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/oauth2/callback?fragment="+url.QueryEscape("state=%zz"), nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("want status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	if !strings.Contains(rec.Body.String(), "Authorization flow failed") {
+		t.Errorf("want error page, got %q", rec.Body.String())
+	}
+
+	got := <-results
+	if got.err == nil {
+		t.Fatal("want error for malformed fragment")
+	}
+}
+
+func Test_startCallbackServer_PortInUse_Strict(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	results := make(chan callbackResult, 1)
+
+	_, _, err = startCallbackServer(port, true, nil, func(w http.ResponseWriter, r *http.Request) {}, results)
+	if err == nil {
+		t.Fatal("want error when the port is already in use with strict set")
+	}
+}
+
+func Test_startCallbackServer_PortInUse_PicksFreePort(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	results := make(chan callbackResult, 1)
+
+	server, boundPort, err := startCallbackServer(port, false, nil, func(w http.ResponseWriter, r *http.Request) {}, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	if boundPort == port || boundPort == 0 {
+		t.Errorf("want a free port other than %d, got %d", port, boundPort)
+	}
+}
+
+func Test_printAuthResultJSON(t *testing.T) {
+	now := time.Unix(time.Now().Unix(), 0)
+	token := makeTestJWT(now.Add(time.Hour))
+
+	stdOut := test.CaptureStdout(func() {
+		if err := printAuthResultJSON(AuthResult{AccessToken: token, Gateway: "http://127.0.0.1:8080"}, now); err != nil {
+			t.Fatalf("want no error, got %s", err)
+		}
+	})
+
+	result := AuthResult{}
+	if err := json.Unmarshal([]byte(stdOut), &result); err != nil {
+		t.Fatalf("want stdout to be JSON, got %q: %s", stdOut, err)
+	}
+
+	if result.AccessToken != token {
+		t.Errorf("want access_token %q, got %q", token, result.AccessToken)
+	}
+	if result.TokenType != "Bearer" {
+		t.Errorf("want token_type Bearer, got %q", result.TokenType)
+	}
+	if result.ExpiresIn != 3600 {
+		t.Errorf("want expires_in 3600, got %d", result.ExpiresIn)
+	}
+	if result.Gateway != "http://127.0.0.1:8080" {
+		t.Errorf("want gateway http://127.0.0.1:8080, got %q", result.Gateway)
+	}
+}
+
+func Test_getBrowserCommand_FlagOverridesEnvironment(t *testing.T) {
+	if got := getBrowserCommand("wslview %s", "firefox %s"); got != "wslview %s" {
+		t.Errorf("want flag value, got %q", got)
+	}
+	if got := getBrowserCommand("", "firefox %s"); got != "firefox %s" {
+		t.Errorf("want environment value, got %q", got)
+	}
+}
+
+func Test_makeBrowserCommand(t *testing.T) {
+	testCases := []struct {
+		title    string
+		template string
+		wantArgs []string
+	}{
+		{title: "URL substituted", template: "echo --new-window %s", wantArgs: []string{"--new-window", "http://idp/authorize"}},
+		{title: "URL appended without %s", template: "echo", wantArgs: []string{"http://idp/authorize"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.title, func(t *testing.T) {
+			command, err := makeBrowserCommand(context.Background(), testCase.template, "http://idp/authorize")
+			if err != nil {
+				t.Fatalf("want no error, got %s", err)
+			}
+
+			if got := strings.Join(command.Args[1:], " "); got != strings.Join(testCase.wantArgs, " ") {
+				t.Errorf("want args %v, got %v", testCase.wantArgs, command.Args[1:])
+			}
+		})
+	}
+}
+
+func Test_makeBrowserCommand_NotFoundInPath(t *testing.T) {
+	_, err := makeBrowserCommand(context.Background(), "no-such-browser-cmd %s", "http://idp/authorize")
+	if err == nil {
+		t.Fatalf("want error for missing command")
+	}
+
+	want := `the browser command "no-such-browser-cmd" was not found in PATH`
+	if !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("want error to start with %q, got %q", want, err.Error())
+	}
+}
+
+func Test_waitForCallback_TimesOut(t *testing.T) {
+	results := make(chan callbackResult, 1)
+
+	_, err := waitForCallback(results, 10*time.Millisecond)
+	if err == nil {
+		t.Fatalf("want timeout error")
+	}
+
+	if want := "authentication timed out after 10ms"; err.Error() != want {
+		t.Errorf("want error %q, got %q", want, err.Error())
+	}
+}
+
+func Test_waitForCallback_ReturnsResultBeforeTimeout(t *testing.T) {
+	results := make(chan callbackResult, 1)
+	results <- callbackResult{value: "token"}
+
+	result, err := waitForCallback(results, time.Minute)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	if result.value != "token" {
+		t.Errorf("want token, got %q", result.value)
+	}
+}
+
+func Test_saveAuthToken_Quiet(t *testing.T) {
+	config.DefaultDir, _ = ioutil.TempDir("", "faas-cli-auth-test")
+	config.DefaultFile = "config.yml"
+	quietOutput = true
+	defer resetForTest()
+
+	stdOut := test.CaptureStdout(func() {
+		err := saveAuthToken(AuthResult{
+			AccessToken: makeTestJWT(time.Now().Add(time.Hour)),
+			Gateway:     "http://openfaas.test",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if stdOut != "" {
+		t.Errorf("want nothing printed with --quiet, got %q", stdOut)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// callbackCertValidity is kept short since the certificate is only used for a
+// single login, it is never written to disk.
+const callbackCertValidity = time.Hour
+
+// makeCallbackTLSConfig returns nil when --callback-tls is not set, otherwise
+// a config with a self-signed certificate for the loopback address.
+func makeCallbackTLSConfig(enabled bool) (*tls.Config, error) {
+	if !enabled {
+		return nil, nil
+	}
+
+	cert, err := makeSelfSignedCert(time.Now(), callbackCertValidity)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate certificate for --callback-tls")
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// makeSelfSignedCert generates an in-memory certificate for 127.0.0.1 and localhost
+func makeSelfSignedCert(now time.Time, validity time.Duration) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"OpenFaaS CLI"}},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:              []string{"localhost"},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}, nil
+}
+
+// callbackRedirectHost switches the scheme of the redirect host to https for --callback-tls
+func callbackRedirectHost(host string, useTLS bool) string {
+	if useTLS && strings.HasPrefix(host, "http://") {
+		return "https://" + strings.TrimPrefix(host, "http://")
+	}
+	return host
+}
@@ -0,0 +1,82 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_makeSelfSignedCert(t *testing.T) {
+	now := time.Now()
+
+	cert, err := makeSelfSignedCert(now, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := parsed.VerifyHostname("127.0.0.1"); err != nil {
+		t.Errorf("want cert valid for 127.0.0.1, got %s", err)
+	}
+
+	if parsed.NotAfter.After(now.Add(time.Hour)) {
+		t.Errorf("want cert to expire within an hour, expires %s", parsed.NotAfter)
+	}
+}
+
+func Test_callbackRedirectHost(t *testing.T) {
+	testCases := []struct {
+		host   string
+		useTLS bool
+		want   string
+	}{
+		{host: "http://127.0.0.1", useTLS: false, want: "http://127.0.0.1"},
+		{host: "http://127.0.0.1", useTLS: true, want: "https://127.0.0.1"},
+		{host: "https://127.0.0.1", useTLS: true, want: "https://127.0.0.1"},
+	}
+
+	for _, testCase := range testCases {
+		if got := callbackRedirectHost(testCase.host, testCase.useTLS); got != testCase.want {
+			t.Errorf("want %q, got %q", testCase.want, got)
+		}
+	}
+}
+
+func Test_startCallbackServer_ServesTLS(t *testing.T) {
+	tlsConfig, err := makeCallbackTLSConfig(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := make(chan callbackResult, 1)
+	server, port, err := startCallbackServer(0, false, tlsConfig, func(w http.ResponseWriter, r *http.Request) {}, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Shutdown(context.Background())
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+
+	res, err := client.Get(fmt.Sprintf("https://127.0.0.1:%d/oauth/callback", port))
+	if err != nil {
+		t.Fatalf("want https callback to be served, got %s", err)
+	}
+	res.Body.Close()
+
+	if res.TLS == nil {
+		t.Errorf("want response over TLS")
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +22,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// maxDefaultBuildParallel caps the default number of builds run at once, as
+// each docker build may use a lot of memory and CPU
+const maxDefaultBuildParallel = 4
+
 // Flags that are to be added to commands.
 var (
 	nocache          bool
@@ -29,12 +36,21 @@ var (
 	buildArgMap      map[string]string
 	buildOptions     []string
 	copyExtra        []string
+	buildContext     string
 	tagFormat        schema.BuildFormat
 	buildLabels      []string
 	buildLabelMap    map[string]string
 	envsubst         bool
 	quietBuild       bool
 	disableStackPull bool
+	platforms        string
+	cacheFrom        []string
+	buildPlatforms   []string
+	buildSBOM        bool
+	sbomFormat       string
+	// buildxPush is set by up to push images for several platforms as
+	// they are built
+	buildxPush bool
 )
 
 func init() {
@@ -47,16 +63,23 @@ func init() {
 	// Setup flags that are used only by this command (variables defined above)
 	buildCmd.Flags().BoolVar(&nocache, "no-cache", false, "Do not use Docker's build cache")
 	buildCmd.Flags().BoolVar(&squash, "squash", false, `Use Docker's squash flag for smaller images [experimental] `)
-	buildCmd.Flags().IntVar(&parallel, "parallel", 1, "Build in parallel to depth specified.")
+	buildCmd.Flags().IntVar(&parallel, "parallel", defaultBuildParallel(), fmt.Sprintf("Build in parallel to depth specified, defaults to the number of CPUs up to %d", maxDefaultBuildParallel))
+	addFailFastFlags(buildCmd)
 	buildCmd.Flags().BoolVar(&shrinkwrap, "shrinkwrap", false, "Just write files to ./build/ folder for shrink-wrapping")
 	buildCmd.Flags().StringArrayVarP(&buildArgs, "build-arg", "b", []string{}, "Add a build-arg for Docker (KEY=VALUE)")
 	buildCmd.Flags().StringArrayVarP(&buildOptions, "build-option", "o", []string{}, "Set a build option, e.g. dev")
 	buildCmd.Flags().Var(&tagFormat, "tag", "Override latest tag on function Docker image, accepts 'latest', 'sha', 'branch', or 'describe'")
 	buildCmd.Flags().StringArrayVar(&buildLabels, "build-label", []string{}, "Add a label for Docker image (LABEL=VALUE)")
 	buildCmd.Flags().StringArrayVar(&copyExtra, "copy-extra", []string{}, "Extra paths that will be copied into the function build context")
+	buildCmd.Flags().StringVar(&buildContext, "build-context", "", "Folder holding the handler whose files are copied into the build along with it, unless the function sets build_context")
 	buildCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yml file")
-	buildCmd.Flags().BoolVar(&quietBuild, "quiet", false, "Perform a quiet build, without showing output from Docker")
+	buildCmd.Flags().BoolVarP(&quietBuild, "quiet", "q", false, "Perform a quiet build, without showing output from Docker or the progress of the build")
+	buildCmd.Flags().StringArrayVar(&cacheFrom, "cache-from", []string{}, "Image to seed the Docker build cache from, repeat to give several")
+	buildCmd.Flags().StringVar(&platforms, "platforms", "", "Build for these platforms with docker buildx, e.g. linux/amd64,linux/arm64")
+	buildCmd.Flags().BoolVar(&buildSBOM, "sbom", false, "Write an SBOM for each image to "+builder.SBOMFolder+" with syft after it is built")
+	buildCmd.Flags().StringVar(&sbomFormat, "sbom-format", builder.DefaultSBOMFormat, fmt.Sprintf("Format of the SBOM written by --sbom, one of: %v", builder.SBOMFormats()))
 	buildCmd.Flags().BoolVar(&disableStackPull, "disable-stack-pull", false, "Disables the template configuration in the stack.yml")
+	buildCmd.Flags().BoolVar(&noTemplateLock, "no-lock", false, "Build with the templates in ./template as they are, rather than at the commits in "+templateLockFile)
 
 	// Set bash-completion.
 	_ = buildCmd.Flags().SetAnnotation("handler", cobra.BashCompSubdirsInDir, []string{})
@@ -66,7 +89,7 @@ func init() {
 
 // buildCmd allows the user to build an OpenFaaS function container
 var buildCmd = &cobra.Command{
-	Use: `build -f YAML_FILE [--no-cache] [--squash]
+	Use: `build -f YAML_FILE [FUNCTION_NAME ...] [--no-cache] [--squash]
   faas-cli build --image IMAGE_NAME
                  --handler HANDLER_DIR
                  --name FUNCTION_NAME
@@ -74,23 +97,53 @@ var buildCmd = &cobra.Command{
                  [--no-cache] [--squash]
                  [--regex "REGEX"]
 				 [--filter "WILDCARD"]
-				 [--parallel PARALLEL_DEPTH]
+				 [--parallel PARALLEL_DEPTH] [--continue-on-error]
 				 [--build-arg KEY=VALUE]
 				 [--build-option VALUE]
 				 [--copy-extra PATH]
+				 [--build-context PATH]
+				 [--sbom] [--sbom-format FORMAT]
 				 [--tag <sha|branch|describe>]`,
 	Short: "Builds OpenFaaS function containers",
 	Long: `Builds OpenFaaS function containers either via the supplied YAML config using
 the "--yaml" flag (which may contain multiple function definitions), or directly
-via flags.`,
+via flags. Give the names of functions in the YAML config, or globs such as
+"web-*", to build only those functions, along with those matching --regex or
+--filter.
+
+Each --build-arg is given to the build of every function, a build_args value
+in a function's stack file entry takes precedence over a --build-arg with the
+same key.
+
+Use --build-context, or build_context in a function's stack file entry, to
+give a folder holding the handler, such as the root of a monorepo. Its files
+are copied into the build with the same paths from the folder, so a handler
+can use packages from folders next to it, and the handler's own files are
+copied over the top.
+
+Use --sbom to write a software bill of materials for each image with syft
+after it is built, to ./build/sbom/ in the format given by --sbom-format.
+When syft is not installed, "faas-cli up --platforms" has docker buildx attach
+an SPDX SBOM to each image as it is pushed.
+
+When a templates.lock file is present, written by "faas-cli template lock",
+each template is checked out at its locked commit before the build, pass
+--no-lock to build with the templates in ./template as they are.
+
+` + failFastNote,
 	Example: `  faas-cli build -f https://domain/path/myfunctions.yml
   faas-cli build -f ./stack.yml --no-cache --build-arg NPM_VERSION=0.2.2
   faas-cli build -f ./stack.yml --build-option dev
   faas-cli build -f ./stack.yml --tag sha
   faas-cli build -f ./stack.yml --tag branch
   faas-cli build -f ./stack.yml --tag describe
+  faas-cli build -f ./stack.yml --no-lock
+  faas-cli build -f ./stack.yml --sbom --sbom-format cyclonedx-json
+  faas-cli build -f ./stack.yml --build-context ./services
+  faas-cli build -f ./stack.yml figlet "web-*"
   faas-cli build -f ./stack.yml --filter "*gif*"
   faas-cli build -f ./stack.yml --regex "fn[0-9]_.*"
+  faas-cli build -f ./stack.yml --regex "^api-" figlet
   faas-cli build --image=my_image --lang=python --handler=/path/to/fn/
                  --name=my_fn --squash
   faas-cli build -f ./stack.yml --build-label org.label-schema.label-name="value"`,
@@ -103,17 +156,27 @@ func preRunBuild(cmd *cobra.Command, args []string) error {
 	language, _ = validateLanguageFlag(language)
 
 	mapped, err := parseBuildArgs(buildArgs)
-
-	if err == nil {
-		buildArgMap = mapped
+	if err != nil {
+		return err
 	}
+	buildArgMap = mapped
 
 	buildLabelMap, err = parseMap(buildLabels, "build-label")
 
+	// parallel is shared with push, which registers a default of its own
+	if !cmd.Flags().Changed("parallel") {
+		parallel = defaultBuildParallel()
+	}
+
 	if parallel < 1 {
 		return fmt.Errorf("the --parallel flag must be great than 0")
 	}
 
+	buildPlatforms = parsePlatforms(platforms)
+	if len(buildPlatforms) > 0 && squash {
+		return fmt.Errorf("--squash can't be used with --platforms")
+	}
+
 	return err
 }
 
@@ -148,15 +211,60 @@ func parseBuildArgs(args []string) (map[string]string, error) {
 	return mapped, nil
 }
 
+// defaultBuildParallel gives the number of CPUs, up to maxDefaultBuildParallel
+func defaultBuildParallel() int {
+	if n := runtime.NumCPU(); n < maxDefaultBuildParallel {
+		return n
+	}
+	return maxDefaultBuildParallel
+}
+
+// parsePlatforms splits the comma separated --platforms value
+func parsePlatforms(value string) []string {
+	var platforms []string
+	for _, platform := range strings.Split(value, ",") {
+		if platform = strings.TrimSpace(platform); len(platform) > 0 {
+			platforms = append(platforms, platform)
+		}
+	}
+	return platforms
+}
+
 func runBuild(cmd *cobra.Command, args []string) error {
+	if len(buildPlatforms) > 1 && !buildxPush {
+		return fmt.Errorf("an image for several platforms can't be loaded into the local image cache, run \"faas-cli up --platforms %s\" to build and push it", strings.Join(buildPlatforms, ","))
+	}
+
+	if len(buildPlatforms) > 0 {
+		if err := builder.CheckBuildx(); err != nil {
+			return err
+		}
+	}
+
+	if buildSBOM {
+		attest, err := builder.CheckSBOM(sbomFormat, buildPlatforms, buildxPush)
+		if err != nil {
+			return err
+		}
+		builder.SetSBOM(sbomFormat, attest)
+		defer builder.SetSBOM("", false)
+	}
 
 	var services stack.Services
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		if err := checkStackFiles(os.Stderr, stackFiles(), false); err != nil {
+			return err
+		}
+
+		parsedServices, err := parseSelectedStack(args)
 		if err != nil {
 			return err
 		}
 
+		if err := checkRemoteHandlers(parsedServices.Functions, stackFiles()); err != nil {
+			return err
+		}
+
 		if parsedServices != nil {
 			services = *parsedServices
 		}
@@ -177,7 +285,7 @@ func runBuild(cmd *cobra.Command, args []string) error {
 		if len(functionName) == 0 {
 			return fmt.Errorf("please provide the deployed --name of your function")
 		}
-		err := builder.BuildImage(image,
+		err := builder.BuildImage(resolveImageTag(tagFormat).imageName(image),
 			handler,
 			functionName,
 			language,
@@ -186,10 +294,14 @@ func runBuild(cmd *cobra.Command, args []string) error {
 			shrinkwrap,
 			buildArgMap,
 			buildOptions,
-			tagFormat,
 			buildLabelMap,
 			quietBuild,
 			copyExtra,
+			buildContext,
+			buildPlatforms,
+			buildxPush,
+			cacheFrom,
+			"",
 		)
 		if err != nil {
 			return err
@@ -204,9 +316,17 @@ func runBuild(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if err := applyTemplateLock(os.Stderr, !noTemplateLock); err != nil {
+		return err
+	}
+
+	if err := checkTemplateDrift(os.Stderr, services.StackConfiguration.TemplateConfigs); err != nil {
+		return err
+	}
+
 	errors := build(&services, parallel, shrinkwrap, quietBuild)
 	if len(errors) > 0 {
-		errorSummary := "Errors received during build:\n"
+		errorSummary := fmt.Sprintf("Errors received during build of %d function(s):\n", len(errors))
 		for _, err := range errors {
 			errorSummary = errorSummary + "- " + err.Error() + "\n"
 		}
@@ -219,6 +339,9 @@ func build(services *stack.Services, queueDepth int, shrinkwrap, quietBuild bool
 	startOuter := time.Now()
 
 	errors := []error{}
+	errorsLock := sync.Mutex{}
+	gate := failureGate{}
+	tag := resolveImageTag(tagFormat)
 
 	wg := sync.WaitGroup{}
 
@@ -228,16 +351,33 @@ func build(services *stack.Services, queueDepth int, shrinkwrap, quietBuild bool
 	for i := 0; i < queueDepth; i++ {
 		go func(index int) {
 			for function := range workChannel {
+				if gate.skip(function.Name) {
+					continue
+				}
 				start := time.Now()
 
-				fmt.Printf(aec.YellowF.Apply("[%d] > Building %s.\n"), index, function.Name)
+				fmt.Fprintf(infoOutput(), aec.YellowF.Apply("[%d] > Building %s.\n"), index, function.Name)
+
+				// Output of builds running at the same time is told apart by
+				// the function name in front of each line
+				var outputPrefix string
+				if queueDepth > 1 {
+					outputPrefix = fmt.Sprintf("[%s] ", function.Name)
+				}
+
+				var err error
 				if len(function.Language) == 0 {
-					fmt.Println("Please provide a valid language for your function.")
+					err = fmt.Errorf("please provide a valid language for your function")
 				} else {
 					combinedBuildOptions := combineBuildOpts(function.BuildOptions, buildOptions)
-					combinedBuildArgMap := mergeMap(function.BuildArgs, buildArgMap)
+					combinedBuildArgMap := combineBuildArgs(function.BuildArgs, buildArgMap)
 					combinedExtraPaths := mergeSlice(services.StackConfiguration.CopyExtraPaths, copyExtra)
-					err := builder.BuildImage(function.Image,
+					combinedCacheFrom := mergeSlice(function.CacheFrom, cacheFrom)
+					functionBuildContext := function.BuildContext
+					if len(functionBuildContext) == 0 {
+						functionBuildContext = buildContext
+					}
+					err = builder.BuildImage(tag.imageName(function.Image),
 						function.Handler,
 						function.Name,
 						function.Language,
@@ -246,22 +386,29 @@ func build(services *stack.Services, queueDepth int, shrinkwrap, quietBuild bool
 						shrinkwrap,
 						combinedBuildArgMap,
 						combinedBuildOptions,
-						tagFormat,
 						buildLabelMap,
 						quietBuild,
 						combinedExtraPaths,
+						functionBuildContext,
+						buildPlatforms,
+						buildxPush,
+						combinedCacheFrom,
+						outputPrefix,
 					)
+				}
 
-					if err != nil {
-						errors = append(errors, err)
-					}
+				if err != nil {
+					gate.fail()
+					errorsLock.Lock()
+					errors = append(errors, fmt.Errorf("%s: %s", function.Name, err))
+					errorsLock.Unlock()
 				}
 
 				duration := time.Since(start)
-				fmt.Printf(aec.YellowF.Apply("[%d] < Building %s done in %1.2fs.\n"), index, function.Name, duration.Seconds())
+				fmt.Fprintf(infoOutput(), aec.YellowF.Apply("[%d] < Building %s done in %1.2fs.\n"), index, function.Name, duration.Seconds())
 			}
 
-			fmt.Printf(aec.YellowF.Apply("[%d] Worker done.\n"), index)
+			fmt.Fprintf(infoOutput(), aec.YellowF.Apply("[%d] Worker done.\n"), index)
 			wg.Done()
 		}(i)
 
@@ -269,7 +416,7 @@ func build(services *stack.Services, queueDepth int, shrinkwrap, quietBuild bool
 
 	for k, function := range services.Functions {
 		if function.SkipBuild {
-			fmt.Printf("Skipping build of: %s.\n", function.Name)
+			fmt.Fprintf(infoOutput(), "Skipping build of: %s.\n", function.Name)
 		} else {
 			function.Name = k
 			workChannel <- function
@@ -280,8 +427,12 @@ func build(services *stack.Services, queueDepth int, shrinkwrap, quietBuild bool
 
 	wg.Wait()
 
+	sort.Slice(errors, func(i, j int) bool {
+		return errors[i].Error() < errors[j].Error()
+	})
+
 	duration := time.Since(startOuter)
-	fmt.Printf("\n%s\n", aec.Apply(fmt.Sprintf("Total build time: %1.2fs", duration.Seconds()), aec.YellowF))
+	fmt.Fprintf(infoOutput(), "\n%s\n", aec.Apply(fmt.Sprintf("Total build time: %1.2fs", duration.Seconds()), aec.YellowF))
 	return errors
 }
 
@@ -307,3 +458,37 @@ func combineBuildOpts(YAMLBuildOpts []string, buildFlagBuildOpts []string) []str
 	return mergeSlice(YAMLBuildOpts, buildFlagBuildOpts)
 
 }
+
+// checkRemoteHandlers gives an error for a function with a relative handler
+// when the stack files are only given by URL, as there is no local folder
+// which the handlers are known to be relative to
+func checkRemoteHandlers(functions map[string]stack.Function, files []string) error {
+	for _, file := range files {
+		if !stack.IsRemoteFile(file) {
+			return nil
+		}
+	}
+
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		function := functions[name]
+		if function.SkipBuild || len(function.Handler) == 0 || filepath.IsAbs(function.Handler) {
+			continue
+		}
+		return fmt.Errorf(`function %s has the relative handler %s, but the stack file was given by URL, so there is no folder to find it in.
+Download the stack file next to the handlers and build with "-f ./stack.yml", or give "-f" a local stack file with the handlers as well as the URL`, name, function.Handler)
+	}
+	return nil
+}
+
+// combineBuildArgs gives the --build-arg values with the function's
+// build_args from the stack file on top, so that a function can override
+// a value given to every function
+func combineBuildArgs(yamlBuildArgs map[string]string, flagBuildArgs map[string]string) map[string]string {
+	return mergeMap(flagBuildArgs, yamlBuildArgs)
+}
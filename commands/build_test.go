@@ -4,7 +4,11 @@
 package commands
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/openfaas/faas-cli/stack"
 )
 
 func Test_build(t *testing.T) {
@@ -91,3 +95,125 @@ func Test_parseBuildArgs_MultipleSeparators(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func Test_parsePlatforms(t *testing.T) {
+	got := parsePlatforms(" linux/amd64, linux/arm64 ,,")
+	want := []string{"linux/amd64", "linux/arm64"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	if got := parsePlatforms(""); len(got) != 0 {
+		t.Errorf("want no platforms, got %v", got)
+	}
+}
+
+func Test_runBuild_SeveralPlatformsNeedPush(t *testing.T) {
+	buildPlatforms = []string{"linux/amd64", "linux/arm64"}
+	defer func() { buildPlatforms = nil }()
+
+	err := runBuild(buildCmd, nil)
+
+	want := `an image for several platforms can't be loaded into the local image cache, run "faas-cli up --platforms linux/amd64,linux/arm64" to build and push it`
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+func Test_preRunBuild_InvalidBuildArg(t *testing.T) {
+	defer func() { buildArgs = []string{} }()
+	buildArgs = []string{"NPM_VERSION"}
+
+	got := preRunBuild(buildCmd, nil)
+
+	want := "each build-arg must take the form key=value"
+	if got == nil || got.Error() != want {
+		t.Errorf("want error %q, got %v", want, got)
+	}
+}
+
+func Test_combineBuildArgs_StackOverridesFlags(t *testing.T) {
+	flagArgs := map[string]string{"GO_VERSION": "1.13", "CGO_ENABLED": "0"}
+	yamlArgs := map[string]string{"GO_VERSION": "1.14"}
+
+	got := combineBuildArgs(yamlArgs, flagArgs)
+	want := map[string]string{"GO_VERSION": "1.14", "CGO_ENABLED": "0"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func Test_build_ErrorsNameEachFunction(t *testing.T) {
+	services := stack.Services{
+		Functions: map[string]stack.Function{
+			"fn-b": {},
+			"fn-a": {},
+		},
+	}
+
+	continueOnError = true
+	defer func() { continueOnError = false }()
+
+	errors := build(&services, 2, false, true)
+
+	var got []string
+	for _, err := range errors {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		"fn-a: please provide a valid language for your function",
+		"fn-b: please provide a valid language for your function",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func Test_build_FailFastStopsAtFirstError(t *testing.T) {
+	services := stack.Services{
+		Functions: map[string]stack.Function{
+			"fn-b": {},
+			"fn-a": {},
+		},
+	}
+
+	errors := build(&services, 1, false, true)
+
+	if len(errors) != 1 {
+		t.Errorf("want only the first function built with --fail-fast, got %v", errors)
+	}
+}
+
+func Test_defaultBuildParallel(t *testing.T) {
+	got := defaultBuildParallel()
+
+	if got < 1 || got > maxDefaultBuildParallel {
+		t.Errorf("want between 1 and %d, got %d", maxDefaultBuildParallel, got)
+	}
+}
+
+func Test_checkRemoteHandlers(t *testing.T) {
+	functions := map[string]stack.Function{
+		"figlet":   {Handler: "./figlet"},
+		"nodeinfo": {Handler: "/src/nodeinfo"},
+		"prebuilt": {Handler: "./prebuilt", SkipBuild: true},
+	}
+
+	err := checkRemoteHandlers(functions, []string{"https://example.com/stack.yml"})
+	if err == nil || !strings.HasPrefix(err.Error(), "function figlet has the relative handler ./figlet, but the stack file was given by URL") {
+		t.Errorf("want an error for the relative handler, got %v", err)
+	}
+
+	delete(functions, "figlet")
+	if err := checkRemoteHandlers(functions, []string{"https://example.com/stack.yml"}); err != nil {
+		t.Errorf("want no error for absolute handlers, got %s", err)
+	}
+
+	functions["figlet"] = stack.Function{Handler: "./figlet"}
+	if err := checkRemoteHandlers(functions, []string{"https://example.com/stack.yml", "./local.yml"}); err != nil {
+		t.Errorf("want no error with a local stack file, got %s", err)
+	}
+}
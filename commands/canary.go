@@ -0,0 +1,88 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/openfaas/faas-cli/proxy"
+	types "github.com/openfaas/faas-provider/types"
+	"github.com/spf13/cobra"
+)
+
+// The label and annotation written on a canary by deploy --canary. A gateway
+// which supports weighted canaries sends the weight, as a percentage, of the
+// traffic for the function named by the label to the canary.
+const (
+	canaryLabel            = "com.openfaas.canary"
+	canaryWeightAnnotation = "com.openfaas.canary.weight"
+	canarySuffix           = "-canary"
+)
+
+const canaryGatewayNote = `Splitting the traffic needs a gateway which supports weighted canaries, from
+the "` + canaryLabel + `" label and "` + canaryWeightAnnotation + `"
+annotation. Other gateways deploy the canary as a separate function which only
+receives the requests sent to it by name.`
+
+func init() {
+	faasCmd.AddCommand(canaryCmd)
+}
+
+var canaryCmd = &cobra.Command{
+	Use:   `canary`,
+	Short: "OpenFaaS canary commands",
+	Long: `Promote or abort a canary deployed with "faas-cli deploy --canary".
+
+` + canaryGatewayNote,
+}
+
+// canaryFunctionName gives the name a canary of the function is deployed as
+func canaryFunctionName(name string) string {
+	return name + canarySuffix
+}
+
+// validateCanaryFlags checks the weight when a canary is deployed
+func validateCanaryFlags(deployFlags DeployFlags) error {
+	if len(deployFlags.canary) == 0 {
+		return nil
+	}
+
+	if deployFlags.canaryWeight < 1 || deployFlags.canaryWeight > 99 {
+		return fmt.Errorf("--weight must be between 1 and 99, but you gave: %d", deployFlags.canaryWeight)
+	}
+	return nil
+}
+
+// applyCanary deploys the spec as a canary of the function given by
+// --canary, with the weight of its traffic
+func applyCanary(spec *proxy.DeployFunctionSpec, deployFlags DeployFlags) {
+	if len(deployFlags.canary) == 0 {
+		return
+	}
+
+	spec.FunctionName = canaryFunctionName(deployFlags.canary)
+	spec.Labels = mergeMap(spec.Labels, map[string]string{canaryLabel: deployFlags.canary})
+	spec.Annotations = mergeMap(spec.Annotations, map[string]string{canaryWeightAnnotation: strconv.Itoa(deployFlags.canaryWeight)})
+}
+
+// checkCanaryOf checks the function was deployed as a canary of name, so
+// that a function which only happens to have the name is left alone
+func checkCanaryOf(canary types.FunctionStatus, name string) error {
+	if canary.Labels == nil || (*canary.Labels)[canaryLabel] != name {
+		return fmt.Errorf("%s is not a canary of %s, it has no %s=%s label", canaryFunctionName(name), name, canaryLabel, name)
+	}
+	return nil
+}
+
+func preRunCanaryName(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("name of the function with the canary required")
+	}
+
+	if len(args) > 1 {
+		return fmt.Errorf("too many values for function name")
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/spf13/cobra"
+)
+
+var canaryAbortCmd = &cobra.Command{
+	Use:   "abort NAME [--gateway GATEWAY_URL] [--namespace NAMESPACE]",
+	Short: "Remove the canary of a function",
+	Long: `Removes the canary of the function NAME, NAME-canary, so that the stable version
+serves all of the traffic again.`,
+	Example: `  faas-cli canary abort figlet
+  faas-cli canary abort figlet --namespace staging-fn`,
+	RunE:    runCanaryAbort,
+	PreRunE: preRunCanaryName,
+}
+
+func init() {
+	canaryAbortCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	canaryAbortCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
+	canaryAbortCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function")
+	canaryCmd.AddCommand(canaryAbortCmd)
+}
+
+func runCanaryAbort(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	canaryName := canaryFunctionName(name)
+	gatewayAddress := getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
+
+	if msg := checkTLSInsecure(gatewayAddress, tlsInsecure); len(msg) > 0 {
+		fmt.Println(msg)
+	}
+
+	cliAuth := NewCLIAuth(token, gatewayAddress)
+	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
+	client := proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
+	ctx := context.Background()
+
+	canary, err := client.GetFunctionInfo(ctx, canaryName, functionNamespace)
+	if err != nil {
+		return err
+	}
+
+	if err := checkCanaryOf(canary, name); err != nil {
+		return err
+	}
+
+	if err := client.DeleteFunction(ctx, canaryName, functionNamespace); err != nil {
+		return err
+	}
+
+	fmt.Printf("Aborted canary %s, %s serves all of the traffic.\n", canaryName, name)
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_canaryAbort(t *testing.T) {
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/figlet-canary",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody: types.FunctionStatus{
+				Name:   "figlet-canary",
+				Image:  "functions/figlet:0.14",
+				Labels: &map[string]string{canaryLabel: "figlet"},
+			},
+		},
+		{
+			Method:             http.MethodDelete,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusAccepted,
+		},
+	})
+	defer s.Close()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"canary",
+			"abort",
+			"figlet",
+			"--gateway=" + s.URL,
+		})
+		if err := faasCmd.Execute(); err != nil {
+			t.Errorf("want no error, got %s", err)
+		}
+	})
+
+	if !strings.Contains(stdOut, "Aborted canary figlet-canary, figlet serves all of the traffic.") {
+		t.Errorf("Output is not as expected:\n%s", stdOut)
+	}
+}
+
+func Test_canaryAbort_NameRequired(t *testing.T) {
+	faasCmd.SetArgs([]string{"canary", "abort"})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != "name of the function with the canary required" {
+		t.Errorf("want an error for the missing name, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/spf13/cobra"
+)
+
+var canaryPromoteCmd = &cobra.Command{
+	Use:   "promote NAME [--gateway GATEWAY_URL] [--namespace NAMESPACE]",
+	Short: "Send all of the traffic to the canary of a function",
+	Long: `Redeploys the function NAME with the image of its canary, NAME-canary, and then
+removes the canary, so that the new version serves all of the traffic. The
+image which was replaced is recorded so that "faas-cli rollback NAME" reverts it.
+
+Only the image of NAME is replaced, its environment variables, secrets,
+constraints, resources, labels and annotations are redeployed as reported by
+the gateway. A gateway which doesn't report these is refused, so redeploy from
+your stack file with the new image instead.`,
+	Example: `  faas-cli canary promote figlet
+  faas-cli canary promote figlet --namespace staging-fn`,
+	RunE:    runCanaryPromote,
+	PreRunE: preRunCanaryName,
+}
+
+func init() {
+	canaryPromoteCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	canaryPromoteCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
+	canaryPromoteCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function")
+	canaryCmd.AddCommand(canaryPromoteCmd)
+}
+
+func runCanaryPromote(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	canaryName := canaryFunctionName(name)
+	gatewayAddress := getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
+
+	if msg := checkTLSInsecure(gatewayAddress, tlsInsecure); len(msg) > 0 {
+		fmt.Println(msg)
+	}
+
+	cliAuth := NewCLIAuth(token, gatewayAddress)
+	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
+	client := proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
+	ctx := context.Background()
+
+	canary, err := client.GetFunctionInfo(ctx, canaryName, functionNamespace)
+	if err != nil {
+		return err
+	}
+
+	if err := checkCanaryOf(canary, name); err != nil {
+		return err
+	}
+
+	status, err := client.GetFunctionStatus(ctx, name, functionNamespace)
+	if err != nil {
+		return err
+	}
+
+	if err := checkReportsSpec(name, status, "promoting its canary"); err != nil {
+		return err
+	}
+
+	deploySpec := statusSpec(name, status)
+	deploySpec.Image = canary.Image
+	deploySpec.Labels = mergeMap(deploySpec.Labels, nil)
+	delete(deploySpec.Labels, canaryLabel)
+	deploySpec.Annotations = mergeMap(deploySpec.Annotations, nil)
+	delete(deploySpec.Annotations, canaryWeightAnnotation)
+	if len(status.Image) > 0 && status.Image != canary.Image {
+		deploySpec.Annotations[previousImageAnnotation] = status.Image
+	}
+	deploySpec.TLSInsecure = tlsInsecure
+	deploySpec.Token = token
+	deploySpec.Namespace = functionNamespace
+
+	fmt.Printf("Promoting %s to %s with image %s.\n", canaryName, name, canary.Image)
+
+	statusCode := client.DeployFunction(ctx, deploySpec)
+	if badStatusCode(statusCode) {
+		return &proxy.HTTPError{StatusCode: statusCode, Message: fmt.Sprintf("Function '%s' failed to be promoted with status code: %d", name, statusCode)}
+	}
+
+	if err := client.DeleteFunction(ctx, canaryName, functionNamespace); err != nil {
+		return fmt.Errorf("promoted %s, but unable to remove %s: %s", name, canaryName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_canaryPromote(t *testing.T) {
+	var (
+		deployed types.FunctionDeployment
+		deleted  map[string]string
+	)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/function/figlet-canary":
+			json.NewEncoder(w).Encode(types.FunctionStatus{
+				Name:        "figlet-canary",
+				Image:       "functions/figlet:0.14",
+				EnvProcess:  "figlet",
+				Labels:      &map[string]string{canaryLabel: "figlet", "team": "canary"},
+				Annotations: &map[string]string{canaryWeightAnnotation: "10"},
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/system/function/figlet":
+			json.NewEncoder(w).Encode(proxy.FunctionStatus{
+				FunctionStatus: types.FunctionStatus{
+					Name:        "figlet",
+					Image:       "functions/figlet:0.13",
+					EnvProcess:  "figlet",
+					Labels:      &map[string]string{"team": "web"},
+					Annotations: &map[string]string{"topic": "art"},
+				},
+				EnvVars:     map[string]string{"LOG_LEVEL": "info"},
+				Secrets:     []string{"api-key"},
+				Constraints: []string{"node.platform.os == linux"},
+				Limits:      &types.FunctionResources{Memory: "128Mi"},
+			})
+		case r.Method == http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&deployed)
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodDelete:
+			json.NewDecoder(r.Body).Decode(&deleted)
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"canary",
+			"promote",
+			"figlet",
+			"--gateway=" + s.URL,
+		})
+		if err := faasCmd.Execute(); err != nil {
+			t.Errorf("want no error, got %s", err)
+		}
+	})
+
+	if !strings.Contains(stdOut, "Promoting figlet-canary to figlet with image functions/figlet:0.14.") {
+		t.Fatalf("Output is not as expected:\n%s", stdOut)
+	}
+
+	if deployed.Service != "figlet" || deployed.Image != "functions/figlet:0.14" || deployed.EnvProcess != "figlet" {
+		t.Errorf("want figlet redeployed with the canary's image, got %+v", deployed)
+	}
+	if !reflect.DeepEqual(deployed.EnvVars, map[string]string{"LOG_LEVEL": "info"}) {
+		t.Errorf("want the env of figlet kept, got %v", deployed.EnvVars)
+	}
+	if !reflect.DeepEqual(deployed.Secrets, []string{"api-key"}) || !reflect.DeepEqual(deployed.Constraints, []string{"node.platform.os == linux"}) {
+		t.Errorf("want the secrets and constraints of figlet kept, got %v and %v", deployed.Secrets, deployed.Constraints)
+	}
+	if deployed.Limits == nil || deployed.Limits.Memory != "128Mi" {
+		t.Errorf("want the limits of figlet kept, got %v", deployed.Limits)
+	}
+	if _, ok := (*deployed.Labels)[canaryLabel]; ok || (*deployed.Labels)["team"] != "web" {
+		t.Errorf("want the labels of figlet without the canary label, got %v", *deployed.Labels)
+	}
+	annotations := *deployed.Annotations
+	if _, ok := annotations[canaryWeightAnnotation]; ok || annotations["topic"] != "art" {
+		t.Errorf("want the annotations of figlet without the weight, got %v", annotations)
+	}
+	if annotations[previousImageAnnotation] != "functions/figlet:0.13" {
+		t.Errorf("want the stable image recorded for rollback, got %v", annotations)
+	}
+
+	if deleted["functionName"] != "figlet-canary" {
+		t.Errorf("want the canary removed, got %v", deleted)
+	}
+}
+
+func Test_canaryPromote_NotACanary(t *testing.T) {
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/figlet-canary",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       types.FunctionStatus{Name: "figlet-canary", Image: "functions/figlet:0.14"},
+		},
+	})
+	defer s.Close()
+
+	faasCmd.SetArgs([]string{
+		"canary",
+		"promote",
+		"figlet",
+		"--gateway=" + s.URL,
+	})
+	err := faasCmd.Execute()
+
+	want := "figlet-canary is not a canary of figlet, it has no com.openfaas.canary=figlet label"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+func Test_canaryPromote_GatewayWithoutSpec(t *testing.T) {
+	deploys := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/function/figlet-canary":
+			json.NewEncoder(w).Encode(types.FunctionStatus{
+				Name:   "figlet-canary",
+				Image:  "functions/figlet:0.14",
+				Labels: &map[string]string{canaryLabel: "figlet"},
+			})
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(types.FunctionStatus{Name: "figlet", Image: "functions/figlet:0.13"})
+		default:
+			deploys++
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer s.Close()
+
+	faasCmd.SetArgs([]string{
+		"canary",
+		"promote",
+		"figlet",
+		"--gateway=" + s.URL,
+	})
+	err := faasCmd.Execute()
+
+	if err == nil || !strings.Contains(err.Error(), "so promoting its canary would remove them") {
+		t.Errorf("want the promote to be refused, got %v", err)
+	}
+	if deploys != 0 {
+		t.Errorf("want nothing deployed or removed, got %d requests", deploys)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_deploy_DryRun_Canary(t *testing.T) {
+	resetForTest()
+	defer func() {
+		deployFlags.dryRun = false
+		deployFlags.output = "yaml"
+		deployFlags.canary = ""
+		deployFlags.canaryWeight = 10
+	}()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"deploy",
+			"--image=functions/figlet:0.14",
+			"--canary=figlet",
+			"--weight=25",
+			"--dry-run",
+			"--output=json",
+		})
+		faasCmd.Execute()
+	})
+
+	var requests []types.FunctionDeployment
+	if err := json.Unmarshal([]byte(stdOut), &requests); err != nil {
+		t.Fatalf("want JSON output, got %q: %s", stdOut, err)
+	}
+
+	if len(requests) != 1 || requests[0].Service != "figlet-canary" || requests[0].Image != "functions/figlet:0.14" {
+		t.Fatalf("unexpected dry-run output: %s", stdOut)
+	}
+	if (*requests[0].Labels)[canaryLabel] != "figlet" {
+		t.Errorf("want the canary label for figlet, got %v", *requests[0].Labels)
+	}
+	if (*requests[0].Annotations)[canaryWeightAnnotation] != "25" {
+		t.Errorf("want the weight annotation 25, got %v", *requests[0].Annotations)
+	}
+}
+
+func Test_deploy_Canary_Errors(t *testing.T) {
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  fn1:
+    image: fn1:latest
+  fn2:
+    image: fn2:latest
+`)
+	stackFile.Close()
+
+	cases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "weight out of range",
+			args: []string{"deploy", "--image=figlet", "--canary=figlet", "--weight=100", "--dry-run"},
+			want: "--weight must be between 1 and 99, but you gave: 100",
+		},
+		{
+			name: "several functions in the stack file",
+			args: []string{"deploy", "-f", stackFile.Name(), "--canary=fn1", "--dry-run"},
+			want: "--canary deploys one function, but the stack file gives 2, use --filter to choose one",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resetForTest()
+			defer func() {
+				deployFlags.dryRun = false
+				deployFlags.canary = ""
+				deployFlags.canaryWeight = 10
+			}()
+
+			faasCmd.SetArgs(c.args)
+			err := faasCmd.Execute()
+			if err == nil || err.Error() != c.want {
+				t.Errorf("want error %q, got %v", c.want, err)
+			}
+		})
+	}
+}
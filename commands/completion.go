@@ -2,39 +2,103 @@ package commands
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/stack"
 	"github.com/spf13/cobra"
 )
 
 var shell string
 
 var completionCmd = &cobra.Command{
-	Use:   "completion SHELL",
+	Use:   "completion [bash|zsh|powershell]",
 	Short: "Generates shell auto completion",
-	Long: `Generates shell auto completion for Bash or ZSH.
+	Long: `Generates shell auto completion for Bash, ZSH or PowerShell and writes it to stdout.
+
+The names of the functions on the gateway are completed for describe, invoke,
+logs and remove in Bash and ZSH, using the --gateway and --namespace given on
+the command line.
 
 Please follow the instructions in the link below to activate the shell auto completion in your environment:
 https://docs.openfaas.com/cli/completion/`,
-	Example: `  faas-cli completion --shell bash
-  faas-cli completion --shell zsh`,
+	Example: `  faas-cli completion bash > /etc/bash_completion.d/faas-cli
+  faas-cli completion zsh > "${fpath[1]}/_faas-cli"
+  faas-cli completion powershell >> $PROFILE
+  faas-cli completion --shell bash`,
 	RunE: runCompletion,
 }
 
+// completeFunctionsCmd prints the names of the functions on the gateway, one
+// per line, for the shell completion of function names
+var completeFunctionsCmd = &cobra.Command{
+	Use:    "__complete_functions",
+	Hidden: true,
+	RunE:   runCompleteFunctions,
+}
+
+// bashCompletionFunction is called by the Bash completion when there is
+// nothing else to complete, it completes the function name for the commands
+// which take one
+const bashCompletionFunction = `__faas-cli_get_functions()
+{
+    local args=() i out
+    for ((i = 1; i < ${#words[@]} - 1; i++)); do
+        case "${words[i]}" in
+            -g|--gateway|-n|--namespace|-k|--token|-f|--yaml|--config)
+                args+=("${words[i]}" "${words[i+1]}")
+                ;;
+            --gateway=*|--namespace=*|--token=*|--yaml=*|--config=*|--tls-no-verify)
+                args+=("${words[i]}")
+                ;;
+        esac
+    done
+
+    if out=$(faas-cli __complete_functions "${args[@]}" 2>/dev/null); then
+        COMPREPLY=( $(compgen -W "${out}" -- "$cur") )
+    fi
+}
+
+__faas-cli_custom_func() {
+    case ${last_command} in
+        faas-cli_describe | faas-cli_invoke | faas-cli_logs | faas-cli_remove)
+            __faas-cli_get_functions
+            return
+            ;;
+        *)
+            ;;
+    esac
+}
+`
+
 func init() {
-	completionCmd.Flags().StringVar(&shell, "shell", "", "Outputs shell completion, must be bash or zsh")
-	completionCmd.MarkFlagRequired("shell")
+	completionCmd.Flags().StringVar(&shell, "shell", "", "Outputs shell completion, must be bash, zsh or powershell")
+
+	completeFunctionsCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	completeFunctionsCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the functions")
+	completeFunctionsCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
 
+	faasCmd.BashCompletionFunction = bashCompletionFunction
 	faasCmd.AddCommand(completionCmd)
+	faasCmd.AddCommand(completeFunctionsCmd)
 }
 
 func runCompletion(cmd *cobra.Command, args []string) (err error) {
-	if shell == "" {
-		return fmt.Errorf("--shell is required and must be bash or zsh")
+	if len(args) > 1 {
+		return fmt.Errorf("give one shell, must be bash, zsh or powershell")
+	}
+	shellName := shell
+	if len(args) == 1 {
+		shellName = args[0]
+	}
+
+	if shellName == "" {
+		return fmt.Errorf("give the shell as an argument, must be bash, zsh or powershell")
 	}
 
-	switch shell {
+	switch shellName {
 	case "bash":
 		err = generateBashCompletion()
 		if err != nil {
@@ -49,11 +113,40 @@ func runCompletion(cmd *cobra.Command, args []string) (err error) {
 		}
 		return nil
 
+	case "powershell":
+		return faasCmd.GenPowerShellCompletion(os.Stdout)
+
+	case "fish":
+		return fmt.Errorf("fish shell not supported yet, must be bash, zsh or powershell")
+
 	default:
-		return fmt.Errorf("%q shell not supported, must be bash or zsh", shell)
+		return fmt.Errorf("%q shell not supported, must be bash, zsh or powershell", shellName)
 	}
 }
 
+func runCompleteFunctions(cmd *cobra.Command, args []string) error {
+	var yamlGateway string
+	if len(yamlFile) > 0 {
+		if services, err := stack.ParseYAMLFiles(stackFiles(), regex, filter, true); err == nil && services != nil {
+			yamlGateway = services.Provider.GatewayURL
+		}
+	}
+	gatewayAddress := getGatewayURL(gateway, defaultGateway, yamlGateway, os.Getenv(openFaaSURLEnvironment))
+
+	cliAuth := NewCLIAuth(token, gatewayAddress)
+	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
+	proxyClient := proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
+	functions, err := proxyClient.ListFunctions(context.Background(), functionNamespace)
+	if err != nil {
+		return err
+	}
+
+	for _, function := range functions {
+		fmt.Println(function.Name)
+	}
+	return nil
+}
+
 func generateBashCompletion() error {
 	err := faasCmd.GenBashCompletion(os.Stdout)
 	if err != nil {
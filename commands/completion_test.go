@@ -1,7 +1,13 @@
 package commands
 
 import (
+	"bytes"
+	"net/http"
+	"strings"
 	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
 )
 
 func Test_ValidShell(t *testing.T) {
@@ -9,6 +15,9 @@ func Test_ValidShell(t *testing.T) {
 	testArgs := [][]string{
 		{"completion", "--shell", "bash"},
 		{"completion", "--shell", "zsh"},
+		{"completion", "bash"},
+		{"completion", "zsh"},
+		{"completion", "powershell"},
 	}
 
 	for _, arg := range testArgs {
@@ -22,3 +31,67 @@ func Test_ValidShell(t *testing.T) {
 		}
 	}
 }
+
+func Test_InvalidShell(t *testing.T) {
+	defer func() { shell = "" }()
+
+	testCases := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"completion", "tcsh"}, want: `"tcsh" shell not supported, must be bash, zsh or powershell`},
+		{args: []string{"completion", "fish"}, want: "fish shell not supported yet, must be bash, zsh or powershell"},
+		{args: []string{"completion", "bash", "zsh"}, want: "give one shell, must be bash, zsh or powershell"},
+	}
+
+	for _, testCase := range testCases {
+		faasCmd.SetArgs(testCase.args)
+		test.CaptureStdout(func() {
+			err := faasCmd.Execute()
+			if err == nil || err.Error() != testCase.want {
+				t.Errorf("want error %q, got %v", testCase.want, err)
+			}
+		})
+	}
+}
+
+func Test_BashCompletion_CompletesFunctionNames(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := faasCmd.GenBashCompletion(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"__faas-cli_custom_func()", "faas-cli __complete_functions", "faas-cli_describe | faas-cli_invoke | faas-cli_logs | faas-cli_remove"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("want the completion to contain %q", want)
+		}
+	}
+}
+
+func Test_completeFunctions(t *testing.T) {
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody: []types.FunctionStatus{
+				{Name: "figlet"},
+				{Name: "nodeinfo"},
+			},
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"__complete_functions", "--gateway=" + s.URL})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if stdOut != "figlet\nnodeinfo\n" {
+		t.Errorf("want one function name per line, got %q", stdOut)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configCmd.AddCommand(configSetGatewayCmd)
+	configCmd.AddCommand(configGetGatewayCmd)
+
+	faasCmd.AddCommand(configCmd)
+}
+
+// configCmd manages the settings in the config file
+var configCmd = &cobra.Command{
+	Use:   `config`,
+	Short: "Manage the settings in the config file",
+	Long: `Manage the settings in the config file, which is given by --config, then
+OPENFAAS_CONFIG and is ~/.openfaas/config.yml otherwise.
+
+The gateway used by each command is, in order of precedence:
+  1. the --gateway flag
+  2. the gateway of the provider in the stack file
+  3. the OPENFAAS_URL environment variable
+  4. the default gateway set with "faas-cli config set-gateway"
+  5. ` + defaultGateway,
+}
+
+var configSetGatewayCmd = &cobra.Command{
+	Use:   `set-gateway URL`,
+	Short: "Set the gateway used when no other gateway is given",
+	Long: `Sets the default gateway in the config file, which is used when no gateway is
+given by --gateway, the stack file or OPENFAAS_URL. Give an empty URL to
+remove it.`,
+	Example: `  faas-cli config set-gateway https://openfaas.example.com
+  faas-cli config set-gateway ""`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigSetGateway,
+}
+
+var configGetGatewayCmd = &cobra.Command{
+	Use:     `get-gateway`,
+	Short:   "Print the gateway set with set-gateway",
+	Example: `  faas-cli config get-gateway`,
+	Args:    cobra.NoArgs,
+	RunE:    runConfigGetGateway,
+}
+
+func runConfigSetGateway(cmd *cobra.Command, args []string) error {
+	gatewayURL := strings.TrimRight(strings.TrimSpace(args[0]), "/")
+	if len(gatewayURL) > 0 && !strings.HasPrefix(gatewayURL, "http://") && !strings.HasPrefix(gatewayURL, "https://") {
+		return fmt.Errorf("the gateway URL must start with http:// or https://")
+	}
+
+	if err := config.SetDefaultGateway(gatewayURL); err != nil {
+		return err
+	}
+
+	if len(gatewayURL) == 0 {
+		fmt.Println("Default gateway removed.")
+		return nil
+	}
+
+	fmt.Printf("Default gateway set to: %s\n", gatewayURL)
+	return nil
+}
+
+func runConfigGetGateway(cmd *cobra.Command, args []string) error {
+	gatewayURL, err := config.GetDefaultGateway()
+	if err != nil {
+		return err
+	}
+
+	if len(gatewayURL) == 0 {
+		return fmt.Errorf("no default gateway is set, use \"faas-cli config set-gateway URL\" to set one")
+	}
+
+	fmt.Println(gatewayURL)
+	return nil
+}
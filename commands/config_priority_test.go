@@ -9,6 +9,9 @@ import (
 
 // Test_getGatewayURL tests for priority of URL for gateway over several sources
 func Test_getGatewayURL(t *testing.T) {
+	defer func(f func() string) { configuredGateway = f }(configuredGateway)
+	configuredGateway = func() string { return "" }
+
 	defaultValue := "http://127.0.0.1:8080"
 	testCases := []struct {
 		name       string
@@ -17,6 +20,7 @@ func Test_getGatewayURL(t *testing.T) {
 		yamlURL        string
 		argumentURL    string
 		environmentURL string
+		configURL      string
 		expectedURL    string
 	}{
 		{
@@ -70,10 +74,31 @@ func Test_getGatewayURL(t *testing.T) {
 			argumentURL:    "http://remote1:8080",
 			expectedURL:    "http://remote1:8080",
 		},
+		{
+			name:        "Config file default used when nothing else is provided",
+			defaultURL:  defaultValue,
+			configURL:   "https://config.example.com",
+			expectedURL: "https://config.example.com",
+		},
+		{
+			name:           "Env-var over config file default",
+			defaultURL:     defaultValue,
+			environmentURL: "http://remote2:8080",
+			configURL:      "https://config.example.com",
+			expectedURL:    "http://remote2:8080",
+		},
+		{
+			name:        "YAML over config file default",
+			defaultURL:  defaultValue,
+			yamlURL:     "http://remote-yml:8080",
+			configURL:   "https://config.example.com",
+			expectedURL: "http://remote-yml:8080",
+		},
 	}
 
 	fails := 0
 	for _, testCase := range testCases {
+		configuredGateway = func() string { return testCase.configURL }
 		url := getGatewayURL(testCase.argumentURL, testCase.defaultURL, testCase.yamlURL, testCase.environmentURL)
 		if url != testCase.expectedURL {
 			t.Logf("gatewayURL %s\nwant: %s, got: %s", testCase.name, testCase.expectedURL, url)
@@ -0,0 +1,68 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_configSetGateway(t *testing.T) {
+	resetForTest()
+	dir, err := ioutil.TempDir("", "faas-cli-config-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "config.yml")
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"config", "set-gateway", "https://openfaas.example.com/", "--config", configPath})
+		err = faasCmd.Execute()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Default gateway set to: https://openfaas.example.com\n"; stdOut != want {
+		t.Errorf("want %q, got %q", want, stdOut)
+	}
+
+	stdOut = test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"config", "get-gateway", "--config", configPath})
+		err = faasCmd.Execute()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://openfaas.example.com\n"; stdOut != want {
+		t.Errorf("want %q, got %q", want, stdOut)
+	}
+
+	if got := getGatewayURL("", defaultGateway, "", ""); got != "https://openfaas.example.com" {
+		t.Errorf("want the default gateway from the config file, got %s", got)
+	}
+	resetForTest()
+
+	data, _ := ioutil.ReadFile(configPath)
+	if !strings.Contains(string(data), "default_gateway: https://openfaas.example.com") {
+		t.Errorf("want default_gateway in the config file, got %q", string(data))
+	}
+}
+
+func Test_configSetGateway_InvalidURL(t *testing.T) {
+	resetForTest()
+
+	faasCmd.SetArgs([]string{"config", "set-gateway", "openfaas.example.com"})
+	err := faasCmd.Execute()
+
+	want := "the gateway URL must start with http:// or https://"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
@@ -8,19 +8,22 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/docker/docker-credential-helpers/client"
 	homedir "github.com/mitchellh/go-homedir"
-	"github.com/openfaas/faas-cli/builder"
 	"github.com/openfaas/faas-cli/proxy"
 	"github.com/openfaas/faas-cli/schema"
 	"github.com/openfaas/faas-cli/stack"
+	types "github.com/openfaas/faas-provider/types"
 	"github.com/spf13/cobra"
 	yaml "gopkg.in/yaml.v2"
 )
@@ -33,6 +36,7 @@ var (
 // DeployFlags holds flags that are to be added to commands.
 type DeployFlags struct {
 	envvarOpts             []string
+	envFiles               []string
 	replace                bool
 	update                 bool
 	readOnlyRootFilesystem bool
@@ -41,6 +45,21 @@ type DeployFlags struct {
 	labelOpts              []string
 	annotationOpts         []string
 	sendRegistryAuth       bool
+	wait                   bool
+	waitTimeout            time.Duration
+	dryRun                 bool
+	output                 string
+	parallel               int
+	secretsFromEnv         string
+	memoryLimits           []string
+	cpuLimits              []string
+	memoryRequests         []string
+	cpuRequests            []string
+	canary                 string
+	canaryWeight           int
+	diff                   bool
+	yes                    bool
+	updateEnv              []string
 }
 
 var deployFlags DeployFlags
@@ -48,7 +67,7 @@ var deployFlags DeployFlags
 func init() {
 	// Setup flags that are used by multiple commands (variables defined in faas.go)
 	deployCmd.Flags().StringVar(&fprocess, "fprocess", "", "fprocess value to be run as a serverless function by the watchdog")
-	deployCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	deployCmd.Flags().VarP(&gatewaysFlag{}, "gateway", "g", "Gateway URL starting with http(s)://, repeat to deploy to each gateway in turn (default \""+defaultGateway+"\")")
 	deployCmd.Flags().StringVar(&handler, "handler", "", "Directory with handler for function, e.g. handler.js")
 	deployCmd.Flags().StringVar(&image, "image", "", "Docker image name to build")
 	deployCmd.Flags().StringVar(&language, "lang", "", "Programming language template")
@@ -58,6 +77,7 @@ func init() {
 
 	// Setup flags that are used only by this command (variables defined above)
 	deployCmd.Flags().StringArrayVarP(&deployFlags.envvarOpts, "env", "e", []string{}, "Set one or more environment variables (ENVVAR=VALUE)")
+	deployCmd.Flags().StringArrayVar(&deployFlags.envFiles, "env-file", []string{}, "Read environment variables from a file of KEY=VALUE lines, overridden by --env and the stack file")
 
 	deployCmd.Flags().StringArrayVarP(&deployFlags.labelOpts, "label", "l", []string{}, "Set one or more label (LABEL=VALUE)")
 
@@ -66,14 +86,29 @@ func init() {
 	deployCmd.Flags().BoolVar(&deployFlags.replace, "replace", false, "Remove and re-create existing function(s)")
 	deployCmd.Flags().BoolVar(&deployFlags.update, "update", true, "Perform rolling update on existing function(s)")
 
-	deployCmd.Flags().StringArrayVar(&deployFlags.constraints, "constraint", []string{}, "Apply a constraint to the function")
+	deployCmd.Flags().StringArrayVar(&deployFlags.constraints, "constraint", []string{}, "Apply a placement constraint such as \"node.platform.os == linux\" to the function")
 	deployCmd.Flags().StringArrayVar(&deployFlags.secrets, "secret", []string{}, "Give the function access to a secure secret")
+	deployCmd.Flags().StringVar(&deployFlags.secretsFromEnv, "with-secrets-from-env", "", "Create or update the secrets used by the function(s) from environment variables with this prefix, i.e. PREFIX_API_KEY for api-key")
+	deployCmd.Flags().StringArrayVar(&deployFlags.memoryLimits, "memory-limit", []string{}, "Override the memory limit of every function, or of one with FUNCTION=QUANTITY, i.e. 128Mi")
+	deployCmd.Flags().StringArrayVar(&deployFlags.cpuLimits, "cpu-limit", []string{}, "Override the CPU limit of every function, or of one with FUNCTION=QUANTITY, i.e. 500m")
+	deployCmd.Flags().StringArrayVar(&deployFlags.memoryRequests, "memory-request", []string{}, "Override the memory request of every function, or of one with FUNCTION=QUANTITY, i.e. 64Mi")
+	deployCmd.Flags().StringArrayVar(&deployFlags.cpuRequests, "cpu-request", []string{}, "Override the CPU request of every function, or of one with FUNCTION=QUANTITY, i.e. 100m")
+	deployCmd.Flags().StringVar(&deployFlags.canary, "canary", "", "Deploy the function as a canary of the function NAME, which keeps serving the rest of the traffic")
+	deployCmd.Flags().IntVar(&deployFlags.canaryWeight, "weight", 10, "Percentage of the traffic sent to the --canary")
 	deployCmd.Flags().BoolVar(&deployFlags.readOnlyRootFilesystem, "readonly", false, "Force the root container filesystem to be read only")
+	deployCmd.Flags().BoolVar(&deployFlags.wait, "wait", false, "Wait for the function(s) to have at least one available replica after deploying")
+	deployCmd.Flags().DurationVar(&deployFlags.waitTimeout, "wait-timeout", 60*time.Second, "Timeout for --wait")
+	deployCmd.Flags().IntVar(&deployFlags.parallel, "parallel", 4, "Deploy functions from the YAML file in parallel to the depth specified")
+	addFailFastFlags(deployCmd)
+	deployCmd.Flags().BoolVar(&deployFlags.diff, "diff", false, "Print the changes to each deployed function and ask before deploying them")
+	deployCmd.Flags().BoolVarP(&deployFlags.yes, "yes", "y", false, "Deploy the changes printed by --diff without asking for confirmation")
+	deployCmd.Flags().StringArrayVar(&deployFlags.updateEnv, "update-env", []string{}, "Redeploy the function named as it is deployed, with only this environment variable changed (KEY=VALUE) or removed (KEY-)")
+	deployCmd.Flags().BoolVar(&deployFlags.dryRun, "dry-run", false, "Print the deployment request for each function without calling the gateway")
+	deployCmd.Flags().StringVar(&deployFlags.output, "output", "yaml", "Output format for --dry-run, either yaml or json")
 
 	deployCmd.Flags().BoolVarP(&deployFlags.sendRegistryAuth, "send-registry-auth", "a", false, "send registryAuth from Docker credentials manager with the request")
 	deployCmd.Flags().Var(&tagFormat, "tag", "Override latest tag on function Docker image, accepts 'latest', 'sha', 'branch', or 'describe'")
 
-	deployCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
 	deployCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yml file")
 	deployCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
 	// Set bash-completion.
@@ -85,11 +120,11 @@ func init() {
 
 // deployCmd handles deploying OpenFaaS function containers
 var deployCmd = &cobra.Command{
-	Use: `deploy -f YAML_FILE [--replace=false]
+	Use: `deploy -f YAML_FILE [FUNCTION_NAME ...] [--replace=false]
   faas-cli deploy --image IMAGE_NAME
                   --name FUNCTION_NAME
                   [--lang <ruby|python|node|csharp>]
-                  [--gateway GATEWAY_URL]
+                  [--gateway GATEWAY_URL ...]
                   [--network NETWORK_NAME]
                   [--handler HANDLER_DIR]
                   [--fprocess PROCESS]
@@ -102,25 +137,79 @@ var deployCmd = &cobra.Command{
                   [--regex "REGEX"]
                   [--filter "WILDCARD"]
 				  [--secret "SECRET_NAME"]
+				  [--with-secrets-from-env PREFIX_]
 				  [--tag <sha|branch|describe>]
 				  [--readonly=false]
-				  [--tls-no-verify]`,
+				  [--canary NAME] [--weight PERCENTAGE]
+				  [--memory-limit [FUNCTION=]QUANTITY] [--cpu-limit [FUNCTION=]QUANTITY]
+				  [--memory-request [FUNCTION=]QUANTITY] [--cpu-request [FUNCTION=]QUANTITY]
+				  [--wait] [--wait-timeout TIMEOUT]
+				  [--dry-run] [--output <yaml|json>]
+				  [--diff [--yes]]
+				  [--parallel PARALLEL_DEPTH] [--continue-on-error]
+				  [--tls-no-verify]
+  faas-cli deploy FUNCTION_NAME --update-env KEY=VALUE [--update-env KEY- ...]`,
 
 	Short: "Deploy OpenFaaS functions",
 	Long: `Deploys OpenFaaS function containers either via the supplied YAML config using
 the "--yaml" flag (which may contain multiple function definitions), or directly
-via flags. Note: --replace and --update are mutually exclusive.`,
+via flags. Note: --replace and --update are mutually exclusive.
+
+Give the names of functions in the YAML config, or globs such as "web-*", to
+deploy only those functions, along with those matching --regex or --filter.
+
+The --memory-limit, --cpu-limit, --memory-request and --cpu-request flags
+override the limits and requests of the stack file. A value such as 128Mi is
+used for every function and one such as FUNCTION=128Mi only for that function,
+which takes precedence.
+
+Each --constraint is added to the constraints of the stack file, replacing any
+with the same key, and must be in the form "key == value" or "key != value".
+
+The --canary flag deploys the function as NAME-canary, to receive the --weight
+percentage of the traffic for NAME, then "faas-cli canary promote NAME" replaces
+NAME with it or "faas-cli canary abort NAME" removes it.
+
+` + canaryGatewayNote + `
+
+The --diff flag compares each function in the stack file with the one which is
+deployed, printing the image, environment, labels, annotations, limits and
+requests as a unified diff, a function which is not deployed is shown as new.
+The changes are deployed once confirmed, or straight away with --yes. Fields
+which the provider doesn't report are shown as added.
+
+The --update-env flag redeploys a single function from the spec reported by
+the gateway with only the given environment variables set, or removed with
+KEY-, so that labels, annotations, secrets and limits are kept without a stack
+file. The environment the function is left with is printed. A gateway which
+doesn't report the environment, secrets, constraints or resources of its
+functions is refused.
+
+` + deployGatewaysNote + `
+
+` + failFastNote,
 	Example: `  faas-cli deploy -f https://domain/path/myfunctions.yml
   faas-cli deploy -f ./stack.yml
   faas-cli deploy -f ./stack.yml --label canary=true
   faas-cli deploy -f ./stack.yml --annotation user=true
+  faas-cli deploy -f ./stack.yml figlet "web-*"
   faas-cli deploy -f ./stack.yml --filter "*gif*" --secret dockerhuborg
   faas-cli deploy -f ./stack.yml --regex "fn[0-9]_.*"
+  faas-cli deploy -f ./stack.yml --regex "^api-" figlet
   faas-cli deploy -f ./stack.yml --replace=false --update=true
   faas-cli deploy -f ./stack.yml --replace=true --update=false
   faas-cli deploy -f ./stack.yml --tag sha
   faas-cli deploy -f ./stack.yml --tag branch
   faas-cli deploy -f ./stack.yml --tag describe
+  faas-cli deploy -f ./stack.yml --wait --wait-timeout 2m
+  faas-cli deploy -f ./stack.yml --dry-run --output json
+  faas-cli deploy -f ./stack.yml --diff
+  faas-cli deploy figlet --update-env LOG_LEVEL=debug --update-env DEBUG-
+  faas-cli deploy -f ./stack.yml -g https://staging.example.com -g https://prod.example.com
+  faas-cli deploy -f ./stack.yml --with-secrets-from-env CI_SECRET_
+  faas-cli deploy -f ./stack.yml --memory-limit 256Mi --cpu-limit fn1=500m
+  faas-cli deploy -f ./stack.yml --constraint "node.platform.os == linux"
+  faas-cli deploy --image=functions/figlet:0.14 --canary figlet --weight 10
   faas-cli deploy --image=alexellis/faas-url-ping --name=url-ping
   faas-cli deploy --image=my_image --name=my_fn --handler=/path/to/fn/
                   --gateway=http://remote-site.com:8080 --lang=python
@@ -133,14 +222,37 @@ via flags. Note: --replace and --update are mutually exclusive.`,
 func preRunDeploy(cmd *cobra.Command, args []string) error {
 	language, _ = validateLanguageFlag(language)
 
+	if len(deployGateways) > 1 && deployFlags.dryRun {
+		return fmt.Errorf("--dry-run prints the requests without calling a gateway, give a single --gateway")
+	}
 	return nil
 }
 
 func runDeploy(cmd *cobra.Command, args []string) error {
-	return runDeployCommand(args, image, fprocess, functionName, deployFlags, tagFormat)
+	if len(deployGateways) < 2 {
+		return runDeployGateway(cmd, args)
+	}
+
+	// Unlike the functions of a stack file, each gateway is tried unless
+	// --fail-fast was asked for
+	skipAfterFailure := failFast && cmd.Flags().Changed("fail-fast")
+	results := deployToGateways(deployGateways, skipAfterFailure, func() error {
+		return runDeployGateway(cmd, args)
+	})
+	printGatewaySummary(results)
+	return gatewaysFailed(results)
+}
+
+// runDeployGateway deploys to the gateway given by --gateway, the stack file
+// or the environment
+func runDeployGateway(cmd *cobra.Command, args []string) error {
+	if len(deployFlags.updateEnv) > 0 {
+		return runDeployUpdateEnv(args, deployFlags.updateEnv)
+	}
+	return runDeployCommand(cmd.InOrStdin(), args, image, fprocess, functionName, deployFlags, tagFormat)
 }
 
-func runDeployCommand(args []string, image string, fprocess string, functionName string, deployFlags DeployFlags, tagMode schema.BuildFormat) error {
+func runDeployCommand(in io.Reader, args []string, image string, fprocess string, functionName string, deployFlags DeployFlags, tagMode schema.BuildFormat) error {
 	if deployFlags.update && deployFlags.replace {
 		fmt.Println(`Cannot specify --update and --replace at the same time. One of --update or --replace must be false.
   --replace    removes an existing deployment before re-creating it
@@ -148,9 +260,41 @@ func runDeployCommand(args []string, image string, fprocess string, functionName
 		return fmt.Errorf("cannot specify --update and --replace at the same time")
 	}
 
+	if deployFlags.parallel < 1 {
+		return fmt.Errorf("the --parallel flag must be greater than 0")
+	}
+
+	if deployFlags.dryRun && deployFlags.output != "yaml" && deployFlags.output != "json" {
+		return fmt.Errorf("--output must be yaml or json, not: %s", deployFlags.output)
+	}
+
+	if deployFlags.diff && deployFlags.dryRun {
+		return fmt.Errorf("--diff deploys the changes once confirmed, so it can't be used with --dry-run")
+	}
+	if deployFlags.diff && len(yamlFile) == 0 {
+		return fmt.Errorf("--diff compares the functions in the stack file with those deployed, so it needs --yaml")
+	}
+
+	resources, err := parseResourceFlags(deployFlags)
+	if err != nil {
+		return err
+	}
+
+	if err := validateCanaryFlags(deployFlags); err != nil {
+		return err
+	}
+
+	if err := validateConstraints(deployFlags.constraints); err != nil {
+		return err
+	}
+
 	var services stack.Services
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		if err := checkStackFiles(os.Stderr, stackFiles(), false); err != nil {
+			return err
+		}
+
+		parsedServices, err := parseSelectedStack(args)
 		if err != nil {
 			return err
 		}
@@ -165,12 +309,27 @@ func runDeployCommand(args []string, image string, fprocess string, functionName
 		if parsedServices != nil {
 			services = *parsedServices
 		}
+
+		if err := resources.checkFunctions(services.Functions); err != nil {
+			return err
+		}
+
+		if len(deployFlags.canary) > 0 && len(services.Functions) != 1 {
+			return fmt.Errorf("--canary deploys one function, but the stack file gives %d, use --filter to choose one", len(services.Functions))
+		}
 	}
 
 	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
 	ctx := context.Background()
 
-	var failedStatusCodes = make(map[string]int)
+	var (
+		failedStatusCodes = make(map[string]int)
+		deployed          []deployedFunction
+		proxyClient       *proxy.Client
+		dryRunRequests    []types.FunctionDeployment
+		deploySpecs       []*proxy.DeployFunctionSpec
+	)
+
 	if len(services.Functions) > 0 {
 
 		if len(services.Provider.Network) == 0 {
@@ -178,21 +337,24 @@ func runDeployCommand(args []string, image string, fprocess string, functionName
 		}
 
 		cliAuth := NewCLIAuth(token, services.Provider.GatewayURL)
-		proxyClient := proxy.NewClient(cliAuth, services.Provider.GatewayURL, transport, &commandTimeout)
+		proxyClient = proxy.NewClient(cliAuth, services.Provider.GatewayURL, transport, &commandTimeout)
+
+		dotEnvironment, err := readEnvFiles(deployFlags.envFiles)
+		if err != nil {
+			return err
+		}
 
 		for k, function := range services.Functions {
 
 			functionSecrets := deployFlags.secrets
 
 			function.Name = k
-			fmt.Printf("Deploying: %s.\n", function.Name)
 
-			var functionConstraints []string
+			var stackConstraints []string
 			if function.Constraints != nil {
-				functionConstraints = *function.Constraints
-			} else if len(deployFlags.constraints) > 0 {
-				functionConstraints = deployFlags.constraints
+				stackConstraints = *function.Constraints
 			}
+			functionConstraints := mergeConstraints(stackConstraints, deployFlags.constraints)
 
 			if len(function.Secrets) > 0 {
 				functionSecrets = mergeSlice(function.Secrets, functionSecrets)
@@ -227,7 +389,7 @@ func runDeployCommand(args []string, image string, fprocess string, functionName
 
 			allLabels := mergeMap(labelMap, labelArgumentMap)
 
-			allEnvironment, envErr := compileEnvironment(deployFlags.envvarOpts, function.Environment, fileEnvironment)
+			allEnvironment, envErr := compileEnvironment(deployFlags.envvarOpts, mergeMap(dotEnvironment, function.Environment), fileEnvironment)
 			if envErr != nil {
 				return envErr
 			}
@@ -245,30 +407,27 @@ Error: %s`, fprocessErr.Error())
 				}
 			}
 
+			limits, requests := resources.apply(function.Name, function.Limits, function.Requests)
 			functionResourceRequest := proxy.FunctionResourceRequest{
-				Limits:   function.Limits,
-				Requests: function.Requests,
+				Limits:   limits,
+				Requests: requests,
 			}
 
-			var annotations map[string]string
-			if function.Annotations != nil {
-				annotations = *function.Annotations
-			}
+			annotations := functionAnnotations(function)
 
-			annotationArgs, annotationErr := parseMap(deployFlags.annotationOpts, "annotation")
+			annotationArgs, annotationErr := parseAnnotations(deployFlags.annotationOpts)
 			if annotationErr != nil {
 				return fmt.Errorf("error parsing annotations: %v", annotationErr)
 			}
 
 			allAnnotations := mergeMap(annotations, annotationArgs)
 
-			branch, sha, err := builder.GetImageTagValues(tagMode)
-			if err != nil {
-				return err
+			if pinned, ok := pinnedImages[k]; ok {
+				function.Image = pinned
+			} else {
+				function.Image = resolveImageTag(tagMode).imageName(function.Image)
 			}
 
-			function.Image = schema.BuildImageName(tagMode, function.Image, sha, branch)
-
 			if deployFlags.readOnlyRootFilesystem {
 				function.ReadOnlyRootFilesystem = deployFlags.readOnlyRootFilesystem
 			}
@@ -293,22 +452,66 @@ Error: %s`, fprocessErr.Error())
 				Token:                   token,
 				Namespace:               function.Namespace,
 			}
+			applyCanary(deploySpec, deployFlags)
+
+			if deployFlags.dryRun {
+				dryRunRequests = append(dryRunRequests, proxy.GenerateFunctionDeployment(deploySpec))
+				continue
+			}
+
+			deploySpecs = append(deploySpecs, deploySpec)
+		}
+
+		if len(deploySpecs) > 0 {
+			if deployFlags.diff {
+				changed, err := diffFunctions(ctx, proxyClient, deploySpecs, os.Stdout)
+				if err != nil {
+					return err
+				}
+				if !changed {
+					fmt.Println("No changes to deploy.")
+				}
+				if err := confirmDeploy(in, len(deploySpecs), deployFlags.yes); err != nil {
+					return err
+				}
+			}
 
-			if msg := checkTLSInsecure(services.Provider.GatewayURL, deploySpec.TLSInsecure); len(msg) > 0 {
+			if msg := checkTLSInsecure(services.Provider.GatewayURL, tlsInsecure); len(msg) > 0 {
 				fmt.Println(msg)
 			}
-			statusCode := proxyClient.DeployFunction(ctx, deploySpec)
-			if badStatusCode(statusCode) {
-				failedStatusCodes[k] = statusCode
+
+			if len(deployFlags.secretsFromEnv) > 0 {
+				secrets := secretsFromEnv(deployFlags.secretsFromEnv, deploySpecs, functionNamespace, os.LookupEnv)
+				if err := applySecrets(ctx, proxyClient, secrets); err != nil {
+					return err
+				}
+			}
+
+			results := deployAll(ctx, proxyClient, deploySpecs, deployFlags.parallel)
+			for _, result := range results {
+				if result.skipped {
+					continue
+				} else if badStatusCode(result.statusCode) {
+					failedStatusCodes[result.name] = result.statusCode
+				} else {
+					deployed = append(deployed, deployedFunction{name: result.name, namespace: result.namespace})
+				}
+			}
+
+			if len(results) > 1 {
+				printDeploySummary(results)
 			}
 		}
 	} else {
+		if len(deployFlags.canary) > 0 {
+			functionName = canaryFunctionName(deployFlags.canary)
+		}
 		if len(image) == 0 || len(functionName) == 0 {
 			return fmt.Errorf("To deploy a function give --yaml/-f or a --image and --name flag")
 		}
 		gateway = getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
 		cliAuth := NewCLIAuth(token, gateway)
-		proxyClient := proxy.NewClient(cliAuth, gateway, transport, &commandTimeout)
+		proxyClient = proxy.NewClient(cliAuth, gateway, transport, &commandTimeout)
 
 		var registryAuth string
 		if deployFlags.sendRegistryAuth {
@@ -323,21 +526,48 @@ Error: %s`, fprocessErr.Error())
 		// default to a readable filesystem until we get more input about the expected behavior
 		// and if we want to add another flag for this case
 		defaultReadOnlyRFS := false
-		statusCode, err := deployImage(ctx, proxyClient, image, fprocess, functionName, registryAuth, deployFlags,
-			tlsInsecure, defaultReadOnlyRFS, token, functionNamespace)
-		if err != nil {
-			return err
-		}
+		if deployFlags.dryRun {
+			deploySpec, err := makeImageDeploySpec(image, fprocess, functionName, registryAuth, deployFlags,
+				tlsInsecure, defaultReadOnlyRFS, token, functionNamespace)
+			if err != nil {
+				return err
+			}
+			dryRunRequests = append(dryRunRequests, proxy.GenerateFunctionDeployment(deploySpec))
+		} else {
+			if len(deployFlags.secretsFromEnv) > 0 {
+				specs := []*proxy.DeployFunctionSpec{{Secrets: deployFlags.secrets, Namespace: functionNamespace}}
+				secrets := secretsFromEnv(deployFlags.secretsFromEnv, specs, functionNamespace, os.LookupEnv)
+				if err := applySecrets(ctx, proxyClient, secrets); err != nil {
+					return err
+				}
+			}
 
-		if badStatusCode(statusCode) {
-			failedStatusCodes[functionName] = statusCode
+			statusCode, err := deployImage(ctx, proxyClient, image, fprocess, functionName, registryAuth, deployFlags,
+				tlsInsecure, defaultReadOnlyRFS, token, functionNamespace)
+			if err != nil {
+				return err
+			}
+
+			if badStatusCode(statusCode) {
+				failedStatusCodes[functionName] = statusCode
+			} else {
+				deployed = append(deployed, deployedFunction{name: functionName, namespace: functionNamespace})
+			}
 		}
 	}
 
+	if deployFlags.dryRun {
+		return printDryRun(dryRunRequests, deployFlags.output)
+	}
+
 	if err := deployFailed(failedStatusCodes); err != nil {
 		return err
 	}
 
+	if deployFlags.wait {
+		return waitForReady(ctx, proxyClient, deployed, deployFlags.waitTimeout)
+	}
+
 	return nil
 }
 
@@ -357,25 +587,74 @@ func deployImage(
 ) (int, error) {
 
 	var statusCode int
+	deploySpec, err := makeImageDeploySpec(image, fprocess, functionName, registryAuth, deployFlags,
+		tlsInsecure, readOnlyRootFilesystem, token, namespace)
+	if err != nil {
+		return statusCode, err
+	}
+
+	if msg := checkTLSInsecure(gateway, deploySpec.TLSInsecure); len(msg) > 0 {
+		fmt.Println(msg)
+	}
+
+	stampPreviousImage(ctx, client, deploySpec)
+	statusCode = client.DeployFunction(ctx, deploySpec)
+
+	return statusCode, nil
+}
+
+// makeImageDeploySpec builds the spec to deploy a function with the given image from flags
+func makeImageDeploySpec(
+	image string,
+	fprocess string,
+	functionName string,
+	registryAuth string,
+	deployFlags DeployFlags,
+	tlsInsecure bool,
+	readOnlyRootFilesystem bool,
+	token string,
+	namespace string,
+) (*proxy.DeployFunctionSpec, error) {
+
 	readOnlyRFS := deployFlags.readOnlyRootFilesystem || readOnlyRootFilesystem
-	envvars, err := parseMap(deployFlags.envvarOpts, "env")
+	dotEnvironment, err := readEnvFiles(deployFlags.envFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	envvarArguments, err := parseMap(deployFlags.envvarOpts, "env")
 
 	if err != nil {
-		return statusCode, fmt.Errorf("error parsing envvars: %v", err)
+		return nil, fmt.Errorf("error parsing envvars: %v", err)
 	}
+	envvars := mergeMap(dotEnvironment, envvarArguments)
 
 	labelMap, labelErr := parseMap(deployFlags.labelOpts, "label")
 
 	if labelErr != nil {
-		return statusCode, fmt.Errorf("error parsing labels: %v", labelErr)
+		return nil, fmt.Errorf("error parsing labels: %v", labelErr)
 	}
 
-	annotationMap, annotationErr := parseMap(deployFlags.annotationOpts, "annotation")
+	annotationMap, annotationErr := parseAnnotations(deployFlags.annotationOpts)
 
 	if annotationErr != nil {
-		return statusCode, fmt.Errorf("error parsing annotations: %v", annotationErr)
+		return nil, fmt.Errorf("error parsing annotations: %v", annotationErr)
+	}
+
+	resources, err := parseResourceFlags(deployFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resources.checkFunctions(map[string]stack.Function{functionName: {}}); err != nil {
+		return nil, err
 	}
 
+	if err := validateConstraints(deployFlags.constraints); err != nil {
+		return nil, err
+	}
+	limits, requests := resources.apply(functionName, nil, nil)
+
 	deploySpec := &proxy.DeployFunctionSpec{
 		FProcess:                fprocess,
 		FunctionName:            functionName,
@@ -390,20 +669,15 @@ func deployImage(
 		Secrets:                 deployFlags.secrets,
 		Labels:                  labelMap,
 		Annotations:             annotationMap,
-		FunctionResourceRequest: proxy.FunctionResourceRequest{},
+		FunctionResourceRequest: proxy.FunctionResourceRequest{Limits: limits, Requests: requests},
 		ReadOnlyRootFilesystem:  readOnlyRFS,
 		TLSInsecure:             tlsInsecure,
 		Token:                   token,
 		Namespace:               namespace,
 	}
+	applyCanary(deploySpec, deployFlags)
 
-	if msg := checkTLSInsecure(gateway, deploySpec.TLSInsecure); len(msg) > 0 {
-		fmt.Println(msg)
-	}
-
-	statusCode = client.DeployFunction(ctx, deploySpec)
-
-	return statusCode, nil
+	return deploySpec, nil
 }
 
 func mergeSlice(values []string, overlay []string) []string {
@@ -449,7 +723,7 @@ func parseMap(envvars []string, keyName string) (map[string]string, error) {
 	for _, envvar := range envvars {
 		s := strings.SplitN(strings.TrimSpace(envvar), "=", 2)
 		if len(s) != 2 {
-			return nil, fmt.Errorf("label format is not correct, needs key=value")
+			return nil, fmt.Errorf("%s format is not correct, needs key=value: [%s]", keyName, envvar)
 		}
 		envvarName := s[0]
 		envvarValue := s[1]
@@ -466,6 +740,41 @@ func parseMap(envvars []string, keyName string) (map[string]string, error) {
 	return result, nil
 }
 
+// parseAnnotations reads the ANNOTATION=VALUE options given by --annotation
+// and checks each can be used as the key of an annotation
+func parseAnnotations(annotationOpts []string) (map[string]string, error) {
+	annotations, err := parseMap(annotationOpts, "annotation")
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := stack.ValidateAnnotationKey(key); err != nil {
+			return nil, err
+		}
+	}
+	return annotations, nil
+}
+
+// functionAnnotations gives the annotations of the function in the stack
+// file, on top of those for its topics and healthcheck
+func functionAnnotations(function stack.Function) map[string]string {
+	annotations := stack.TopicsAnnotations(function.Topics)
+	if function.HealthCheck != nil {
+		annotations = mergeMap(annotations, function.HealthCheck.Annotations())
+	}
+	if function.Annotations != nil {
+		annotations = mergeMap(annotations, *function.Annotations)
+	}
+	return annotations
+}
+
 func mergeMap(i map[string]string, j map[string]string) map[string]string {
 	merged := make(map[string]string)
 
@@ -630,11 +939,24 @@ func deployFailed(status map[string]int) error {
 	}
 
 	var allErrors []string
+	statusCode := 0
 	for funcName, funcStatus := range status {
 		err := fmt.Errorf("Function '%s' failed to deploy with status code: %d", funcName, funcStatus)
 		allErrors = append(allErrors, err.Error())
+
+		// The status is only kept when every function failed with it
+		if statusCode == 0 || statusCode == funcStatus {
+			statusCode = funcStatus
+		} else {
+			statusCode = -1
+		}
+	}
+
+	message := strings.Join(allErrors, "\n")
+	if statusCode > 0 {
+		return &proxy.HTTPError{StatusCode: statusCode, Message: message}
 	}
-	return fmt.Errorf(strings.Join(allErrors, "\n"))
+	return fmt.Errorf("%s", message)
 }
 
 func badStatusCode(statusCode int) bool {
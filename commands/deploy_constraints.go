@@ -0,0 +1,54 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// constraintPattern matches a placement constraint such as
+// "node.platform.os == linux", giving its key, operator and value
+var constraintPattern = regexp.MustCompile(`^\s*([^\s=!]+)\s*(==|!=|=)\s*([^\s=].*?)\s*$`)
+
+// validateConstraints checks each --constraint is a key, an operator of ==,
+// != or = and a value
+func validateConstraints(constraints []string) error {
+	for _, constraint := range constraints {
+		if !constraintPattern.MatchString(constraint) {
+			return fmt.Errorf(`--constraint must be in the form "key == value" or "key != value", but you gave: %s`, constraint)
+		}
+	}
+	return nil
+}
+
+// mergeConstraints gives the constraints of the stack file with those given
+// by --constraint, which replace any in the stack file with the same key
+func mergeConstraints(stackConstraints, flagConstraints []string) []string {
+	if len(flagConstraints) == 0 {
+		return stackConstraints
+	}
+
+	flagKeys := map[string]bool{}
+	for _, constraint := range flagConstraints {
+		flagKeys[constraintKey(constraint)] = true
+	}
+
+	merged := []string{}
+	for _, constraint := range stackConstraints {
+		if key := constraintKey(constraint); len(key) == 0 || !flagKeys[key] {
+			merged = append(merged, constraint)
+		}
+	}
+	return append(merged, flagConstraints...)
+}
+
+// constraintKey gives the key of a constraint, or "" when it can't be parsed
+func constraintKey(constraint string) string {
+	match := constraintPattern.FindStringSubmatch(constraint)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
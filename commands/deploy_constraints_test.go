@@ -0,0 +1,124 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_validateConstraints(t *testing.T) {
+	cases := []struct {
+		constraint string
+		valid      bool
+	}{
+		{"node.platform.os == linux", true},
+		{"node.role!=manager", true},
+		{"kubernetes.io/hostname=node1", true},
+		{"node.labels.zone == eu west", true},
+		{"node.platform.os", false},
+		{"== linux", false},
+		{"node.platform.os ==", false},
+		{"", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.constraint, func(t *testing.T) {
+			err := validateConstraints([]string{c.constraint})
+			if c.valid && err != nil {
+				t.Errorf("want %q to be valid, got %s", c.constraint, err)
+			}
+			if !c.valid && err == nil {
+				t.Errorf("want %q to be refused", c.constraint)
+			}
+		})
+	}
+}
+
+func Test_mergeConstraints(t *testing.T) {
+	stackConstraints := []string{"node.platform.os == linux", "node.role == worker"}
+
+	merged := mergeConstraints(stackConstraints, []string{"node.platform.os == windows", "node.labels.gpu == true"})
+	want := []string{"node.role == worker", "node.platform.os == windows", "node.labels.gpu == true"}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("want %v, got %v", want, merged)
+	}
+
+	if merged := mergeConstraints(stackConstraints, nil); !reflect.DeepEqual(merged, stackConstraints) {
+		t.Errorf("want the stack constraints without flags, got %v", merged)
+	}
+}
+
+func Test_deploy_DryRun_Constraints(t *testing.T) {
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  figlet:
+    image: functions/figlet:latest
+    constraints:
+      - node.platform.os == linux
+      - node.role == worker
+`)
+	stackFile.Close()
+
+	resetForTest()
+	defer func() {
+		deployFlags.dryRun = false
+		deployFlags.output = "yaml"
+		deployFlags.constraints = []string{}
+	}()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"deploy",
+			"-f", stackFile.Name(),
+			"--constraint=node.role == manager",
+			"--dry-run",
+			"--output=json",
+		})
+		faasCmd.Execute()
+	})
+
+	var requests []types.FunctionDeployment
+	if err := json.Unmarshal([]byte(stdOut), &requests); err != nil {
+		t.Fatalf("want JSON output, got %q: %s", stdOut, err)
+	}
+
+	want := []string{"node.platform.os == linux", "node.role == manager"}
+	if len(requests) != 1 || !reflect.DeepEqual(requests[0].Constraints, want) {
+		t.Errorf("want the constraints %v, got: %s", want, stdOut)
+	}
+}
+
+func Test_deploy_Constraints_Invalid(t *testing.T) {
+	resetForTest()
+	defer func() {
+		deployFlags.dryRun = false
+		deployFlags.constraints = []string{}
+	}()
+
+	faasCmd.SetArgs([]string{
+		"deploy",
+		"--image=golang",
+		"--name=test-function",
+		"--constraint=linux",
+		"--dry-run",
+	})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != `--constraint must be in the form "key == value" or "key != value", but you gave: linux` {
+		t.Errorf("want an error for the constraint, got %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/openfaas/faas-cli/proxy"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+// diffSections is the order of the fields printed by --diff
+var diffSections = []string{"image", "env", "label", "annotation", "limits", "requests"}
+
+// diffFunctions prints the changes each spec makes to the function which is
+// deployed, and gives true when any function is new or changed
+func diffFunctions(ctx context.Context, client *proxy.Client, specs []*proxy.DeployFunctionSpec, out io.Writer) (bool, error) {
+	changed := false
+	for _, spec := range specs {
+		status, err := client.GetFunctionStatus(ctx, spec.FunctionName, spec.Namespace)
+		if err != nil && proxy.StatusCode(err) != http.StatusNotFound {
+			return false, fmt.Errorf("unable to compare %s with the deployed function: %s", spec.FunctionName, err)
+		}
+
+		var deployed map[string]string
+		if err == nil {
+			deployed = deployedFields(status)
+		}
+
+		if printFunctionDiff(out, spec.FunctionName, deployed, specFields(spec)) {
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// confirmDeploy asks whether to deploy the functions once the diff has been
+// printed, --yes deploys them without asking
+func confirmDeploy(in io.Reader, count int, yes bool) error {
+	if yes {
+		return nil
+	}
+
+	fmt.Printf("Deploy %d function(s)? [y/N] ", count)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		return fmt.Errorf("no functions were deployed, answer y to deploy them or pass --yes")
+	}
+	return nil
+}
+
+// printFunctionDiff prints the fields of the function as a unified diff, from
+// those deployed to those of the spec, a nil deployed is a new function
+func printFunctionDiff(out io.Writer, name string, deployed, spec map[string]string) bool {
+	if deployed == nil {
+		fmt.Fprintf(out, "--- %s (not deployed)\n+++ %s (stack file)\n", name, name)
+		for _, key := range sortedDiffKeys(spec) {
+			fmt.Fprintf(out, "+%s\n", diffLine(key, spec[key]))
+		}
+		return true
+	}
+
+	same := func(key string) bool {
+		deployedValue, inDeployed := deployed[key]
+		specValue, inSpec := spec[key]
+		return inDeployed == inSpec && deployedValue == specValue
+	}
+
+	keys := sortedDiffKeys(mergeMap(deployed, spec))
+	changed := false
+	for _, key := range keys {
+		if !same(key) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		fmt.Fprintf(out, "%s: no changes\n", name)
+		return false
+	}
+
+	fmt.Fprintf(out, "--- %s (deployed)\n+++ %s (stack file)\n", name, name)
+	for _, key := range keys {
+		if same(key) {
+			fmt.Fprintf(out, " %s\n", diffLine(key, spec[key]))
+			continue
+		}
+		if value, ok := deployed[key]; ok {
+			fmt.Fprintf(out, "-%s\n", diffLine(key, value))
+		}
+		if value, ok := spec[key]; ok {
+			fmt.Fprintf(out, "+%s\n", diffLine(key, value))
+		}
+	}
+	return true
+}
+
+// diffLine prints a field, with the values of variables which look like
+// credentials redacted, though a change to them is still shown
+func diffLine(key, value string) string {
+	if strings.HasPrefix(key, "env.") && secretEnvPattern.MatchString(strings.TrimPrefix(key, "env.")) {
+		value = redactedValue
+	}
+	return key + ": " + value
+}
+
+// specFields gives the fields compared by --diff for the function to deploy
+func specFields(spec *proxy.DeployFunctionSpec) map[string]string {
+	fields := diffFields(spec.Image, spec.EnvVars, spec.Labels, spec.Annotations)
+	if limits := spec.FunctionResourceRequest.Limits; limits != nil {
+		addResourceFields(fields, "limits", limits.Memory, limits.CPU)
+	}
+	if requests := spec.FunctionResourceRequest.Requests; requests != nil {
+		addResourceFields(fields, "requests", requests.Memory, requests.CPU)
+	}
+	return fields
+}
+
+// deployedFields gives the fields compared by --diff for the deployed
+// function, the environment and resources are empty when the provider
+// doesn't report them
+func deployedFields(status proxy.FunctionStatus) map[string]string {
+	var labels, annotations map[string]string
+	if status.Labels != nil {
+		labels = *status.Labels
+	}
+	if status.Annotations != nil {
+		annotations = *status.Annotations
+	}
+
+	fields := diffFields(status.Image, status.EnvVars, labels, annotations)
+	addFunctionResources(fields, "limits", status.Limits)
+	addFunctionResources(fields, "requests", status.Requests)
+	return fields
+}
+
+func diffFields(image string, envVars, labels, annotations map[string]string) map[string]string {
+	fields := map[string]string{"image": image}
+	for key, value := range envVars {
+		fields["env."+key] = value
+	}
+	for key, value := range labels {
+		fields["label."+key] = value
+	}
+	for key, value := range annotations {
+		// The previous image is recorded by deploy itself, so is no change
+		if key != previousImageAnnotation {
+			fields["annotation."+key] = value
+		}
+	}
+	return fields
+}
+
+func addFunctionResources(fields map[string]string, section string, resources *types.FunctionResources) {
+	if resources != nil {
+		addResourceFields(fields, section, resources.Memory, resources.CPU)
+	}
+}
+
+func addResourceFields(fields map[string]string, section, memory, cpu string) {
+	if len(memory) > 0 {
+		fields[section+".memory"] = memory
+	}
+	if len(cpu) > 0 {
+		fields[section+".cpu"] = cpu
+	}
+}
+
+// sortedDiffKeys orders the fields by diffSections and then by their keys
+func sortedDiffKeys(fields map[string]string) []string {
+	rank := func(key string) int {
+		section := strings.SplitN(key, ".", 2)[0]
+		for i, s := range diffSections {
+			if s == section {
+				return i
+			}
+		}
+		return len(diffSections)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if rank(keys[i]) != rank(keys[j]) {
+			return rank(keys[i]) < rank(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
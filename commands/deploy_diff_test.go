@@ -0,0 +1,168 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_printFunctionDiff(t *testing.T) {
+	deployed := map[string]string{
+		"image":             "functions/figlet:0.13",
+		"env.write_debug":   "true",
+		"env.api_token":     "old",
+		"label.team":        "web",
+		"annotation.topic":  "payments",
+		"limits.memory":     "128Mi",
+		"requests.cpu":      "100m",
+		"label.unchanged":   "yes",
+		"annotation.remove": "me",
+	}
+	spec := map[string]string{
+		"image":            "functions/figlet:0.14",
+		"env.write_debug":  "true",
+		"env.api_token":    "new",
+		"label.team":       "api",
+		"annotation.topic": "payments",
+		"limits.memory":    "256Mi",
+		"requests.cpu":     "100m",
+		"label.unchanged":  "yes",
+	}
+
+	var out bytes.Buffer
+	if !printFunctionDiff(&out, "figlet", deployed, spec) {
+		t.Errorf("want the function changed")
+	}
+
+	want := `--- figlet (deployed)
++++ figlet (stack file)
+-image: functions/figlet:0.13
++image: functions/figlet:0.14
+-env.api_token: ` + redactedValue + `
++env.api_token: ` + redactedValue + `
+ env.write_debug: true
+-label.team: web
++label.team: api
+ label.unchanged: yes
+-annotation.remove: me
+ annotation.topic: payments
+-limits.memory: 128Mi
++limits.memory: 256Mi
+ requests.cpu: 100m
+`
+	if out.String() != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, out.String())
+	}
+
+	out.Reset()
+	if printFunctionDiff(&out, "figlet", spec, spec) || out.String() != "figlet: no changes\n" {
+		t.Errorf("want no changes, got %q", out.String())
+	}
+
+	out.Reset()
+	printFunctionDiff(&out, "figlet", nil, map[string]string{"image": "functions/figlet:0.14", "label.team": "api"})
+	if want := "--- figlet (not deployed)\n+++ figlet (stack file)\n+image: functions/figlet:0.14\n+label.team: api\n"; out.String() != want {
+		t.Errorf("want a new function, got %q", out.String())
+	}
+}
+
+func Test_deploy_Diff(t *testing.T) {
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  figlet:
+    image: functions/figlet:0.14
+`)
+	stackFile.Close()
+
+	deployedStatus := types.FunctionStatus{Name: "figlet", Image: "functions/figlet:0.13"}
+
+	cases := []struct {
+		name     string
+		input    string
+		args     []string
+		requests []test.Request
+		wantErr  string
+	}{
+		{
+			name:  "declined",
+			input: "n\n",
+			requests: []test.Request{
+				{Method: http.MethodGet, Uri: "/system/function/figlet", ResponseStatusCode: http.StatusOK, ResponseBody: deployedStatus},
+			},
+			wantErr: "no functions were deployed, answer y to deploy them or pass --yes",
+		},
+		{
+			name: "with --yes",
+			args: []string{"--yes"},
+			requests: []test.Request{
+				{Method: http.MethodGet, Uri: "/system/function/figlet", ResponseStatusCode: http.StatusOK, ResponseBody: deployedStatus},
+				{Method: http.MethodGet, Uri: "/system/function/figlet", ResponseStatusCode: http.StatusOK, ResponseBody: deployedStatus},
+				{Method: http.MethodPut, Uri: "/system/functions", ResponseStatusCode: http.StatusAccepted},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := test.MockHttpServer(t, c.requests)
+			defer s.Close()
+
+			resetForTest()
+			faasCmd.SetIn(strings.NewReader(c.input))
+			defer func() {
+				deployFlags.diff = false
+				deployFlags.yes = false
+				faasCmd.SetIn(nil)
+			}()
+
+			var runErr error
+			stdOut := test.CaptureStdout(func() {
+				faasCmd.SetArgs(append([]string{"deploy", "-f", stackFile.Name(), "--gateway=" + s.URL, "--diff"}, c.args...))
+				runErr = faasCmd.Execute()
+			})
+
+			if !strings.Contains(stdOut, "-image: functions/figlet:0.13\n+image: functions/figlet:0.14\n") {
+				t.Errorf("want the change of image, got:\n%s", stdOut)
+			}
+
+			if len(c.wantErr) > 0 {
+				if runErr == nil || runErr.Error() != c.wantErr {
+					t.Errorf("want error %q, got %v", c.wantErr, runErr)
+				}
+			} else if runErr != nil {
+				t.Errorf("want no error, got %s", runErr)
+			}
+		})
+	}
+}
+
+func Test_deploy_DiffWithDryRun(t *testing.T) {
+	resetForTest()
+	defer func() {
+		deployFlags.diff = false
+		deployFlags.dryRun = false
+	}()
+
+	faasCmd.SetArgs([]string{"deploy", "-f", "stack.yml", "--diff", "--dry-run"})
+	err := faasCmd.Execute()
+
+	want := "--diff deploys the changes once confirmed, so it can't be used with --dry-run"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
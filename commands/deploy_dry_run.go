@@ -0,0 +1,54 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	types "github.com/openfaas/faas-provider/types"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// redactedValue replaces the registry credentials when printing a dry-run
+const redactedValue = "<redacted>"
+
+// printDryRun prints the requests which deploy would send to the gateway,
+// sorted by function name, as YAML or JSON.
+func printDryRun(requests []types.FunctionDeployment, output string) error {
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].Service < requests[j].Service
+	})
+
+	for i := range requests {
+		if len(requests[i].RegistryAuth) > 0 {
+			requests[i].RegistryAuth = redactedValue
+		}
+	}
+
+	jsonBytes, err := json.MarshalIndent(requests, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal deployment requests: %s", err.Error())
+	}
+
+	if output == "json" {
+		fmt.Println(string(jsonBytes))
+		return nil
+	}
+
+	// go through JSON so that the YAML keys match the gateway's API
+	var values []yaml.MapSlice
+	if err := yaml.Unmarshal(jsonBytes, &values); err != nil {
+		return fmt.Errorf("unable to convert deployment requests to YAML: %s", err.Error())
+	}
+
+	yamlBytes, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("unable to marshal deployment requests: %s", err.Error())
+	}
+
+	fmt.Print(string(yamlBytes))
+	return nil
+}
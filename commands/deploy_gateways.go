@@ -0,0 +1,128 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// deployGateways holds each --gateway given to deploy and up
+var deployGateways []string
+
+const deployGatewaysNote = `Repeat --gateway to deploy to each gateway in turn, such as staging then prod,
+the credentials for each are those saved by "faas-cli login" for its URL unless
+--token is given. Each gateway is tried even when an earlier one failed, and a
+result is printed for each once they are done. Pass --fail-fast explicitly to
+skip the gateways after one which failed.`
+
+// gatewaysFlag collects each --gateway given in order, gateway is set to the
+// first for the code which only talks to a single gateway.
+type gatewaysFlag struct{}
+
+// Type implements pflag.Value
+func (f *gatewaysFlag) Type() string {
+	return "stringArray"
+}
+
+// String implements Stringer
+func (f *gatewaysFlag) String() string {
+	return strings.Join(deployGateways, ",")
+}
+
+// Set implements pflag.Value
+func (f *gatewaysFlag) Set(value string) error {
+	deployGateways = append(deployGateways, value)
+	gateway = deployGateways[0]
+	return nil
+}
+
+type gatewayResult struct {
+	gateway string
+	err     error
+	// skipped is true when nothing was deployed to the gateway, as an
+	// earlier one failed with --fail-fast given explicitly
+	skipped bool
+}
+
+// deployToGateways runs deploy once for each gateway in order, with gateway
+// set to it, and gives the result for each. The gateways after one which
+// failed are skipped when failFast is set.
+func deployToGateways(gateways []string, failFast bool, deploy func() error) []gatewayResult {
+	defer func(first string) { gateway = first }(gateway)
+
+	var (
+		results []gatewayResult
+		failed  bool
+	)
+	for _, gatewayURL := range gateways {
+		if failed && failFast {
+			fmt.Fprintf(os.Stderr, "Skipping gateway %s, as an earlier gateway failed with --fail-fast.\n", gatewayURL)
+			results = append(results, gatewayResult{gateway: gatewayURL, skipped: true})
+			continue
+		}
+
+		fmt.Fprintf(infoOutput(), "Deploying to gateway: %s\n", gatewayURL)
+		gateway = gatewayURL
+		err := deploy()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error deploying to gateway %s: %s\n", gatewayURL, err)
+			failed = true
+		}
+		fmt.Fprintln(infoOutput())
+
+		results = append(results, gatewayResult{gateway: gatewayURL, err: err})
+	}
+	return results
+}
+
+// printGatewaySummary lists the gateways which were deployed to, those which
+// failed along with the error, then those which were skipped
+func printGatewaySummary(results []gatewayResult) {
+	var succeeded, failed, skipped []gatewayResult
+	for _, result := range results {
+		if result.skipped {
+			skipped = append(skipped, result)
+		} else if result.err != nil {
+			failed = append(failed, result)
+		} else {
+			succeeded = append(succeeded, result)
+		}
+	}
+
+	fmt.Printf("Deployed to %d of %d gateway(s).\n", len(succeeded), len(results))
+	for _, result := range succeeded {
+		fmt.Printf("  %s\n", result.gateway)
+	}
+
+	if len(failed) > 0 {
+		fmt.Println("Failed:")
+		for _, result := range failed {
+			fmt.Printf("  %s\t%s\n", result.gateway, strings.SplitN(result.err.Error(), "\n", 2)[0])
+		}
+	}
+
+	if len(skipped) > 0 {
+		fmt.Println("Skipped:")
+		for _, result := range skipped {
+			fmt.Printf("  %s\n", result.gateway)
+		}
+	}
+}
+
+// gatewaysFailed gives an error naming each gateway which failed
+func gatewaysFailed(results []gatewayResult) error {
+	var failed []string
+	for _, result := range results {
+		if result.err != nil {
+			failed = append(failed, result.gateway)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed to deploy to %d of %d gateway(s): %s", len(failed), len(results), strings.Join(failed, ", "))
+}
@@ -0,0 +1,113 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+// gatewayServer counts the deploys it is sent and answers them with statusCode
+func gatewayServer(statusCode int, deploys *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		atomic.AddInt32(deploys, 1)
+		w.WriteHeader(statusCode)
+	}))
+}
+
+func Test_deploy_MultipleGateways(t *testing.T) {
+	cases := []struct {
+		name        string
+		args        []string
+		wantDeploys [3]int32
+		wantSummary []string
+	}{
+		{
+			name:        "every gateway by default",
+			wantDeploys: [3]int32{1, 1, 1},
+			wantSummary: []string{"Deployed to 2 of 3 gateway(s).", "Failed:"},
+		},
+		{
+			name:        "fail fast",
+			args:        []string{"--fail-fast"},
+			wantDeploys: [3]int32{1, 1, 0},
+			wantSummary: []string{"Deployed to 1 of 3 gateway(s).", "Failed:", "Skipped:"},
+		},
+		{
+			name:        "continue on error",
+			args:        []string{"--continue-on-error"},
+			wantDeploys: [3]int32{1, 1, 1},
+			wantSummary: []string{"Deployed to 2 of 3 gateway(s).", "Failed:"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resetForTest()
+			defer resetForTest()
+			deployCmd.Flags().Lookup("fail-fast").Changed = false
+			defer func() { deployCmd.Flags().Lookup("fail-fast").Changed = false }()
+
+			var deploys [3]int32
+			staging := gatewayServer(http.StatusAccepted, &deploys[0])
+			defer staging.Close()
+			broken := gatewayServer(http.StatusInternalServerError, &deploys[1])
+			defer broken.Close()
+			prod := gatewayServer(http.StatusAccepted, &deploys[2])
+			defer prod.Close()
+
+			var err error
+			stdOut := test.CaptureStdout(func() {
+				faasCmd.SetArgs(append([]string{
+					"deploy",
+					"--image=functions/alpine",
+					"--name=echo",
+					"--gateway=" + staging.URL,
+					"--gateway=" + broken.URL,
+					"--gateway=" + prod.URL,
+				}, c.args...))
+				err = faasCmd.Execute()
+			})
+
+			if err == nil || !strings.Contains(err.Error(), "failed to deploy to 1 of 3 gateway(s): "+broken.URL) {
+				t.Errorf("want an error naming the gateway which failed, got %v", err)
+			}
+			if deploys != c.wantDeploys {
+				t.Errorf("want deploys %v, got %v", c.wantDeploys, deploys)
+			}
+			for _, want := range c.wantSummary {
+				if !strings.Contains(stdOut, want) {
+					t.Errorf("want %q in the output, got %q", want, stdOut)
+				}
+			}
+		})
+	}
+}
+
+func Test_deploy_MultipleGateways_DryRun(t *testing.T) {
+	resetForTest()
+	defer resetForTest()
+	defer func() { deployFlags.dryRun = false }()
+
+	faasCmd.SetArgs([]string{
+		"deploy",
+		"--image=functions/alpine",
+		"--name=echo",
+		"--gateway=http://staging:8080",
+		"--gateway=http://prod:8080",
+		"--dry-run",
+	})
+	if err := faasCmd.Execute(); err == nil || !strings.Contains(err.Error(), "give a single --gateway") {
+		t.Errorf("want --dry-run to be rejected with several gateways, got %v", err)
+	}
+}
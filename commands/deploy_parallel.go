@@ -0,0 +1,110 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/openfaas/faas-cli/proxy"
+)
+
+type deployResult struct {
+	name       string
+	namespace  string
+	statusCode int
+	// skipped is true when the function was not deployed, as an earlier
+	// one failed with --fail-fast
+	skipped bool
+}
+
+// deployAll deploys the functions with a pool of workers bounded by queueDepth,
+// the output of each deploy is printed in one go so that it is not interleaved.
+// The results are sorted by function name.
+func deployAll(ctx context.Context, client *proxy.Client, specs []*proxy.DeployFunctionSpec, queueDepth int) []deployResult {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []deployResult
+		gate    failureGate
+	)
+
+	workChannel := make(chan *proxy.DeployFunctionSpec)
+
+	wg.Add(queueDepth)
+	for i := 0; i < queueDepth; i++ {
+		go func() {
+			defer wg.Done()
+
+			for spec := range workChannel {
+				if gate.skip(spec.FunctionName) {
+					mu.Lock()
+					results = append(results, deployResult{name: spec.FunctionName, namespace: spec.Namespace, skipped: true})
+					mu.Unlock()
+					continue
+				}
+
+				stampPreviousImage(ctx, client, spec)
+				statusCode, output := client.DeployFunctionOutput(ctx, spec)
+				if badStatusCode(statusCode) {
+					gate.fail()
+				}
+
+				mu.Lock()
+				fmt.Fprintf(infoOutput(), "Deploying: %s.\n", spec.FunctionName)
+				fmt.Print(output)
+				results = append(results, deployResult{name: spec.FunctionName, namespace: spec.Namespace, statusCode: statusCode})
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, spec := range specs {
+		workChannel <- spec
+	}
+	close(workChannel)
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].name < results[j].name
+	})
+	return results
+}
+
+// printDeploySummary lists the functions which were deployed and those which
+// failed, along with the HTTP status for each, then those which were skipped
+func printDeploySummary(results []deployResult) {
+	var succeeded, failed, skipped []deployResult
+	for _, result := range results {
+		if result.skipped {
+			skipped = append(skipped, result)
+		} else if badStatusCode(result.statusCode) {
+			failed = append(failed, result)
+		} else {
+			succeeded = append(succeeded, result)
+		}
+	}
+
+	fmt.Printf("Deployed %d of %d function(s).\n", len(succeeded), len(results))
+	for _, result := range succeeded {
+		fmt.Printf("  %s\t%d %s\n", result.name, result.statusCode, http.StatusText(result.statusCode))
+	}
+
+	if len(failed) > 0 {
+		fmt.Println("Failed:")
+		for _, result := range failed {
+			fmt.Printf("  %s\t%d %s\n", result.name, result.statusCode, http.StatusText(result.statusCode))
+		}
+	}
+
+	if len(skipped) > 0 {
+		fmt.Println("Skipped:")
+		for _, result := range skipped {
+			fmt.Printf("  %s\n", result.name)
+		}
+	}
+}
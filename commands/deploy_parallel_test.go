@@ -0,0 +1,180 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_deployAll_BoundedByQueueDepth(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		req := types.FunctionDeployment{}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Service == "fn-3" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	var specs []*proxy.DeployFunctionSpec
+	for i := 0; i < 6; i++ {
+		specs = append(specs, &proxy.DeployFunctionSpec{FunctionName: fmt.Sprintf("fn-%d", i), Image: "functions/alpine"})
+	}
+
+	client := proxy.NewClient(NewCLIAuth("", s.URL), s.URL, nil, nil)
+
+	continueOnError = true
+	defer func() { continueOnError = false }()
+
+	var results []deployResult
+	test.CaptureStdout(func() {
+		results = deployAll(context.Background(), client, specs, 2)
+	})
+
+	if maxInFlight > 2 {
+		t.Errorf("want at most 2 deploys in flight, got %d", maxInFlight)
+	}
+
+	if len(results) != 6 {
+		t.Fatalf("want 6 results, got %d", len(results))
+	}
+
+	for i, result := range results {
+		want := http.StatusAccepted
+		if result.name == "fn-3" {
+			want = http.StatusInternalServerError
+		}
+		if result.name != fmt.Sprintf("fn-%d", i) || result.statusCode != want {
+			t.Errorf("want fn-%d with status %d, got %s with %d", i, want, result.name, result.statusCode)
+		}
+	}
+}
+
+func Test_deployAll_FailFastSkipsTheRest(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := types.FunctionDeployment{}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Service == "fn-1" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	var specs []*proxy.DeployFunctionSpec
+	for i := 0; i < 3; i++ {
+		specs = append(specs, &proxy.DeployFunctionSpec{FunctionName: fmt.Sprintf("fn-%d", i), Image: "functions/alpine"})
+	}
+
+	client := proxy.NewClient(NewCLIAuth("", s.URL), s.URL, nil, nil)
+
+	var results []deployResult
+	stdOut := test.CaptureStdout(func() {
+		results = deployAll(context.Background(), client, specs, 1)
+		printDeploySummary(results)
+	})
+
+	if len(results) != 3 || results[0].skipped || results[1].statusCode != http.StatusInternalServerError || !results[2].skipped {
+		t.Errorf("want fn-2 skipped after fn-1 failed, got %+v", results)
+	}
+	if !strings.Contains(stdOut, "Deployed 1 of 3 function(s).") || !strings.Contains(stdOut, "Skipped:\n  fn-2\n") {
+		t.Errorf("want the skipped function in the summary, got:\n%s", stdOut)
+	}
+}
+
+func Test_printDeploySummary(t *testing.T) {
+	stdOut := test.CaptureStdout(func() {
+		printDeploySummary([]deployResult{
+			{name: "figlet", statusCode: http.StatusAccepted},
+			{name: "nodeinfo", statusCode: http.StatusInternalServerError},
+		})
+	})
+
+	if !strings.Contains(stdOut, "Deployed 1 of 2 function(s).") {
+		t.Errorf("want count of deployed functions, got:\n%s", stdOut)
+	}
+
+	failedAt := strings.Index(stdOut, "Failed:")
+	if failedAt == -1 || strings.Index(stdOut, "nodeinfo\t500 Internal Server Error") < failedAt {
+		t.Errorf("want nodeinfo listed under failures, got:\n%s", stdOut)
+	}
+}
+
+func Test_deploy_Quiet(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  figlet:
+    image: functions/figlet:latest
+  nodeinfo:
+    image: functions/nodeinfo:latest
+`)
+	stackFile.Close()
+
+	resetForTest()
+	defer resetForTest()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"deploy",
+			"-f", stackFile.Name(),
+			"--gateway=" + s.URL,
+			"-q",
+		})
+		if err := faasCmd.Execute(); err != nil {
+			t.Errorf("want no error, got %s", err)
+		}
+	})
+
+	if strings.Contains(stdOut, "Deploying:") {
+		t.Errorf("want no progress with --quiet, got:\n%s", stdOut)
+	}
+	if !strings.Contains(stdOut, "Deployed 2 of 2 function(s).") {
+		t.Errorf("want the summary with --quiet, got:\n%s", stdOut)
+	}
+}
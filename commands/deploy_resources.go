@@ -0,0 +1,129 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/openfaas/faas-cli/stack"
+)
+
+// quantityPattern matches a Kubernetes resource quantity such as 128Mi, 1G,
+// 0.5 or 500m
+var quantityPattern = regexp.MustCompile(`^([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][-+]?[0-9]+|m|k|M|G|T|P|E|Ki|Mi|Gi|Ti|Pi|Ei)?$`)
+
+// resourceOverrides holds the limits and requests given by the resource
+// flags, keyed by function name, or by "" for those given for every function
+type resourceOverrides struct {
+	limits   map[string]*stack.FunctionResources
+	requests map[string]*stack.FunctionResources
+	// flags gives the first flag which named each function
+	flags map[string]string
+}
+
+// parseResourceFlags reads the --memory-limit, --cpu-limit, --memory-request
+// and --cpu-request flags, each value is either QUANTITY for every function
+// or FUNCTION=QUANTITY for one function
+func parseResourceFlags(deployFlags DeployFlags) (resourceOverrides, error) {
+	overrides := resourceOverrides{
+		limits:   map[string]*stack.FunctionResources{},
+		requests: map[string]*stack.FunctionResources{},
+		flags:    map[string]string{},
+	}
+
+	flags := []struct {
+		name      string
+		values    []string
+		resources map[string]*stack.FunctionResources
+		set       func(*stack.FunctionResources, string)
+	}{
+		{"memory-limit", deployFlags.memoryLimits, overrides.limits, setMemory},
+		{"cpu-limit", deployFlags.cpuLimits, overrides.limits, setCPU},
+		{"memory-request", deployFlags.memoryRequests, overrides.requests, setMemory},
+		{"cpu-request", deployFlags.cpuRequests, overrides.requests, setCPU},
+	}
+
+	for _, flag := range flags {
+		for _, value := range flag.values {
+			name, quantity := "", value
+			if i := strings.Index(value, "="); i > -1 {
+				name, quantity = value[:i], value[i+1:]
+				if len(name) == 0 {
+					return overrides, fmt.Errorf("--%s needs a function name before =, but you gave: %s", flag.name, value)
+				}
+				if _, ok := overrides.flags[name]; !ok {
+					overrides.flags[name] = flag.name
+				}
+			}
+
+			if !quantityPattern.MatchString(quantity) {
+				return overrides, fmt.Errorf("--%s must be a quantity such as 128Mi or 500m, but you gave: %s", flag.name, quantity)
+			}
+
+			if flag.resources[name] == nil {
+				flag.resources[name] = &stack.FunctionResources{}
+			}
+			flag.set(flag.resources[name], quantity)
+		}
+	}
+
+	return overrides, nil
+}
+
+// checkFunctions gives an error for a FUNCTION=QUANTITY flag which names a
+// function that is not being deployed, such as one with a typo
+func (o resourceOverrides) checkFunctions(functions map[string]stack.Function) error {
+	names := make([]string, 0, len(o.flags))
+	for name := range o.flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := functions[name]; !ok {
+			return fmt.Errorf("--%s names the function %s, which is not being deployed", o.flags[name], name)
+		}
+	}
+	return nil
+}
+
+// apply gives the limits and requests of a function. The stack file is
+// overridden by the flags given for every function, which are overridden by
+// those given for this function.
+func (o resourceOverrides) apply(name string, limits, requests *stack.FunctionResources) (*stack.FunctionResources, *stack.FunctionResources) {
+	limits = overlayResources(overlayResources(limits, o.limits[""]), o.limits[name])
+	requests = overlayResources(overlayResources(requests, o.requests[""]), o.requests[name])
+	return limits, requests
+}
+
+// overlayResources gives base with the fields set in overlay replaced,
+// without changing base
+func overlayResources(base, overlay *stack.FunctionResources) *stack.FunctionResources {
+	if overlay == nil {
+		return base
+	}
+
+	merged := stack.FunctionResources{}
+	if base != nil {
+		merged = *base
+	}
+	if len(overlay.Memory) > 0 {
+		merged.Memory = overlay.Memory
+	}
+	if len(overlay.CPU) > 0 {
+		merged.CPU = overlay.CPU
+	}
+	return &merged
+}
+
+func setMemory(resources *stack.FunctionResources, quantity string) {
+	resources.Memory = quantity
+}
+
+func setCPU(resources *stack.FunctionResources, quantity string) {
+	resources.CPU = quantity
+}
@@ -0,0 +1,205 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/openfaas/faas-cli/stack"
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_parseResourceFlags_Quantities(t *testing.T) {
+	cases := []struct {
+		quantity string
+		valid    bool
+	}{
+		{"128Mi", true},
+		{"1Gi", true},
+		{"500m", true},
+		{"0.5", true},
+		{"1e3", true},
+		{"2", true},
+		{"128MB", false},
+		{"-1", false},
+		{"Mi", false},
+		{"", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.quantity, func(t *testing.T) {
+			_, err := parseResourceFlags(DeployFlags{memoryLimits: []string{c.quantity}})
+			if c.valid && err != nil {
+				t.Errorf("want %q to be valid, got %s", c.quantity, err)
+			}
+			if !c.valid && (err == nil || err.Error() != "--memory-limit must be a quantity such as 128Mi or 500m, but you gave: "+c.quantity) {
+				t.Errorf("want %q to be refused, got %v", c.quantity, err)
+			}
+		})
+	}
+
+	_, err := parseResourceFlags(DeployFlags{cpuRequests: []string{"=100m"}})
+	if err == nil || err.Error() != "--cpu-request needs a function name before =, but you gave: =100m" {
+		t.Errorf("want an error for a missing function name, got %v", err)
+	}
+}
+
+func Test_resourceOverrides_apply(t *testing.T) {
+	overrides, err := parseResourceFlags(DeployFlags{
+		memoryLimits:   []string{"256Mi", "fn1=512Mi"},
+		cpuLimits:      []string{"fn2=1"},
+		memoryRequests: []string{"64Mi"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stackLimits := &stack.FunctionResources{Memory: "128Mi", CPU: "200m"}
+	stackRequests := &stack.FunctionResources{CPU: "100m"}
+
+	cases := []struct {
+		name         string
+		wantLimits   stack.FunctionResources
+		wantRequests stack.FunctionResources
+	}{
+		{"fn1", stack.FunctionResources{Memory: "512Mi", CPU: "200m"}, stack.FunctionResources{Memory: "64Mi", CPU: "100m"}},
+		{"fn2", stack.FunctionResources{Memory: "256Mi", CPU: "1"}, stack.FunctionResources{Memory: "64Mi", CPU: "100m"}},
+		{"fn3", stack.FunctionResources{Memory: "256Mi", CPU: "200m"}, stack.FunctionResources{Memory: "64Mi", CPU: "100m"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			limits, requests := overrides.apply(c.name, stackLimits, stackRequests)
+			if *limits != c.wantLimits {
+				t.Errorf("want limits %v, got %v", c.wantLimits, *limits)
+			}
+			if *requests != c.wantRequests {
+				t.Errorf("want requests %v, got %v", c.wantRequests, *requests)
+			}
+		})
+	}
+
+	if stackLimits.Memory != "128Mi" || stackRequests.Memory != "" {
+		t.Errorf("want the stack resources unchanged, got %v %v", *stackLimits, *stackRequests)
+	}
+
+	limits, requests := resourceOverrides{}.apply("fn1", nil, stackRequests)
+	if limits != nil || requests != stackRequests {
+		t.Errorf("want the stack resources without flags, got %v %v", limits, requests)
+	}
+}
+
+func Test_deploy_DryRun_ResourceFlags(t *testing.T) {
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  figlet:
+    image: functions/figlet:latest
+    limits:
+      memory: 40Mi
+      cpu: 100m
+    requests:
+      memory: 20Mi
+`)
+	stackFile.Close()
+
+	resetForTest()
+	defer func() {
+		deployFlags.dryRun = false
+		deployFlags.output = "yaml"
+		deployFlags.memoryLimits = []string{}
+		deployFlags.cpuRequests = []string{}
+	}()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"deploy",
+			"-f", stackFile.Name(),
+			"--memory-limit=128Mi",
+			"--cpu-request=figlet=50m",
+			"--dry-run",
+			"--output=json",
+		})
+		faasCmd.Execute()
+	})
+
+	var requests []types.FunctionDeployment
+	if err := json.Unmarshal([]byte(stdOut), &requests); err != nil {
+		t.Fatalf("want JSON output, got %q: %s", stdOut, err)
+	}
+
+	if len(requests) != 1 || requests[0].Limits == nil || requests[0].Requests == nil {
+		t.Fatalf("unexpected dry-run output: %s", stdOut)
+	}
+
+	if requests[0].Limits.Memory != "128Mi" || requests[0].Limits.CPU != "100m" {
+		t.Errorf("want the flag to override the memory limit only, got %v", *requests[0].Limits)
+	}
+	if requests[0].Requests.Memory != "20Mi" || requests[0].Requests.CPU != "50m" {
+		t.Errorf("want the CPU request for figlet from the flag, got %v", *requests[0].Requests)
+	}
+}
+
+func Test_deploy_ResourceFlags_Invalid(t *testing.T) {
+	resetForTest()
+	defer func() {
+		deployFlags.dryRun = false
+		deployFlags.cpuLimits = []string{}
+	}()
+
+	faasCmd.SetArgs([]string{
+		"deploy",
+		"--image=golang",
+		"--name=test-function",
+		"--cpu-limit=half",
+		"--dry-run",
+	})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != "--cpu-limit must be a quantity such as 128Mi or 500m, but you gave: half" {
+		t.Errorf("want an error for the quantity, got %v", err)
+	}
+}
+
+func Test_deploy_ResourceFlags_UnknownFunction(t *testing.T) {
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  figlet:
+    image: functions/figlet:latest
+`)
+	stackFile.Close()
+
+	resetForTest()
+	defer func() {
+		deployFlags.dryRun = false
+		deployFlags.memoryLimits = []string{}
+	}()
+
+	faasCmd.SetArgs([]string{
+		"deploy",
+		"-f", stackFile.Name(),
+		"--memory-limit=figlett=128Mi",
+		"--dry-run",
+	})
+	err = faasCmd.Execute()
+
+	if err == nil || err.Error() != "--memory-limit names the function figlett, which is not being deployed" {
+		t.Errorf("want an error naming the unknown function, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/openfaas/faas-cli/proxy"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+var secretEnvReplacer = strings.NewReplacer("-", "_", ".", "_")
+
+// secretEnvName gives the environment variable which holds the value for a
+// secret, i.e. api-key with the prefix CI_ is read from CI_API_KEY
+func secretEnvName(prefix string, secret string) string {
+	return prefix + strings.ToUpper(secretEnvReplacer.Replace(secret))
+}
+
+// secretsFromEnv gives the secrets referenced by the deployments which have a
+// value in an environment variable with the prefix, secrets without one are
+// expected to exist already. Secrets are created in the function's namespace,
+// or the default namespace when it has none.
+func secretsFromEnv(prefix string, specs []*proxy.DeployFunctionSpec, namespace string, lookupEnv func(string) (string, bool)) []types.Secret {
+	seen := map[string]bool{}
+	secrets := []types.Secret{}
+
+	for _, spec := range specs {
+		secretNamespace := namespace
+		if len(spec.Namespace) > 0 {
+			secretNamespace = spec.Namespace
+		}
+
+		for _, name := range spec.Secrets {
+			key := secretNamespace + "/" + name
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			value, ok := lookupEnv(secretEnvName(prefix, name))
+			if !ok || len(value) == 0 {
+				continue
+			}
+
+			secrets = append(secrets, types.Secret{
+				Name:      name,
+				Namespace: secretNamespace,
+				Value:     value,
+			})
+		}
+	}
+
+	sort.SliceStable(secrets, func(i, j int) bool {
+		if secrets[i].Namespace != secrets[j].Namespace {
+			return secrets[i].Namespace < secrets[j].Namespace
+		}
+		return secrets[i].Name < secrets[j].Name
+	})
+
+	return secrets
+}
+
+// applySecrets creates each secret, or updates it when it exists already.
+// The values are never printed and are redacted from the gateway's output.
+func applySecrets(ctx context.Context, client *proxy.Client, secrets []types.Secret) error {
+	for _, secret := range secrets {
+		fmt.Fprintf(infoOutput(), "Creating secret: %s\n", secret.Name)
+
+		status, output := client.CreateSecret(ctx, secret)
+		if status == http.StatusConflict {
+			fmt.Fprintf(infoOutput(), "Secret exists, updating secret: %s\n", secret.Name)
+			status, output = client.UpdateSecret(ctx, secret)
+		}
+
+		output = strings.Replace(output, secret.Value, redactedValue, -1)
+		if !isSecretStatusOK(status) {
+			return &proxy.HTTPError{StatusCode: status, Message: fmt.Sprintf("unable to create secret %s: %s", secret.Name, strings.TrimSpace(output))}
+		}
+		fmt.Fprint(infoOutput(), output)
+	}
+
+	return nil
+}
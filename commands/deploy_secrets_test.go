@@ -0,0 +1,90 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_secretEnvName(t *testing.T) {
+	if got := secretEnvName("CI_", "api-key.v2"); got != "CI_API_KEY_V2" {
+		t.Errorf("want CI_API_KEY_V2, got %s", got)
+	}
+}
+
+func Test_secretsFromEnv(t *testing.T) {
+	env := map[string]string{
+		"CI_API_KEY": "key",
+		"CI_DB_PASS": "pass",
+		"CI_UNUSED":  "unused",
+		"CI_EMPTY":   "",
+	}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	specs := []*proxy.DeployFunctionSpec{
+		{FunctionName: "fn1", Secrets: []string{"db-pass", "api-key", "existing"}},
+		{FunctionName: "fn2", Secrets: []string{"api-key", "empty"}},
+		{FunctionName: "fn3", Secrets: []string{"api-key"}, Namespace: "dev"},
+	}
+
+	got := secretsFromEnv("CI_", specs, "staging", lookupEnv)
+	want := []types.Secret{
+		{Name: "api-key", Namespace: "dev", Value: "key"},
+		{Name: "api-key", Namespace: "staging", Value: "key"},
+		{Name: "db-pass", Namespace: "staging", Value: "pass"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func Test_applySecrets_UpdatesExistingAndRedacts(t *testing.T) {
+	var methods []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("invalid value s3cr3t"))
+		}
+	}))
+	defer s.Close()
+
+	client := proxy.NewClient(NewCLIAuth("", s.URL), s.URL, nil, nil)
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		err = applySecrets(context.Background(), client, []types.Secret{{Name: "api-key", Value: "s3cr3t"}})
+	})
+
+	if !reflect.DeepEqual(methods, []string{http.MethodPost, http.MethodPut}) {
+		t.Errorf("want a POST then a PUT, got %v", methods)
+	}
+
+	if !strings.Contains(stdOut, "Secret exists, updating secret: api-key") {
+		t.Errorf("want the update to be printed, got %q", stdOut)
+	}
+
+	if err == nil {
+		t.Fatal("want an error for the failed update")
+	}
+	if strings.Contains(err.Error(), "s3cr3t") || !strings.Contains(err.Error(), redactedValue) {
+		t.Errorf("want the value to be redacted, got %q", err.Error())
+	}
+}
@@ -4,17 +4,31 @@
 package commands
 
 import (
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
 )
 
 func Test_deploy(t *testing.T) {
+	resetForTest()
+	defer resetForTest()
+
 	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/test-function",
+			ResponseStatusCode: http.StatusNotFound,
+		},
 		{
 			Method:             http.MethodPut,
 			Uri:                "/system/functions",
@@ -200,3 +214,323 @@ func Test_badStatusCOde(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func Test_deploy_DryRun_DoesNotCallGateway(t *testing.T) {
+	resetForTest()
+	defer resetForTest()
+
+	s := test.MockHttpServer(t, []test.Request{})
+	defer s.Close()
+	defer func() {
+		deployFlags.dryRun = false
+		deployFlags.output = "yaml"
+		deployFlags.labelOpts = []string{}
+	}()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"deploy",
+			"--gateway=" + s.URL,
+			"--image=golang",
+			"--name=test-function",
+			"--label=canary=true",
+			"--dry-run",
+			"--output=json",
+		})
+		faasCmd.Execute()
+	})
+
+	var requests []types.FunctionDeployment
+	if err := json.Unmarshal([]byte(stdOut), &requests); err != nil {
+		t.Fatalf("want JSON output, got %q: %s", stdOut, err)
+	}
+
+	if len(requests) != 1 || requests[0].Service != "test-function" || requests[0].Image != "golang" {
+		t.Fatalf("unexpected dry-run output: %s", stdOut)
+	}
+
+	if (*requests[0].Labels)["canary"] != "true" {
+		t.Errorf("want label canary=true, got %v", *requests[0].Labels)
+	}
+}
+
+func Test_deploy_DryRun_HealthCheckAnnotations(t *testing.T) {
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  figlet:
+    image: functions/figlet:latest
+    healthcheck:
+      path: /_/ready
+      initial_delay: 2s
+      period: 10s
+    annotations:
+      com.openfaas.health.http.periodSeconds: "5"
+`)
+	stackFile.Close()
+
+	resetForTest()
+	defer func() {
+		deployFlags.dryRun = false
+		deployFlags.output = "yaml"
+	}()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"deploy",
+			"-f", stackFile.Name(),
+			"--dry-run",
+			"--output=json",
+		})
+		faasCmd.Execute()
+	})
+
+	var requests []types.FunctionDeployment
+	if err := json.Unmarshal([]byte(stdOut), &requests); err != nil {
+		t.Fatalf("want JSON output, got %q: %s", stdOut, err)
+	}
+
+	if len(requests) != 1 || requests[0].Annotations == nil {
+		t.Fatalf("unexpected dry-run output: %s", stdOut)
+	}
+
+	annotations := *requests[0].Annotations
+	if annotations["com.openfaas.health.http.path"] != "/_/ready" || annotations["com.openfaas.health.http.initialDelay"] != "2s" {
+		t.Errorf("want the healthcheck as annotations, got %v", annotations)
+	}
+	if annotations["com.openfaas.health.http.periodSeconds"] != "5" {
+		t.Errorf("want the annotations in the stack file to override the healthcheck, got %v", annotations)
+	}
+}
+
+func Test_deploy_DryRun_TopicsAnnotation(t *testing.T) {
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  figlet:
+    image: functions/figlet:latest
+    topics:
+      - payments
+      - orders
+`)
+	stackFile.Close()
+
+	resetForTest()
+	defer func() {
+		deployFlags.dryRun = false
+		deployFlags.output = "yaml"
+	}()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"deploy",
+			"-f", stackFile.Name(),
+			"--dry-run",
+			"--output=json",
+		})
+		faasCmd.Execute()
+	})
+
+	var requests []types.FunctionDeployment
+	if err := json.Unmarshal([]byte(stdOut), &requests); err != nil {
+		t.Fatalf("want JSON output, got %q: %s", stdOut, err)
+	}
+
+	if len(requests) != 1 || requests[0].Annotations == nil {
+		t.Fatalf("unexpected dry-run output: %s", stdOut)
+	}
+	if topic := (*requests[0].Annotations)["topic"]; topic != "payments,orders" {
+		t.Errorf("want the topics joined in the topic annotation, got %q", topic)
+	}
+}
+
+func Test_deploy_DryRun_FunctionNames(t *testing.T) {
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  figlet:
+    image: functions/figlet:latest
+  web-a:
+    image: functions/web-a:latest
+  web-b:
+    image: functions/web-b:latest
+`)
+	stackFile.Close()
+
+	resetForTest()
+	defer func() {
+		deployFlags.dryRun = false
+		deployFlags.output = "yaml"
+	}()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"deploy",
+			"-f", stackFile.Name(),
+			"--dry-run",
+			"--output=json",
+			"web-*",
+		})
+		faasCmd.Execute()
+	})
+
+	var requests []types.FunctionDeployment
+	if err := json.Unmarshal([]byte(stdOut), &requests); err != nil {
+		t.Fatalf("want JSON output, got %q: %s", stdOut, err)
+	}
+
+	var services []string
+	for _, request := range requests {
+		services = append(services, request.Service)
+	}
+	sort.Strings(services)
+	if !reflect.DeepEqual(services, []string{"web-a", "web-b"}) {
+		t.Errorf("want only the functions matching web-*, got %v", services)
+	}
+
+	faasCmd.SetArgs([]string{
+		"deploy",
+		"-f", stackFile.Name(),
+		"--dry-run",
+		"figlet",
+		"nodeinfo",
+	})
+	err = faasCmd.Execute()
+	if err == nil || err.Error() != "no function matching nodeinfo was found in the stack file" {
+		t.Errorf("want an error for a name which isn't in the stack file, got %v", err)
+	}
+}
+
+func Test_deploy_DryRun_RegexAndFunctionNames(t *testing.T) {
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  figlet:
+    image: functions/figlet:latest
+  api-a:
+    image: functions/api-a:latest
+  web-a:
+    image: functions/web-a:latest
+`)
+	stackFile.Close()
+
+	resetForTest()
+	defer func() {
+		deployFlags.dryRun = false
+		deployFlags.output = "yaml"
+		regex = ""
+	}()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"deploy",
+			"-f", stackFile.Name(),
+			"--dry-run",
+			"--output=json",
+			"--regex=^api-",
+			"figlet",
+		})
+		faasCmd.Execute()
+	})
+
+	var requests []types.FunctionDeployment
+	if err := json.Unmarshal([]byte(stdOut), &requests); err != nil {
+		t.Fatalf("want JSON output, got %q: %s", stdOut, err)
+	}
+
+	var services []string
+	for _, request := range requests {
+		services = append(services, request.Service)
+	}
+	sort.Strings(services)
+	if !reflect.DeepEqual(services, []string{"api-a", "figlet"}) {
+		t.Errorf("want the functions matching the regex and the names, got %v", services)
+	}
+
+	resetForTest()
+	faasCmd.SetArgs([]string{
+		"deploy",
+		"-f", stackFile.Name(),
+		"--dry-run",
+		"--regex=api-(",
+	})
+	err = faasCmd.Execute()
+	if err == nil || !strings.HasPrefix(err.Error(), `invalid --regex "api-(": `) {
+		t.Errorf("want an error for the regex, got %v", err)
+	}
+}
+
+func Test_printDryRun_YAML(t *testing.T) {
+	requests := []types.FunctionDeployment{
+		{Service: "nodeinfo", Image: "functions/nodeinfo", RegistryAuth: "c2VjcmV0"},
+		{Service: "figlet", Image: "functions/figlet"},
+	}
+
+	stdOut := test.CaptureStdout(func() {
+		if err := printDryRun(requests, "yaml"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if strings.Index(stdOut, "service: figlet") > strings.Index(stdOut, "service: nodeinfo") {
+		t.Errorf("want functions sorted by name, got:\n%s", stdOut)
+	}
+
+	if strings.Contains(stdOut, "c2VjcmV0") || !strings.Contains(stdOut, "registryAuth: <redacted>") {
+		t.Errorf("want registryAuth redacted, got:\n%s", stdOut)
+	}
+}
+
+func Test_deploy_InvalidAnnotation(t *testing.T) {
+	resetForTest()
+	defer func() {
+		deployFlags.dryRun = false
+		deployFlags.annotationOpts = []string{}
+	}()
+
+	cases := []struct {
+		annotation string
+		want       string
+	}{
+		{"topic", "error parsing annotations: annotation format is not correct, needs key=value: [topic]"},
+		{"bad key=true", `error parsing annotations: annotation "bad key" must be up to 63 letters, digits, '-', '_' or '.' and start and end with a letter or digit`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.annotation, func(t *testing.T) {
+			deployFlags.annotationOpts = []string{}
+			faasCmd.SetArgs([]string{
+				"deploy",
+				"--image=golang",
+				"--name=test-function",
+				"--annotation=" + c.annotation,
+				"--dry-run",
+			})
+			err := faasCmd.Execute()
+
+			if err == nil || err.Error() != c.want {
+				t.Errorf("want error %q, got %v", c.want, err)
+			}
+		})
+	}
+}
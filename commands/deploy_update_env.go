@@ -0,0 +1,126 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/stack"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+// parseEnvUpdates splits the --update-env values into those to set, given
+// as KEY=VALUE, and the keys to remove, given as KEY-
+func parseEnvUpdates(updates []string) (map[string]string, []string, error) {
+	set := map[string]string{}
+	var remove []string
+
+	for _, update := range updates {
+		if index := strings.Index(update, "="); index > 0 {
+			set[update[:index]] = update[index+1:]
+			continue
+		}
+
+		if strings.HasSuffix(update, "-") && len(update) > 1 {
+			remove = append(remove, strings.TrimSuffix(update, "-"))
+			continue
+		}
+
+		return nil, nil, fmt.Errorf("each --update-env must be KEY=VALUE to set a variable or KEY- to remove it, not: %s", update)
+	}
+
+	for _, key := range remove {
+		if _, ok := set[key]; ok {
+			return nil, nil, fmt.Errorf("--update-env both sets and removes %s", key)
+		}
+	}
+	return set, remove, nil
+}
+
+// applyEnvUpdates gives a copy of the environment with the updates made
+func applyEnvUpdates(envVars map[string]string, set map[string]string, remove []string) map[string]string {
+	updated := mergeMap(envVars, set)
+	for _, key := range remove {
+		delete(updated, key)
+	}
+	return updated
+}
+
+// runDeployUpdateEnv redeploys a function with only its environment changed,
+// from the spec which the gateway reports for it
+func runDeployUpdateEnv(args []string, updates []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("--update-env changes a single deployed function, give its name, i.e. faas-cli deploy NAME --update-env KEY=VALUE")
+	}
+	name := args[0]
+
+	set, remove, err := parseEnvUpdates(updates)
+	if err != nil {
+		return err
+	}
+
+	gatewayAddress := getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
+
+	cliAuth := NewCLIAuth(token, gatewayAddress)
+	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
+	proxyClient := proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
+	ctx := context.Background()
+
+	status, err := proxyClient.GetFunctionStatus(ctx, name, functionNamespace)
+	if err != nil {
+		return err
+	}
+
+	if err := checkReportsSpec(name, status, "--update-env"); err != nil {
+		return err
+	}
+
+	deploySpec := statusSpec(name, status)
+	deploySpec.EnvVars = applyEnvUpdates(status.EnvVars, set, remove)
+	deploySpec.TLSInsecure = tlsInsecure
+	deploySpec.Token = token
+	deploySpec.Namespace = functionNamespace
+
+	statusCode := proxyClient.DeployFunction(ctx, deploySpec)
+	if badStatusCode(statusCode) {
+		return &proxy.HTTPError{StatusCode: statusCode, Message: fmt.Sprintf("Function '%s' failed to update its environment with status code: %d", name, statusCode)}
+	}
+
+	printEnvVars(os.Stdout, name, deploySpec.EnvVars)
+	return nil
+}
+
+// stackResources gives the limits or requests reported by the gateway in the
+// form of the stack file
+func stackResources(resources *types.FunctionResources) *stack.FunctionResources {
+	if resources == nil {
+		return nil
+	}
+	return &stack.FunctionResources{Memory: resources.Memory, CPU: resources.CPU}
+}
+
+// printEnvVars prints the environment sorted by key, with the values of
+// variables which look like credentials redacted
+func printEnvVars(w io.Writer, name string, envVars map[string]string) {
+	keys := make([]string, 0, len(envVars))
+	for key := range envVars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(w, "Environment of %s:\n", name)
+	for _, key := range keys {
+		value := envVars[key]
+		if secretEnvPattern.MatchString(key) {
+			value = redactedValue
+		}
+		fmt.Fprintf(w, "  %s=%s\n", key, value)
+	}
+}
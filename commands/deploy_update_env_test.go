@@ -0,0 +1,146 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_parseEnvUpdates(t *testing.T) {
+	set, remove, err := parseEnvUpdates([]string{"LOG_LEVEL=debug", "URL=http://a?b=c", "DEBUG-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"LOG_LEVEL": "debug", "URL": "http://a?b=c"}; !reflect.DeepEqual(set, want) {
+		t.Errorf("want %v set, got %v", want, set)
+	}
+	if want := []string{"DEBUG"}; !reflect.DeepEqual(remove, want) {
+		t.Errorf("want %v removed, got %v", want, remove)
+	}
+
+	for _, update := range []string{"DEBUG", "=value", "-"} {
+		if _, _, err := parseEnvUpdates([]string{update}); err == nil {
+			t.Errorf("want an error for %q", update)
+		}
+	}
+
+	if _, _, err := parseEnvUpdates([]string{"DEBUG=1", "DEBUG-"}); err == nil {
+		t.Error("want an error when a key is both set and removed")
+	}
+}
+
+func Test_deploy_UpdateEnv(t *testing.T) {
+	resetForTest()
+	defer resetForTest()
+	defer func() { deployFlags.updateEnv = []string{} }()
+
+	var deployed types.FunctionDeployment
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(proxy.FunctionStatus{
+				FunctionStatus: types.FunctionStatus{
+					Name:       "figlet",
+					Image:      "functions/figlet:0.2",
+					EnvProcess: "figlet",
+					Labels:     &map[string]string{"team": "web"},
+				},
+				EnvVars: map[string]string{"LOG_LEVEL": "info", "DEBUG": "1", "API_TOKEN": "s3cr3t"},
+				Secrets: []string{"api-key"},
+				Limits:  &types.FunctionResources{Memory: "128Mi"},
+			})
+			return
+		}
+
+		json.NewDecoder(r.Body).Decode(&deployed)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"deploy",
+			"figlet",
+			"--gateway=" + s.URL,
+			"--update-env=LOG_LEVEL=debug",
+			"--update-env=DEBUG-",
+		})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	wantEnv := map[string]string{"LOG_LEVEL": "debug", "API_TOKEN": "s3cr3t"}
+	if !reflect.DeepEqual(deployed.EnvVars, wantEnv) {
+		t.Errorf("want env %v, got %v", wantEnv, deployed.EnvVars)
+	}
+	if deployed.Image != "functions/figlet:0.2" || deployed.EnvProcess != "figlet" {
+		t.Errorf("want the image and fprocess kept, got %s and %s", deployed.Image, deployed.EnvProcess)
+	}
+	if deployed.Labels == nil || (*deployed.Labels)["team"] != "web" {
+		t.Errorf("want the labels kept, got %v", deployed.Labels)
+	}
+	if !reflect.DeepEqual(deployed.Secrets, []string{"api-key"}) {
+		t.Errorf("want the secrets kept, got %v", deployed.Secrets)
+	}
+	if deployed.Limits == nil || deployed.Limits.Memory != "128Mi" {
+		t.Errorf("want the limits kept, got %v", deployed.Limits)
+	}
+
+	want := "Environment of figlet:\n  API_TOKEN=<redacted>\n  LOG_LEVEL=debug\n"
+	if !strings.HasSuffix(stdOut, want) {
+		t.Errorf("want the environment printed, got %q", stdOut)
+	}
+}
+
+func Test_deploy_UpdateEnv_GatewayWithoutSpec(t *testing.T) {
+	resetForTest()
+	defer resetForTest()
+	defer func() { deployFlags.updateEnv = []string{} }()
+
+	deploys := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(types.FunctionStatus{Name: "figlet", Image: "functions/figlet:0.2"})
+			return
+		}
+
+		deploys++
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	faasCmd.SetArgs([]string{
+		"deploy",
+		"figlet",
+		"--gateway=" + s.URL,
+		"--update-env=LOG_LEVEL=debug",
+	})
+	err := faasCmd.Execute()
+
+	if err == nil || !strings.Contains(err.Error(), "so --update-env would remove them") {
+		t.Errorf("want the update to be refused, got %v", err)
+	}
+	if deploys != 0 {
+		t.Errorf("want no deploy, got %d", deploys)
+	}
+}
+
+func Test_printEnvVars(t *testing.T) {
+	var buf bytes.Buffer
+	printEnvVars(&buf, "figlet", map[string]string{})
+
+	if got := buf.String(); got != "Environment of figlet:\n" {
+		t.Errorf("want only the heading for no variables, got %q", got)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openfaas/faas-cli/proxy"
+)
+
+// readyPollInterval is how often the gateway is checked for --wait
+var readyPollInterval = time.Second
+
+type deployedFunction struct {
+	name      string
+	namespace string
+}
+
+// waitForReady polls the gateway for all functions at once until each has at
+// least one available replica, functions which are not ready by the timeout
+// are named in the error.
+func waitForReady(ctx context.Context, client *proxy.Client, functions []deployedFunction, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		notReady []string
+	)
+
+	for _, function := range functions {
+		wg.Add(1)
+		go func(function deployedFunction) {
+			defer wg.Done()
+
+			if !pollUntilReady(ctx, client, function) {
+				mu.Lock()
+				notReady = append(notReady, function.name)
+				mu.Unlock()
+				return
+			}
+			fmt.Fprintf(infoOutput(), "Function %s is ready.\n", function.name)
+		}(function)
+	}
+	wg.Wait()
+
+	if len(notReady) > 0 {
+		sort.Strings(notReady)
+		return fmt.Errorf("function(s) not ready after %s: %s", timeout, strings.Join(notReady, ", "))
+	}
+	return nil
+}
+
+func pollUntilReady(ctx context.Context, client *proxy.Client, function deployedFunction) bool {
+	for {
+		status, err := client.GetFunctionInfo(ctx, function.name, function.namespace)
+		if err == nil && status.AvailableReplicas >= 1 {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(readyPollInterval):
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openfaas/faas-cli/proxy"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_waitForReady_BecomesReady(t *testing.T) {
+	readyPollInterval = time.Millisecond
+	defer func() { readyPollInterval = time.Second }()
+
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var replicas uint64
+		if atomic.AddInt32(&calls, 1) > 2 {
+			replicas = 1
+		}
+		json.NewEncoder(w).Encode(types.FunctionStatus{Name: "figlet", AvailableReplicas: replicas})
+	}))
+	defer s.Close()
+
+	client := proxy.NewClient(NewCLIAuth("", s.URL), s.URL, nil, nil)
+	err := waitForReady(context.Background(), client, []deployedFunction{{name: "figlet"}}, time.Second)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+}
+
+func Test_waitForReady_TimesOut(t *testing.T) {
+	readyPollInterval = time.Millisecond
+	defer func() { readyPollInterval = time.Second }()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/system/function/")
+		var replicas uint64
+		if name == "figlet" {
+			replicas = 1
+		}
+		json.NewEncoder(w).Encode(types.FunctionStatus{Name: name, AvailableReplicas: replicas})
+	}))
+	defer s.Close()
+
+	client := proxy.NewClient(NewCLIAuth("", s.URL), s.URL, nil, nil)
+	functions := []deployedFunction{{name: "figlet"}, {name: "nodeinfo"}, {name: "env"}}
+
+	err := waitForReady(context.Background(), client, functions, 20*time.Millisecond)
+	if err == nil {
+		t.Fatalf("want timeout error")
+	}
+
+	want := "function(s) not ready after 20ms: env, nodeinfo"
+	if err.Error() != want {
+		t.Errorf("want error %q, got %q", want, err.Error())
+	}
+}
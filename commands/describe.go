@@ -5,55 +5,122 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"text/template"
 
 	"github.com/openfaas/faas-cli/proxy"
 	"github.com/openfaas/faas-cli/schema"
 	"github.com/openfaas/faas-cli/stack"
 
 	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
 )
 
+var (
+	describeOutput      string
+	describeShowSecrets bool
+	describeFormat      string
+	describeURL         bool
+	describeAsync       bool
+
+	describeTemplate *template.Template
+)
+
+// secretEnvPattern matches environment variable names which are likely to hold
+// a credential, their values are redacted unless --show-secrets is given
+var secretEnvPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|access_?key|private_?key|credential)`)
+
 func init() {
 	describeCmd.Flags().StringVar(&functionName, "name", "", "Name of the function")
 	describeCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
-	describeCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
 	describeCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yml file")
 	describeCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
 	describeCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function")
+	describeCmd.Flags().StringVarP(&describeOutput, "output", "o", "", "Output format, either json or yaml, instead of text")
+	describeCmd.Flags().StringVar(&describeFormat, "format", "", "Print each function with a Go template instead of text, i.e. '{{.Name}}\\t{{.Status}}'")
+	describeCmd.Flags().BoolVar(&describeURL, "url", false, "Print only the URL to invoke each function with")
+	describeCmd.Flags().BoolVar(&describeAsync, "async", false, "Print the URL to invoke each function asynchronously with (requires --url)")
+	describeCmd.Flags().BoolVar(&describeShowSecrets, "show-secrets", false, "Show the values of environment variables which look like credentials in --output or --format")
 
 	faasCmd.AddCommand(describeCmd)
 }
 
 var describeCmd = &cobra.Command{
-	Use:   "describe FUNCTION_NAME [--gateway GATEWAY_URL]",
+	Use:   "describe FUNCTION_NAME... [--gateway GATEWAY_URL] [--output json|yaml] [--format TEMPLATE] [--url [--async]]",
 	Short: "Describe an OpenFaaS function",
-	Long:  `Display details of an OpenFaaS function`,
+	Long: `Display details of one or more OpenFaaS functions, or of each function in the
+stack file when no names are given. The constraints are shown when the
+provider reports them. With "--output" or "--format" the environment, and the
+constraints a provider doesn't report, are included when the function is found
+in the stack file given with "--yaml", values of variables which look like
+credentials are redacted.
+A function which can't be described is reported and the rest are still shown.
+
+The --format flag prints each function with a Go template, where \t and \n give
+a tab and a newline. The fields are those of --output json with the first
+letter in upper case, such as .Name, .Status, .Replicas, .Image, .URL and
+.Annotations, and {{json .Labels}} gives a field as JSON.
+
+The --url flag prints only the URL of each function, from the gateway and the
+--namespace, or the URL to invoke it asynchronously with --async. It is an
+error for a function not to be deployed.`,
 	Example: `faas-cli describe figlet 
 faas-cli describe env --gateway http://127.0.0.1:8080
-faas-cli describe echo -g http://127.0.0.1.8080`,
+faas-cli describe echo -g http://127.0.0.1.8080
+faas-cli describe figlet -f ./stack.yml --output json
+faas-cli describe figlet nodeinfo env
+faas-cli describe -f ./stack.yml --output yaml
+faas-cli describe -f ./stack.yml --format '{{.Name}}\t{{.Status}}\t{{.URL}}'
+faas-cli describe figlet --url
+faas-cli describe figlet --url --async --namespace dev`,
 	PreRunE: preRunDescribe,
 	RunE:    runDescribe,
 }
 
 func preRunDescribe(cmd *cobra.Command, args []string) error {
+	if len(describeOutput) > 0 && describeOutput != "json" && describeOutput != "yaml" {
+		return fmt.Errorf("--output must be json or yaml, not: %s", describeOutput)
+	}
+
+	if describeAsync && !describeURL {
+		return fmt.Errorf("the --async flag requires --url")
+	}
+	if describeURL && (len(describeOutput) > 0 || len(describeFormat) > 0) {
+		return fmt.Errorf("--url can't be used with --output or --format")
+	}
+
+	describeTemplate = nil
+	if len(describeFormat) > 0 {
+		if len(describeOutput) > 0 {
+			return fmt.Errorf("--output and --format can't be used together")
+		}
+
+		tmpl, err := parseFormat(describeFormat)
+		if err != nil {
+			return err
+		}
+		describeTemplate = tmpl
+	}
 	return nil
 }
 
 func runDescribe(cmd *cobra.Command, args []string) error {
-	if len(args) < 1 {
+	if len(args) < 1 && len(yamlFile) == 0 {
 		return fmt.Errorf("please provide a name for the function")
 	}
 	var yamlGateway string
 	var services stack.Services
-	functionName = args[0]
 
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		parsedServices, err := stack.ParseYAMLFiles(stackFiles(), regex, filter, envsubst)
 		if err != nil {
 			return err
 		}
@@ -63,21 +130,84 @@ func runDescribe(cmd *cobra.Command, args []string) error {
 			yamlGateway = services.Provider.GatewayURL
 		}
 	}
+
+	names := args
+	if len(names) == 0 {
+		for name := range services.Functions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
 	gatewayAddress := getGatewayURL(gateway, defaultGateway, yamlGateway, os.Getenv(openFaaSURLEnvironment))
 	cliAuth := NewCLIAuth(token, gatewayAddress)
 	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
 	cliClient := proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
 	ctx := context.Background()
 
-	function, err := cliClient.GetFunctionInfo(ctx, functionName, functionNamespace)
+	if describeURL {
+		return printFunctionURLs(ctx, cliClient, gatewayAddress, names)
+	}
+
+	var descriptions []schema.FunctionDescription
+	failed := 0
+	for _, name := range names {
+		funcDesc, err := describeFunction(ctx, cliClient, gatewayAddress, name, services)
+		if err != nil && len(names) == 1 {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to describe %s: %s\n", name, err.Error())
+			failed++
+			continue
+		}
+
+		if len(describeOutput) == 0 && describeTemplate == nil {
+			if len(descriptions) > 0 {
+				fmt.Println()
+			}
+			printFunctionDescription(funcDesc)
+		}
+		descriptions = append(descriptions, funcDesc)
+	}
+
+	if len(describeOutput) > 0 {
+		// a single name keeps giving an object rather than an array
+		var result interface{} = descriptions
+		if len(args) == 1 && len(descriptions) == 1 {
+			result = descriptions[0]
+		}
+		if err := printFunctionDescriptionAs(result, describeOutput); err != nil {
+			return err
+		}
+	}
+
+	if describeTemplate != nil {
+		var items []interface{}
+		for _, funcDesc := range descriptions {
+			items = append(items, funcDesc)
+		}
+		if err := printFormatted(os.Stdout, describeTemplate, items); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("unable to describe %d of %d function(s)", failed, len(names))
+	}
+	return nil
+}
+
+func describeFunction(ctx context.Context, cliClient *proxy.Client, gatewayAddress string, functionName string, services stack.Services) (schema.FunctionDescription, error) {
+	function, err := cliClient.GetFunctionStatus(ctx, functionName, functionNamespace)
 	if err != nil {
-		return err
+		return schema.FunctionDescription{}, err
 	}
 
 	//To get correct value for invocation count from /system/functions endpoint
 	functionList, err := cliClient.ListFunctions(ctx, functionNamespace)
 	if err != nil {
-		return err
+		return schema.FunctionDescription{}, err
 	}
 
 	var invocationCount int
@@ -107,10 +237,78 @@ func runDescribe(cmd *cobra.Command, args []string) error {
 		AsyncURL:          asyncURL,
 		Labels:            function.Labels,
 		Annotations:       function.Annotations,
+		Namespace:         function.Namespace,
+		Constraints:       function.Constraints,
+	}
+
+	if function.Annotations != nil {
+		funcDesc.HealthCheck = stack.HealthCheckFromAnnotations(*function.Annotations)
+		funcDesc.Topics = stack.TopicsFromAnnotations(*function.Annotations)
+	}
+
+	if stackFunction, ok := services.Functions[functionName]; ok && (len(describeOutput) > 0 || len(describeFormat) > 0) {
+		fileEnvironment, err := readFiles(stackFunction.EnvironmentFile)
+		if err != nil {
+			return funcDesc, err
+		}
+
+		environment := mergeMap(stackFunction.Environment, fileEnvironment)
+		if !describeShowSecrets {
+			environment = redactEnvironment(environment)
+		}
+		funcDesc.Environment = environment
+
+		if stackFunction.Constraints != nil && len(funcDesc.Constraints) == 0 {
+			funcDesc.Constraints = *stackFunction.Constraints
+		}
+	}
+
+	return funcDesc, nil
+}
+
+// redactEnvironment replaces the values of variables which look like credentials
+func redactEnvironment(environment map[string]string) map[string]string {
+	redacted := map[string]string{}
+	for key, value := range environment {
+		if secretEnvPattern.MatchString(key) {
+			value = redactedValue
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+func printFunctionDescriptionAs(funcDesc interface{}, output string) error {
+	var out []byte
+	var err error
+	if output == "json" {
+		out, err = json.MarshalIndent(funcDesc, "", "  ")
+		out = append(out, '\n')
+	} else {
+		out, err = yaml.Marshal(funcDesc)
 	}
+	if err != nil {
+		return fmt.Errorf("unable to marshal the function description: %s", err.Error())
+	}
+
+	fmt.Print(string(out))
+	return nil
+}
 
-	printFunctionDescription(funcDesc)
+// printFunctionURLs prints the URL of each function, once it is found to be
+// deployed, so that it can be used in a script
+func printFunctionURLs(ctx context.Context, cliClient *proxy.Client, gatewayAddress string, names []string) error {
+	for _, name := range names {
+		if _, err := cliClient.GetFunctionInfo(ctx, name, functionNamespace); err != nil {
+			return err
+		}
 
+		url, asyncURL := getFunctionURLs(gatewayAddress, name, functionNamespace)
+		if describeAsync {
+			url = asyncURL
+		}
+		fmt.Println(url)
+	}
 	return nil
 }
 
@@ -140,18 +338,32 @@ func printFunctionDescription(funcDesc schema.FunctionDescription) {
 	fmt.Fprintln(w, "URL:\t "+funcDesc.URL)
 	fmt.Fprintln(w, "Async URL:\t "+funcDesc.AsyncURL)
 
+	if len(funcDesc.Constraints) > 0 {
+		fmt.Fprintln(w, "Constraints:\t "+strings.Join(funcDesc.Constraints, ", "))
+	}
+
 	if funcDesc.Labels != nil {
 		fmt.Fprintf(w, "Labels:")
-		for key, value := range *funcDesc.Labels {
-			fmt.Fprintln(w, " \t "+key+" : "+value)
-		}
+		printSortedMap(w, *funcDesc.Labels)
 	}
 
 	if funcDesc.Annotations != nil {
 		fmt.Fprintf(w, "Annotations:")
-		for key, value := range *funcDesc.Annotations {
-			fmt.Fprintln(w, " \t "+key+" : "+value)
-		}
+		printSortedMap(w, *funcDesc.Annotations)
 	}
 	w.Flush()
 }
+
+// printSortedMap prints the map in order of its keys, so that the output is
+// the same for each describe
+func printSortedMap(w io.Writer, values map[string]string) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintln(w, " \t "+key+" : "+values[key])
+	}
+}
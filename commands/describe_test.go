@@ -1,6 +1,18 @@
 package commands
 
-import "testing"
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/schema"
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
 
 func Test_getFunctionURLs(t *testing.T) {
 	cases := []struct {
@@ -26,3 +38,365 @@ func Test_getFunctionURLs(t *testing.T) {
 		})
 	}
 }
+
+func Test_describe_OutputJSONRedactsSecrets(t *testing.T) {
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  figlet:
+    image: functions/figlet:latest
+    environment:
+      write_debug: true
+      DB_PASSWORD: hunter2
+    constraints:
+      - node.platform.os == linux
+`)
+	stackFile.Close()
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/figlet",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       types.FunctionStatus{Name: "figlet", Image: "functions/figlet:latest", Replicas: 1, AvailableReplicas: 1},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []types.FunctionStatus{{Name: "figlet", InvocationCount: 4}},
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+	defer func() { describeOutput = "" }()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"describe",
+			"figlet",
+			"--gateway=" + s.URL,
+			"-f", stackFile.Name(),
+			"--output=json",
+		})
+		faasCmd.Execute()
+	})
+
+	var desc schema.FunctionDescription
+	if err := json.Unmarshal([]byte(stdOut), &desc); err != nil {
+		t.Fatalf("want JSON output, got %q: %s", stdOut, err)
+	}
+
+	if desc.Status != "Ready" || desc.InvocationCount != 4 || desc.URL != s.URL+"/function/figlet" {
+		t.Errorf("want gateway details, got %+v", desc)
+	}
+	if desc.Environment["DB_PASSWORD"] != redactedValue || desc.Environment["write_debug"] != "true" {
+		t.Errorf("want DB_PASSWORD redacted, got %v", desc.Environment)
+	}
+	if len(desc.Constraints) != 1 {
+		t.Errorf("want constraints from the stack file, got %v", desc.Constraints)
+	}
+}
+
+func Test_describe_OutputJSONHealthCheck(t *testing.T) {
+	annotations := map[string]string{
+		"com.openfaas.health.http.path":          "/_/ready",
+		"com.openfaas.health.http.periodSeconds": "10",
+		"topic":                                  "payments,orders",
+	}
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/figlet",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       types.FunctionStatus{Name: "figlet", Annotations: &annotations},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []types.FunctionStatus{{Name: "figlet"}},
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+	defer func() { describeOutput = "" }()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"describe", "figlet", "--gateway=" + s.URL, "--output=json"})
+		faasCmd.Execute()
+	})
+
+	var desc schema.FunctionDescription
+	if err := json.Unmarshal([]byte(stdOut), &desc); err != nil {
+		t.Fatalf("want JSON output, got %q: %s", stdOut, err)
+	}
+
+	if desc.HealthCheck == nil || desc.HealthCheck.Path != "/_/ready" || desc.HealthCheck.Period != "10s" {
+		t.Errorf("want the healthcheck read from the annotations, got %+v", desc.HealthCheck)
+	}
+	if !reflect.DeepEqual(desc.Topics, []string{"payments", "orders"}) {
+		t.Errorf("want the topics read from the annotation, got %v", desc.Topics)
+	}
+}
+
+func Test_describe_ConstraintsFromGateway(t *testing.T) {
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/figlet",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       map[string]interface{}{"name": "figlet", "constraints": []string{"node.platform.os == linux"}},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []types.FunctionStatus{{Name: "figlet"}},
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"describe", "figlet", "--gateway=" + s.URL})
+		faasCmd.Execute()
+	})
+
+	if !strings.Contains(stdOut, "\nConstraints:         node.platform.os == linux\n") {
+		t.Errorf("want the constraints reported by the gateway, got %q", stdOut)
+	}
+}
+
+func Test_describe_AnnotationsInOrder(t *testing.T) {
+	annotations := map[string]string{
+		"topic":                "cron-function",
+		"prometheus.io/scrape": "false",
+		"com.openfaas.scale":   "true",
+	}
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/figlet",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       types.FunctionStatus{Name: "figlet", Annotations: &annotations},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []types.FunctionStatus{{Name: "figlet"}},
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"describe", "figlet", "--gateway=" + s.URL})
+		faasCmd.Execute()
+	})
+
+	scale := strings.Index(stdOut, "com.openfaas.scale : true")
+	scrape := strings.Index(stdOut, "prometheus.io/scrape : false")
+	topic := strings.Index(stdOut, "topic : cron-function")
+	if scale == -1 || scrape < scale || topic < scrape {
+		t.Errorf("want the annotations in order of their keys, got:\n%s", stdOut)
+	}
+}
+
+func Test_describe_Format(t *testing.T) {
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/figlet",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody: types.FunctionStatus{
+				Name:              "figlet",
+				AvailableReplicas: 1,
+				Labels:            &map[string]string{"team": "web"},
+			},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []types.FunctionStatus{{Name: "figlet"}},
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+	defer func() { describeFormat = "" }()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"describe", "figlet", "--gateway=" + s.URL, `--format={{.Name}} {{.Status}} {{json .Labels}}`})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if want := "figlet Ready {\"team\":\"web\"}\n"; stdOut != want {
+		t.Errorf("want %q, got %q", want, stdOut)
+	}
+}
+
+func Test_redactEnvironment(t *testing.T) {
+	redacted := redactEnvironment(map[string]string{
+		"api_key":       "abc",
+		"GITHUB_TOKEN":  "ghp",
+		"read_timeout":  "10s",
+		"client_secret": "xyz",
+	})
+
+	for key, want := range map[string]string{"api_key": redactedValue, "GITHUB_TOKEN": redactedValue, "read_timeout": "10s", "client_secret": redactedValue} {
+		if redacted[key] != want {
+			t.Errorf("%s: want %q, got %q", key, want, redacted[key])
+		}
+	}
+}
+
+func Test_describe_MultipleContinuesAfterMissing(t *testing.T) {
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/figlet",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       types.FunctionStatus{Name: "figlet"},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []types.FunctionStatus{{Name: "figlet"}},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/missing",
+			ResponseStatusCode: http.StatusNotFound,
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/env",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       types.FunctionStatus{Name: "env"},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []types.FunctionStatus{{Name: "env"}},
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+	defer func() { describeOutput = "" }()
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"describe",
+			"figlet", "missing", "env",
+			"--gateway=" + s.URL,
+			"--output=json",
+		})
+		err = faasCmd.Execute()
+	})
+
+	if err == nil || err.Error() != "unable to describe 1 of 3 function(s)" {
+		t.Errorf("want error for the missing function, got %v", err)
+	}
+
+	var descs []schema.FunctionDescription
+	if jsonErr := json.Unmarshal([]byte(stdOut), &descs); jsonErr != nil {
+		t.Fatalf("want a JSON array, got %q: %s", stdOut, jsonErr)
+	}
+	if len(descs) != 2 || descs[0].Name != "figlet" || descs[1].Name != "env" {
+		t.Errorf("want figlet and env, got %+v", descs)
+	}
+}
+
+func Test_describe_URL(t *testing.T) {
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/figlet?namespace=dev",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       types.FunctionStatus{Name: "figlet", Namespace: "dev"},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/figlet?namespace=dev",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       types.FunctionStatus{Name: "figlet", Namespace: "dev"},
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+	defer func() {
+		describeURL = false
+		describeAsync = false
+		functionNamespace = ""
+	}()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"describe", "figlet", "--gateway=" + s.URL + "/", "--namespace=dev", "--url"})
+		faasCmd.Execute()
+	})
+	if want := s.URL + "/function/figlet.dev\n"; stdOut != want {
+		t.Errorf("want only the URL %q, got %q", want, stdOut)
+	}
+
+	stdOut = test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"describe", "figlet", "--gateway=" + s.URL, "--namespace=dev", "--url", "--async"})
+		faasCmd.Execute()
+	})
+	if want := s.URL + "/async-function/figlet.dev\n"; stdOut != want {
+		t.Errorf("want only the async URL %q, got %q", want, stdOut)
+	}
+}
+
+func Test_describe_URLNotDeployed(t *testing.T) {
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/figlet",
+			ResponseStatusCode: http.StatusNotFound,
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+	defer func() { describeURL = false }()
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"describe", "figlet", "--gateway=" + s.URL, "--url"})
+		err = faasCmd.Execute()
+	})
+	if err == nil || len(stdOut) > 0 {
+		t.Errorf("want an error and no URL for a function which isn't deployed, got %v and %q", err, stdOut)
+	}
+}
+
+func Test_describe_AsyncRequiresURL(t *testing.T) {
+	resetForTest()
+	defer func() { describeAsync = false }()
+
+	faasCmd.SetArgs([]string{"describe", "figlet", "--async"})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != "the --async flag requires --url" {
+		t.Errorf("want an error for --async without --url, got %v", err)
+	}
+}
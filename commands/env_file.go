@@ -0,0 +1,96 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var envFileKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// readEnvFiles reads each dotenv-style file in order, values in later
+// files take precedence.
+func readEnvFiles(files []string) (map[string]string, error) {
+	envs := map[string]string{}
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read --env-file: %s", err.Error())
+		}
+
+		fileEnvs, err := parseEnvFile(f, file)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for k, v := range fileEnvs {
+			envs[k] = v
+		}
+	}
+	return envs, nil
+}
+
+// parseEnvFile parses KEY=VALUE lines, an optional "export " prefix is dropped
+// and values may be wrapped in single or double quotes. Blank lines and lines
+// starting with # are ignored.
+func parseEnvFile(r io.Reader, name string) (map[string]string, error) {
+	envs := map[string]string{}
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		parts := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || !envFileKey.MatchString(key) {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE, got: %s", name, lineNumber, scanner.Text())
+		}
+
+		value, err := unquoteEnvValue(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", name, lineNumber, err.Error())
+		}
+		envs[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return envs, nil
+}
+
+func unquoteEnvValue(value string) (string, error) {
+	if len(value) == 0 {
+		return value, nil
+	}
+
+	quote := value[0]
+	if quote != '"' && quote != '\'' {
+		return value, nil
+	}
+
+	if len(value) < 2 || value[len(value)-1] != quote {
+		return "", fmt.Errorf("unterminated quoted value: %s", value)
+	}
+
+	value = value[1 : len(value)-1]
+	if quote == '"' {
+		value = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(value)
+	}
+	return value, nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseEnvFile(t *testing.T) {
+	input := `# database settings
+DB_HOST=db.local
+
+export DB_PORT=5432
+GREETING="hello world"
+QUOTED='$literal'
+ESCAPED="line1\nline2 \"quoted\""
+EMPTY=
+`
+	envs, err := parseEnvFile(strings.NewReader(input), ".env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"DB_HOST":  "db.local",
+		"DB_PORT":  "5432",
+		"GREETING": "hello world",
+		"QUOTED":   "$literal",
+		"ESCAPED":  "line1\nline2 \"quoted\"",
+		"EMPTY":    "",
+	}
+	if !reflect.DeepEqual(envs, want) {
+		t.Errorf("want %v, got %v", want, envs)
+	}
+}
+
+func Test_parseEnvFile_Malformed(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "no equals", input: "A=1\nDB_HOST\n", wantErr: ".env:2: expected KEY=VALUE, got: DB_HOST"},
+		{name: "empty key", input: "=value\n", wantErr: ".env:1: expected KEY=VALUE, got: =value"},
+		{name: "space in key", input: "DB HOST=x\n", wantErr: ".env:1: expected KEY=VALUE, got: DB HOST=x"},
+		{name: "unterminated quote", input: `A="open`, wantErr: `.env:1: unterminated quoted value: "open`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := parseEnvFile(strings.NewReader(c.input), ".env")
+			if err == nil || err.Error() != c.wantErr {
+				t.Errorf("want error %q, got %v", c.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_readEnvFiles_LaterFilesTakePrecedence(t *testing.T) {
+	first, err := ioutil.TempFile("", "env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(first.Name())
+	first.WriteString("A=1\nB=1\n")
+	first.Close()
+
+	second, err := ioutil.TempFile("", "env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(second.Name())
+	second.WriteString("B=2\n")
+	second.Close()
+
+	envs, err := readEnvFiles([]string{first.Name(), second.Name()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"A": "1", "B": "2"}
+	if !reflect.DeepEqual(envs, want) {
+		t.Errorf("want %v, got %v", want, envs)
+	}
+}
+
+func Test_compileEnvironment_EnvFileHasLowestPrecedence(t *testing.T) {
+	dotEnv := map[string]string{"A": "dotenv", "B": "dotenv", "C": "dotenv"}
+	yamlEnv := map[string]string{"B": "yaml"}
+
+	envs, err := compileEnvironment([]string{"C=flag"}, mergeMap(dotEnv, yamlEnv), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"A": "dotenv", "B": "yaml", "C": "flag"}
+	if !reflect.DeepEqual(envs, want) {
+		t.Errorf("want %v, got %v", want, envs)
+	}
+}
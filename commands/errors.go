@@ -10,6 +10,9 @@ import (
 const (
 	// NoTLSWarn Warning thrown when no SSL/TLS is used
 	NoTLSWarn = "WARNING! Communication is not secure, please consider using HTTPS. Letsencrypt.org offers free SSL/TLS certificates."
+
+	// tlsNoVerifyWarning is written to stderr when --tls-no-verify is given
+	tlsNoVerifyWarning = "WARNING! TLS certificate verification is disabled by --tls-no-verify, every HTTPS request is open to man-in-the-middle attacks. Use --ca-bundle to trust a self-signed certificate instead."
 )
 
 // checkTLSInsecure returns a warning message if the given gateway does not have https.
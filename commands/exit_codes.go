@@ -0,0 +1,105 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/spf13/cobra"
+)
+
+// The exit codes of faas-cli, so that scripts can tell the class of a
+// failure apart
+const (
+	exitCodeError    = 1 // any other failure
+	exitCodeUsage    = 2 // an unknown command, flag or argument
+	exitCodeAuth     = 3 // the gateway refused the credentials with a 401 or 403
+	exitCodeNotFound = 4 // the gateway has no such function, namespace or secret
+	exitCodeNetwork  = 5 // the gateway could not be reached, or did not respond in time
+)
+
+const exitCodesHelp = `Exit codes:
+  1  any other failure
+  2  an unknown command, flag or argument
+  3  the gateway refused the credentials with a 401 or 403
+  4  the gateway has no such function, namespace or secret
+  5  the gateway could not be reached, or did not respond in time`
+
+// usageError is returned when the command line could not be parsed
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+// markUsageErrors makes the flag and argument errors of cmd and each command
+// under it a usage error
+func markUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
+
+	if validateArgs := cmd.Args; validateArgs != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			if err := validateArgs(cmd, args); err != nil {
+				return &usageError{err: err}
+			}
+			return nil
+		}
+	}
+
+	for _, child := range cmd.Commands() {
+		markUsageErrors(child)
+	}
+}
+
+// exitCode gives the exit code for the class of err
+func exitCode(err error) int {
+	var usageErr *usageError
+	if errors.As(err, &usageErr) || strings.HasPrefix(err.Error(), "unknown command ") {
+		return exitCodeUsage
+	}
+
+	if proxy.IsConnectionError(err) {
+		return exitCodeNetwork
+	}
+
+	switch proxy.StatusCode(err) {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return exitCodeAuth
+	case http.StatusNotFound:
+		return exitCodeNotFound
+	}
+	return exitCodeError
+}
+
+// printError prints err for a failed command and gives its exit code. The
+// error is written to stderr as JSON when the command was given --output
+// json, so that a script can read the code along with the message.
+func printError(cmd *cobra.Command, err error, stdout, stderr io.Writer) int {
+	code := exitCode(err)
+	message := err.Error()
+
+	if cmd != nil {
+		if output := cmd.Flags().Lookup("output"); output != nil && output.Value.String() == "json" {
+			out, _ := json.Marshal(struct {
+				Error string `json:"error"`
+				Code  int    `json:"code"`
+			}{Error: message, Code: code})
+			fmt.Fprintln(stderr, string(out))
+			return code
+		}
+	}
+
+	fmt.Fprintln(stdout, strings.ToUpper(message[:1])+message[1:])
+	return code
+}
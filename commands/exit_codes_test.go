@@ -0,0 +1,89 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openfaas/faas-cli/proxy"
+)
+
+func Test_exitCode(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	_, connectErr := proxy.NewClient(NewCLIAuth("", closed.URL), closed.URL, nil, nil).ListFunctions(context.Background(), "")
+
+	cases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"other", fmt.Errorf("something went wrong"), exitCodeError},
+		{"usage", &usageError{err: fmt.Errorf("unknown flag: --bogus")}, exitCodeUsage},
+		{"unknown command", fmt.Errorf(`unknown command "bogus" for "faas-cli"`), exitCodeUsage},
+		{"unauthorized", &proxy.HTTPError{StatusCode: http.StatusUnauthorized}, exitCodeAuth},
+		{"forbidden", &proxy.HTTPError{StatusCode: http.StatusForbidden}, exitCodeAuth},
+		{"not found", &proxy.HTTPError{StatusCode: http.StatusNotFound}, exitCodeNotFound},
+		{"server error", &proxy.HTTPError{StatusCode: http.StatusInternalServerError}, exitCodeError},
+		{"unreachable", connectErr, exitCodeNetwork},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := exitCode(c.err); got != c.want {
+				t.Errorf("want exit code %d for %v, got %d", c.want, c.err, got)
+			}
+		})
+	}
+}
+
+func Test_markUsageErrors(t *testing.T) {
+	resetForTest()
+	markUsageErrors(faasCmd)
+
+	for _, args := range [][]string{
+		{"list", "--bogus"},
+		{"template", "lint"},
+	} {
+		faasCmd.SetArgs(args)
+		cmd, err := faasCmd.ExecuteC()
+		if err == nil {
+			t.Fatalf("want an error for %v", args)
+		}
+
+		var stdout, stderr bytes.Buffer
+		if code := printError(cmd, err, &stdout, &stderr); code != exitCodeUsage {
+			t.Errorf("want exit code %d for %v, got %d: %s", exitCodeUsage, args, code, err)
+		}
+	}
+}
+
+func Test_printError(t *testing.T) {
+	err := &proxy.HTTPError{StatusCode: http.StatusNotFound, Message: "No such function: figlet"}
+
+	var stdout, stderr bytes.Buffer
+	if code := printError(nil, err, &stdout, &stderr); code != exitCodeNotFound {
+		t.Errorf("want exit code %d, got %d", exitCodeNotFound, code)
+	}
+	if stdout.String() != "No such function: figlet\n" || stderr.Len() > 0 {
+		t.Errorf("want the error on stdout, got %q and %q", stdout.String(), stderr.String())
+	}
+
+	output := describeCmd.Flags().Lookup("output")
+	output.Value.Set("json")
+	defer output.Value.Set(output.DefValue)
+
+	stdout.Reset()
+	printError(describeCmd, err, &stdout, &stderr)
+
+	want := `{"error":"No such function: figlet","code":4}` + "\n"
+	if stderr.String() != want || stdout.Len() > 0 {
+		t.Errorf("want %q on stderr, got %q and %q", want, stderr.String(), stdout.String())
+	}
+}
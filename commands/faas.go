@@ -4,11 +4,17 @@
 package commands
 
 import (
+	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
 
 	"github.com/docker/docker/pkg/term"
+	"github.com/openfaas/faas-cli/builder"
+	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/stack"
 	"github.com/openfaas/faas-cli/version"
 	"github.com/spf13/cobra"
 )
@@ -22,11 +28,22 @@ const (
 
 // Flags that are to be added to all commands.
 var (
-	yamlFile string
-	regex    string
-	filter   string
+	yamlFile  string
+	yamlFiles []string
+	regex     string
+	filter    string
+	tokenFile string
+	tlsCert   string
+	tlsKey    string
+	tlsCA     string
+	caBundle  string
+	httpProxy string
+	rateLimit float64
 )
 
+// tokenFromFile is read from --token-file before any command runs
+var tokenFromFile string
+
 // Flags that are to be added to subset of commands.
 var (
 	fprocess     string
@@ -38,9 +55,11 @@ var (
 	image        string
 	imagePrefix  string
 	language     string
-	tlsInsecure  bool
 )
 
+// tlsInsecure is set by the global --tls-no-verify flag
+var tlsInsecure bool
+
 var stat = func(filename string) (os.FileInfo, error) {
 	return os.Stat(filename)
 }
@@ -48,20 +67,49 @@ var stat = func(filename string) (os.FileInfo, error) {
 // TODO: remove this workaround once these vars are no longer global
 func resetForTest() {
 	yamlFile = ""
+	yamlFiles = nil
+	deployGateways = nil
 	regex = ""
 	filter = ""
 	version.Version = ""
 	shortVersion = false
+	versionOutput = ""
 	appendFile = ""
+	tokenFile = ""
+	tokenFromFile = ""
+	tlsCert = ""
+	tlsKey = ""
+	tlsCA = ""
+	caBundle = ""
+	httpProxy = ""
+	rateLimit = 0
+	tlsInsecure = false
+	commandTimeout = defaultCommandTimeout
+	quietOutput = false
+	quietBuild = false
+	failFast = true
+	continueOnError = false
+	config.ConfigPath = ""
 }
 
 func init() {
 	// Setup terminal std
 	term.StdStreams()
 
-	faasCmd.PersistentFlags().StringVarP(&yamlFile, "yaml", "f", "", "Path to YAML file describing function(s)")
+	faasCmd.PersistentFlags().VarP(&stackFilesFlag{}, "yaml", "f", "Path or http(s) URL of a YAML file describing function(s), or - for STDIN, repeat to merge files where later files take precedence")
 	faasCmd.PersistentFlags().StringVarP(&regex, "regex", "", "", "Regex to match with function names in YAML file")
 	faasCmd.PersistentFlags().StringVarP(&filter, "filter", "", "", "Wildcard to match with function names in YAML file")
+	faasCmd.PersistentFlags().StringVar(&config.ConfigPath, "config", "", "Path to the config file which stores the credentials for each gateway, overrides "+config.ConfigEnvironment+" and ~/.openfaas/config.yml")
+	faasCmd.PersistentFlags().StringVar(&tlsCert, "tls-cert", "", "Path to a client certificate for mutual TLS with the gateway, or set "+tlsCertEnvironment)
+	faasCmd.PersistentFlags().StringVar(&tlsKey, "tls-key", "", "Path to the key of the --tls-cert client certificate, or set "+tlsKeyEnvironment)
+	faasCmd.PersistentFlags().StringVar(&tlsCA, "tls-ca", "", "Path to a CA certificate to verify the gateway with, or set "+tlsCAEnvironment)
+	faasCmd.PersistentFlags().StringVar(&caBundle, "ca-bundle", "", "Path to a bundle of CA certificates to trust along with the system's for every HTTPS request, or set "+caBundleEnvironment)
+	faasCmd.PersistentFlags().StringVar(&httpProxy, "proxy", "", "URL of a proxy for every HTTP(S) request, overrides HTTP_PROXY, HTTPS_PROXY and NO_PROXY")
+	faasCmd.PersistentFlags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation for every HTTPS request, prefer --ca-bundle for self-signed certificates")
+	faasCmd.PersistentFlags().DurationVar(&commandTimeout, "timeout", defaultCommandTimeout, "Timeout for each request to the gateway, such as 30s or 2m")
+	faasCmd.PersistentFlags().Float64Var(&rateLimit, "rate-limit", 0, "Maximum requests per second to the gateway, shared by parallel deploys, removes and invokes, 0 for no limit")
+	faasCmd.PersistentFlags().BoolVarP(&quietOutput, "quiet", "q", false, "Print only errors and the result of the command, without the progress")
+	faasCmd.PersistentFlags().StringVar(&tokenFile, "token-file", "", "Path to a file containing a JWT token to use instead of basic auth")
 
 	// Set Bash completion options
 	validYAMLFilenames := []string{"yaml", "yml"}
@@ -75,13 +123,54 @@ func Execute(customArgs []string) {
 	faasCmd.SilenceUsage = true
 	faasCmd.SilenceErrors = true
 	faasCmd.SetArgs(customArgs[1:])
-	if err := faasCmd.Execute(); err != nil {
-		e := err.Error()
-		fmt.Println(strings.ToUpper(e[:1]) + e[1:])
-		os.Exit(1)
+	markUsageErrors(faasCmd)
+	if cmd, err := faasCmd.ExecuteC(); err != nil {
+		os.Exit(printError(cmd, err, os.Stdout, os.Stderr))
 	}
 }
 
+// stackFilesFlag collects each --yaml given in order, yamlFile is set to the
+// first for the commands which only read a single file.
+type stackFilesFlag struct{}
+
+// Type implements pflag.Value
+func (f *stackFilesFlag) Type() string {
+	return "stringArray"
+}
+
+// String implements Stringer
+func (f *stackFilesFlag) String() string {
+	return strings.Join(yamlFiles, ",")
+}
+
+// Set implements pflag.Value
+func (f *stackFilesFlag) Set(value string) error {
+	yamlFiles = append(yamlFiles, value)
+	yamlFile = yamlFiles[0]
+	return nil
+}
+
+// stackFiles returns every --yaml given, or the default stack.yml
+func stackFiles() []string {
+	if len(yamlFiles) > 0 {
+		return yamlFiles
+	}
+	return []string{yamlFile}
+}
+
+// parseSelectedStack parses the stack files and keeps the functions matching
+// --regex or --filter, or named by args
+func parseSelectedStack(args []string) (*stack.Services, error) {
+	services, err := stack.ParseYAMLFiles(stackFiles(), "", "", envsubst)
+	if err != nil {
+		return nil, err
+	}
+	if err := stack.SelectFunctions(services, regex, filter, args); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
 func checkAndSetDefaultYaml() {
 	// Check if there is a default yaml file and set it
 	if _, err := stat(defaultYAML); err == nil {
@@ -95,8 +184,105 @@ var faasCmd = &cobra.Command{
 	Use:   "faas-cli",
 	Short: "Manage your OpenFaaS functions from the command line",
 	Long: `
-Manage your OpenFaaS functions from the command line`,
-	Run: runFaas,
+Manage your OpenFaaS functions from the command line
+
+` + exitCodesHelp,
+	Run:               runFaas,
+	PersistentPreRunE: preRunFaas,
+}
+
+// preRunFaas validates the flags given to every command
+func preRunFaas(cmd *cobra.Command, args []string) error {
+	// build and up have their own --quiet, which also stops the output of Docker
+	quietOutput = quietOutput || quietBuild
+	builder.SetQuiet(quietOutput)
+
+	if err := proxy.SetHTTPProxy(httpProxy); err != nil {
+		return err
+	}
+
+	if rateLimit < 0 {
+		return fmt.Errorf("--rate-limit must be 0 for no limit or a number of requests per second, but you gave: %v", rateLimit)
+	}
+	proxy.SetRateLimit(rateLimit)
+
+	if err := preRunFailFast(cmd); err != nil {
+		return err
+	}
+
+	if err := preRunClientTLS(); err != nil {
+		return err
+	}
+	return preRunTokenFile(cmd, args)
+}
+
+// preRunClientTLS loads the client certificate and CAs for HTTPS requests up
+// front, so that a bad file is reported before anything is done
+func preRunClientTLS() error {
+	tlsConfig, err := proxy.LoadClientTLS(
+		getFlagOrEnv(tlsCert, os.Getenv(tlsCertEnvironment)),
+		getFlagOrEnv(tlsKey, os.Getenv(tlsKeyEnvironment)),
+		getFlagOrEnv(tlsCA, os.Getenv(tlsCAEnvironment)),
+		getFlagOrEnv(caBundle, os.Getenv(caBundleEnvironment)),
+	)
+	if err != nil {
+		return err
+	}
+
+	if tlsInsecure {
+		fmt.Fprintln(os.Stderr, tlsNoVerifyWarning)
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	proxy.SetClientTLS(tlsConfig)
+	return nil
+}
+
+func getFlagOrEnv(flagValue, envValue string) string {
+	if len(flagValue) > 0 {
+		return flagValue
+	}
+	return envValue
+}
+
+// preRunTokenFile reads the token from --token-file so that it stays out of
+// the shell history and process list
+func preRunTokenFile(cmd *cobra.Command, args []string) error {
+	if len(tokenFile) == 0 {
+		return nil
+	}
+
+	if flag := cmd.Flags().Lookup("token"); flag != nil && flag.Changed {
+		return fmt.Errorf("--token and --token-file are mutually exclusive")
+	}
+
+	value, err := readTokenFile(tokenFile)
+	if err != nil {
+		return err
+	}
+
+	tokenFromFile = value
+	return nil
+}
+
+func readTokenFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("--token-file %s does not exist", path)
+		}
+		return "", fmt.Errorf("unable to read --token-file %s: %s", path, err.Error())
+	}
+
+	value := strings.TrimSpace(string(data))
+	if len(value) == 0 {
+		return "", fmt.Errorf("--token-file %s is empty", path)
+	}
+
+	return value, nil
 }
 
 // runFaas TODO
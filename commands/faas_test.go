@@ -2,8 +2,12 @@ package commands
 
 import (
 	"io/ioutil"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/openfaas/faas-cli/proxy"
 )
 
 var mockStatParams string
@@ -58,3 +62,135 @@ func TestDoesNotLoadDefaultYAMLWhenMissing(t *testing.T) {
 		t.Fatalf("Expected yamlFile to be blank got %v\n", yamlFile)
 	}
 }
+
+func Test_readTokenFile_TrimsWhitespace(t *testing.T) {
+	file, _ := ioutil.TempFile("", "faas-cli-token")
+	defer os.Remove(file.Name())
+	file.WriteString("  my-token\n")
+	file.Close()
+
+	value, err := readTokenFile(file.Name())
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	if value != "my-token" {
+		t.Errorf("want %q, got %q", "my-token", value)
+	}
+}
+
+func Test_readTokenFile_Empty(t *testing.T) {
+	file, _ := ioutil.TempFile("", "faas-cli-token")
+	defer os.Remove(file.Name())
+	file.WriteString("\n")
+	file.Close()
+
+	_, err := readTokenFile(file.Name())
+	if err == nil || err.Error() != "--token-file "+file.Name()+" is empty" {
+		t.Errorf("want empty file error, got %v", err)
+	}
+}
+
+func Test_readTokenFile_Missing(t *testing.T) {
+	_, err := readTokenFile("/no-such-dir/token")
+	if err == nil || err.Error() != "--token-file /no-such-dir/token does not exist" {
+		t.Errorf("want missing file error, got %v", err)
+	}
+}
+
+func Test_TokenFile_MutuallyExclusiveWithToken(t *testing.T) {
+	resetForTest()
+	defer func() {
+		resetForTest()
+		token = ""
+		listCmd.Flags().Lookup("token").Changed = false
+	}()
+
+	faasCmd.SetArgs([]string{"list", "--token", "abc", "--token-file", "/tmp/token"})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != "--token and --token-file are mutually exclusive" {
+		t.Errorf("want mutually exclusive error, got %v", err)
+	}
+}
+
+func Test_preRunFaas_NegativeRateLimit(t *testing.T) {
+	resetForTest()
+	defer resetForTest()
+
+	faasCmd.SetArgs([]string{"list", "--rate-limit", "-1"})
+	err := faasCmd.Execute()
+
+	want := "--rate-limit must be 0 for no limit or a number of requests per second, but you gave: -1"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+func Test_preRunClientTLS_CertWithoutKey(t *testing.T) {
+	resetForTest()
+	defer resetForTest()
+
+	faasCmd.SetArgs([]string{"list", "--tls-cert", "/tmp/client.crt"})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != "--tls-cert and --tls-key must be given together" {
+		t.Errorf("want an error for --tls-cert without --tls-key, got %v", err)
+	}
+}
+
+func Test_preRunClientTLS_FromEnvironment(t *testing.T) {
+	resetForTest()
+	os.Setenv(tlsKeyEnvironment, "/tmp/client.key")
+	defer os.Unsetenv(tlsKeyEnvironment)
+
+	err := preRunClientTLS()
+
+	if err == nil || err.Error() != "--tls-cert and --tls-key must be given together" {
+		t.Errorf("want the key from %s to be used, got %v", tlsKeyEnvironment, err)
+	}
+}
+
+func Test_preRunClientTLS_NoVerify(t *testing.T) {
+	resetForTest()
+	defer resetForTest()
+	defer proxy.SetClientTLS(nil)
+
+	tlsInsecure = true
+
+	stdErr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	err := preRunClientTLS()
+	w.Close()
+	os.Stderr = stdErr
+	out, _ := ioutil.ReadAll(r)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), tlsNoVerifyWarning) {
+		t.Errorf("want the warning on stderr, got %q", string(out))
+	}
+
+	if tlsConfig := proxy.TLSClientConfig(false); tlsConfig == nil || !tlsConfig.InsecureSkipVerify {
+		t.Errorf("want verification to be skipped for the gateway, got %v", tlsConfig)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport)
+	if transport.TLSClientConfig == nil || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("want verification to be skipped for the default transport")
+	}
+}
+
+func Test_preRunClientTLS_CABundleFromEnvironment(t *testing.T) {
+	resetForTest()
+	os.Setenv(caBundleEnvironment, "/tmp/missing-ca-bundle.pem")
+	defer os.Unsetenv(caBundleEnvironment)
+
+	err := preRunClientTLS()
+
+	if err == nil || !strings.HasPrefix(err.Error(), "unable to read the TLS CA /tmp/missing-ca-bundle.pem") {
+		t.Errorf("want the bundle from %s to be used, got %v", caBundleEnvironment, err)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	failFast        bool
+	continueOnError bool
+)
+
+const failFastNote = `By default --fail-fast stops at the first function which fails, those already
+being worked on by --parallel are finished, but no more are started. With
+--continue-on-error every function is tried and the errors are given together
+once they have all finished, the command still fails when any of them did.`
+
+// addFailFastFlags adds --fail-fast and --continue-on-error, which are shared
+// by the commands that work through the functions of a stack file
+func addFailFastFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&failFast, "fail-fast", true, "Stop at the first function which fails")
+	cmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "Try every function and report the errors together, in place of --fail-fast")
+}
+
+// preRunFailFast gives --continue-on-error precedence over the default of
+// --fail-fast, as long as both were not asked for
+func preRunFailFast(cmd *cobra.Command) error {
+	flag := cmd.Flags().Lookup("fail-fast")
+	if flag == nil {
+		return nil
+	}
+
+	if continueOnError && flag.Changed && failFast {
+		return fmt.Errorf("--fail-fast and --continue-on-error are mutually exclusive")
+	}
+	if !failFast {
+		continueOnError = true
+	}
+	failFast = !continueOnError
+	return nil
+}
+
+// failureGate stops any more functions from being started once one has
+// failed, unless --continue-on-error is given
+type failureGate struct {
+	mu     sync.Mutex
+	failed bool
+}
+
+// fail records that a function failed
+func (g *failureGate) fail() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.failed = true
+}
+
+// skip is true when the function should not be started, as an earlier one
+// failed with --fail-fast
+func (g *failureGate) skip(name string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.failed || continueOnError {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "Skipping %s, as an earlier function failed, pass --continue-on-error to try every function.\n", name)
+	return true
+}
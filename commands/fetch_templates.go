@@ -9,6 +9,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/openfaas/faas-cli/builder"
 	"github.com/openfaas/faas-cli/versioncontrol"
@@ -36,8 +37,31 @@ func fetchTemplates(templateURL string, refName string, overwrite bool) error {
 	log.Printf("Attempting to expand templates from %s\n", templateURL)
 	pullDebugPrint(fmt.Sprintf("Temp files in %s", dir))
 	args := map[string]string{"dir": dir, "repo": templateURL, "refname": refName}
-	if err := versioncontrol.GitClone.Invoke(".", args); err != nil {
-		return err
+	env := templateGitEnv(templateURL)
+	if versioncontrol.IsCommitSHA(refName) {
+		err := fetchWithRetry(templateURL, dir, templateRetries, templateRetryDelay, func() error {
+			return versioncontrol.GitFetchCommit.InvokeWithEnv(".", args, env)
+		})
+		if err != nil {
+			return fmt.Errorf("%s, check the commit %s exists", err, refName)
+		}
+	} else {
+		// Errors other than a missing ref, such as the network, are left to the clone
+		if exists, err := versioncontrol.GitRefExists(templateURL, refName, env); err == nil && !exists {
+			return fmt.Errorf("the branch or tag %s was not found in %s", refName, templateURL)
+		}
+
+		err := fetchWithRetry(templateURL, dir, templateRetries, templateRetryDelay, func() error {
+			return versioncontrol.GitClone.InvokeWithEnv(".", args, env)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	commit, err := versioncontrol.GetGitCommit(dir)
+	if err != nil {
+		return fmt.Errorf("unable to resolve the commit for %s: %s", refName, err)
 	}
 
 	preExistingLanguages, fetchedLanguages, err := moveTemplates(dir, overwrite)
@@ -49,11 +73,39 @@ func fetchTemplates(templateURL string, refName string, overwrite bool) error {
 		log.Printf("Cannot overwrite the following %d template(s): %v\n", len(preExistingLanguages), preExistingLanguages)
 	}
 
-	log.Printf("Fetched %d template(s) : %v from %s\n", len(fetchedLanguages), fetchedLanguages, templateURL)
+	log.Printf("Fetched %d template(s) : %v from %s at %s (%s)\n", len(fetchedLanguages), fetchedLanguages, templateURL, refName, shortCommit(commit))
+
+	source := templateSource{Repository: templateURL, Ref: refName, Commit: commit}
+	if err := recordTemplateSources(fetchedLanguages, source); err != nil {
+		log.Printf("Unable to record the source of the templates: %s\n", err)
+	}
 
 	return err
 }
 
+// templateGitEnv gives the environment to authenticate git with the token
+// from --auth-token or GIT_TOKEN. The token is only sent to HTTPS remotes,
+// SSH remotes use the local SSH agent and no token means an anonymous clone.
+func templateGitEnv(templateURL string) []string {
+	token := templateAuthToken
+	if len(token) == 0 {
+		token = os.Getenv(gitTokenEnvironment)
+	}
+
+	if len(token) == 0 {
+		return nil
+	}
+
+	if !strings.HasPrefix(strings.ToLower(templateURL), "https://") {
+		if strings.HasPrefix(strings.ToLower(templateURL), "http://") {
+			log.Printf("Not sending the auth token to %s as it does not use HTTPS\n", templateURL)
+		}
+		return nil
+	}
+
+	return versioncontrol.GitTokenEnv(token)
+}
+
 // canWriteLanguage tells whether the language can be expanded from the zip or not.
 // availableLanguages map keeps track of which languages we know to be okay to copy.
 // overwrite flag will allow to force copy the language template
@@ -117,14 +169,22 @@ func moveTemplates(repoPath string, overwrite bool) ([]string, []string, error)
 	return existingLanguages, fetchedLanguages, nil
 }
 
-func pullTemplate(repository string) error {
+// pullTemplate fetches the templates from the repository at the ref, or at
+// the ref pinned in the URL when the ref is empty
+func pullTemplate(repository string, refName string) error {
 	if _, err := os.Stat(repository); err != nil {
 		if !versioncontrol.IsGitRemote(repository) && !versioncontrol.IsPinnedGitRemote(repository) {
 			return fmt.Errorf("The repository URL must be a valid git repo uri")
 		}
 	}
 
-	repository, refName := versioncontrol.ParsePinnedRemote(repository)
+	pinned := versioncontrol.IsPinnedGitRemote(repository)
+	repository, pinnedRef := versioncontrol.ParsePinnedRemote(repository)
+	if len(refName) == 0 {
+		refName = pinnedRef
+	} else if pinned && pinnedRef != refName {
+		return fmt.Errorf("the ref %s in the URL does not match --ref %s", pinnedRef, refName)
+	}
 
 	if err := versioncontrol.GitCheckRefName.Invoke("", map[string]string{"refname": refName}); err != nil {
 		fmt.Printf("Invalid tag or branch name `%s`\n", refName)
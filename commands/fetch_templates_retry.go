@@ -0,0 +1,54 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/openfaas/faas-cli/versioncontrol"
+)
+
+// templateRetryDelay is the delay before the first retry of a template fetch,
+// it is replaced in tests to avoid waiting between attempts
+var templateRetryDelay = 2 * time.Second
+
+// fetchWithRetry calls fetch until it succeeds, fails for a reason other than
+// a temporary network error or the retries are used up, waiting from delay
+// between attempts. The directory is emptied before each retry so that git
+// can clone into it again.
+func fetchWithRetry(templateURL string, dir string, retries int, delay time.Duration, fetch func() error) error {
+	attempt := 0
+	for {
+		attempt++
+		err := fetch()
+		if err == nil {
+			return nil
+		}
+
+		if versioncontrol.IsNotFoundError(err) {
+			return fmt.Errorf("unable to fetch %s, check the repo exists and can be read: %s", templateURL, versioncontrol.ErrorCause(err))
+		}
+		if attempt > retries || !versioncontrol.IsTemporaryError(err) {
+			return fmt.Errorf("unable to fetch %s after %d attempt(s): %s", templateURL, attempt, versioncontrol.ErrorCause(err))
+		}
+
+		wait := retryBackoff(delay, attempt)
+		fmt.Fprintf(os.Stderr, "Attempt %d to fetch %s failed: %s, retrying in %s\n", attempt, templateURL, versioncontrol.ErrorCause(err), wait.Round(time.Millisecond))
+		retrySleep(wait)
+
+		if err := resetDir(dir); err != nil {
+			return err
+		}
+	}
+}
+
+// resetDir removes everything from dir and leaves it empty
+func resetDir(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.MkdirAll(dir, 0700)
+}
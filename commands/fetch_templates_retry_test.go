@@ -0,0 +1,96 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/versioncontrol"
+)
+
+func Test_fetchWithRetry(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "openFaasTemplates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	networkErr := &versioncontrol.CommandError{
+		Output: "fatal: unable to access 'https://github.com/openfaas/templates/': Failed to connect to github.com port 443: Connection timed out\n",
+		Err:    errors.New("exit status 128"),
+	}
+	unresolvedErr := &versioncontrol.CommandError{
+		Output: "fatal: unable to access 'https://github.com/openfaas/templates/': Could not resolve host: github.com\n",
+		Err:    errors.New("exit status 128"),
+	}
+	notFoundErr := &versioncontrol.CommandError{
+		Output: "remote: Repository not found.\nfatal: repository 'https://github.com/openfaas/missing/' not found\n",
+		Err:    errors.New("exit status 128"),
+	}
+
+	t.Run("temporary error is retried", func(t *testing.T) {
+		attempts := 0
+		err := fetchWithRetry("https://github.com/openfaas/templates", dir, 3, 0, func() error {
+			attempts++
+			if attempts < 3 {
+				return networkErr
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("want no error, got %s", err)
+		}
+		if attempts != 3 {
+			t.Errorf("want 3 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("retries are used up", func(t *testing.T) {
+		attempts := 0
+		err := fetchWithRetry("https://github.com/openfaas/templates", dir, 2, 0, func() error {
+			attempts++
+			return networkErr
+		})
+		if attempts != 3 {
+			t.Errorf("want 3 attempts, got %d", attempts)
+		}
+		want := "unable to fetch https://github.com/openfaas/templates after 3 attempt(s): fatal: unable to access 'https://github.com/openfaas/templates/': Failed to connect to github.com port 443: Connection timed out"
+		if err == nil || err.Error() != want {
+			t.Errorf("want error %q, got %v", want, err)
+		}
+	})
+
+	t.Run("unresolved host is not retried", func(t *testing.T) {
+		attempts := 0
+		err := fetchWithRetry("https://github.com/openfaas/templates", dir, 3, 0, func() error {
+			attempts++
+			return unresolvedErr
+		})
+		if attempts != 1 {
+			t.Errorf("want 1 attempt, got %d", attempts)
+		}
+		if err == nil || !strings.Contains(err.Error(), "Could not resolve host") {
+			t.Errorf("want the DNS error, got %v", err)
+		}
+	})
+
+	t.Run("not found is not retried", func(t *testing.T) {
+		attempts := 0
+		err := fetchWithRetry("https://github.com/openfaas/missing", dir, 3, 0, func() error {
+			attempts++
+			return notFoundErr
+		})
+		if attempts != 1 {
+			t.Errorf("want 1 attempt, got %d", attempts)
+		}
+		if err == nil || !strings.Contains(err.Error(), "check the repo exists") {
+			t.Errorf("want a not found error, got %v", err)
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/openfaas/faas-cli/builder"
@@ -70,3 +71,30 @@ func tearDownFetchTemplates(t *testing.T) {
 		t.Logf("Directory template was not created: %s", err)
 	}
 }
+
+func Test_templateGitEnv(t *testing.T) {
+	defer func() { templateAuthToken = "" }()
+	os.Unsetenv(gitTokenEnvironment)
+
+	if env := templateGitEnv("https://github.com/example/templates.git"); env != nil {
+		t.Errorf("want no credentials without a token, got %v", env)
+	}
+
+	os.Setenv(gitTokenEnvironment, "from-env")
+	defer os.Unsetenv(gitTokenEnvironment)
+
+	if env := templateGitEnv("https://github.com/example/templates.git"); !reflect.DeepEqual(env, versioncontrol.GitTokenEnv("from-env")) {
+		t.Errorf("want the token from %s, got %v", gitTokenEnvironment, env)
+	}
+
+	templateAuthToken = "from-flag"
+	if env := templateGitEnv("https://github.com/example/templates.git"); !reflect.DeepEqual(env, versioncontrol.GitTokenEnv("from-flag")) {
+		t.Errorf("want the token from --auth-token, got %v", env)
+	}
+
+	for _, url := range []string{"http://example.com/templates.git", "git@github.com:example/templates.git"} {
+		if env := templateGitEnv(url); env != nil {
+			t.Errorf("want no token sent to %s, got %v", url, env)
+		}
+	}
+}
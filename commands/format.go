@@ -0,0 +1,49 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// formatEscapes lets a tab or newline be typed as \t or \n in --format, as
+// with docker
+var formatEscapes = strings.NewReplacer(`\t`, "\t", `\n`, "\n")
+
+// formatFuncs can be used in a --format template, i.e. {{json .Labels}}
+var formatFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		out, err := json.Marshal(v)
+		return string(out), err
+	},
+}
+
+// parseFormat parses the Go template given by --format
+func parseFormat(format string) (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(formatFuncs).Parse(formatEscapes.Replace(format))
+	if err != nil {
+		return nil, fmt.Errorf("--format is not a valid template: %s", err)
+	}
+	return tmpl, nil
+}
+
+// printFormatted executes the template for each item, followed by a newline.
+// Nothing is printed when the template can't be executed for one of them.
+func printFormatted(w io.Writer, tmpl *template.Template, items []interface{}) error {
+	var out bytes.Buffer
+	for _, item := range items {
+		if err := tmpl.Execute(&out, item); err != nil {
+			return fmt.Errorf("unable to execute the --format template: %s", err)
+		}
+		out.WriteString("\n")
+	}
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
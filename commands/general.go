@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"crypto/tls"
 	"net"
 	"net/http"
 	"time"
@@ -10,8 +9,12 @@ import (
 	"github.com/openfaas/faas-cli/proxy"
 )
 
+// defaultCommandTimeout is the default of --timeout for each request to the
+// gateway
+const defaultCommandTimeout = 60 * time.Second
+
 var (
-	commandTimeout = 60 * time.Second
+	commandTimeout = defaultCommandTimeout
 )
 
 //CLIAuth auth struct for the CLI
@@ -62,9 +65,19 @@ func NewCLIAuth(token string, gateway string) proxy.ClientAuth {
 
 	}
 
+	if len(token) == 0 {
+		token = tokenFromFile
+	}
+
 	// User specified token gets priority
 	if len(token) > 0 {
 		bearerToken = token
+	} else if authConfig.Auth == config.Oauth2AuthType {
+		return &OAuth2Token{
+			BearerToken: BearerToken{token: refreshIfExpiring(gateway, authConfig)},
+			gateway:     gateway,
+			authConfig:  authConfig,
+		}
 	} else {
 		bearerToken = authConfig.Token
 	}
@@ -75,9 +88,11 @@ func NewCLIAuth(token string, gateway string) proxy.ClientAuth {
 }
 
 func GetDefaultCLITransport(tlsInsecure bool, timeout *time.Duration) *http.Transport {
-	if timeout != nil || tlsInsecure {
+	tlsConfig := proxy.TLSClientConfig(tlsInsecure)
+
+	if timeout != nil || tlsConfig != nil {
 		tr := &http.Transport{
-			Proxy:             http.ProxyFromEnvironment,
+			Proxy:             proxy.HTTPProxy,
 			DisableKeepAlives: false,
 		}
 
@@ -90,8 +105,8 @@ func GetDefaultCLITransport(tlsInsecure bool, timeout *time.Duration) *http.Tran
 			tr.ExpectContinueTimeout = 1500 * time.Millisecond
 		}
 
-		if tlsInsecure {
-			tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: tlsInsecure}
+		if tlsConfig != nil {
+			tr.TLSClientConfig = tlsConfig
 		}
 		tr.DisableKeepAlives = false
 
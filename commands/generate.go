@@ -5,12 +5,15 @@ package commands
 
 import (
 	"fmt"
+	"os"
+	"sort"
+
 	v2 "github.com/openfaas/faas-cli/schema/store/v2"
 
-	"github.com/openfaas/faas-cli/builder"
 	"github.com/openfaas/faas-cli/proxy"
 	"github.com/openfaas/faas-cli/schema"
 	knativev1alpha1 "github.com/openfaas/faas-cli/schema/knative/v1alpha1"
+	openfaasv1 "github.com/openfaas/faas-cli/schema/openfaas/v1"
 	openfaasv1alpha2 "github.com/openfaas/faas-cli/schema/openfaas/v1alpha2"
 	"github.com/openfaas/faas-cli/stack"
 	"github.com/pkg/errors"
@@ -21,7 +24,8 @@ import (
 const (
 	defaultFunctionNamespace = "openfaas-fn"
 	resourceKind             = "Function"
-	defaultAPIVersion        = "openfaas.com/v1alpha2"
+	defaultAPIVersion        = openfaasv1.APIVersionLatest
+	generateFormatCRD        = "crd"
 )
 
 var (
@@ -29,13 +33,15 @@ var (
 	functionNamespace string
 	fromStore         string
 	desiredArch       string
+	generateFormat    string
 )
 
 func init() {
 
 	generateCmd.Flags().StringVar(&fromStore, "from-store", "", "generate using a store image")
 
-	generateCmd.Flags().StringVar(&api, "api", defaultAPIVersion, "CRD API version e.g openfaas.com/v1alpha2, serving.knative.dev/v1alpha1")
+	generateCmd.Flags().StringVar(&api, "api", defaultAPIVersion, "CRD API version e.g openfaas.com/v1, openfaas.com/v1alpha2, serving.knative.dev/v1alpha1")
+	generateCmd.Flags().StringVar(&generateFormat, "format", generateFormatCRD, "Output format, crd for the YAML of the custom resources")
 	generateCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", defaultFunctionNamespace, "Kubernetes namespace for functions")
 	generateCmd.Flags().Var(&tagFormat, "tag", "Override latest tag on function Docker image, accepts 'latest', 'sha', 'branch', 'describe'")
 	generateCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yml file")
@@ -44,10 +50,18 @@ func init() {
 }
 
 var generateCmd = &cobra.Command{
-	Use:   "generate --api=openfaas.com/v1alpha2 --yaml stack.yml --tag sha --namespace=openfaas-fn",
+	Use:   "generate --api=openfaas.com/v1 --yaml stack.yml --tag sha --namespace=openfaas-fn",
 	Short: "Generate Kubernetes CRD YAML file",
-	Long:  `The generate command creates kubernetes CRD YAML file for functions`,
-	Example: `faas-cli generate --api=openfaas.com/v1alpha2 --yaml stack.yml | kubectl apply  -f -
+	Long: `The generate command creates kubernetes CRD YAML file for functions, as a
+document for each function, which can be given to "kubectl apply" or committed
+for GitOps in place of deploying through the gateway.
+
+The environment, environment files, labels, annotations, secrets, limits,
+requests and constraints of each function in the stack file are given in its
+Function. A function's namespace in the stack file takes precedence over
+--namespace.`,
+	Example: `faas-cli generate -f stack.yml --format crd | kubectl apply -f -
+faas-cli generate --api=openfaas.com/v1 --yaml stack.yml | kubectl apply  -f -
 faas-cli generate --api=openfaas.com/v1alpha2 -f stack.yml
 faas-cli generate --api=serving.knative.dev/v1alpha1 -f stack.yml
 faas-cli generate --api=openfaas.com/v1alpha2 --namespace openfaas-fn -f stack.yml
@@ -60,6 +74,9 @@ func preRunGenerate(cmd *cobra.Command, args []string) error {
 	if len(api) == 0 {
 		return fmt.Errorf("You must supply api version with the --api flag")
 	}
+	if generateFormat != generateFormatCRD {
+		return fmt.Errorf("--format must be %s, not: %s", generateFormatCRD, generateFormat)
+	}
 	return nil
 }
 
@@ -95,9 +112,10 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 
 		services.Functions = make(map[string]stack.Function)
 
-		items, err := proxy.FunctionStoreList(storeAddress)
+		storeURL := getStoreURL(storeAddress, os.Getenv(storeURLEnvironment), defaultStore)
+		items, err := proxy.FunctionStoreList(storeURL)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Unable to retrieve functions from URL %s", storeAddress))
+			return errors.Wrap(err, fmt.Sprintf("Unable to retrieve functions from URL %s", storeURL))
 		}
 
 		item, err := filterStoreItem(items, fromStore)
@@ -124,7 +142,7 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		}
 
 	} else if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		parsedServices, err := stack.ParseYAMLFiles(stackFiles(), regex, filter, envsubst)
 		if err != nil {
 			return err
 		}
@@ -134,12 +152,9 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	branch, version, err := builder.GetImageTagValues(tagFormat)
-	if err != nil {
-		return err
-	}
+	tag := resolveImageTag(tagFormat)
 
-	objectsString, err := generateCRDYAML(services, tagFormat, api, functionNamespace, branch, version)
+	objectsString, err := generateCRDYAML(services, tag.format, api, functionNamespace, tag.branch, tag.version)
 	if err != nil {
 		return err
 	}
@@ -160,8 +175,12 @@ func generateCRDYAML(services stack.Services, format schema.BuildFormat, apiVers
 		if apiVersion == knativev1alpha1.APIVersionLatest {
 			return generateknativev1alpha1ServingCRDYAML(services, format, api, functionNamespace, branch, version)
 		}
+		if apiVersion == openfaasv1.APIVersionLatest {
+			return generateOpenFaaSv1CRDYAML(services, format, apiVersion, namespace, branch, version)
+		}
 
-		for name, function := range services.Functions {
+		for _, name := range sortedFunctionNames(services.Functions) {
+			function := services.Functions[name]
 			//read environment variables from the file
 			fileEnvironment, err := readFiles(function.EnvironmentFile)
 			if err != nil {
@@ -207,6 +226,67 @@ func generateCRDYAML(services stack.Services, format schema.BuildFormat, apiVers
 	return objectsString, nil
 }
 
+func generateOpenFaaSv1CRDYAML(services stack.Services, format schema.BuildFormat, apiVersion, namespace, branch, version string) (string, error) {
+	var objectsString string
+
+	for _, name := range sortedFunctionNames(services.Functions) {
+		function := services.Functions[name]
+
+		fileEnvironment, err := readFiles(function.EnvironmentFile)
+		if err != nil {
+			return "", err
+		}
+
+		allEnvironment, envErr := compileEnvironment([]string{}, function.Environment, fileEnvironment)
+		if envErr != nil {
+			return "", envErr
+		}
+
+		metadata := schema.Metadata{Name: name, Namespace: namespace}
+		if len(function.Namespace) > 0 {
+			metadata.Namespace = function.Namespace
+		}
+
+		crd := openfaasv1.CRD{
+			APIVersion: apiVersion,
+			Kind:       resourceKind,
+			Metadata:   metadata,
+			Spec: openfaasv1.Spec{
+				Name:                   name,
+				Image:                  schema.BuildImageName(format, function.Image, version, branch),
+				Handler:                function.FProcess,
+				Annotations:            functionAnnotations(function),
+				Labels:                 function.Labels,
+				Environment:            allEnvironment,
+				Constraints:            function.Constraints,
+				Secrets:                function.Secrets,
+				Limits:                 function.Limits,
+				Requests:               function.Requests,
+				ReadOnlyRootFilesystem: function.ReadOnlyRootFilesystem,
+			},
+		}
+
+		objectString, err := yaml.Marshal(crd)
+		if err != nil {
+			return "", err
+		}
+		objectsString += "---\n" + string(objectString)
+	}
+
+	return objectsString, nil
+}
+
+// sortedFunctionNames gives the functions in the order of their names, so
+// that the YAML generated for a stack file is the same each time
+func sortedFunctionNames(functions map[string]stack.Function) []string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func generateknativev1alpha1ServingCRDYAML(services stack.Services, format schema.BuildFormat, apiVersion, namespace, branch, version string) (string, error) {
 	crds := []knativev1alpha1.ServingCRD{}
 
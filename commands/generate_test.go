@@ -170,6 +170,68 @@ spec:
 		Branch:     "",
 		Version:    "",
 	},
+	{
+		Name: "openfaas.com/v1 with the function's configuration",
+		Input: `
+provider:
+  name: openfaas
+functions:
+ url-ping:
+  image: alexellis/faas-url-ping:0.2
+  fprocess: python index.py
+  namespace: staging
+  environment:
+   write_debug: true
+  labels:
+   com.openfaas.scale.min: "2"
+  annotations:
+   topic: payments
+  secrets:
+   - api-key
+  constraints:
+   - node.platform.os == linux
+  limits:
+   memory: 40Mi
+  readonly_root_filesystem: true
+ astronaut-finder:
+  image: astronaut-finder`,
+		Output: []string{`---
+apiVersion: openfaas.com/v1
+kind: Function
+metadata:
+  name: astronaut-finder
+  namespace: openfaas-fn
+spec:
+  name: astronaut-finder
+  image: astronaut-finder:latest
+---
+apiVersion: openfaas.com/v1
+kind: Function
+metadata:
+  name: url-ping
+  namespace: staging
+spec:
+  name: url-ping
+  image: alexellis/faas-url-ping:0.2
+  handler: python index.py
+  annotations:
+    topic: payments
+  labels:
+    com.openfaas.scale.min: "2"
+  environment:
+    write_debug: "true"
+  constraints:
+  - node.platform.os == linux
+  secrets:
+  - api-key
+  limits:
+    memory: 40Mi
+  readOnlyRootFilesystem: true
+`},
+		Format:     schema.DefaultFormat,
+		APIVersion: "openfaas.com/v1",
+		Namespace:  "openfaas-fn",
+	},
 }
 
 func Test_generateCRDYAML(t *testing.T) {
@@ -198,6 +260,18 @@ func Test_generateCRDYAML(t *testing.T) {
 
 }
 
+func Test_generate_InvalidFormat(t *testing.T) {
+	resetForTest()
+	defer func() { generateFormat = generateFormatCRD }()
+
+	faasCmd.SetArgs([]string{"generate", "--format", "helm"})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != "--format must be crd, not: helm" {
+		t.Errorf("want an error for the format, got %v", err)
+	}
+}
+
 func stringInSlice(a string, list []string) bool {
 	for _, b := range list {
 		if b == a {
@@ -0,0 +1,78 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/openfaas/faas-cli/builder"
+	"github.com/openfaas/faas-cli/schema"
+	"github.com/openfaas/faas-cli/stack"
+)
+
+// imageTag is the format and the git values used to tag images
+type imageTag struct {
+	format  schema.BuildFormat
+	branch  string
+	version string
+}
+
+var (
+	resolvedImageTags = map[string]imageTag{}
+	imageTagLock      sync.Mutex
+)
+
+// resolveImageTag gives the format and git values for the --tag mode, read
+// from the git repo holding the stack file. They are resolved once, so that
+// build, push and deploy in "faas-cli up" tag the image the same way even
+// when a commit is made part way through. Outside of a git repo a warning is
+// printed and the image from the stack file is used as it is.
+func resolveImageTag(tagMode schema.BuildFormat) imageTag {
+	return resolveImageTagIn(os.Stderr, tagMode, stackFileDir())
+}
+
+func resolveImageTagIn(w io.Writer, tagMode schema.BuildFormat, dir string) imageTag {
+	if tagMode == schema.DefaultFormat {
+		return imageTag{format: tagMode}
+	}
+
+	imageTagLock.Lock()
+	defer imageTagLock.Unlock()
+
+	key := fmt.Sprintf("%d:%s", tagMode, dir)
+	if tag, ok := resolvedImageTags[key]; ok {
+		return tag
+	}
+
+	tag := imageTag{format: tagMode}
+
+	var err error
+	tag.branch, tag.version, err = builder.GetImageTagValues(tagMode, dir)
+	if err != nil {
+		fmt.Fprintf(w, "Warning: %s, the image tag will not be changed by --tag %s.\n", err, tagMode.String())
+		tag = imageTag{format: schema.DefaultFormat}
+	}
+
+	resolvedImageTags[key] = tag
+	return tag
+}
+
+// imageName gives the image tagged with the resolved values
+func (t imageTag) imageName(image string) string {
+	return schema.BuildImageName(t.format, image, t.version, t.branch)
+}
+
+// stackFileDir gives the folder of the first stack file, or the working
+// directory when it is given as a URL or read from STDIN
+func stackFileDir() string {
+	files := stackFiles()
+	if len(files) == 0 || files[0] == stack.StdinFile || stack.IsRemoteFile(files[0]) {
+		return "."
+	}
+	return filepath.Dir(files[0])
+}
@@ -0,0 +1,89 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/schema"
+)
+
+func Test_resolveImageTag_OutsideGitRepo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "faas-cli-image-tag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	tag := resolveImageTagIn(&out, schema.SHAFormat, dir)
+
+	if got := tag.imageName("alexellis/figlet"); got != "alexellis/figlet:latest" {
+		t.Errorf("want the image to be kept outside of a git repo, got %s", got)
+	}
+
+	want := "Warning: cannot tag image with Git SHA as this is not a Git repository, the image tag will not be changed by --tag sha.\n"
+	if out.String() != want {
+		t.Errorf("want warning %q, got %q", want, out.String())
+	}
+}
+
+func Test_resolveImageTag_StackFileRepo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "faas-cli-image-tag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "stack.yml"), []byte("functions: {}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"add", "stack.yml"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "stack"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %s %s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = dir
+	sha, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	tag := resolveImageTagIn(&out, schema.SHAFormat, dir)
+
+	want := "alexellis/figlet:latest-" + strings.TrimSpace(string(sha))
+	if got := tag.imageName("alexellis/figlet"); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+	if out.Len() > 0 {
+		t.Errorf("want no warning, got %q", out.String())
+	}
+
+	// A later commit does not change the tag already resolved
+	ioutil.WriteFile(filepath.Join(dir, "stack.yml"), []byte("functions: {}\nversion: 1.0\n"), 0600)
+	cmd = exec.Command("git", "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-am", "version")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %s %s", err, out)
+	}
+
+	if got := resolveImageTagIn(&out, schema.SHAFormat, dir).imageName("alexellis/figlet"); got != want {
+		t.Errorf("want the tag resolved earlier %s, got %s", want, got)
+	}
+}
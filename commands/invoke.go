@@ -4,12 +4,19 @@
 package commands
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"hash"
+	"io"
 	"io/ioutil"
 	"os"
+	"strings"
+	"time"
 
-	"github.com/alexellis/hmac"
 	"github.com/openfaas/faas-cli/proxy"
 	"github.com/openfaas/faas-cli/stack"
 	"github.com/spf13/cobra"
@@ -20,9 +27,23 @@ var (
 	query                   []string
 	headers                 []string
 	invokeAsync             bool
+	invokeCallbackURL       string
+	invokeRetries           int
+	invokeRetryDelay        time.Duration
+	invokeRetryMethods      []string
+	invokeStream            bool
+	invokeCompress          bool
+	invokeTiming            bool
+	invokeTimeout           time.Duration
+	invokeBatch             string
+	invokeByLine            bool
+	invokeParallel          int
+	invokeOutputDir         string
 	httpMethod              string
 	sigHeader               string
 	key                     string
+	signSecretFile          string
+	signHash                string
 	functionInvokeNamespace string
 )
 
@@ -34,13 +55,29 @@ func init() {
 	invokeCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
 
 	invokeCmd.Flags().StringVar(&contentType, "content-type", "text/plain", "The content-type HTTP header such as application/json")
-	invokeCmd.Flags().StringArrayVar(&query, "query", []string{}, "pass query-string options")
+	invokeCmd.Flags().StringArrayVar(&query, "query", []string{}, "pass query-string options (KEY=VALUE), values are URL-encoded")
 	invokeCmd.Flags().StringArrayVarP(&headers, "header", "H", []string{}, "pass HTTP request header")
 	invokeCmd.Flags().BoolVarP(&invokeAsync, "async", "a", false, "Invoke the function asynchronously")
+	invokeCmd.Flags().StringVar(&invokeCallbackURL, "callback-url", "", "URL to send the result of an async invocation to (requires --async)")
 	invokeCmd.Flags().StringVarP(&httpMethod, "method", "m", "POST", "pass HTTP request method")
-	invokeCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
+	invokeCmd.Flags().BoolVar(&invokeCompress, "compress", false, "Compress the body of the request with gzip and send it with Content-Encoding: gzip")
+	invokeCmd.Flags().BoolVar(&invokeTiming, "timing", false, "Print the DNS, connect, TLS, time to first byte and total timings, the status and the size of the response to STDERR")
+	invokeCmd.Flags().DurationVar(&invokeTimeout, "invoke-timeout", 0, "Timeout for the function to respond, in place of the global --timeout, 0 lets the function run for as long as it needs")
+	invokeCmd.Flags().BoolVar(&invokeStream, "stream", false, "Write the response to STDOUT as it arrives, this is the default for chunked and text/event-stream responses")
+	invokeCmd.Flags().StringVar(&invokeBatch, "batch", "", "Send each file in a directory, or a file, as a separate request instead of reading STDIN")
+	invokeCmd.Flags().BoolVar(&invokeByLine, "by-line", false, "Send each line of the --batch file as a separate request")
+	invokeCmd.Flags().IntVar(&invokeParallel, "parallel", 1, "Number of --batch requests to send at once")
+	invokeCmd.Flags().StringVar(&invokeOutputDir, "output-dir", "", "Write the response to each --batch request to a file named after the payload")
+	invokeCmd.Flags().IntVar(&invokeRetries, "retry", 0, "Retry up to this many times on a 429, 503 or refused connection")
+	invokeCmd.Flags().DurationVar(&invokeRetryDelay, "retry-delay", time.Second, "Delay before the first retry, doubled with jitter for each retry after")
+	invokeCmd.Flags().StringArrayVar(&invokeRetryMethods, "retry-method", defaultRetryMethods, "HTTP method which is safe to retry, repeat for more than one")
+	invokeCmd.Flags().StringArrayVar(&invokeExpectStatus, "expect-status", []string{}, "Fail unless the response has this status code, or one in a range such as 2xx, repeat for more than one")
+	invokeCmd.Flags().StringVar(&invokeExpectBody, "expect-body", "", "Fail unless the body of the response contains this text")
 	invokeCmd.Flags().StringVar(&sigHeader, "sign", "", "name of HTTP request header to hold the signature")
-	invokeCmd.Flags().StringVar(&key, "key", "", "key to be used to sign the request (must be used with --sign)")
+	invokeCmd.Flags().StringVar(&key, "sign-secret", "", "secret used to sign the request with an HMAC of the body (must be used with --sign)")
+	invokeCmd.Flags().StringVar(&key, "key", "", "key to be used to sign the request (must be used with --sign), the same as --sign-secret")
+	invokeCmd.Flags().StringVar(&signSecretFile, "sign-secret-file", "", "file holding the secret used to sign the request (must be used with --sign)")
+	invokeCmd.Flags().StringVar(&signHash, "sign-hash", "sha256", "hash for the HMAC signature, sha256 or sha1")
 
 	invokeCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yml file")
 
@@ -50,15 +87,44 @@ func init() {
 var invokeCmd = &cobra.Command{
 	Use:   `invoke FUNCTION_NAME [--gateway GATEWAY_URL] [--content-type CONTENT_TYPE] [--query PARAM=VALUE] [--header PARAM=VALUE] [--method HTTP_METHOD]`,
 	Short: "Invoke an OpenFaaS function",
-	Long:  `Invokes an OpenFaaS function and reads from STDIN for the body of the request`,
+	Long: `Invokes an OpenFaaS function and reads from STDIN for the body of the request.
+With "--async" the call-id given by the gateway is printed instead of the result.
+With "--compress" the body is sent compressed with gzip, which the function must
+decompress. A response with Content-Encoding: gzip is always decompressed.
+With "--timing" the time taken by each step of the request, the status and the
+size of the response are printed to STDERR, so a slow cold start can be told
+apart from a slow network.
+
+There is no limit on how long the function takes to respond by default, so
+that long-running functions and streamed responses are not cut off. Set
+"--invoke-timeout", or the global "--timeout", to give up on the function once
+it has passed, "--invoke-timeout" is used when both are given.
+
+With "--expect-status" invoke fails unless the status of the response is one
+of those given, which may be a range such as 2xx, and the body of a response
+with an expected error status is printed as for a success. With
+"--expect-body" invoke fails unless the body contains the text, so that
+invoke can be used as a smoke test.`,
 	Example: `  faas-cli invoke echo --gateway https://domain:port
   faas-cli invoke echo --gateway https://domain:port --content-type application/json
   faas-cli invoke env --query repo=faas-cli --query org=openfaas
   faas-cli invoke env --header X-Ping-Url=http://request.bin/etc
   faas-cli invoke resize-img --async -H "X-Callback-Url=http://gateway:8080/function/send2slack" < image.png
+  faas-cli invoke resize-img --async --callback-url http://gateway:8080/function/send2slack < image.png
   faas-cli invoke env -H X-Ping-Url=http://request.bin/etc
   faas-cli invoke flask --method GET --namespace dev
-  faas-cli invoke env --sign X-GitHub-Event --key yoursecret`,
+  faas-cli invoke flask --method GET --retry 5 --retry-delay 500ms
+  faas-cli invoke resize-img --retry 3 --retry-method POST < image.png
+  faas-cli invoke env --sign X-GitHub-Event --key yoursecret
+  faas-cli invoke env --sign X-Hub-Signature-256 --sign-secret-file ./payload-secret
+  faas-cli invoke env --sign X-Hub-Signature --sign-hash sha1 --sign-secret yoursecret
+  faas-cli invoke resize-img --compress < image.png
+  faas-cli invoke env --timing
+  faas-cli invoke train-model --invoke-timeout 10m < data.csv
+  faas-cli invoke resize-img --batch ./images/ --parallel 4 --output-dir ./resized/
+  faas-cli invoke sentiment --batch ./tweets.txt --by-line
+  faas-cli invoke env --method GET --expect-status 2xx --expect-body "fprocess"
+  faas-cli invoke missing-user --expect-status 404 --expect-status 410`,
 	RunE: runInvoke,
 }
 
@@ -69,15 +135,52 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("please provide a name for the function")
 	}
 
+	if len(signSecretFile) > 0 {
+		if len(key) > 0 {
+			return fmt.Errorf("give the secret with only one of --sign-secret, --key or --sign-secret-file")
+		}
+		secret, err := ioutil.ReadFile(signSecretFile)
+		if err != nil {
+			return fmt.Errorf("unable to read --sign-secret-file: %s", err.Error())
+		}
+		key = strings.TrimSpace(string(secret))
+		defer func() { key = "" }()
+	}
+
 	if missingSignFlag(sigHeader, key) {
-		return fmt.Errorf("signing requires both --sign <header-value> and --key <key-value>")
+		return fmt.Errorf("signing requires both --sign <header-value> and --sign-secret <secret-value>")
+	}
+
+	if _, err := signHashFunc(signHash); err != nil {
+		return err
+	}
+
+	if err := checkRetryMethod(invokeRetries, httpMethod, invokeRetryMethods); err != nil {
+		return err
+	}
+
+	if len(invokeCallbackURL) > 0 && !invokeAsync {
+		return fmt.Errorf("the --callback-url flag requires --async")
+	}
+
+	if invokeTiming && len(invokeBatch) > 0 {
+		return fmt.Errorf("the --timing flag can't be used with --batch, which prints a summary of the latencies")
+	}
+
+	if err := checkExpectStatus(invokeExpectStatus); err != nil {
+		return err
+	}
+
+	expect := len(invokeExpectStatus) > 0 || len(invokeExpectBody) > 0
+	if expect && len(invokeBatch) > 0 {
+		return fmt.Errorf("the --expect-status and --expect-body flags can't be used with --batch")
 	}
 
 	var yamlGateway string
 	functionName = args[0]
 
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		parsedServices, err := stack.ParseYAMLFiles(stackFiles(), regex, filter, envsubst)
 		if err != nil {
 			return err
 		}
@@ -90,6 +193,28 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 
 	gatewayAddress := getGatewayURL(gateway, defaultGateway, yamlGateway, os.Getenv(openFaaSURLEnvironment))
 
+	timeout := invokeTimeout
+	if !cmd.Flags().Changed("invoke-timeout") && cmd.Flags().Changed("timeout") {
+		timeout = commandTimeout
+	}
+	proxy.SetInvokeTimeout(timeout)
+	defer proxy.SetInvokeTimeout(0)
+
+	if len(invokeBatch) > 0 {
+		requestHeaders := headers
+		if len(invokeCallbackURL) > 0 {
+			requestHeaders = append(requestHeaders, "X-Callback-Url="+invokeCallbackURL)
+		}
+		if invokeCompress {
+			requestHeaders = append(requestHeaders, "Content-Encoding=gzip")
+		}
+		return runInvokeBatch(gatewayAddress, requestHeaders)
+	}
+
+	if invokeByLine || len(invokeOutputDir) > 0 {
+		return fmt.Errorf("the --by-line and --output-dir flags require --batch")
+	}
+
 	stat, _ := os.Stdin.Stat()
 	if (stat.Mode() & os.ModeCharDevice) != 0 {
 		fmt.Fprintf(os.Stderr, "Reading from STDIN - hit (Control + D) to stop.\n")
@@ -101,38 +226,89 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(sigHeader) > 0 {
-		signedHeader, err := generateSignedHeader(functionInput, key, sigHeader)
+		signedHeader, err := generateSignedHeader(functionInput, key, sigHeader, signHash)
 		if err != nil {
 			return fmt.Errorf("unable to sign message: %s", err.Error())
 		}
 		headers = append(headers, signedHeader)
 	}
 
-	response, err := proxy.InvokeFunction(gatewayAddress, functionName, &functionInput, contentType, query, headers, invokeAsync, httpMethod, tlsInsecure, functionInvokeNamespace)
+	if len(invokeCallbackURL) > 0 {
+		headers = append(headers, "X-Callback-Url="+invokeCallbackURL)
+	}
+
+	if invokeCompress {
+		compressed, err := proxy.GzipBody(functionInput)
+		if err != nil {
+			return fmt.Errorf("unable to compress the request: %s", err.Error())
+		}
+		functionInput = compressed
+		headers = append(headers, "Content-Encoding=gzip")
+	}
+
+	if invokeTiming {
+		proxy.SetInvokeTimings(os.Stderr)
+		defer proxy.SetInvokeTimings(nil)
+	}
+
+	var out io.Writer = os.Stdout
+	var body bytes.Buffer
+	var statusCode int
+	if expect {
+		out = io.MultiWriter(os.Stdout, &body)
+		proxy.SetInvokeStatus(func(code int) { statusCode = code })
+		defer proxy.SetInvokeStatus(nil)
+	}
+
+	attempts, err := invokeWithRetry(invokeRetries, invokeRetryDelay, func() error {
+		body.Reset()
+		return proxy.InvokeFunctionStream(out, invokeStream, gatewayAddress, functionName, &functionInput, contentType, query, headers, invokeAsync, httpMethod, tlsInsecure, functionInvokeNamespace)
+	})
+	if expect {
+		err = checkInvokeExpectations(os.Stdout, err, statusCode, body.String(), invokeExpectStatus, invokeExpectBody)
+	}
 	if err != nil {
 		return err
 	}
 
-	if response != nil {
-		os.Stdout.Write(*response)
+	if invokeRetries > 0 {
+		fmt.Fprintf(os.Stderr, "Function invoked after %d attempt(s).\n", attempts)
 	}
 
 	return nil
 }
 
-func generateSignedHeader(message []byte, key string, headerName string) (string, error) {
+// generateSignedHeader gives the header holding the HMAC of the message,
+// prefixed by the hash, such as X-Hub-Signature-256=sha256=<hex>
+func generateSignedHeader(message []byte, key string, headerName string, hashName string) (string, error) {
 
 	if len(headerName) == 0 {
 		return "", fmt.Errorf("signed header must have a non-zero length")
 	}
 
-	hash := hmac.Sign(message, []byte(key))
-	signature := hex.EncodeToString(hash)
-	signedHeader := fmt.Sprintf(`%s=%s=%s`, headerName, "sha1", string(signature[:]))
+	newHash, err := signHashFunc(hashName)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(newHash, []byte(key))
+	mac.Write(message)
+	signature := hex.EncodeToString(mac.Sum(nil))
+	signedHeader := fmt.Sprintf(`%s=%s=%s`, headerName, hashName, signature)
 
 	return signedHeader, nil
 }
 
+func signHashFunc(name string) (func() hash.Hash, error) {
+	switch name {
+	case "sha256":
+		return sha256.New, nil
+	case "sha1":
+		return sha1.New, nil
+	}
+	return nil, fmt.Errorf("--sign-hash must be sha256 or sha1, not: %s", name)
+}
+
 func missingSignFlag(header string, key string) bool {
 	return (len(header) > 0 && len(key) == 0) || (len(header) == 0 && len(key) > 0)
 }
@@ -0,0 +1,239 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openfaas/faas-cli/proxy"
+)
+
+type batchPayload struct {
+	name string
+	body []byte
+}
+
+type batchResult struct {
+	name     string
+	err      error
+	duration time.Duration
+}
+
+// readBatchPayloads gives each file in a directory as a payload, sorted by
+// name, or each non-empty line of a file when byLine is set.
+func readBatchPayloads(path string, byLine bool) ([]batchPayload, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read --batch: %s", err.Error())
+	}
+
+	if info.IsDir() {
+		if byLine {
+			return nil, fmt.Errorf("--by-line needs a file, but %s is a directory", path)
+		}
+
+		files, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var payloads []batchPayload
+		for _, file := range files {
+			if !file.Mode().IsRegular() {
+				continue
+			}
+			body, err := ioutil.ReadFile(filepath.Join(path, file.Name()))
+			if err != nil {
+				return nil, err
+			}
+			payloads = append(payloads, batchPayload{name: file.Name(), body: body})
+		}
+		return payloads, nil
+	}
+
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !byLine {
+		return []batchPayload{{name: info.Name(), body: body}}, nil
+	}
+
+	var payloads []batchPayload
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		payloads = append(payloads, batchPayload{
+			name: fmt.Sprintf("line-%d", lineNumber),
+			body: append([]byte{}, line...),
+		})
+	}
+	return payloads, scanner.Err()
+}
+
+// invokeAll sends each payload with a pool of workers bounded by queueDepth.
+// Responses are written to outputDir as one file per payload, or to stdout in
+// one go so that they are not interleaved. The results are sorted by name.
+func invokeAll(payloads []batchPayload, queueDepth int, outputDir string, invoke func(body []byte) ([]byte, error)) []batchResult {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []batchResult
+	)
+
+	workChannel := make(chan batchPayload)
+
+	wg.Add(queueDepth)
+	for i := 0; i < queueDepth; i++ {
+		go func() {
+			defer wg.Done()
+
+			for payload := range workChannel {
+				start := time.Now()
+				output, err := invoke(payload.body)
+				result := batchResult{name: payload.name, err: err, duration: time.Since(start)}
+
+				if err == nil && len(outputDir) > 0 {
+					result.err = ioutil.WriteFile(filepath.Join(outputDir, payload.name), output, 0600)
+				}
+
+				mu.Lock()
+				if err == nil && len(outputDir) == 0 {
+					os.Stdout.Write(output)
+				}
+				results = append(results, result)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, payload := range payloads {
+		workChannel <- payload
+	}
+	close(workChannel)
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].name < results[j].name
+	})
+	return results
+}
+
+// printBatchSummary lists the failed payloads and the latency percentiles
+// across all of the requests
+func printBatchSummary(results []batchResult) {
+	var failed []batchResult
+	var durations []time.Duration
+	for _, result := range results {
+		if result.err != nil {
+			failed = append(failed, result)
+		}
+		durations = append(durations, result.duration)
+	}
+
+	fmt.Fprintf(os.Stderr, "\nInvoked %d of %d payload(s) successfully.\n", len(results)-len(failed), len(results))
+	if len(failed) > 0 {
+		fmt.Fprintln(os.Stderr, "Failed:")
+		for _, result := range failed {
+			fmt.Fprintf(os.Stderr, "  %s\t%s\n", result.name, result.err.Error())
+		}
+	}
+
+	if len(durations) > 0 {
+		sort.Slice(durations, func(i, j int) bool {
+			return durations[i] < durations[j]
+		})
+		fmt.Fprintf(os.Stderr, "Latency p50: %s, p90: %s, p99: %s, max: %s\n",
+			percentile(durations, 50).Round(time.Millisecond),
+			percentile(durations, 90).Round(time.Millisecond),
+			percentile(durations, 99).Round(time.Millisecond),
+			durations[len(durations)-1].Round(time.Millisecond))
+	}
+}
+
+// percentile uses the nearest-rank method on sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func runInvokeBatch(gatewayAddress string, requestHeaders []string) error {
+	if invokeParallel < 1 {
+		return fmt.Errorf("the --parallel flag must be greater than 0")
+	}
+
+	payloads, err := readBatchPayloads(invokeBatch, invokeByLine)
+	if err != nil {
+		return err
+	}
+	if len(payloads) == 0 {
+		return fmt.Errorf("no payloads found in %s", invokeBatch)
+	}
+
+	if len(invokeOutputDir) > 0 {
+		if err := os.MkdirAll(invokeOutputDir, 0700); err != nil {
+			return fmt.Errorf("unable to create --output-dir: %s", err.Error())
+		}
+	}
+
+	results := invokeAll(payloads, invokeParallel, invokeOutputDir, func(body []byte) ([]byte, error) {
+		payloadHeaders := append([]string{}, requestHeaders...)
+		if len(sigHeader) > 0 {
+			signedHeader, err := generateSignedHeader(body, key, sigHeader, signHash)
+			if err != nil {
+				return nil, fmt.Errorf("unable to sign message: %s", err.Error())
+			}
+			payloadHeaders = append(payloadHeaders, signedHeader)
+		}
+
+		if invokeCompress {
+			compressed, err := proxy.GzipBody(body)
+			if err != nil {
+				return nil, fmt.Errorf("unable to compress the request: %s", err.Error())
+			}
+			body = compressed
+		}
+
+		var out bytes.Buffer
+		_, err := invokeWithRetry(invokeRetries, invokeRetryDelay, func() error {
+			out.Reset()
+			return proxy.InvokeFunctionStream(&out, false, gatewayAddress, functionName, &body, contentType, query, payloadHeaders, invokeAsync, httpMethod, tlsInsecure, functionInvokeNamespace)
+		})
+		return out.Bytes(), err
+	})
+
+	printBatchSummary(results)
+
+	if failed := countFailed(results); failed > 0 {
+		return fmt.Errorf("%d of %d payload(s) failed", failed, len(results))
+	}
+	return nil
+}
+
+func countFailed(results []batchResult) int {
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,127 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+func makeBatchDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "faas-cli-batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func Test_readBatchPayloads_Directory(t *testing.T) {
+	dir := makeBatchDir(t, map[string]string{"b.json": `{"b":1}`, "a.json": `{"a":1}`})
+	defer os.RemoveAll(dir)
+	os.Mkdir(filepath.Join(dir, "nested"), 0700)
+
+	payloads, err := readBatchPayloads(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(payloads) != 2 || payloads[0].name != "a.json" || string(payloads[1].body) != `{"b":1}` {
+		t.Errorf("want a.json and b.json in order, got %v", payloads)
+	}
+
+	if _, err := readBatchPayloads(dir, true); err == nil {
+		t.Errorf("want error for --by-line with a directory")
+	}
+}
+
+func Test_readBatchPayloads_ByLine(t *testing.T) {
+	dir := makeBatchDir(t, map[string]string{"payloads.txt": "one\n\ntwo\n"})
+	defer os.RemoveAll(dir)
+
+	payloads, err := readBatchPayloads(filepath.Join(dir, "payloads.txt"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(payloads) != 2 || payloads[0].name != "line-1" || payloads[1].name != "line-3" || string(payloads[1].body) != "two" {
+		t.Errorf("want line-1 and line-3, got %v", payloads)
+	}
+}
+
+func Test_percentile(t *testing.T) {
+	var durations []time.Duration
+	for i := 1; i <= 10; i++ {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+
+	if got := percentile(durations, 50); got != 5*time.Millisecond {
+		t.Errorf("want p50 of 5ms, got %s", got)
+	}
+	if got := percentile(durations, 90); got != 9*time.Millisecond {
+		t.Errorf("want p90 of 9ms, got %s", got)
+	}
+	if got := percentile(durations, 99); got != 10*time.Millisecond {
+		t.Errorf("want p99 of 10ms, got %s", got)
+	}
+}
+
+func Test_invoke_BatchToOutputDir(t *testing.T) {
+	payloadDir := makeBatchDir(t, map[string]string{"a.txt": "a", "b.txt": "b"})
+	defer os.RemoveAll(payloadDir)
+
+	outputDir := filepath.Join(payloadDir, "out")
+	defer func() {
+		invokeBatch = ""
+		invokeOutputDir = ""
+	}()
+
+	funcName := "test-1"
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodPost,
+			Uri:                "/function/" + funcName,
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       "done",
+		},
+		{
+			Method:             http.MethodPost,
+			Uri:                "/function/" + funcName,
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       "done",
+		},
+	})
+	defer s.Close()
+
+	faasCmd.SetArgs([]string{
+		"invoke",
+		"--gateway=" + s.URL,
+		"--batch=" + payloadDir,
+		"--output-dir=" + outputDir,
+		funcName,
+	})
+	if err := faasCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		output, err := ioutil.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(output) != "done" {
+			t.Errorf("%s: want response written, got %q", name, string(output))
+		}
+	}
+}
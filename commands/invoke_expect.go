@@ -0,0 +1,77 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openfaas/faas-cli/proxy"
+)
+
+var (
+	invokeExpectStatus []string
+	invokeExpectBody   string
+)
+
+// expectStatusPattern matches a status code such as 200, or a class of
+// status codes such as 2xx
+var expectStatusPattern = regexp.MustCompile(`^[1-5]([0-9]{2}|xx)$`)
+
+// checkExpectStatus gives an error for an --expect-status which is not a
+// status code or a class of them
+func checkExpectStatus(expected []string) error {
+	for _, status := range expected {
+		if !expectStatusPattern.MatchString(strings.ToLower(status)) {
+			return fmt.Errorf("the --expect-status flag must be a status code such as 200, or a range such as 2xx, not: %s", status)
+		}
+	}
+	return nil
+}
+
+// statusExpected is true when the status code matches one of the expected
+// status codes or ranges
+func statusExpected(statusCode int, expected []string) bool {
+	code := strconv.Itoa(statusCode)
+	for _, status := range expected {
+		status = strings.ToLower(status)
+		if status == code || (strings.HasSuffix(status, "xx") && status[0] == code[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkInvokeExpectations gives an error when the status of the response is
+// not one of those expected, or the body does not hold the expected text.
+// An error from the invoke for a status which was expected is not an error,
+// the body of that response is written to out as for any other response.
+func checkInvokeExpectations(out io.Writer, invokeErr error, statusCode int, body string, expected []string, expectBody string) error {
+	if invokeErr != nil {
+		if len(expected) == 0 || proxy.StatusCode(invokeErr) == 0 {
+			return invokeErr
+		}
+		if !statusExpected(statusCode, expected) {
+			return fmt.Errorf("the function returned status %d, expected %s: %s", statusCode, strings.Join(expected, " or "), invokeErr)
+		}
+
+		body = proxy.ResponseBody(invokeErr)
+		if _, err := io.WriteString(out, body); err != nil {
+			return err
+		}
+	}
+
+	if len(expected) > 0 && !statusExpected(statusCode, expected) {
+		return fmt.Errorf("the function returned status %d, expected %s", statusCode, strings.Join(expected, " or "))
+	}
+
+	if len(expectBody) > 0 && !strings.Contains(body, expectBody) {
+		return fmt.Errorf("the response from the function did not contain %q", expectBody)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_statusExpected(t *testing.T) {
+	cases := []struct {
+		statusCode int
+		expected   []string
+		want       bool
+	}{
+		{statusCode: 200, expected: []string{"200"}, want: true},
+		{statusCode: 204, expected: []string{"2xx"}, want: true},
+		{statusCode: 204, expected: []string{"2XX"}, want: true},
+		{statusCode: 404, expected: []string{"2xx", "404"}, want: true},
+		{statusCode: 500, expected: []string{"2xx", "404"}, want: false},
+		{statusCode: 200, expected: []string{"201"}, want: false},
+	}
+
+	for _, c := range cases {
+		if got := statusExpected(c.statusCode, c.expected); got != c.want {
+			t.Errorf("%d in %v: want %t, got %t", c.statusCode, c.expected, c.want, got)
+		}
+	}
+}
+
+func Test_checkExpectStatus(t *testing.T) {
+	if err := checkExpectStatus([]string{"200", "4xx"}); err != nil {
+		t.Errorf("want the status codes to be accepted, got %s", err)
+	}
+	for _, status := range []string{"ok", "20", "2x", "600", "x00"} {
+		if err := checkExpectStatus([]string{status}); err == nil {
+			t.Errorf("want an error for %q", status)
+		}
+	}
+}
+
+func Test_invoke_ExpectStatus(t *testing.T) {
+	defer func() {
+		invokeExpectStatus = []string{}
+		invokeExpectBody = ""
+	}()
+
+	funcName := "test-1"
+	cases := []struct {
+		name       string
+		statusCode int
+		args       []string
+		wantErr    string
+	}{
+		{
+			name:       "expected status",
+			statusCode: http.StatusOK,
+			args:       []string{"--expect-status=2xx", "--expect-body=healthy"},
+		},
+		{
+			name:       "expected error status",
+			statusCode: http.StatusNotFound,
+			args:       []string{"--expect-status=404"},
+		},
+		{
+			name:       "unexpected status",
+			statusCode: http.StatusOK,
+			args:       []string{"--expect-status=201"},
+			wantErr:    "the function returned status 200, expected 201",
+		},
+		{
+			name:       "unexpected error status",
+			statusCode: http.StatusInternalServerError,
+			args:       []string{"--expect-status=2xx"},
+			wantErr:    "the function returned status 500, expected 2xx",
+		},
+		{
+			name:       "missing body",
+			statusCode: http.StatusOK,
+			args:       []string{"--expect-body=ready"},
+			wantErr:    `the response from the function did not contain "ready"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			invokeExpectStatus = []string{}
+			invokeExpectBody = ""
+
+			s := test.MockHttpServer(t, []test.Request{
+				{
+					Method:             http.MethodPost,
+					Uri:                "/function/" + funcName,
+					ResponseStatusCode: c.statusCode,
+					ResponseBody:       "healthy",
+				},
+			})
+			defer s.Close()
+
+			os.Stdin, _ = ioutil.TempFile("", "stdin")
+			defer os.Remove(os.Stdin.Name())
+
+			var err error
+			stdOut := test.CaptureStdout(func() {
+				faasCmd.SetArgs(append([]string{"invoke", "--gateway=" + s.URL, funcName}, c.args...))
+				err = faasCmd.Execute()
+			})
+
+			if len(c.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("want no error, got %s", err)
+				}
+				if stdOut != "healthy" {
+					t.Errorf("want the body of the response, got %q", stdOut)
+				}
+				return
+			}
+
+			if err == nil || !strings.HasPrefix(err.Error(), c.wantErr) {
+				t.Errorf("want error %q, got %v", c.wantErr, err)
+			}
+		})
+	}
+}
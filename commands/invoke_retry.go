@@ -0,0 +1,67 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openfaas/faas-cli/proxy"
+)
+
+// defaultRetryMethods are the idempotent methods which are safe to retry
+var defaultRetryMethods = []string{http.MethodGet, http.MethodPut, http.MethodDelete}
+
+// retrySleep is replaced in tests to avoid waiting between attempts
+var retrySleep = time.Sleep
+
+// checkRetryMethod gives an error when retries are requested for a method
+// which has not been opted into with --retry-method.
+func checkRetryMethod(retries int, method string, retryMethods []string) error {
+	if retries < 0 {
+		return fmt.Errorf("the --retry flag must be 0 or greater")
+	}
+	if retries == 0 {
+		return nil
+	}
+
+	for _, retryMethod := range retryMethods {
+		if strings.EqualFold(retryMethod, method) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s requests are not retried as they may not be idempotent, pass --retry-method %s to retry them", method, method)
+}
+
+// invokeWithRetry calls invoke until it succeeds, gives an error which is not
+// retryable or the retries are used up. The number of attempts is returned.
+func invokeWithRetry(retries int, delay time.Duration, invoke func() error) (int, error) {
+	attempt := 0
+	for {
+		attempt++
+		err := invoke()
+		if err == nil || attempt > retries || !proxy.IsRetryable(err) {
+			return attempt, err
+		}
+
+		wait := retryBackoff(delay, attempt)
+		fmt.Fprintf(os.Stderr, "Attempt %d failed: %s, retrying in %s\n", attempt, err.Error(), wait.Round(time.Millisecond))
+		retrySleep(wait)
+	}
+}
+
+// retryBackoff doubles the delay for each attempt, then picks a random wait
+// between half and all of it so that concurrent callers spread out.
+func retryBackoff(delay time.Duration, attempt int) time.Duration {
+	backoff := delay << uint(attempt-1)
+	half := backoff / 2
+	if half <= 0 {
+		return backoff
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
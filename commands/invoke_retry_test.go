@@ -0,0 +1,107 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_checkRetryMethod(t *testing.T) {
+	if err := checkRetryMethod(0, http.MethodPost, defaultRetryMethods); err != nil {
+		t.Errorf("want no error without retries, got %s", err)
+	}
+	if err := checkRetryMethod(3, http.MethodGet, defaultRetryMethods); err != nil {
+		t.Errorf("want GET to be retried, got %s", err)
+	}
+
+	want := "POST requests are not retried as they may not be idempotent, pass --retry-method POST to retry them"
+	if err := checkRetryMethod(3, http.MethodPost, defaultRetryMethods); err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+	if err := checkRetryMethod(3, http.MethodPost, []string{"post"}); err != nil {
+		t.Errorf("want POST to be retried after opting in, got %s", err)
+	}
+}
+
+func Test_retryBackoff(t *testing.T) {
+	for attempt := 1; attempt <= 4; attempt++ {
+		max := time.Second << uint(attempt-1)
+		wait := retryBackoff(time.Second, attempt)
+		if wait < max/2 || wait > max {
+			t.Errorf("attempt %d: want wait between %s and %s, got %s", attempt, max/2, max, wait)
+		}
+	}
+}
+
+func Test_invoke_RetriesOn503(t *testing.T) {
+	retrySleep = func(time.Duration) {}
+	defer func() {
+		retrySleep = time.Sleep
+		invokeRetries = 0
+		httpMethod = http.MethodPost
+	}()
+
+	funcName := "test-1"
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/function/" + funcName,
+			ResponseStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/function/" + funcName,
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       "done",
+		},
+	})
+	defer s.Close()
+
+	os.Stdin, _ = ioutil.TempFile("", "stdin")
+	defer os.Remove(os.Stdin.Name())
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"invoke",
+			"--gateway=" + s.URL,
+			"--method=GET",
+			"--retry=2",
+			funcName,
+		})
+		err = faasCmd.Execute()
+	})
+
+	if err != nil {
+		t.Fatalf("want success after a retry, got %s", err)
+	}
+	if stdOut != "done" {
+		t.Errorf("want function output, got %q", stdOut)
+	}
+}
+
+func Test_invokeWithRetry_StopsOnClientError(t *testing.T) {
+	retrySleep = func(time.Duration) {}
+	defer func() { retrySleep = time.Sleep }()
+
+	s := test.MockHttpServerStatus(t, http.StatusNotFound)
+	defer s.Close()
+
+	body := []byte("test data")
+	attempts, err := invokeWithRetry(3, time.Second, func() error {
+		_, err := proxy.InvokeFunction(s.URL, "test-1", &body, "text/plain", nil, nil, false, http.MethodGet, false, "")
+		return err
+	})
+
+	if err == nil || attempts != 1 {
+		t.Errorf("want a single attempt, got %d attempt(s) and error %v", attempts, err)
+	}
+}
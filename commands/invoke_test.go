@@ -4,7 +4,9 @@
 package commands
 
 import (
+	"compress/gzip"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"regexp"
 	"strings"
@@ -52,6 +54,57 @@ func Test_invoke(t *testing.T) {
 
 }
 
+func Test_invoke_Compress(t *testing.T) {
+	var received string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("want Content-Encoding: gzip, got %q", r.Header.Get("Content-Encoding"))
+		}
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("want a gzip body, got %s", err)
+			return
+		}
+		body, _ := ioutil.ReadAll(reader)
+		received = string(body)
+
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		writer.Write([]byte("echo: " + received))
+		writer.Close()
+	}))
+	defer s.Close()
+
+	os.Stdin, _ = ioutil.TempFile("", "stdin")
+	os.Stdin.WriteString("test-data")
+	os.Stdin.Seek(0, 0)
+	defer func() {
+		os.Remove(os.Stdin.Name())
+		invokeCompress = false
+		headers = []string{}
+	}()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"invoke",
+			"--gateway=" + s.URL,
+			"--compress",
+			"-H", "Accept-Encoding=gzip",
+			"echo",
+		})
+		if err := faasCmd.Execute(); err != nil {
+			t.Errorf("want no error, got %s", err)
+		}
+	})
+
+	if received != "test-data" {
+		t.Errorf("want the function to receive test-data, got %q", received)
+	}
+	if stdOut != "echo: test-data" {
+		t.Errorf("want the decompressed response, got %q", stdOut)
+	}
+}
+
 func Test_async_invoke(t *testing.T) {
 	funcName := "test-1"
 
@@ -87,6 +140,101 @@ func Test_async_invoke(t *testing.T) {
 
 }
 
+func Test_invoke_CallbackURLRequiresAsync(t *testing.T) {
+	invokeAsync = false
+	defer func() { invokeCallbackURL = "" }()
+
+	faasCmd.SetArgs([]string{
+		"invoke",
+		"--callback-url=http://gateway:8080/function/send2slack",
+		"test-1",
+	})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != "the --callback-url flag requires --async" {
+		t.Errorf("want --callback-url error, got %v", err)
+	}
+}
+
+func Test_invoke_TimingWithBatch(t *testing.T) {
+	resetForTest()
+	defer func() {
+		invokeTiming = false
+		invokeBatch = ""
+	}()
+
+	faasCmd.SetArgs([]string{"invoke", "echo", "--timing", "--batch=./payloads"})
+	err := faasCmd.Execute()
+
+	want := "the --timing flag can't be used with --batch, which prints a summary of the latencies"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+func Test_invoke_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer s.Close()
+	defer close(release)
+
+	os.Stdin, _ = ioutil.TempFile("", "stdin")
+	os.Stdin.WriteString("test-data")
+	os.Stdin.Seek(0, 0)
+	defer func() {
+		os.Remove(os.Stdin.Name())
+	}()
+
+	defer func() {
+		resetForTest()
+		invokeTimeout = 0
+		invokeCmd.Flags().Lookup("invoke-timeout").Changed = false
+		faasCmd.PersistentFlags().Lookup("timeout").Changed = false
+	}()
+
+	cases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "invoke timeout", args: []string{"--invoke-timeout=50ms"}, want: "50ms"},
+		{name: "global timeout", args: []string{"--timeout=60ms"}, want: "60ms"},
+		{name: "invoke timeout in place of the global timeout", args: []string{"--timeout=1m", "--invoke-timeout=70ms"}, want: "70ms"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resetForTest()
+			invokeTimeout = 0
+			invokeCmd.Flags().Lookup("invoke-timeout").Changed = false
+			faasCmd.PersistentFlags().Lookup("timeout").Changed = false
+			os.Stdin.Seek(0, 0)
+
+			var err error
+			test.CaptureStdout(func() {
+				faasCmd.SetArgs(append([]string{
+					"invoke",
+					"--gateway=" + s.URL,
+					"echo",
+				}, c.args...))
+				err = faasCmd.Execute()
+			})
+
+			if err == nil || err.Error() != "gateway did not respond within "+c.want {
+				t.Errorf("want a timeout error after %s, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func Test_invoke_NoTimeoutByDefault(t *testing.T) {
+	if got := invokeCmd.Flags().Lookup("invoke-timeout").DefValue; got != "0s" {
+		t.Errorf("want invoke to wait for the function for as long as it runs by default, got a timeout of %s", got)
+	}
+}
+
 func Test_generateSignedHeader(t *testing.T) {
 
 	var generateTestcases = []struct {
@@ -94,6 +242,7 @@ func Test_generateSignedHeader(t *testing.T) {
 		message     []byte
 		key         string
 		headerName  string
+		hashName    string
 		expectedSig string
 		expectedErr bool
 	}{
@@ -102,6 +251,7 @@ func Test_generateSignedHeader(t *testing.T) {
 			message:     []byte("This is a message"),
 			key:         "",
 			headerName:  "HeaderSet",
+			hashName:    "sha1",
 			expectedSig: "HeaderSet=sha1=cdefd604e685e5c8b31fbcf6621a6e8282770dfe",
 			expectedErr: false,
 		},
@@ -110,6 +260,7 @@ func Test_generateSignedHeader(t *testing.T) {
 			message:     []byte("This is a message"),
 			key:         "KeySet",
 			headerName:  "",
+			hashName:    "sha1",
 			expectedSig: "",
 			expectedErr: true,
 		},
@@ -118,6 +269,7 @@ func Test_generateSignedHeader(t *testing.T) {
 			message:     []byte(""),
 			key:         "KeySet",
 			headerName:  "HeaderSet",
+			hashName:    "sha1",
 			expectedSig: "HeaderSet=sha1=33dcd94ffaf13fce58615585c030c1a39d100b3c",
 			expectedErr: false,
 		},
@@ -126,13 +278,32 @@ func Test_generateSignedHeader(t *testing.T) {
 			message:     []byte(""),
 			key:         "",
 			headerName:  "HeaderSet",
+			hashName:    "sha1",
 			expectedSig: "HeaderSet=sha1=fbdb1d1b18aa6c08324b7d64b71fb76370690e1d",
 			expectedErr: false,
 		},
+		{
+			title:       "SHA256 test vector from GitHub",
+			message:     []byte("Hello, World!"),
+			key:         "It's a Secret to Everybody",
+			headerName:  "X-Hub-Signature-256",
+			hashName:    "sha256",
+			expectedSig: "X-Hub-Signature-256=sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17",
+			expectedErr: false,
+		},
+		{
+			title:       "Unknown hash",
+			message:     []byte("This is a message"),
+			key:         "KeySet",
+			headerName:  "HeaderSet",
+			hashName:    "md5",
+			expectedSig: "",
+			expectedErr: true,
+		},
 	}
 	for _, test := range generateTestcases {
 		t.Run(test.title, func(t *testing.T) {
-			sig, err := generateSignedHeader(test.message, test.key, test.headerName)
+			sig, err := generateSignedHeader(test.message, test.key, test.headerName, test.hashName)
 
 			if sig != test.expectedSig {
 				t.Fatalf("error generating signature, wanted: %s, got %s", test.expectedSig, sig)
@@ -141,7 +312,7 @@ func Test_generateSignedHeader(t *testing.T) {
 				t.Fatalf("error generating expected error: %v, got: %v", err != nil, test.expectedErr)
 			}
 
-			if test.expectedErr == false {
+			if test.expectedErr == false && test.hashName == "sha1" {
 
 				encodedHash := strings.SplitN(test.expectedSig, "=", 2)
 
@@ -155,6 +326,46 @@ func Test_generateSignedHeader(t *testing.T) {
 	}
 }
 
+func Test_invoke_SignSecretFile(t *testing.T) {
+	var signature string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Hub-Signature-256")
+	}))
+	defer s.Close()
+
+	secretFile, _ := ioutil.TempFile("", "secret")
+	secretFile.WriteString("It's a Secret to Everybody\n")
+	secretFile.Close()
+
+	os.Stdin, _ = ioutil.TempFile("", "stdin")
+	os.Stdin.WriteString("Hello, World!")
+	os.Stdin.Seek(0, 0)
+	defer func() {
+		os.Remove(os.Stdin.Name())
+		os.Remove(secretFile.Name())
+		sigHeader = ""
+		signSecretFile = ""
+		headers = []string{}
+	}()
+
+	test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"invoke",
+			"--gateway=" + s.URL,
+			"--sign=X-Hub-Signature-256",
+			"--sign-secret-file=" + secretFile.Name(),
+			"echo",
+		})
+		if err := faasCmd.Execute(); err != nil {
+			t.Errorf("want no error, got %s", err)
+		}
+	})
+
+	if want := "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"; signature != want {
+		t.Errorf("want the signature %q, got %q", want, signature)
+	}
+}
+
 func Test_missingSignFlag(t *testing.T) {
 
 	var signtestcases = []struct {
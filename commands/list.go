@@ -5,26 +5,57 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
 
 	"github.com/openfaas/faas-cli/proxy"
 	"github.com/openfaas/faas-cli/stack"
+	types "github.com/openfaas/faas-provider/types"
 	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
 )
 
 var (
 	verboseList bool
 	token       string
+	listOutput  string
+	selectors   []string
+	listSort    string
+	listReverse bool
+	listFormat  string
+
+	listAllNamespaces bool
 )
 
+// listedFunction is printed by list --output, the field names are kept stable
+// so that scripts don't break when the gateway's API changes
+type listedFunction struct {
+	Name              string            `json:"name" yaml:"name"`
+	Namespace         string            `json:"namespace" yaml:"namespace"`
+	Image             string            `json:"image" yaml:"image"`
+	Replicas          uint64            `json:"replicas" yaml:"replicas"`
+	AvailableReplicas uint64            `json:"availableReplicas" yaml:"availableReplicas"`
+	InvocationCount   int64             `json:"invocationCount" yaml:"invocationCount"`
+	Labels            map[string]string `json:"labels" yaml:"labels"`
+}
+
 func init() {
 	// Setup flags that are used by multiple commands (variables defined in faas.go)
 	listCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
 	listCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function")
+	listCmd.Flags().BoolVarP(&listAllNamespaces, "all-namespaces", "A", false, "List the functions in every namespace, --output gives them keyed by namespace")
 
 	listCmd.Flags().BoolVarP(&verboseList, "verbose", "v", false, "Verbose output for the function list")
-	listCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
+	listCmd.Flags().StringArrayVar(&selectors, "selector", []string{}, "Only list functions with matching labels (KEY=VALUE or KEY!=VALUE), comma separate or repeat to match all of them")
+	listCmd.Flags().StringVar(&listSort, "sort", "name", "Sort functions by name, invocations or replicas")
+	listCmd.Flags().BoolVar(&listReverse, "reverse", false, "Reverse the --sort order")
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "", "Output format, either json or yaml, instead of a table")
+	listCmd.Flags().StringVar(&listFormat, "format", "", "Print each function with a Go template instead of a table, i.e. '{{.Name}}\\t{{.Replicas}}'")
 	listCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yml file")
 	listCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
 
@@ -32,21 +63,61 @@ func init() {
 }
 
 var listCmd = &cobra.Command{
-	Use:     `list [--gateway GATEWAY_URL] [--verbose] [--tls-no-verify]`,
+	Use:     `list [--gateway GATEWAY_URL] [--verbose] [--tls-no-verify] [--selector KEY=VALUE] [--output json|yaml] [--format TEMPLATE] [--all-namespaces]`,
 	Aliases: []string{"ls"},
 	Short:   "List OpenFaaS functions",
-	Long:    `Lists OpenFaaS functions either on a local or remote gateway`,
+	Long: `Lists OpenFaaS functions either on a local or remote gateway.
+
+The --format flag prints each function with a Go template, where \t and \n give
+a tab and a newline. The fields are .Name, .Namespace, .Image, .Replicas,
+.AvailableReplicas, .InvocationCount and .Labels, and {{json .Labels}} gives a
+field as JSON.`,
 	Example: `  faas-cli list
-  faas-cli list --gateway https://127.0.0.1:8080 --verbose`,
+  faas-cli list --gateway https://127.0.0.1:8080 --verbose
+  faas-cli list --output json | jq -r '.[].name'
+  faas-cli list --selector com.example.team=payments,stage!=dev
+  faas-cli list --sort invocations --reverse
+  faas-cli list --all-namespaces
+  faas-cli list --format '{{.Name}}\t{{.Replicas}}\t{{.Image}}'`,
 	RunE: runList,
 }
 
 func runList(cmd *cobra.Command, args []string) error {
+	if len(listOutput) > 0 && listOutput != "json" && listOutput != "yaml" {
+		return fmt.Errorf("--output must be json or yaml, not: %s", listOutput)
+	}
+
+	if len(listOutput) > 0 && len(listFormat) > 0 {
+		return fmt.Errorf("--output and --format can't be used together")
+	}
+
+	var formatTemplate *template.Template
+	if len(listFormat) > 0 {
+		tmpl, err := parseFormat(listFormat)
+		if err != nil {
+			return err
+		}
+		formatTemplate = tmpl
+	}
+
+	if listSort != "name" && listSort != "invocations" && listSort != "replicas" {
+		return fmt.Errorf("--sort must be name, invocations or replicas, not: %s", listSort)
+	}
+
+	if listAllNamespaces && len(functionNamespace) > 0 {
+		return fmt.Errorf("--namespace and --all-namespaces can't be used together")
+	}
+
+	requirements, err := parseSelectors(selectors)
+	if err != nil {
+		return err
+	}
+
 	var services stack.Services
 	var gatewayAddress string
 	var yamlGateway string
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		parsedServices, err := stack.ParseYAMLFiles(stackFiles(), regex, filter, envsubst)
 		if err != nil {
 			return err
 		}
@@ -60,27 +131,222 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	cliAuth := NewCLIAuth(token, gatewayAddress)
 	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
+
+	if listAllNamespaces {
+		newClient := func() *proxy.Client {
+			return proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
+		}
+		byNamespace, err := listFunctionsInAllNamespaces(context.Background(), newClient)
+		if err != nil {
+			return err
+		}
+
+		for namespace, functions := range byNamespace {
+			functions = filterByLabels(functions, requirements)
+			sortFunctions(functions, listSort, listReverse)
+			byNamespace[namespace] = functions
+		}
+
+		if formatTemplate != nil {
+			namespaces := make([]string, 0, len(byNamespace))
+			for namespace := range byNamespace {
+				namespaces = append(namespaces, namespace)
+			}
+			sort.Strings(namespaces)
+
+			var functions []types.FunctionStatus
+			for _, namespace := range namespaces {
+				functions = append(functions, byNamespace[namespace]...)
+			}
+			return printFunctionsFormatted(functions, formatTemplate)
+		}
+		if len(listOutput) > 0 {
+			return printFunctionsByNamespace(byNamespace, listOutput)
+		}
+		printFunctionTable(byNamespace)
+		return nil
+	}
+
 	proxyClient := proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
 	functions, err := proxyClient.ListFunctions(context.Background(), functionNamespace)
 	if err != nil {
 		return err
 	}
 
-	if verboseList {
-		fmt.Printf("%-30s\t%-40s\t%-15s\t%-5s\n", "Function", "Image", "Invocations", "Replicas")
-		for _, function := range functions {
-			functionImage := function.Image
-			if len(function.Image) > 40 {
-				functionImage = functionImage[0:38] + ".."
+	functions = filterByLabels(functions, requirements)
+	sortFunctions(functions, listSort, listReverse)
+
+	if formatTemplate != nil {
+		return printFunctionsFormatted(functions, formatTemplate)
+	}
+	if len(listOutput) > 0 {
+		return printFunctionList(functions, listOutput)
+	}
+
+	printFunctionTable(map[string][]types.FunctionStatus{"": functions})
+	return nil
+}
+
+// listFunctionsInAllNamespaces lists the namespaces on the gateway and then
+// the functions in each of them at once. Each list has its own client, as
+// the client's redirect policy is set by every call.
+func listFunctionsInAllNamespaces(ctx context.Context, newClient func() *proxy.Client) (map[string][]types.FunctionStatus, error) {
+	namespaces, err := newClient().ListNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byNamespace := make(map[string][]types.FunctionStatus, len(namespaces))
+	var errs []string
+	var lock sync.Mutex
+	wg := sync.WaitGroup{}
+
+	for _, namespace := range namespaces {
+		wg.Add(1)
+		go func(namespace string) {
+			defer wg.Done()
+
+			functions, err := newClient().ListFunctions(ctx, namespace)
+
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", namespace, err))
+				return
 			}
-			fmt.Printf("%-30s\t%-40s\t%-15d\t%-5d\n", function.Name, functionImage, int64(function.InvocationCount), function.Replicas)
+			byNamespace[namespace] = functions
+		}(namespace)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("unable to list the functions in every namespace:\n%s", strings.Join(errs, "\n"))
+	}
+
+	return byNamespace, nil
+}
+
+// printFunctionTable prints the functions by namespace, the namespace column
+// is only printed when the functions are not all in the "" namespace
+func printFunctionTable(byNamespace map[string][]types.FunctionStatus) {
+	var namespaces []string
+	for namespace := range byNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	withNamespace := len(namespaces) != 1 || namespaces[0] != ""
+	prefix := func(namespace string) string {
+		if withNamespace {
+			return fmt.Sprintf("%-20s\t", namespace)
 		}
+		return ""
+	}
+
+	if verboseList {
+		fmt.Printf("%s%-30s\t%-40s\t%-15s\t%-5s\n", prefix("Namespace"), "Function", "Image", "Invocations", "Replicas")
 	} else {
-		fmt.Printf("%-30s\t%-15s\t%-5s\n", "Function", "Invocations", "Replicas")
-		for _, function := range functions {
-			fmt.Printf("%-30s\t%-15d\t%-5d\n", function.Name, int64(function.InvocationCount), function.Replicas)
+		fmt.Printf("%s%-30s\t%-15s\t%-5s\n", prefix("Namespace"), "Function", "Invocations", "Replicas")
+	}
 
+	for _, namespace := range namespaces {
+		for _, function := range byNamespace[namespace] {
+			if verboseList {
+				functionImage := function.Image
+				if len(function.Image) > 40 {
+					functionImage = functionImage[0:38] + ".."
+				}
+				fmt.Printf("%s%-30s\t%-40s\t%-15d\t%-5d\n", prefix(namespace), function.Name, functionImage, int64(function.InvocationCount), function.Replicas)
+			} else {
+				fmt.Printf("%s%-30s\t%-15d\t%-5d\n", prefix(namespace), function.Name, int64(function.InvocationCount), function.Replicas)
+			}
 		}
 	}
+}
+
+// printFunctionList prints the functions as a JSON or YAML array
+func printFunctionList(functions []types.FunctionStatus, output string) error {
+	return printListed(toListedFunctions(functions), output)
+}
+
+// printFunctionsByNamespace prints the functions as a JSON or YAML object
+// keyed by namespace
+func printFunctionsByNamespace(byNamespace map[string][]types.FunctionStatus, output string) error {
+	listed := map[string][]listedFunction{}
+	for namespace, functions := range byNamespace {
+		listed[namespace] = toListedFunctions(functions)
+	}
+	return printListed(listed, output)
+}
+
+// printFunctionsFormatted prints each function with the --format template
+func printFunctionsFormatted(functions []types.FunctionStatus, tmpl *template.Template) error {
+	var items []interface{}
+	for _, function := range toListedFunctions(functions) {
+		items = append(items, function)
+	}
+	return printFormatted(os.Stdout, tmpl, items)
+}
+
+func toListedFunctions(functions []types.FunctionStatus) []listedFunction {
+	listed := []listedFunction{}
+	for _, function := range functions {
+		labels := map[string]string{}
+		if function.Labels != nil {
+			labels = *function.Labels
+		}
+
+		listed = append(listed, listedFunction{
+			Name:              function.Name,
+			Namespace:         function.Namespace,
+			Image:             function.Image,
+			Replicas:          function.Replicas,
+			AvailableReplicas: function.AvailableReplicas,
+			InvocationCount:   int64(function.InvocationCount),
+			Labels:            labels,
+		})
+	}
+	return listed
+}
+
+func printListed(listed interface{}, output string) error {
+	var out []byte
+	var err error
+	if output == "json" {
+		out, err = json.MarshalIndent(listed, "", "  ")
+		out = append(out, '\n')
+	} else {
+		out, err = yaml.Marshal(listed)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to marshal the function list: %s", err.Error())
+	}
+
+	fmt.Print(string(out))
 	return nil
 }
+
+// sortFunctions sorts by name, invocations or replicas, ties are sorted by name
+func sortFunctions(functions []types.FunctionStatus, by string, reverse bool) {
+	less := func(a, b types.FunctionStatus) bool {
+		switch by {
+		case "invocations":
+			if a.InvocationCount != b.InvocationCount {
+				return a.InvocationCount < b.InvocationCount
+			}
+		case "replicas":
+			if a.Replicas != b.Replicas {
+				return a.Replicas < b.Replicas
+			}
+		}
+		return a.Name < b.Name
+	}
+
+	sort.SliceStable(functions, func(i, j int) bool {
+		if reverse {
+			return less(functions[j], functions[i])
+		}
+		return less(functions[i], functions[j])
+	})
+}
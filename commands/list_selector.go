@@ -0,0 +1,82 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	types "github.com/openfaas/faas-provider/types"
+)
+
+// labelRequirement matches a label with a value, or without it when notEqual
+// is set, in which case a function without the label also matches
+type labelRequirement struct {
+	key      string
+	value    string
+	notEqual bool
+}
+
+func (r labelRequirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+	if r.notEqual {
+		return !ok || value != r.value
+	}
+	return ok && value == r.value
+}
+
+// parseSelectors parses each comma-separated KEY=VALUE or KEY!=VALUE term
+func parseSelectors(selectors []string) ([]labelRequirement, error) {
+	var requirements []labelRequirement
+
+	for _, selector := range selectors {
+		for _, term := range strings.Split(selector, ",") {
+			term = strings.TrimSpace(term)
+
+			requirement := labelRequirement{}
+			parts := strings.SplitN(term, "!=", 2)
+			if len(parts) == 2 {
+				requirement.notEqual = true
+			} else {
+				parts = strings.SplitN(term, "=", 2)
+			}
+
+			if len(parts) != 2 || len(strings.TrimSpace(parts[0])) == 0 || strings.ContainsAny(parts[1], "=!") {
+				return nil, fmt.Errorf("invalid --selector %q, expected KEY=VALUE or KEY!=VALUE", term)
+			}
+
+			requirement.key = strings.TrimSpace(parts[0])
+			requirement.value = strings.TrimSpace(parts[1])
+			requirements = append(requirements, requirement)
+		}
+	}
+	return requirements, nil
+}
+
+// filterByLabels gives the functions which match all of the requirements
+func filterByLabels(functions []types.FunctionStatus, requirements []labelRequirement) []types.FunctionStatus {
+	if len(requirements) == 0 {
+		return functions
+	}
+
+	var filtered []types.FunctionStatus
+	for _, function := range functions {
+		labels := map[string]string{}
+		if function.Labels != nil {
+			labels = *function.Labels
+		}
+
+		match := true
+		for _, requirement := range requirements {
+			if !requirement.matches(labels) {
+				match = false
+				break
+			}
+		}
+		if match {
+			filtered = append(filtered, function)
+		}
+	}
+	return filtered
+}
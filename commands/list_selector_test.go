@@ -0,0 +1,70 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_parseSelectors(t *testing.T) {
+	requirements, err := parseSelectors([]string{"com.example.team=payments, stage!=dev", "tier=web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []labelRequirement{
+		{key: "com.example.team", value: "payments"},
+		{key: "stage", value: "dev", notEqual: true},
+		{key: "tier", value: "web"},
+	}
+	if !reflect.DeepEqual(requirements, want) {
+		t.Errorf("want %v, got %v", want, requirements)
+	}
+}
+
+func Test_parseSelectors_Malformed(t *testing.T) {
+	for _, selector := range []string{"team", "=payments", "team=a=b", "a=b,,c=d", "team!=a!=b"} {
+		if _, err := parseSelectors([]string{selector}); err == nil {
+			t.Errorf("want error for selector %q", selector)
+		}
+	}
+}
+
+func Test_filterByLabels(t *testing.T) {
+	functions := []types.FunctionStatus{
+		{Name: "checkout", Labels: &map[string]string{"team": "payments", "stage": "prod"}},
+		{Name: "refund", Labels: &map[string]string{"team": "payments", "stage": "dev"}},
+		{Name: "figlet"},
+	}
+
+	cases := []struct {
+		selector string
+		want     []string
+	}{
+		{selector: "team=payments", want: []string{"checkout", "refund"}},
+		{selector: "team=payments,stage!=dev", want: []string{"checkout"}},
+		{selector: "stage!=dev", want: []string{"checkout", "figlet"}},
+		{selector: "team=search", want: nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.selector, func(t *testing.T) {
+			requirements, err := parseSelectors([]string{c.selector})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, function := range filterByLabels(functions, requirements) {
+				names = append(names, function.Name)
+			}
+			if !reflect.DeepEqual(names, c.want) {
+				t.Errorf("want %v, got %v", c.want, names)
+			}
+		})
+	}
+}
@@ -4,8 +4,11 @@
 package commands
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/openfaas/faas-cli/test"
@@ -78,3 +81,287 @@ func Test_list_errors(t *testing.T) {
 		t.Fatal("No error found while testing missing yaml")
 	}
 }
+
+func Test_list_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer s.Close()
+	defer close(release)
+
+	resetForTest()
+	defer resetForTest()
+
+	faasCmd.SetArgs([]string{
+		"list",
+		"--gateway=" + s.URL,
+		"--timeout=50ms",
+	})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != "gateway did not respond within 50ms" {
+		t.Errorf("want a timeout error, got %v", err)
+	}
+}
+
+func Test_list_OutputJSON(t *testing.T) {
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody: []types.FunctionStatus{
+				{Name: "nodeinfo", Image: "functions/nodeinfo:latest", Replicas: 2, InvocationCount: 10, Labels: &map[string]string{"team": "cli"}},
+				{Name: "figlet", Image: "functions/figlet:latest", Replicas: 1, InvocationCount: 3},
+			},
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+	defer func() { listOutput = "" }()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"list",
+			"--gateway=" + s.URL,
+			"--output=json",
+		})
+		faasCmd.Execute()
+	})
+
+	want := `[
+  {
+    "name": "figlet",
+    "namespace": "",
+    "image": "functions/figlet:latest",
+    "replicas": 1,
+    "availableReplicas": 0,
+    "invocationCount": 3,
+    "labels": {}
+  },
+  {
+    "name": "nodeinfo",
+    "namespace": "",
+    "image": "functions/nodeinfo:latest",
+    "replicas": 2,
+    "availableReplicas": 0,
+    "invocationCount": 10,
+    "labels": {
+      "team": "cli"
+    }
+  }
+]
+`
+	if stdOut != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, stdOut)
+	}
+}
+
+func Test_list_InvalidOutput(t *testing.T) {
+	resetForTest()
+	defer func() { listOutput = "" }()
+
+	faasCmd.SetArgs([]string{"list", "--output=table"})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != "--output must be json or yaml, not: table" {
+		t.Errorf("want --output error, got %v", err)
+	}
+}
+
+// allNamespacesServer serves a namespace list and the functions in each
+// namespace, the list calls can arrive in any order
+func allNamespacesServer(t *testing.T) *httptest.Server {
+	byNamespace := map[string][]types.FunctionStatus{
+		"openfaas-fn": {{Name: "figlet", Namespace: "openfaas-fn", Replicas: 1}},
+		"dev":         {{Name: "nodeinfo", Namespace: "dev", Replicas: 2}, {Name: "env", Namespace: "dev"}},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/system/namespaces":
+			json.NewEncoder(w).Encode([]string{"openfaas-fn", "dev"})
+		case "/system/functions":
+			json.NewEncoder(w).Encode(byNamespace[r.URL.Query().Get("namespace")])
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func Test_list_AllNamespaces(t *testing.T) {
+	s := allNamespacesServer(t)
+	defer s.Close()
+
+	resetForTest()
+	defer func() { listAllNamespaces = false }()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"list", "--gateway=" + s.URL, "--all-namespaces"})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	lines := strings.Split(strings.TrimSpace(stdOut), "\n")
+	want := []string{"Namespace", `dev\s+env`, `dev\s+nodeinfo`, `openfaas-fn\s+figlet`}
+	if len(lines) != len(want) {
+		t.Fatalf("want %d lines, got:\n%s", len(want), stdOut)
+	}
+	for i, line := range lines {
+		if !regexp.MustCompile("^" + want[i]).MatchString(line) {
+			t.Errorf("want line %d to match %q, got %q", i, want[i], line)
+		}
+	}
+}
+
+func Test_list_AllNamespaces_OutputJSON(t *testing.T) {
+	s := allNamespacesServer(t)
+	defer s.Close()
+
+	resetForTest()
+	defer func() {
+		listAllNamespaces = false
+		listOutput = ""
+	}()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"list", "--gateway=" + s.URL, "--all-namespaces", "--output=json"})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var got map[string][]listedFunction
+	if err := json.Unmarshal([]byte(stdOut), &got); err != nil {
+		t.Fatalf("want JSON keyed by namespace, got %q: %s", stdOut, err)
+	}
+
+	if len(got) != 2 || len(got["dev"]) != 2 || got["dev"][0].Name != "env" || got["openfaas-fn"][0].Name != "figlet" {
+		t.Errorf("unexpected functions by namespace: %+v", got)
+	}
+}
+
+func Test_list_AllNamespaces_WithNamespace(t *testing.T) {
+	resetForTest()
+	defer func() {
+		listAllNamespaces = false
+		functionNamespace = ""
+	}()
+
+	faasCmd.SetArgs([]string{"list", "--all-namespaces", "--namespace=dev"})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != "--namespace and --all-namespaces can't be used together" {
+		t.Errorf("want an error for --namespace with --all-namespaces, got %v", err)
+	}
+}
+
+func Test_list_Format(t *testing.T) {
+	s := allNamespacesServer(t)
+	defer s.Close()
+
+	resetForTest()
+	defer func() {
+		listAllNamespaces = false
+		listFormat = ""
+	}()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"list", "--gateway=" + s.URL, "--all-namespaces", `--format={{.Namespace}}/{{.Name}}\t{{.Replicas}}`})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	want := "dev/env\t0\ndev/nodeinfo\t2\nopenfaas-fn/figlet\t1\n"
+	if stdOut != want {
+		t.Errorf("want:\n%q\ngot:\n%q", want, stdOut)
+	}
+}
+
+func Test_list_FormatErrors(t *testing.T) {
+	s := allNamespacesServer(t)
+	defer s.Close()
+
+	cases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "with --output",
+			args: []string{"--format={{.Name}}", "--output=json"},
+			want: "--output and --format can't be used together",
+		},
+		{
+			name: "bad template",
+			args: []string{"--format={{.Name"},
+			want: "--format is not a valid template: ",
+		},
+		{
+			name: "unknown field",
+			args: []string{"--format={{.Owner}}", "--namespace=dev"},
+			want: "unable to execute the --format template: ",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resetForTest()
+			defer func() {
+				listFormat = ""
+				listOutput = ""
+				functionNamespace = ""
+			}()
+
+			var err error
+			stdOut := test.CaptureStdout(func() {
+				faasCmd.SetArgs(append([]string{"list", "--gateway=" + s.URL}, c.args...))
+				err = faasCmd.Execute()
+			})
+
+			if err == nil || !strings.HasPrefix(err.Error(), c.want) {
+				t.Errorf("want error starting %q, got %v", c.want, err)
+			}
+			if strings.Contains(stdOut, "nodeinfo") {
+				t.Errorf("want no functions printed, got:\n%s", stdOut)
+			}
+		})
+	}
+}
+
+func Test_sortFunctions(t *testing.T) {
+	functions := []types.FunctionStatus{
+		{Name: "nodeinfo", InvocationCount: 10, Replicas: 1},
+		{Name: "figlet", InvocationCount: 50, Replicas: 3},
+		{Name: "env", InvocationCount: 10, Replicas: 2},
+	}
+
+	cases := []struct {
+		by      string
+		reverse bool
+		want    []string
+	}{
+		{by: "name", want: []string{"env", "figlet", "nodeinfo"}},
+		{by: "name", reverse: true, want: []string{"nodeinfo", "figlet", "env"}},
+		{by: "invocations", want: []string{"env", "nodeinfo", "figlet"}},
+		{by: "invocations", reverse: true, want: []string{"figlet", "nodeinfo", "env"}},
+		{by: "replicas", want: []string{"nodeinfo", "env", "figlet"}},
+	}
+
+	for _, c := range cases {
+		sortFunctions(functions, c.by, c.reverse)
+
+		var names []string
+		for _, function := range functions {
+			names = append(names, function.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(c.want, ",") {
+			t.Errorf("--sort %s --reverse=%v: want %v, got %v", c.by, c.reverse, c.want, names)
+		}
+	}
+}
@@ -4,13 +4,16 @@
 package commands
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/docker/docker/pkg/term"
 	"github.com/openfaas/faas-cli/proxy"
 
 	"github.com/openfaas/faas-cli/config"
@@ -25,25 +28,47 @@ var (
 
 func init() {
 	loginCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
-	loginCmd.Flags().StringVarP(&username, "username", "u", "admin", "Gateway username")
+	loginCmd.Flags().StringVarP(&username, "username", "u", "admin", "Gateway username, or set "+usernameEnvironment)
 	loginCmd.Flags().StringVarP(&password, "password", "p", "", "Gateway password")
 	loginCmd.Flags().BoolVarP(&passwordStdin, "password-stdin", "s", false, "Reads the gateway password from stdin")
-	loginCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
+	loginCmd.Flags().StringVarP(&token, "token", "k", "", "Save a bearer token obtained elsewhere, such as from an identity provider, in place of a username and password")
 
 	faasCmd.AddCommand(loginCmd)
 }
 
 var loginCmd = &cobra.Command{
-	Use:   `login [--username admin|USERNAME] [--password PASSWORD] [--gateway GATEWAY_URL] [--tls-no-verify]`,
+	Use: `login [--username admin|USERNAME] [--password PASSWORD] [--gateway GATEWAY_URL] [--tls-no-verify]
+  faas-cli login --token TOKEN|--token-file PATH [--gateway GATEWAY_URL]`,
 	Short: "Log in to OpenFaaS gateway",
-	Long:  "Log in to OpenFaaS gateway.\nIf no gateway is specified, the default value will be used.",
+	Long: `Log in to OpenFaaS gateway.
+If no gateway is specified, the default value will be used.
+
+The password is read from "--password-stdin", then ` + passwordEnvironment + `, and is
+prompted for without being echoed when STDIN is a terminal. The username is
+read from ` + usernameEnvironment + ` when "--username" is not given. Prefer these to
+"--password", which is kept in the history of the shell.
+
+A bearer token obtained elsewhere, such as from the portal of an identity
+provider, is saved for the gateway with "--token" or "--token-file" in the same
+way as by "faas-cli auth", once the gateway accepts it.`,
 	Example: `  cat ~/faas_pass.txt | faas-cli login -u user --password-stdin
   echo $PASSWORD | faas-cli login -s  --gateway https://openfaas.mydomain.com
-  faas-cli login -u user -p password`,
+  OPENFAAS_USERNAME=user OPENFAAS_PASSWORD=$PASSWORD faas-cli login
+  faas-cli login -u user
+  faas-cli login --token-file ./token.txt --gateway https://openfaas.mydomain.com`,
 	RunE: runLogin,
 }
 
 func runLogin(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().Changed("token") || len(tokenFromFile) > 0 {
+		return runLoginToken(cmd)
+	}
+
+	if !cmd.Flags().Changed("username") {
+		if envUsername := os.Getenv(usernameEnvironment); len(envUsername) > 0 {
+			username = envUsername
+		}
+	}
 
 	if len(username) == 0 {
 		return fmt.Errorf("must provide --username or -u")
@@ -65,24 +90,35 @@ func runLogin(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("must provide --username with --password-stdin")
 		}
 
-		passwordStdin, err := ioutil.ReadAll(os.Stdin)
+		passwordStdin, err := ioutil.ReadAll(cmd.InOrStdin())
 		if err != nil {
 			return err
 		}
 
 		password = strings.TrimSpace(string(passwordStdin))
+	} else if len(password) == 0 {
+		password = os.Getenv(passwordEnvironment)
+
+		if len(password) == 0 {
+			prompted, err := promptPassword(cmd.InOrStdin())
+			if err != nil {
+				return fmt.Errorf("unable to read the password: %s", err)
+			}
+			password = prompted
+		}
 	}
 
 	password = strings.TrimSpace(password)
 	if len(password) == 0 {
-		return fmt.Errorf("must provide a non-empty password via --password or --password-stdin")
+		return fmt.Errorf("must provide a non-empty password via --password-stdin, %s or --password", passwordEnvironment)
 	}
 
 	fmt.Println("Calling the OpenFaaS server to validate the credentials...")
 
 	gateway = getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
 
-	if err := validateLogin(gateway, username, password); err != nil {
+	basicAuth := &BasicAuth{username: username, password: password}
+	if err := validateLogin(gateway, basicAuth, "unable to login, either username or password is incorrect"); err != nil {
 		return err
 	}
 
@@ -105,7 +141,69 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func validateLogin(gatewayURL string, user string, pass string) error {
+// runLoginToken saves the bearer token given by --token or --token-file for
+// the gateway, as an oauth2 token without a refresh token
+func runLoginToken(cmd *cobra.Command) error {
+	if cmd.Flags().Changed("username") || len(password) > 0 || passwordStdin {
+		return fmt.Errorf("--token can't be used with --username, --password or --password-stdin")
+	}
+
+	bearerToken := strings.TrimSpace(token)
+	if len(bearerToken) == 0 {
+		bearerToken = tokenFromFile
+	}
+	if len(bearerToken) == 0 {
+		return fmt.Errorf("must provide a non-empty token via --token or --token-file")
+	}
+
+	fmt.Println("Calling the OpenFaaS server to validate the token...")
+
+	gateway = getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
+
+	if err := validateLogin(gateway, &BearerToken{token: bearerToken}, "unable to login, the token was refused"); err != nil {
+		return err
+	}
+
+	if err := config.UpdateAuthConfig(gateway, bearerToken, config.Oauth2AuthType); err != nil {
+		return err
+	}
+	fmt.Println("token saved for", gateway)
+
+	return nil
+}
+
+// promptPassword reads the password from a terminal without echoing it,
+// nothing is read when in is not a terminal
+func promptPassword(in io.Reader) (string, error) {
+	fd, isTerminal := term.GetFdInfo(in)
+	if !isTerminal {
+		return "", nil
+	}
+
+	state, err := term.SaveState(fd)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	if err := term.DisableEcho(fd, state); err != nil {
+		return "", err
+	}
+	defer func() {
+		term.RestoreTerminal(fd, state)
+		fmt.Fprintln(os.Stderr)
+	}()
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return line, nil
+}
+
+// validateLogin lists the functions with the credentials, unauthorized is
+// the error given when the gateway refuses them
+func validateLogin(gatewayURL string, auth proxy.ClientAuth, unauthorized string) error {
 	timeout := time.Duration(5 * time.Second)
 	client := proxy.MakeHTTPClient(&timeout, tlsInsecure)
 
@@ -114,7 +212,7 @@ func validateLogin(gatewayURL string, user string, pass string) error {
 		return fmt.Errorf("invalid URL: %s", gatewayURL)
 	}
 
-	req.SetBasicAuth(user, pass)
+	auth.Set(req)
 	res, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("cannot connect to OpenFaaS on URL: %s. %v", gatewayURL, err)
@@ -132,7 +230,7 @@ func validateLogin(gatewayURL string, user string, pass string) error {
 	case http.StatusOK:
 		return nil
 	case http.StatusUnauthorized:
-		return fmt.Errorf("unable to login, either username or password is incorrect")
+		return &proxy.HTTPError{StatusCode: res.StatusCode, Message: unauthorized}
 	default:
 		bytesOut, err := ioutil.ReadAll(res.Body)
 		if err == nil {
@@ -0,0 +1,178 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/test"
+)
+
+// loginServer accepts the username user and the password pass
+func loginServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func Test_login_Environment(t *testing.T) {
+	s := loginServer()
+	defer s.Close()
+
+	config.DefaultDir, _ = ioutil.TempDir("", "faas-cli-login-test")
+	config.DefaultFile = "config.yml"
+	defer os.RemoveAll(config.DefaultDir)
+
+	os.Setenv(usernameEnvironment, "user")
+	os.Setenv(passwordEnvironment, "pass")
+	defer os.Unsetenv(usernameEnvironment)
+	defer os.Unsetenv(passwordEnvironment)
+
+	resetForTest()
+	defer func() {
+		username = "admin"
+		password = ""
+		faasCmd.SetIn(nil)
+	}()
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetIn(strings.NewReader(""))
+		faasCmd.SetArgs([]string{"login", "--gateway=" + s.URL})
+		err = faasCmd.Execute()
+	})
+
+	if err != nil {
+		t.Fatalf("want the credentials from the environment, got %s", err)
+	}
+	if !strings.Contains(stdOut, "credentials saved for user") {
+		t.Errorf("want the credentials saved, got %q", stdOut)
+	}
+	if strings.Contains(stdOut, "WARNING! Using --password") {
+		t.Errorf("want no warning for %s, got %q", passwordEnvironment, stdOut)
+	}
+}
+
+func Test_login_PasswordStdin(t *testing.T) {
+	s := loginServer()
+	defer s.Close()
+
+	config.DefaultDir, _ = ioutil.TempDir("", "faas-cli-login-test")
+	config.DefaultFile = "config.yml"
+	defer os.RemoveAll(config.DefaultDir)
+
+	os.Setenv(usernameEnvironment, "user")
+	os.Setenv(passwordEnvironment, "wrong")
+	defer os.Unsetenv(usernameEnvironment)
+	defer os.Unsetenv(passwordEnvironment)
+
+	resetForTest()
+	defer func() {
+		username = "admin"
+		password = ""
+		passwordStdin = false
+		faasCmd.SetIn(nil)
+	}()
+
+	var err error
+	test.CaptureStdout(func() {
+		faasCmd.SetIn(strings.NewReader("pass\n"))
+		faasCmd.SetArgs([]string{"login", "--gateway=" + s.URL, "--password-stdin"})
+		err = faasCmd.Execute()
+	})
+
+	if err != nil {
+		t.Errorf("want the password from STDIN in place of %s, got %s", passwordEnvironment, err)
+	}
+}
+
+func Test_login_NoPassword(t *testing.T) {
+	resetForTest()
+	defer func() {
+		faasCmd.SetIn(nil)
+	}()
+
+	faasCmd.SetIn(strings.NewReader(""))
+	faasCmd.SetArgs([]string{"login", "--gateway=http://127.0.0.1:8080"})
+	err := faasCmd.Execute()
+
+	want := "must provide a non-empty password via --password-stdin, OPENFAAS_PASSWORD or --password"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+func Test_login_Token(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer my-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	config.DefaultDir, _ = ioutil.TempDir("", "faas-cli-login-test")
+	config.DefaultFile = "config.yml"
+	defer os.RemoveAll(config.DefaultDir)
+
+	resetForTest()
+	defer func() {
+		loginCmd.Flags().Lookup("token").Changed = false
+	}()
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"login", "--gateway=" + s.URL, "--token=my-token"})
+		err = faasCmd.Execute()
+	})
+
+	if err != nil {
+		t.Fatalf("want the token saved, got %s", err)
+	}
+	if !strings.Contains(stdOut, "token saved for "+s.URL) {
+		t.Errorf("want the token saved, got %q", stdOut)
+	}
+
+	authConfig, err := config.LookupAuthConfig(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authConfig.Auth != config.Oauth2AuthType || authConfig.Token != "my-token" {
+		t.Errorf("want a bearer token in the config, got %+v", authConfig)
+	}
+
+	test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"login", "--gateway=" + s.URL, "--token=other-token"})
+		err = faasCmd.Execute()
+	})
+	if err == nil || err.Error() != "unable to login, the token was refused" {
+		t.Errorf("want the refused token reported, got %v", err)
+	}
+}
+
+func Test_login_EmptyToken(t *testing.T) {
+	resetForTest()
+	defer func() {
+		loginCmd.Flags().Lookup("token").Changed = false
+	}()
+
+	faasCmd.SetArgs([]string{"login", "--gateway=http://127.0.0.1:8080", "--token= "})
+	err := faasCmd.Execute()
+
+	want := "must provide a non-empty token via --token or --token-file"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
@@ -12,32 +12,77 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	logoutAll    bool
+	logoutStrict bool
+)
+
 func init() {
 	logoutCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	logoutCmd.Flags().BoolVar(&logoutAll, "all", false, "Remove the credentials for every gateway")
+	logoutCmd.Flags().BoolVar(&logoutStrict, "strict", false, "Return an error when no credentials were stored")
 
 	faasCmd.AddCommand(logoutCmd)
 }
 
 var logoutCmd = &cobra.Command{
-	Use:     `logout [--gateway GATEWAY_URL]`,
-	Short:   "Log out from OpenFaaS gateway",
-	Long:    "Log out from OpenFaaS gateway.\nIf no gateway is specified, the default local one will be used.",
-	Example: `  faas-cli logout --gateway https://openfaas.mydomain.com`,
-	RunE:    runLogout,
+	Use:   `logout [--gateway GATEWAY_URL] [--all] [--strict]`,
+	Short: "Log out from OpenFaaS gateway",
+	Long:  "Log out from OpenFaaS gateway.\nIf no gateway is specified, the default local one will be used.",
+	Example: `  faas-cli logout --gateway https://openfaas.mydomain.com
+  faas-cli logout --all`,
+	RunE: runLogout,
 }
 
 func runLogout(cmd *cobra.Command, args []string) error {
+	if logoutAll {
+		return runLogoutAll()
+	}
+
 	if len(gateway) == 0 {
 		return fmt.Errorf("gateway cannot be an empty string")
 	}
 	gateway = strings.TrimSpace(gateway)
 	gateway = getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
 
-	err := config.RemoveAuthConfig(gateway)
+	found, err := config.HasAuthConfig(gateway)
 	if err != nil {
 		return err
 	}
+
+	if !found {
+		if logoutStrict {
+			return fmt.Errorf("no credentials stored for %s", gateway)
+		}
+		fmt.Println("no credentials stored for", gateway)
+		return nil
+	}
+
+	if err := config.RemoveAuthConfig(gateway); err != nil {
+		return err
+	}
 	fmt.Println("credentials removed for", gateway)
 
 	return nil
 }
+
+func runLogoutAll() error {
+	removed, err := config.RemoveAllAuthConfigs()
+	if err != nil {
+		return err
+	}
+
+	if len(removed) == 0 {
+		if logoutStrict {
+			return fmt.Errorf("no credentials stored")
+		}
+		fmt.Println("no credentials stored")
+		return nil
+	}
+
+	for _, gatewayURL := range removed {
+		fmt.Println("credentials removed for", gatewayURL)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_runLogout_NothingStored_IsIdempotent(t *testing.T) {
+	config.DefaultDir, _ = ioutil.TempDir("", "faas-cli-logout-test")
+	config.DefaultFile = "config.yml"
+	defer func() {
+		logoutAll = false
+		logoutStrict = false
+	}()
+
+	gateway = "http://127.0.0.1:8080"
+
+	stdOut := test.CaptureStdout(func() {
+		if err := runLogout(nil, nil); err != nil {
+			t.Errorf("want no error, got %s", err)
+		}
+	})
+
+	if !strings.Contains(stdOut, "no credentials stored for http://127.0.0.1:8080") {
+		t.Errorf("want message for missing credentials, got %q", stdOut)
+	}
+}
+
+func Test_runLogout_NothingStored_Strict(t *testing.T) {
+	config.DefaultDir, _ = ioutil.TempDir("", "faas-cli-logout-test")
+	config.DefaultFile = "config.yml"
+	defer func() {
+		logoutAll = false
+		logoutStrict = false
+	}()
+
+	gateway = "http://127.0.0.1:8080"
+	logoutStrict = true
+
+	err := runLogout(nil, nil)
+	if err == nil || err.Error() != "no credentials stored for http://127.0.0.1:8080" {
+		t.Errorf("want error for missing credentials, got %v", err)
+	}
+}
+
+func Test_runLogout_All(t *testing.T) {
+	config.DefaultDir, _ = ioutil.TempDir("", "faas-cli-logout-test")
+	config.DefaultFile = "config.yml"
+	defer func() {
+		logoutAll = false
+		logoutStrict = false
+	}()
+
+	config.UpdateAuthConfig("http://127.0.0.1:8080", "token1", config.Oauth2AuthType)
+	config.UpdateAuthConfig("https://openfaas.example.com", "token2", config.Oauth2AuthType)
+	logoutAll = true
+
+	stdOut := test.CaptureStdout(func() {
+		if err := runLogout(nil, nil); err != nil {
+			t.Errorf("want no error, got %s", err)
+		}
+	})
+
+	for _, gatewayURL := range []string{"http://127.0.0.1:8080", "https://openfaas.example.com"} {
+		if !strings.Contains(stdOut, "credentials removed for "+gatewayURL) {
+			t.Errorf("want %s to be removed, got %q", gatewayURL, stdOut)
+		}
+	}
+}
@@ -5,11 +5,11 @@ package commands
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"time"
 
 	"github.com/openfaas/faas-cli/flags"
@@ -26,9 +26,10 @@ var (
 
 type logFlags struct {
 	instance        string
-	since           time.Duration
+	since           flags.SinceFlag
 	sinceTime       flags.TimestampFlag
 	follow          bool
+	noReconnect     bool
 	tail            int
 	token           string
 	logFormat       flags.LogFormat
@@ -47,12 +48,18 @@ var functionLogsCmd = &cobra.Command{
 	Use:     `logs <NAME> [--tls-no-verify] [--gateway]`,
 	Aliases: []string{"ls"},
 	Short:   "Tail logs from your functions",
-	Long:    "Tail logs from your functions",
+	Long: `Tail logs from your functions, new logs are printed until the end of the
+request, up to 30s. Pass --follow to reconnect each time the stream ends, until
+interrupted, or --follow=false to print only the logs which were already written.`,
 	Example: `faas-cli logs echo
+faas-cli logs echo --follow
 faas-cli logs echo --tail=5
 faas-cli logs echo --follow=false
+faas-cli logs echo --no-reconnect
+faas-cli logs echo --output json | jq -r .text
 faas-cli logs echo --follow=false --since=10m
-faas-cli logs echo --follow=false --since=2010-01-01T00:00:00Z`,
+faas-cli logs echo --follow=false --since=2010-01-01T00:00:00Z
+faas-cli logs echo --follow=false --since-time=2010-01-01T00:00:00Z`,
 	Args:    cobra.MaximumNArgs(1),
 	RunE:    runLogs,
 	PreRunE: noopPreRunCmd,
@@ -73,16 +80,16 @@ func initLogCmdFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
 	cmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function")
 
-	cmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
-
-	cmd.Flags().DurationVar(&logFlagValues.since, "since", 0*time.Second, "return logs newer than a relative duration like 5s")
+	cmd.Flags().Var(&logFlagValues.since, "since", "return logs newer than a relative duration like 5s, or a RFC3339 timestamp")
 	cmd.Flags().Var(&logFlagValues.sinceTime, "since-time", "include logs since the given timestamp (RFC3339)")
 	cmd.Flags().IntVar(&logFlagValues.tail, "tail", -1, "number of recent log lines file to display. Defaults to -1, unlimited if <=0")
-	cmd.Flags().BoolVar(&logFlagValues.follow, "follow", true, "continue printing new logs until the end of the request, up to 30s")
+	cmd.Flags().BoolVar(&logFlagValues.follow, "follow", true, "continue printing new logs until the end of the request, up to 30s, pass --follow to reconnect until interrupted")
+	cmd.Flags().BoolVar(&logFlagValues.noReconnect, "no-reconnect", false, "exit when the stream of an explicit --follow ends instead of reconnecting")
 	cmd.Flags().StringVarP(&logFlagValues.token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
 
 	logFlagValues.timeFormat = flags.TimeFormat(time.RFC3339)
 	cmd.Flags().Var(&logFlagValues.logFormat, "format", "output format.  Note that JSON format will always include all log message keys (plain|key-value|json)")
+	cmd.Flags().VarP(&logFlagValues.logFormat, "output", "o", "alias for --format, json writes one object per line with the timestamp as RFC3339 in UTC")
 	cmd.Flags().Var(&logFlagValues.timeFormat, "time-format", "string format for the timestamp, any value go time format string is allowed, empty will not print the timestamp")
 	cmd.Flags().BoolVar(&logFlagValues.includeName, "name", false, "print the function name")
 	cmd.Flags().BoolVar(&logFlagValues.includeInstance, "instance", false, "print the function instance name/id")
@@ -101,19 +108,47 @@ func runLogs(cmd *cobra.Command, args []string) error {
 	transport := getLogStreamingTransport(tlsInsecure)
 	cliClient := proxy.NewClient(cliAuth, gatewayAddress, transport, nil)
 
+	formatter := GetLogFormatter(string(logFlagValues.logFormat))
+	printMsg := func(logMsg logs.Message) {
+		fmt.Fprintln(os.Stdout, formatter(logMsg, logFlagValues.timeFormat.String(), logFlagValues.includeName, logFlagValues.includeInstance))
+	}
+
+	if reconnectLogs(cmd) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		defer signal.Stop(interrupt)
+		go func() {
+			select {
+			case <-interrupt:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		return followLogs(ctx, cliClient, logRequest, printMsg)
+	}
+
 	logEvents, err := cliClient.GetLogs(context.Background(), logRequest)
 	if err != nil {
 		return err
 	}
 
-	formatter := GetLogFormatter(string(logFlagValues.logFormat))
 	for logMsg := range logEvents {
-		fmt.Fprintln(os.Stdout, formatter(logMsg, logFlagValues.timeFormat.String(), logFlagValues.includeName, logFlagValues.includeInstance))
+		printMsg(logMsg)
 	}
 
 	return nil
 }
 
+// reconnectLogs is true when the stream should be reopened each time it ends,
+// which needs --follow to be given, as it is on by default for a single request
+func reconnectLogs(cmd *cobra.Command) bool {
+	return logFlagValues.follow && cmd.Flags().Changed("follow") && !logFlagValues.noReconnect
+}
+
 func logRequestFromFlags(cmd *cobra.Command, args []string) logs.Request {
 
 	ns, err := cmd.Flags().GetString("namespace")
@@ -130,24 +165,20 @@ func logRequestFromFlags(cmd *cobra.Command, args []string) logs.Request {
 	}
 }
 
-func sinceValue(t time.Time, d time.Duration) *time.Time {
+func sinceValue(t time.Time, since flags.SinceFlag) *time.Time {
 	if !t.IsZero() {
 		return &t
 	}
 
-	if d.String() != "0s" {
-		ts := nowFunc().Add(-1 * d)
-		return &ts
-	}
-	return nil
+	return since.AsTime(nowFunc())
 }
 
 func getLogStreamingTransport(tlsInsecure bool) http.RoundTripper {
-	if tlsInsecure {
+	if tlsConfig := proxy.TLSClientConfig(tlsInsecure); tlsConfig != nil {
 		tr := &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
+			Proxy: proxy.HTTPProxy,
 		}
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: tlsInsecure}
+		tr.TLSClientConfig = tlsConfig
 
 		return tr
 	}
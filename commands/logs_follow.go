@@ -0,0 +1,83 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/morikuni/aec"
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-provider/logs"
+)
+
+var (
+	// reconnectDelay is the wait before the first reconnect, doubled for each
+	// failed attempt up to maxReconnectDelay
+	reconnectDelay    = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+type logStreamer interface {
+	GetLogs(ctx context.Context, params logs.Request) (<-chan logs.Message, error)
+}
+
+// followLogs prints the log stream and reconnects when the stream ends or the
+// gateway can't be reached, resuming from the timestamp of the last message.
+// Messages already printed for that timestamp are skipped so that none are
+// repeated. It returns when ctx is cancelled or on an error which is not transient.
+func followLogs(ctx context.Context, client logStreamer, request logs.Request, print func(logs.Message)) error {
+	var lastSeen time.Time
+	seen := map[string]bool{}
+	delay := reconnectDelay
+
+	for {
+		logEvents, err := client.GetLogs(ctx, request)
+		if err != nil && !proxy.IsConnectionError(err) && !proxy.IsRetryable(err) {
+			return err
+		}
+
+		if err == nil {
+			delay = reconnectDelay
+			for msg := range logEvents {
+				if msg.Timestamp.Before(lastSeen) || seen[logKey(msg)] {
+					continue
+				}
+				if msg.Timestamp.After(lastSeen) {
+					lastSeen = msg.Timestamp
+					seen = map[string]bool{}
+				}
+				seen[logKey(msg)] = true
+				print(msg)
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		fmt.Fprintln(os.Stderr, aec.Faint.Apply("reconnecting..."))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+
+		if !lastSeen.IsZero() {
+			since := lastSeen
+			request.Since = &since
+			request.Tail = 0
+		}
+	}
+}
+
+func logKey(msg logs.Message) string {
+	return msg.Instance + "\x00" + msg.Text
+}
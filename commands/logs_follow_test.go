@@ -0,0 +1,89 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/openfaas/faas-provider/logs"
+)
+
+type fakeStreamer struct {
+	streams  [][]logs.Message
+	requests []logs.Request
+	err      error
+}
+
+func (f *fakeStreamer) GetLogs(ctx context.Context, params logs.Request) (<-chan logs.Message, error) {
+	f.requests = append(f.requests, params)
+	if len(f.streams) == 0 {
+		return nil, f.err
+	}
+
+	stream := make(chan logs.Message, len(f.streams[0]))
+	for _, msg := range f.streams[0] {
+		stream <- msg
+	}
+	close(stream)
+	f.streams = f.streams[1:]
+	return stream, nil
+}
+
+func Test_followLogs_ResumesWithoutDuplicates(t *testing.T) {
+	reconnectDelay = time.Millisecond
+	defer func() { reconnectDelay = time.Second }()
+
+	t1 := time.Date(2020, time.January, 1, 0, 0, 1, 0, time.UTC)
+	t2 := t1.Add(500 * time.Millisecond)
+	t3 := t1.Add(2 * time.Second)
+
+	streamer := &fakeStreamer{
+		streams: [][]logs.Message{
+			{{Text: "one", Timestamp: t1}, {Text: "two", Timestamp: t2}},
+			// the gateway resumes from the start of the second
+			{{Text: "one", Timestamp: t1}, {Text: "two", Timestamp: t2}, {Text: "three", Timestamp: t3}},
+		},
+		err: fmt.Errorf("unauthorized access"),
+	}
+
+	var printed []string
+	err := followLogs(context.Background(), streamer, logs.Request{Name: "figlet", Follow: true, Tail: 5}, func(msg logs.Message) {
+		printed = append(printed, msg.Text)
+	})
+
+	if err == nil || err.Error() != "unauthorized access" {
+		t.Errorf("want the non-transient error returned, got %v", err)
+	}
+
+	if fmt.Sprint(printed) != "[one two three]" {
+		t.Errorf("want each line once, got %v", printed)
+	}
+
+	if len(streamer.requests) != 3 {
+		t.Fatalf("want 3 requests, got %d", len(streamer.requests))
+	}
+	resumed := streamer.requests[1]
+	if resumed.Since == nil || !resumed.Since.Equal(t2) || resumed.Tail != 0 {
+		t.Errorf("want the reconnect to resume from %s without a tail, got %s", t2, resumed)
+	}
+}
+
+func Test_followLogs_StopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	streamer := &fakeStreamer{streams: [][]logs.Message{{{Text: "one"}}}}
+
+	err := followLogs(ctx, streamer, logs.Request{Name: "figlet", Follow: true}, func(msg logs.Message) {
+		cancel()
+	})
+
+	if err != nil {
+		t.Errorf("want no error after cancelling, got %s", err)
+	}
+	if len(streamer.requests) != 1 {
+		t.Errorf("want no reconnect after cancelling, got %d requests", len(streamer.requests))
+	}
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/openfaas/faas-cli/flags"
 	"github.com/openfaas/faas-provider/logs"
@@ -23,11 +24,27 @@ func GetLogFormatter(name string) LogFormatter {
 	}
 }
 
+// jsonLogMessage is written for each line with the JSON format, the field
+// names are kept stable for log shippers
+type jsonLogMessage struct {
+	Timestamp string `json:"timestamp"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Instance  string `json:"instance"`
+	Text      string `json:"text"`
+}
+
 // JSONFormatMessage is a JSON formatting for log messages, the options are ignored and the entire log
-// message json serialized
+// message json serialized with the timestamp in UTC as RFC3339 and the trailing newline removed
 func JSONFormatMessage(msg logs.Message, timeFormat string, includeName, includeInstance bool) string {
 	// error really can't happen here because of how simple the msg object is
-	b, _ := json.Marshal(msg)
+	b, _ := json.Marshal(jsonLogMessage{
+		Timestamp: msg.Timestamp.UTC().Format(time.RFC3339Nano),
+		Name:      msg.Name,
+		Namespace: msg.Namespace,
+		Instance:  msg.Instance,
+		Text:      strings.TrimRight(msg.Text, "\n"),
+	})
 	return string(b)
 }
 
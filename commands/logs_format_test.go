@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"encoding/json"
 	"strings"
 	"testing"
 	"time"
@@ -10,14 +9,14 @@ import (
 )
 
 func Test_JSONLogFormatter(t *testing.T) {
-	now := time.Now()
+	ts := time.Date(2009, time.November, 10, 23, 0, 0, 500, time.FixedZone("CET", 3600))
 	msg := logs.Message{
-		Timestamp: now,
+		Timestamp: ts,
 		Name:      "test-func",
 		Instance:  "123test",
 		Text:      "test message\n",
 	}
-	msgJSON, _ := json.Marshal(msg)
+	msgJSON := `{"timestamp":"2009-11-10T22:00:00.0000005Z","name":"test-func","instance":"123test","text":"test message"}`
 
 	cases := []struct {
 		name            string
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/openfaas/faas-cli/flags"
 	"github.com/openfaas/faas-provider/logs"
 )
 
@@ -26,6 +27,7 @@ func Test_logsCmdFlagParsing(t *testing.T) {
 		{"can limit number of messages returned", []string{"funcFoo", "--tail=5"}, logs.Request{Name: "funcFoo", Follow: true, Tail: 5}},
 		{"can set timestamp to send logs since using duration", []string{"funcFoo", "--since=5m"}, logs.Request{Name: "funcFoo", Follow: true, Tail: -1, Since: &fiveMinAgo}},
 		{"can set timestamp to send logs since using timestamp", []string{"funcFoo", "--since-time=" + fiveMinAgoStr}, logs.Request{Name: "funcFoo", Follow: true, Tail: -1, Since: &fiveMinAgo}},
+		{"can set timestamp to send logs since using a timestamp for since", []string{"funcFoo", "--since=" + fiveMinAgoStr}, logs.Request{Name: "funcFoo", Follow: true, Tail: -1, Since: &fiveMinAgo}},
 	}
 
 	for _, s := range scenarios {
@@ -43,6 +45,45 @@ func Test_logsCmdFlagParsing(t *testing.T) {
 	}
 }
 
+func Test_reconnectLogs(t *testing.T) {
+	scenarios := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"follow by default is a single request", []string{"funcFoo"}, false},
+		{"explicit follow reconnects", []string{"funcFoo", "--follow"}, true},
+		{"explicit follow with no-reconnect", []string{"funcFoo", "--follow", "--no-reconnect"}, false},
+		{"follow disabled", []string{"funcFoo", "--follow=false"}, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			functionLogsCmd.ResetFlags()
+			initLogCmdFlags(functionLogsCmd)
+			if err := functionLogsCmd.ParseFlags(s.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := reconnectLogs(functionLogsCmd); got != s.want {
+				t.Errorf("want reconnect %t, got %t", s.want, got)
+			}
+		})
+	}
+}
+
 func strP(s string) *string {
 	return &s
 }
+
+func Test_logsCmdOutputAlias(t *testing.T) {
+	functionLogsCmd.ResetFlags()
+	initLogCmdFlags(functionLogsCmd)
+
+	if err := functionLogsCmd.ParseFlags([]string{"funcFoo", "--output=json"}); err != nil {
+		t.Fatal(err)
+	}
+	if logFlagValues.logFormat != flags.JSONLogFormat {
+		t.Errorf("want --output to set the json format, got %s", logFlagValues.logFormat)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	namespaceListCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	namespaceListCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
+	namespaceListCmd.Flags().StringVarP(&namespacesOutput, "output", "o", "", "Output format, only json is supported, instead of text")
+
+	namespaceCmd.AddCommand(namespaceListCmd)
+	faasCmd.AddCommand(namespaceCmd)
+}
+
+var namespaceCmd = &cobra.Command{
+	Use:   `namespace`,
+	Short: "OpenFaaS namespace commands",
+	Long:  "Manage the namespaces functions are deployed to",
+}
+
+var namespaceListCmd = &cobra.Command{
+	Use:     `list [--gateway GATEWAY_URL] [--output json]`,
+	Aliases: []string{"ls"},
+	Short:   "List OpenFaaS namespaces",
+	Long:    `Lists OpenFaaS namespaces either on a local or remote gateway, the same as the namespaces command`,
+	Example: `  faas-cli namespace list
+  faas-cli namespace list --output json`,
+	RunE: runNamespaces,
+}
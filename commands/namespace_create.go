@@ -0,0 +1,58 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/spf13/cobra"
+)
+
+var namespaceCreateCmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Create a namespace",
+	Long:  `Create a namespace on the gateway for functions to be deployed to`,
+	Example: `  faas-cli namespace create dev
+  faas-cli namespace create dev --gateway=http://127.0.0.1:8080`,
+	RunE:    runNamespaceCreate,
+	PreRunE: preRunNamespaceName,
+}
+
+func init() {
+	namespaceCreateCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	namespaceCreateCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
+	namespaceCmd.AddCommand(namespaceCreateCmd)
+}
+
+func preRunNamespaceName(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("namespace name required")
+	}
+
+	if len(args) > 1 {
+		return fmt.Errorf("too many values for namespace name")
+	}
+	return nil
+}
+
+func runNamespaceCreate(cmd *cobra.Command, args []string) error {
+	gatewayAddress := getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
+
+	if msg := checkTLSInsecure(gatewayAddress, tlsInsecure); len(msg) > 0 {
+		fmt.Println(msg)
+	}
+
+	cliAuth := NewCLIAuth(token, gatewayAddress)
+	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
+	client := proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
+	if err := client.CreateNamespace(context.Background(), args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created namespace: %s\n", args[0])
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_namespaceCreate(t *testing.T) {
+	resetForTest()
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodPost,
+			Uri:                "/system/namespace",
+			ResponseStatusCode: http.StatusCreated,
+		},
+	})
+	defer s.Close()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"namespace", "create", "dev", "--gateway=" + s.URL})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if !strings.Contains(stdOut, "Created namespace: dev") {
+		t.Errorf("want the namespace to be created, got %q", stdOut)
+	}
+}
+
+func Test_namespaceCreate_NameRequired(t *testing.T) {
+	resetForTest()
+
+	faasCmd.SetArgs([]string{"namespace", "create"})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != "namespace name required" {
+		t.Errorf("want an error without a name, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/spf13/cobra"
+)
+
+var namespaceDeleteForce bool
+
+var namespaceDeleteCmd = &cobra.Command{
+	Use:     "delete NAME [--force]",
+	Aliases: []string{"rm", "remove"},
+	Short:   "Delete a namespace",
+	Long: `Delete a namespace from the gateway. A namespace which still has functions
+deployed to it is only deleted with --force, which deletes the functions too.`,
+	Example: `  faas-cli namespace delete dev
+  faas-cli namespace delete dev --force`,
+	RunE:    runNamespaceDelete,
+	PreRunE: preRunNamespaceName,
+}
+
+func init() {
+	namespaceDeleteCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	namespaceDeleteCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
+	namespaceDeleteCmd.Flags().BoolVar(&namespaceDeleteForce, "force", false, "Delete the namespace even when it still has functions")
+	namespaceCmd.AddCommand(namespaceDeleteCmd)
+}
+
+func runNamespaceDelete(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	gatewayAddress := getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
+
+	if msg := checkTLSInsecure(gatewayAddress, tlsInsecure); len(msg) > 0 {
+		fmt.Println(msg)
+	}
+
+	cliAuth := NewCLIAuth(token, gatewayAddress)
+	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
+	client := proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
+
+	if !namespaceDeleteForce {
+		functions, err := client.ListFunctions(context.Background(), name)
+		if err != nil {
+			return fmt.Errorf("unable to check for functions in namespace %s: %s, use --force to delete it anyway", name, err)
+		}
+
+		if len(functions) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: namespace %s still has %d function(s):\n", name, len(functions))
+			for _, function := range functions {
+				fmt.Fprintf(os.Stderr, " - %s\n", function.Name)
+			}
+			return fmt.Errorf("namespace %s still has functions, use --force to delete it and its functions", name)
+		}
+	}
+
+	if err := client.DeleteNamespace(context.Background(), name); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted namespace: %s\n", name)
+	return nil
+}
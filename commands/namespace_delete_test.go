@@ -0,0 +1,85 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_namespaceDelete(t *testing.T) {
+	listWith := func(functions []types.FunctionStatus) test.Request {
+		return test.Request{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions?namespace=dev",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       functions,
+		}
+	}
+	deleteRequest := test.Request{
+		Method:             http.MethodDelete,
+		Uri:                "/system/namespace/dev",
+		ResponseStatusCode: http.StatusAccepted,
+	}
+
+	cases := []struct {
+		name     string
+		force    bool
+		requests []test.Request
+		wantErr  string
+	}{
+		{
+			name:     "empty namespace is deleted",
+			requests: []test.Request{listWith([]types.FunctionStatus{}), deleteRequest},
+		},
+		{
+			name:     "namespace with functions is refused",
+			requests: []test.Request{listWith([]types.FunctionStatus{{Name: "figlet"}})},
+			wantErr:  "namespace dev still has functions, use --force to delete it and its functions",
+		},
+		{
+			name:     "namespace with functions is deleted with --force",
+			force:    true,
+			requests: []test.Request{deleteRequest},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resetForTest()
+			defer func() { namespaceDeleteForce = false }()
+
+			s := test.MockHttpServer(t, c.requests)
+			defer s.Close()
+
+			args := []string{"namespace", "delete", "dev", "--gateway=" + s.URL}
+			if c.force {
+				args = append(args, "--force")
+			}
+
+			var err error
+			stdOut := test.CaptureStdout(func() {
+				faasCmd.SetArgs(args)
+				err = faasCmd.Execute()
+			})
+
+			if len(c.wantErr) > 0 {
+				if err == nil || err.Error() != c.wantErr {
+					t.Fatalf("want error %q, got %v", c.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(stdOut, "Deleted namespace: dev") {
+				t.Errorf("want the namespace to be deleted, got %q", stdOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_namespaceList_OutputJSON(t *testing.T) {
+	resetForTest()
+	defer func() { namespacesOutput = "" }()
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/namespaces",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []string{"openfaas-fn", "dev"},
+		},
+	})
+	defer s.Close()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"namespace", "list", "--gateway=" + s.URL, "--output=json"})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	want := "[\n  \"openfaas-fn\",\n  \"dev\"\n]\n"
+	if stdOut != want {
+		t.Errorf("want %q, got %q", want, stdOut)
+	}
+}
+
+func Test_namespaceList_InvalidOutput(t *testing.T) {
+	resetForTest()
+	defer func() { namespacesOutput = "" }()
+
+	faasCmd.SetArgs([]string{"namespace", "list", "--output=yaml"})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != "--output must be json, not: yaml" {
+		t.Errorf("want an error for --output yaml, got %v", err)
+	}
+}
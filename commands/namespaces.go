@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -9,11 +10,13 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var namespacesOutput string
+
 func init() {
 	// Setup flags that are used by multiple commands (variables defined in faas.go)
 	namespacesCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
-	namespacesCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
 	namespacesCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
+	namespacesCmd.Flags().StringVarP(&namespacesOutput, "output", "o", "", "Output format, only json is supported, instead of text")
 
 	faasCmd.AddCommand(namespacesCmd)
 }
@@ -24,11 +27,16 @@ var namespacesCmd = &cobra.Command{
 	Short:   "List OpenFaaS namespaces",
 	Long:    `Lists OpenFaaS namespaces either on a local or remote gateway`,
 	Example: `  faas-cli namespaces
-  faas-cli namespaces --gateway https://127.0.0.1:8080`,
+  faas-cli namespaces --gateway https://127.0.0.1:8080
+  faas-cli namespaces --output json`,
 	RunE: runNamespaces,
 }
 
 func runNamespaces(cmd *cobra.Command, args []string) error {
+	if len(namespacesOutput) > 0 && namespacesOutput != "json" {
+		return fmt.Errorf("--output must be json, not: %s", namespacesOutput)
+	}
+
 	gatewayAddress := getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
 	cliAuth := NewCLIAuth(token, gatewayAddress)
 	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
@@ -37,6 +45,19 @@ func runNamespaces(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+
+	if namespacesOutput == "json" {
+		if namespaces == nil {
+			namespaces = []string{}
+		}
+		out, err := json.MarshalIndent(namespaces, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
 	printNamespaces(namespaces)
 	return nil
 }
@@ -25,6 +25,7 @@ var (
 	cpuLimit      string
 	memoryRequest string
 	cpuRequest    string
+	newForce      bool
 )
 
 func init() {
@@ -42,18 +43,30 @@ func init() {
 	newFunctionCmd.Flags().BoolVar(&list, "list", false, "List available languages")
 	newFunctionCmd.Flags().StringVarP(&appendFile, "append", "a", "", "Append to existing YAML file")
 	newFunctionCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Skip template notes")
+	newFunctionCmd.Flags().BoolVar(&newForce, "force", false, "Replace a function with the same name in the --append stack file")
 
 	faasCmd.AddCommand(newFunctionCmd)
 }
 
 // newFunctionCmd displays newFunction information
 var newFunctionCmd = &cobra.Command{
-	Use:   "new FUNCTION_NAME --lang=FUNCTION_LANGUAGE [--gateway=http://domain:port] | --list | --append=STACK_FILE)",
+	Use:   "new FUNCTION_NAME [FUNCTION_NAME...] --lang=FUNCTION_LANGUAGE [--gateway=http://domain:port] | --list | --append=STACK_FILE)",
 	Short: "Create a new template in the current folder with the name given as name",
 	Long: `The new command creates a new function based upon hello-world in the given
-language or type in --list for a list of languages available.`,
+language or type in --list for a list of languages available.
+
+Several functions can be created at once, each in its own folder. They are all
+added to the --append stack file, or to the stack file named after the first
+function. A function which already exists is skipped with a warning.
+
+When appending, the function is added to the end of the functions block and
+the rest of the stack file, including its comments, is left as it is. Use
+--force to replace a function of the same name in the stack file, its handler
+folder must have been removed first.`,
 	Example: `  faas-cli new chatbot --lang node
   faas-cli new chatbot --lang node --append stack.yml
+  faas-cli new fn1 fn2 fn3 --lang go --append stack.yml
+  faas-cli new chatbot --lang python3 --append stack.yml --force
   faas-cli new text-parser --lang python --quiet
   faas-cli new text-parser --lang python --gateway http://mydomain:8080
   faas-cli new --list`,
@@ -94,8 +107,14 @@ func preRunNewFunction(cmd *cobra.Command, args []string) error {
 
 	functionName = args[0]
 
-	if err := validateFunctionName(functionName); err != nil {
-		return err
+	for _, name := range args {
+		if err := validateFunctionName(name); err != nil {
+			return err
+		}
+	}
+
+	if len(args) > 1 && len(handlerDir) > 0 {
+		return fmt.Errorf("--handler can only be given when creating a single function")
 	}
 
 	return nil
@@ -144,33 +163,76 @@ Download templates:
 			return fmt.Errorf("unable to find file: %s - %s", appendFile, statErr.Error())
 		}
 
-		var duplicateError error
-		duplicateError = duplicateFunctionName(functionName, appendFile)
-
-		if duplicateError != nil {
-			return duplicateError
-		}
-
 		fileName = appendFile
 		outputMsg = fmt.Sprintf("Stack file updated: %s\n", fileName)
 
 	} else {
 		gateway = getGatewayURL(gateway, defaultGateway, gateway, os.Getenv(openFaaSURLEnvironment))
-		fileName = functionName + ".yml"
+		fileName = args[0] + ".yml"
 		outputMsg = fmt.Sprintf("Stack file written: %s\n", fileName)
+
+		if _, err := os.Stat(fileName); err == nil {
+			return fmt.Errorf("file: %s already exists", fileName)
+		}
 	}
 
-	if len(handlerDir) == 0 {
-		handlerDir = functionName
+	var created []string
+	for _, name := range args {
+		functionHandler := handlerDir
+		if len(functionHandler) == 0 {
+			functionHandler = name
+		}
+
+		// Functions after the first are appended to the stack file written for it
+		_, statErr := os.Stat(fileName)
+		err := newFunction(name, functionHandler, fileName, statErr == nil)
+		if err != nil {
+			if len(args) == 1 {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %s\n", name, strings.TrimSpace(err.Error()))
+			continue
+		}
+		created = append(created, functionHandler)
 	}
 
-	if _, err := os.Stat(handlerDir); err == nil {
-		return fmt.Errorf("folder: %s already exists", handlerDir)
+	if len(created) == 0 {
+		return fmt.Errorf("no functions were created")
+	}
+
+	fmt.Print(outputMsg)
+
+	if len(args) > 1 {
+		fmt.Printf("\nCreated %d of %d function(s):\n", len(created), len(args))
+		for _, path := range created {
+			fmt.Printf("- ./%s\n", path)
+		}
+	}
+
+	if !quiet {
+		languageTemplate, _ := stack.LoadLanguageTemplate(language)
+
+		if languageTemplate.WelcomeMessage != "" {
+			fmt.Printf("\nNotes:\n")
+			fmt.Printf("%s\n", languageTemplate.WelcomeMessage)
+		}
+	}
+
+	return nil
+}
+
+// newFunction creates the handler folder for the function from the template
+// and adds it to the stack file, which is written when appendMode is false
+func newFunction(functionName string, handlerDir string, fileName string, appendMode bool) error {
+	if appendMode && !newForce {
+		if err := duplicateFunctionName(functionName, fileName); err != nil {
+			return err
+		}
 	}
 
-	_, err := os.Stat(fileName)
-	if err == nil && appendMode == false {
-		return fmt.Errorf("file: %s already exists", fileName)
+	if _, err := os.Stat(handlerDir); err == nil {
+		return fmt.Errorf("folder: %s already exists", handlerDir)
 	}
 
 	if err := os.Mkdir(handlerDir, 0700); err != nil {
@@ -235,25 +297,12 @@ Download templates:
 
 	yamlContent := prepareYAMLContent(appendMode, gateway, &function)
 
-	f, err := os.OpenFile("./"+fileName, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
-	if err != nil {
-		return fmt.Errorf("could not open file '%s' %s", fileName, err)
+	if appendMode {
+		return appendFunction(fileName, functionName, yamlContent)
 	}
 
-	_, stackWriteErr := f.Write([]byte(yamlContent))
-	if stackWriteErr != nil {
-		return fmt.Errorf("error writing stack file %s", stackWriteErr)
-	}
-
-	fmt.Print(outputMsg)
-
-	if !quiet {
-		languageTemplate, _ := stack.LoadLanguageTemplate(language)
-
-		if languageTemplate.WelcomeMessage != "" {
-			fmt.Printf("\nNotes:\n")
-			fmt.Printf("%s\n", languageTemplate.WelcomeMessage)
-		}
+	if err := ioutil.WriteFile("./"+fileName, []byte(yamlContent), 0600); err != nil {
+		return fmt.Errorf("error writing stack file %s", err)
 	}
 
 	return nil
@@ -326,6 +375,31 @@ func printAvailableTemplates(availableTemplates []string) string {
 	return result
 }
 
+// appendFunction adds the function to the functions block of the stack file,
+// replacing a function of the same name when --force is given
+func appendFunction(fileName string, functionName string, yamlContent string) error {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return fmt.Errorf("could not open file '%s' %s", fileName, err)
+	}
+
+	fileBytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("unable to read %s to append, %s", fileName, err)
+	}
+
+	updated, err := addFunctionToStack(fileBytes, functionName, yamlContent, newForce)
+	if err != nil {
+		return fmt.Errorf("unable to append to %s, %s", fileName, err)
+	}
+
+	if err := ioutil.WriteFile(fileName, updated, info.Mode()); err != nil {
+		return fmt.Errorf("error writing stack file %s", err)
+	}
+
+	return nil
+}
+
 func duplicateFunctionName(functionName string, appendFile string) error {
 	fileBytes, readErr := ioutil.ReadFile(appendFile)
 	if readErr != nil {
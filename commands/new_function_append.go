@@ -0,0 +1,135 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var functionsBlockRegexp = regexp.MustCompile(`^functions:\s*(#.*)?$`)
+
+// addFunctionToStack adds the entry for the function, as written by
+// prepareYAMLContent, to the end of the functions block of the stack file,
+// or replaces the existing entry for the function when replace is set.
+// The file is edited line by line, so the rest of it, including the
+// provider and any comments, is kept as it is.
+func addFunctionToStack(data []byte, functionName string, entry string, replace bool) ([]byte, error) {
+	content := string(data)
+	if len(content) > 0 && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	lines := strings.SplitAfter(content, "\n")
+	lines = lines[:len(lines)-1]
+
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "functions:") {
+			if !functionsBlockRegexp.MatchString(strings.TrimRight(line, "\r\n")) {
+				return nil, fmt.Errorf("the functions in the stack file must be written as a block to add to them")
+			}
+			start = i
+			break
+		}
+	}
+
+	if start == -1 {
+		return []byte(content + "functions:\n" + strings.TrimRight(entry, "\n") + "\n"), nil
+	}
+
+	// The block ends at the next key which is not indented
+	end := len(lines)
+	lastContent := start
+	indent := 0
+	for i := start + 1; i < len(lines); i++ {
+		if isBlankOrComment(lines[i]) {
+			continue
+		}
+
+		n := indentation(lines[i])
+		if n == 0 {
+			end = i
+			break
+		}
+		if indent == 0 {
+			indent = n
+		}
+		lastContent = i
+	}
+	if indent == 0 {
+		indent = 2
+	}
+
+	entryLines := reindentEntry(strings.TrimRight(entry, "\n"), indent)
+
+	existing := findFunctionEntry(lines[start+1:end], functionName, indent)
+	if existing != nil {
+		if !replace {
+			return nil, fmt.Errorf("function %s already exists in the stack file", functionName)
+		}
+
+		from, to := start+1+existing[0], start+1+existing[1]
+		return []byte(joinLines(lines[:from], entryLines, lines[to:])), nil
+	}
+
+	insert := append([]string{"\n"}, entryLines...)
+	return []byte(joinLines(lines[:lastContent+1], insert, lines[lastContent+1:])), nil
+}
+
+// findFunctionEntry gives the first and last+1 line of the function's entry
+// in the lines of the functions block, or nil when it is not found
+func findFunctionEntry(lines []string, functionName string, indent int) []int {
+	key := regexp.MustCompile(`^\s*["']?` + regexp.QuoteMeta(functionName) + `["']?:\s*(#.*)?$`)
+
+	for i, line := range lines {
+		if isBlankOrComment(line) || indentation(line) != indent || !key.MatchString(strings.TrimRight(line, "\r\n")) {
+			continue
+		}
+
+		last := i
+		for j := i + 1; j < len(lines); j++ {
+			if isBlankOrComment(lines[j]) {
+				continue
+			}
+			if indentation(lines[j]) <= indent {
+				break
+			}
+			last = j
+		}
+		return []int{i, last + 1}
+	}
+
+	return nil
+}
+
+// reindentEntry changes the two space indentation used by
+// prepareYAMLContent to the indentation of the stack file
+func reindentEntry(entry string, indent int) []string {
+	var lines []string
+	for _, line := range strings.Split(entry, "\n") {
+		n := indentation(line)
+		lines = append(lines, strings.Repeat(" ", n/2*indent)+strings.TrimLeft(line, " ")+"\n")
+	}
+	return lines
+}
+
+func joinLines(parts ...[]string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		for _, line := range part {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+func isBlankOrComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return len(trimmed) == 0 || strings.HasPrefix(trimmed, "#")
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
@@ -0,0 +1,169 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"testing"
+)
+
+const appendEntry = `  new-fn:
+    lang: go
+    handler: ./new-fn
+    image: new-fn:latest
+
+`
+
+func Test_addFunctionToStack(t *testing.T) {
+	cases := []struct {
+		name    string
+		stack   string
+		replace bool
+		want    string
+		wantErr string
+	}{
+		{
+			name: "added to the end of the file",
+			stack: `version: 1.0
+provider:
+  name: openfaas
+functions:
+  fn1:
+    lang: go
+`,
+			want: `version: 1.0
+provider:
+  name: openfaas
+functions:
+  fn1:
+    lang: go
+
+  new-fn:
+    lang: go
+    handler: ./new-fn
+    image: new-fn:latest
+`,
+		},
+		{
+			name: "added before a later block and comments are kept",
+			stack: `# my stack
+version: 1.0
+provider:
+  name: openfaas # the provider
+  gateway: http://127.0.0.1:8080
+functions:
+  # the first function
+  fn1:
+    lang: go
+
+# shared settings
+configuration:
+  templates:
+    - name: golang-middleware
+`,
+			want: `# my stack
+version: 1.0
+provider:
+  name: openfaas # the provider
+  gateway: http://127.0.0.1:8080
+functions:
+  # the first function
+  fn1:
+    lang: go
+
+  new-fn:
+    lang: go
+    handler: ./new-fn
+    image: new-fn:latest
+
+# shared settings
+configuration:
+  templates:
+    - name: golang-middleware
+`,
+		},
+		{
+			name: "indentation of the stack file is used",
+			stack: `functions:
+    fn1:
+        lang: go
+`,
+			want: `functions:
+    fn1:
+        lang: go
+
+    new-fn:
+        lang: go
+        handler: ./new-fn
+        image: new-fn:latest
+`,
+		},
+		{
+			name: "duplicate is refused",
+			stack: `functions:
+  new-fn:
+    lang: node
+`,
+			wantErr: "function new-fn already exists in the stack file",
+		},
+		{
+			name: "duplicate is replaced",
+			stack: `functions:
+  new-fn:
+    lang: node
+    environment:
+      a: b
+  # keep me
+  fn2:
+    lang: go
+`,
+			replace: true,
+			want: `functions:
+  new-fn:
+    lang: go
+    handler: ./new-fn
+    image: new-fn:latest
+  # keep me
+  fn2:
+    lang: go
+`,
+		},
+		{
+			name: "functions block is written when missing",
+			stack: `provider:
+  name: openfaas`,
+			want: `provider:
+  name: openfaas
+functions:
+  new-fn:
+    lang: go
+    handler: ./new-fn
+    image: new-fn:latest
+`,
+		},
+		{
+			name:    "flow mapping is refused",
+			stack:   "functions: {}\n",
+			wantErr: "the functions in the stack file must be written as a block to add to them",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := addFunctionToStack([]byte(c.stack), "new-fn", appendEntry, c.replace)
+			if len(c.wantErr) > 0 {
+				if err == nil || err.Error() != c.wantErr {
+					t.Fatalf("want error %q, got %v", c.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != c.want {
+				t.Errorf("want:\n%s\ngot:\n%s", c.want, string(got))
+			}
+		})
+	}
+}
@@ -341,6 +341,58 @@ func Test_duplicateFunctionName(t *testing.T) {
 	}
 }
 
+func Test_newMultipleFunctions(t *testing.T) {
+	resetForTest()
+
+	templatePullLocalTemplateRepo(t)
+	defer tearDownFetchTemplates(t)
+	defer tearDownNewFunction(t, "multi-existing")
+	defer tearDownNewFunction(t, "multi-a")
+	defer tearDownNewFunction(t, "multi-b")
+
+	faasCmd.SetArgs([]string{"new", "multi-existing", "--lang=ruby"})
+	if err := faasCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"new", "multi-a", "multi-existing", "multi-b", "--lang=ruby", "--append=multi-existing.yml"})
+		err = faasCmd.Execute()
+	})
+	appendFile = ""
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	services, err := stack.ParseYAMLFile("multi-existing.yml", "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"multi-existing", "multi-a", "multi-b"} {
+		function, ok := services.Functions[name]
+		if !ok || function.Handler != "./"+name {
+			t.Errorf("want %s in the stack file with its own handler, got %v", name, services.Functions)
+		}
+	}
+
+	if !strings.Contains(stdOut, "Created 2 of 3 function(s):\n- ./multi-a\n- ./multi-b\n") {
+		t.Errorf("want the created paths to be listed, got %q", stdOut)
+	}
+}
+
+func Test_newMultipleFunctionsWithHandler(t *testing.T) {
+	resetForTest()
+	defer func() { handlerDir = "" }()
+
+	faasCmd.SetArgs([]string{"new", "fn1", "fn2", "--lang=ruby", "--handler=src"})
+	err := faasCmd.Execute()
+	if err == nil || err.Error() != "--handler can only be given when creating a single function" {
+		t.Errorf("want an error for --handler with several functions, got %v", err)
+	}
+}
+
 func Test_backfillTemplates(t *testing.T) {
 	resetForTest()
 	const functionName = "samplefunc"
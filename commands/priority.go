@@ -6,14 +6,35 @@ package commands
 import (
 	"fmt"
 	"strings"
+
+	"github.com/openfaas/faas-cli/config"
 )
 
 const (
 	openFaaSURLEnvironment      = "OPENFAAS_URL"
 	templateURLEnvironment      = "OPENFAAS_TEMPLATE_URL"
 	templateStoreURLEnvironment = "OPENFAAS_TEMPLATE_STORE_URL"
+	storeURLEnvironment         = "FAAS_STORE_URL"
+	gitTokenEnvironment         = "GIT_TOKEN"
+	tlsCertEnvironment          = "OPENFAAS_TLS_CERT"
+	tlsKeyEnvironment           = "OPENFAAS_TLS_KEY"
+	tlsCAEnvironment            = "OPENFAAS_TLS_CA"
+	caBundleEnvironment         = "OPENFAAS_CA_BUNDLE"
+	usernameEnvironment         = "OPENFAAS_USERNAME"
+	passwordEnvironment         = "OPENFAAS_PASSWORD"
+	scanCommandEnvironment      = "OPENFAAS_SCAN_COMMAND"
 )
 
+// configuredGateway gives the default gateway stored in the config file by
+// "faas-cli config set-gateway"
+var configuredGateway = func() string {
+	gateway, _ := config.GetDefaultGateway()
+	return gateway
+}
+
+// getGatewayURL gives the gateway from the --gateway flag, then the stack
+// file, then OPENFAAS_URL, then the default gateway in the config file and
+// last the defaultURL
 func getGatewayURL(argumentURL, defaultURL, yamlURL, environmentURL string) string {
 	var gatewayURL string
 
@@ -23,6 +44,8 @@ func getGatewayURL(argumentURL, defaultURL, yamlURL, environmentURL string) stri
 		gatewayURL = yamlURL
 	} else if len(environmentURL) > 0 {
 		gatewayURL = environmentURL
+	} else if configURL := configuredGateway(); len(configURL) > 0 {
+		gatewayURL = configURL
 	} else {
 		gatewayURL = defaultURL
 	}
@@ -58,3 +81,12 @@ func getTemplateStoreURL(argumentURL, environmentURL, defaultURL string) string
 		return defaultURL
 	}
 }
+
+func getStoreURL(argumentURL, environmentURL, defaultURL string) string {
+	if len(argumentURL) > 0 && argumentURL != defaultURL {
+		return argumentURL
+	} else if len(environmentURL) > 0 {
+		return environmentURL
+	}
+	return defaultURL
+}
@@ -49,3 +49,38 @@ func Test_getTemplateStoreURL(t *testing.T) {
 		})
 	}
 }
+
+func Test_getStoreURL(t *testing.T) {
+	tests := []struct {
+		title       string
+		envURL      string
+		argURL      string
+		expectedURL string
+	}{
+		{
+			title:       "Environmental variable is set and argument equals defaultURL",
+			envURL:      "https://store.example.com/functions.json",
+			argURL:      defaultStore,
+			expectedURL: "https://store.example.com/functions.json",
+		},
+		{
+			title:       "Environmental variable and argument are unset",
+			argURL:      defaultStore,
+			expectedURL: defaultStore,
+		},
+		{
+			title:       "Environmental variable and argument are set",
+			envURL:      "https://store.example.com/functions.json",
+			argURL:      "https://curated.example.com/functions.json",
+			expectedURL: "https://curated.example.com/functions.json",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			storeURL := getStoreURL(test.argURL, test.envURL, defaultStore)
+			if storeURL != test.expectedURL {
+				t.Errorf("expected store URL: `%s` got: `%s`", test.expectedURL, storeURL)
+			}
+		})
+	}
+}
@@ -5,52 +5,90 @@ package commands
 
 import (
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/openfaas/faas-cli/builder"
 	"github.com/openfaas/faas-cli/exec"
 
 	"github.com/morikuni/aec"
-	"github.com/openfaas/faas-cli/builder"
 	"github.com/openfaas/faas-cli/schema"
 	"github.com/openfaas/faas-cli/stack"
 	"github.com/spf13/cobra"
 )
 
+var resolveDigests bool
+
+// pinnedImages holds the image pinned to its digest for each function pushed
+// with --resolve-digests, which deploy uses in place of the image's tag
+var pinnedImages = map[string]string{}
+
+// imageDigest is replaced in the tests, which have no docker to inspect
+var imageDigest = builder.ImageDigest
+
 func init() {
 	faasCmd.AddCommand(pushCmd)
 
 	pushCmd.Flags().IntVar(&parallel, "parallel", 1, "Push images in parallel to depth specified.")
+	addFailFastFlags(pushCmd)
 	pushCmd.Flags().Var(&tagFormat, "tag", "Override latest tag on function Docker image, accepts 'latest', 'sha', 'branch', 'describe'")
 	pushCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yml file")
+	pushCmd.Flags().BoolVar(&resolveDigests, "resolve-digests", false, "Print the digest each image was pushed with, up then deploys the image by its digest in place of its tag")
+	pushCmd.Flags().BoolVar(&pushScan, "scan", false, "Scan each image for vulnerabilities once it is pushed and fail on findings at --scan-severity or above")
+	pushCmd.Flags().StringVar(&scanSeverity, "scan-severity", "HIGH", "Lowest severity of finding which fails --scan, one of: "+strings.Join(scanSeverities, ", "))
+	pushCmd.Flags().StringVar(&scanCommand, "scan-command", "", "Command to scan an image with, {image} is replaced by the image, or set "+scanCommandEnvironment+", defaults to: "+defaultScanCommand)
 
 }
 
 // pushCmd handles pushing function container images to a remote repo
 var pushCmd = &cobra.Command{
-	Use:   `push -f YAML_FILE [--regex "REGEX"] [--filter "WILDCARD"] [--parallel] [--tag <sha|branch>]`,
+	Use:   `push -f YAML_FILE [FUNCTION_NAME ...] [--regex "REGEX"] [--filter "WILDCARD"] [--parallel] [--continue-on-error] [--tag <sha|branch>] [--scan]`,
 	Short: "Push OpenFaaS functions to remote registry (Docker Hub)",
 	Long: `Pushes the OpenFaaS function container image(s) defined in the supplied YAML
 config to a remote repository.
 
-These container images must already be present in your local image cache.`,
+These container images must already be present in your local image cache.
+Use --resolve-digests to print the digest of each image once it is pushed,
+"faas-cli up --resolve-digests" deploys each function by its digest, so that
+the image deployed is the one which was built even when the tag is moved.
+Give the names of functions in the YAML config, or globs such as "web-*", to
+push only those functions, along with those matching --regex or --filter.
+
+Use --scan to scan each image once it has been pushed, with trivy by default,
+a count of the findings of each severity is printed and the push fails when
+any are at --scan-severity or above. Give another scanner with --scan-command
+or OPENFAAS_SCAN_COMMAND, a scanner which does not print a report in trivy's
+JSON format fails the image by its exit code.
+
+` + failFastNote,
 
 	Example: `  faas-cli push -f https://domain/path/myfunctions.yml
   faas-cli push -f ./stack.yml
   faas-cli push -f ./stack.yml --parallel 4
+  faas-cli push -f ./stack.yml figlet "web-*"
   faas-cli push -f ./stack.yml --filter "*gif*"
   faas-cli push -f ./stack.yml --regex "fn[0-9]_.*"
   faas-cli push -f ./stack.yml --tag sha
   faas-cli push -f ./stack.yml --tag branch
-  faas-cli push -f ./stack.yml --tag describe`,
+  faas-cli push -f ./stack.yml --tag describe
+  faas-cli push -f ./stack.yml --resolve-digests
+  faas-cli push -f ./stack.yml --scan --scan-severity CRITICAL
+  faas-cli push -f ./stack.yml --scan --scan-command "grype {image} --fail-on high"`,
 	RunE: runPush,
 }
 
 func runPush(cmd *cobra.Command, args []string) error {
+	if pushScan {
+		if err := checkScanSeverity(scanSeverity); err != nil {
+			return err
+		}
+	}
 
 	var services stack.Services
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		parsedServices, err := parseSelectedStack(args)
 		if err != nil {
 			return err
 		}
@@ -71,45 +109,88 @@ Unable to push one or more of your functions to Docker Hub:
 You must provide a username or registry prefix to the Function's image such as user1/function1`)
 		}
 
-		pushStack(&services, parallel, tagFormat)
+		if errors := pushStack(&services, parallel, tagFormat); len(errors) > 0 {
+			errorSummary := fmt.Sprintf("Errors received during push of %d function(s):\n", len(errors))
+			for _, err := range errors {
+				errorSummary = errorSummary + "- " + err.Error() + "\n"
+			}
+			return fmt.Errorf("%s", aec.Apply(errorSummary, aec.RedF))
+		}
+
+		if pushScan {
+			if err := runScan(services.Functions); err != nil {
+				return err
+			}
+		}
+
+		if resolveDigests {
+			if err := resolveImageDigests(services.Functions, tagFormat); err != nil {
+				return err
+			}
+		}
 	} else {
 		return fmt.Errorf("you must supply a valid YAML file")
 	}
 	return nil
 }
 
-func pushImage(image string) {
-	exec.Command("./", []string{"docker", "push", image})
+// runScan scans the pushed image of each function, giving an error which
+// lists each function with findings at --scan-severity or above
+func runScan(functions map[string]stack.Function) error {
+	command := getScanCommand(scanCommand, os.Getenv(scanCommandEnvironment))
+	if errors := scanStack(os.Stdout, functions, tagFormat, command, scanSeverity); len(errors) > 0 {
+		errorSummary := fmt.Sprintf("Errors received during scan of %d function(s):\n", len(errors))
+		for _, err := range errors {
+			errorSummary = errorSummary + "- " + err.Error() + "\n"
+		}
+		return fmt.Errorf("%s", aec.Apply(errorSummary, aec.RedF))
+	}
+	return nil
+}
+
+// pushImage is replaced in the tests
+var pushImage = func(image string) error {
+	return exec.Run("./", []string{"docker", "push", image})
 }
 
-func pushStack(services *stack.Services, queueDepth int, tagMode schema.BuildFormat) {
+// pushStack pushes the image of each function, giving an error for each
+// which could not be pushed
+func pushStack(services *stack.Services, queueDepth int, tagMode schema.BuildFormat) []error {
 	wg := sync.WaitGroup{}
+	errors := []error{}
+	errorsLock := sync.Mutex{}
+	gate := failureGate{}
 
 	workChannel := make(chan stack.Function)
+	tag := resolveImageTag(tagMode)
 
 	wg.Add(queueDepth)
 	for i := 0; i < queueDepth; i++ {
 		go func(index int) {
 			for function := range workChannel {
-				branch, sha, err := builder.GetImageTagValues(tagMode)
-				if err != nil {
-					tagMode = schema.DefaultFormat
+				if gate.skip(function.Name) {
+					continue
 				}
-				imageName := schema.BuildImageName(tagMode, function.Image, sha, branch)
+				imageName := tag.imageName(function.Image)
 
-				fmt.Printf(aec.YellowF.Apply("[%d] > Pushing %s [%s].\n"), index, function.Name, imageName)
+				fmt.Fprintf(infoOutput(), aec.YellowF.Apply("[%d] > Pushing %s [%s].\n"), index, function.Name, imageName)
 				if len(function.Image) == 0 {
 					fmt.Println("Please provide a valid Image value in the YAML file.")
 				} else if function.SkipBuild {
-					fmt.Printf("Skipping %s\n", function.Name)
+					fmt.Fprintf(infoOutput(), "Skipping %s\n", function.Name)
+				} else if schema.IsPinnedImage(function.Image) {
+					fmt.Fprintf(infoOutput(), "Skipping %s, its image is pinned to a digest\n", function.Name)
+				} else if err := pushImage(imageName); err != nil {
+					gate.fail()
+					errorsLock.Lock()
+					errors = append(errors, fmt.Errorf("%s: unable to push %s: %s", function.Name, imageName, err))
+					errorsLock.Unlock()
 				} else {
-
-					pushImage(imageName)
-					fmt.Printf(aec.YellowF.Apply("[%d] < Pushing %s [%s] done.\n"), index, function.Name, imageName)
+					fmt.Fprintf(infoOutput(), aec.YellowF.Apply("[%d] < Pushing %s [%s] done.\n"), index, function.Name, imageName)
 				}
 			}
 
-			fmt.Printf(aec.YellowF.Apply("[%d] Worker done.\n"), index)
+			fmt.Fprintf(infoOutput(), aec.YellowF.Apply("[%d] Worker done.\n"), index)
 			wg.Done()
 		}(i)
 	}
@@ -123,6 +204,34 @@ func pushStack(services *stack.Services, queueDepth int, tagMode schema.BuildFor
 
 	wg.Wait()
 
+	sort.Slice(errors, func(i, j int) bool {
+		return errors[i].Error() < errors[j].Error()
+	})
+	return errors
+}
+
+// resolveImageDigests records and prints the digest each function's image
+// was pushed with, for deploy to use
+func resolveImageDigests(functions map[string]stack.Function, tagMode schema.BuildFormat) error {
+	tag := resolveImageTag(tagMode)
+
+	names := make([]string, 0, len(functions))
+	for name, function := range functions {
+		if !function.SkipBuild && !schema.IsPinnedImage(function.Image) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		pinned, err := imageDigest(tag.imageName(functions[name].Image))
+		if err != nil {
+			return fmt.Errorf("unable to resolve the digest for %s: %s", name, err)
+		}
+		pinnedImages[name] = pinned
+		fmt.Printf("%s: %s\n", name, pinned)
+	}
+	return nil
 }
 
 func validateImages(functions map[string]stack.Function) []string {
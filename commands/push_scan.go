@@ -0,0 +1,171 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	v1execute "github.com/alexellis/go-execute/pkg/v1"
+	"github.com/openfaas/faas-cli/schema"
+	"github.com/openfaas/faas-cli/stack"
+)
+
+// defaultScanCommand scans an image with trivy, {image} is replaced with the
+// image which was pushed
+const defaultScanCommand = "trivy image --quiet --format json {image}"
+
+// scanSeverities are the severities of a finding, from the lowest
+var scanSeverities = []string{"UNKNOWN", "LOW", "MEDIUM", "HIGH", "CRITICAL"}
+
+var (
+	pushScan     bool
+	scanSeverity string
+	scanCommand  string
+)
+
+// scanImage runs the scanner and gives what it printed, it is replaced in
+// the tests
+var scanImage = func(args []string) (string, error) {
+	task := v1execute.ExecTask{
+		Command: args[0],
+		Args:    args[1:],
+	}
+
+	res, err := task.Execute()
+	if err != nil {
+		return "", err
+	}
+	if res.ExitCode != 0 {
+		return "", fmt.Errorf("%s exited with %d: %s", args[0], res.ExitCode, strings.TrimSpace(res.Stderr))
+	}
+	return res.Stdout, nil
+}
+
+// scanReport is the part of the JSON report of trivy which gives the findings
+type scanReport struct {
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID string `json:"VulnerabilityID"`
+			Severity        string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// getScanCommand gives the scanner from --scan-command, then
+// OPENFAAS_SCAN_COMMAND and last the default of trivy
+func getScanCommand(argumentCommand, environmentCommand string) string {
+	if len(argumentCommand) > 0 {
+		return argumentCommand
+	} else if len(environmentCommand) > 0 {
+		return environmentCommand
+	}
+	return defaultScanCommand
+}
+
+// severityRank gives the position of the severity in scanSeverities, or -1
+// when it is not known
+func severityRank(severity string) int {
+	for i, known := range scanSeverities {
+		if strings.EqualFold(known, severity) {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkScanSeverity gives an error for a --scan-severity which is not known
+func checkScanSeverity(severity string) error {
+	if severityRank(severity) < 0 {
+		return fmt.Errorf("the --scan-severity flag must be one of: %s", strings.Join(scanSeverities, ", "))
+	}
+	return nil
+}
+
+// scanStack scans the image of each function which was pushed, giving an
+// error for each with findings at or above the severity
+func scanStack(w io.Writer, functions map[string]stack.Function, tagMode schema.BuildFormat, command string, severity string) []error {
+	tag := resolveImageTag(tagMode)
+
+	names := make([]string, 0, len(functions))
+	for name, function := range functions {
+		if len(function.Image) > 0 && !function.SkipBuild && !schema.IsPinnedImage(function.Image) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var errors []error
+	for _, name := range names {
+		imageName := tag.imageName(functions[name].Image)
+		if err := scanFunctionImage(w, name, imageName, command, severity); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return errors
+}
+
+// scanFunctionImage runs the scanner against the image and prints a count of
+// the findings of each severity. A scanner which does not print a report in
+// trivy's JSON format passes or fails the image by its exit code alone.
+func scanFunctionImage(w io.Writer, name string, imageName string, command string, severity string) error {
+	args := strings.Fields(strings.Replace(command, "{image}", imageName, -1))
+	if len(args) == 0 {
+		return fmt.Errorf("%s: the scan command is empty", name)
+	}
+
+	fmt.Fprintf(w, "Scanning %s [%s]\n", name, imageName)
+	out, err := scanImage(args)
+	if err != nil {
+		return fmt.Errorf("%s: unable to scan %s: %s", name, imageName, err)
+	}
+
+	report := scanReport{}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		fmt.Fprintf(w, "%s: passed, the scanner did not print a report in trivy's JSON format\n", name)
+		return nil
+	}
+
+	counts := map[string]int{}
+	var found []string
+	threshold := severityRank(severity)
+	for _, result := range report.Results {
+		for _, vulnerability := range result.Vulnerabilities {
+			rank := severityRank(vulnerability.Severity)
+			if rank < 0 {
+				rank = 0
+			}
+			counts[scanSeverities[rank]]++
+			if rank >= threshold {
+				found = append(found, vulnerability.VulnerabilityID)
+			}
+		}
+	}
+
+	summary := make([]string, 0, len(scanSeverities))
+	for i := len(scanSeverities) - 1; i >= 0; i-- {
+		summary = append(summary, fmt.Sprintf("%d %s", counts[scanSeverities[i]], scanSeverities[i]))
+	}
+	fmt.Fprintf(w, "%s: %s\n", name, strings.Join(summary, ", "))
+
+	if len(found) > 0 {
+		return fmt.Errorf("%s: %d finding(s) at %s or above in %s: %s", name, len(found), strings.ToUpper(severity), imageName, strings.Join(dedupeStrings(found), ", "))
+	}
+	return nil
+}
+
+func dedupeStrings(values []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
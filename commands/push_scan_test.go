@@ -0,0 +1,115 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/schema"
+	"github.com/openfaas/faas-cli/stack"
+)
+
+const trivyReport = `{
+  "Results": [
+    {
+      "Target": "alexellis/figlet:latest (alpine 3.12.0)",
+      "Vulnerabilities": [
+        {"VulnerabilityID": "CVE-2020-1967", "Severity": "HIGH"},
+        {"VulnerabilityID": "CVE-2020-28928", "Severity": "MEDIUM"},
+        {"VulnerabilityID": "CVE-2021-23840", "Severity": "CRITICAL"}
+      ]
+    },
+    {"Target": "app/package-lock.json"}
+  ]
+}`
+
+func Test_getScanCommand(t *testing.T) {
+	if got := getScanCommand("", ""); got != defaultScanCommand {
+		t.Errorf("want the default of trivy, got %q", got)
+	}
+	if got := getScanCommand("", "grype {image}"); got != "grype {image}" {
+		t.Errorf("want the command from %s, got %q", scanCommandEnvironment, got)
+	}
+	if got := getScanCommand("snyk container test {image}", "grype {image}"); got != "snyk container test {image}" {
+		t.Errorf("want the command from --scan-command, got %q", got)
+	}
+}
+
+func Test_scanStack(t *testing.T) {
+	previousScanImage := scanImage
+	defer func() { scanImage = previousScanImage }()
+
+	var scanned [][]string
+	scanImage = func(args []string) (string, error) {
+		scanned = append(scanned, args)
+		if args[len(args)-1] == "alexellis/figlet:latest" {
+			return trivyReport, nil
+		}
+		return `{"Results": []}`, nil
+	}
+
+	functions := map[string]stack.Function{
+		"figlet":   {Image: "alexellis/figlet"},
+		"nodeinfo": {Image: "alexellis/nodeinfo"},
+		"external": {Image: "alexellis/external", SkipBuild: true},
+	}
+
+	var buf bytes.Buffer
+	errors := scanStack(&buf, functions, schema.DefaultFormat, defaultScanCommand, "high")
+
+	want := [][]string{
+		{"trivy", "image", "--quiet", "--format", "json", "alexellis/figlet:latest"},
+		{"trivy", "image", "--quiet", "--format", "json", "alexellis/nodeinfo:latest"},
+	}
+	if !reflect.DeepEqual(scanned, want) {
+		t.Errorf("want the pushed images scanned with %v, got %v", want, scanned)
+	}
+
+	if len(errors) != 1 || errors[0].Error() != "figlet: 2 finding(s) at HIGH or above in alexellis/figlet:latest: CVE-2020-1967, CVE-2021-23840" {
+		t.Errorf("want an error for figlet, got %v", errors)
+	}
+	if !strings.Contains(buf.String(), "figlet: 1 CRITICAL, 1 HIGH, 1 MEDIUM, 0 LOW, 0 UNKNOWN") {
+		t.Errorf("want a summary of the findings, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if errors := scanStack(&buf, functions, schema.DefaultFormat, defaultScanCommand, "CRITICAL"); len(errors) != 1 {
+		t.Errorf("want figlet to fail at CRITICAL, got %v", errors)
+	}
+}
+
+func Test_scanFunctionImage_CustomScanner(t *testing.T) {
+	previousScanImage := scanImage
+	defer func() { scanImage = previousScanImage }()
+
+	scanImage = func(args []string) (string, error) {
+		return "No vulnerabilities found", nil
+	}
+
+	var buf bytes.Buffer
+	if err := scanFunctionImage(&buf, "figlet", "alexellis/figlet:latest", "grype {image} --fail-on high", "HIGH"); err != nil {
+		t.Errorf("want a scanner without a report to pass by its exit code, got %s", err)
+	}
+
+	scanImage = func(args []string) (string, error) {
+		return "", fmt.Errorf("grype exited with 1: discovered vulnerabilities at or above the severity threshold")
+	}
+	err := scanFunctionImage(&buf, "figlet", "alexellis/figlet:latest", "grype {image} --fail-on high", "HIGH")
+	if err == nil || !strings.HasPrefix(err.Error(), "figlet: unable to scan alexellis/figlet:latest: grype exited with 1") {
+		t.Errorf("want the scanner's exit code to fail the image, got %v", err)
+	}
+}
+
+func Test_checkScanSeverity(t *testing.T) {
+	if err := checkScanSeverity("medium"); err != nil {
+		t.Errorf("want the severity to be accepted in lower case, got %s", err)
+	}
+	if err := checkScanSeverity("severe"); err == nil {
+		t.Error("want an error for an unknown severity")
+	}
+}
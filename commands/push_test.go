@@ -4,9 +4,17 @@
 package commands
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 
+	"github.com/openfaas/faas-cli/builder"
+	"github.com/openfaas/faas-cli/schema"
 	"github.com/openfaas/faas-cli/stack"
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
 )
 
 func Test_PushValidation(t *testing.T) {
@@ -40,3 +48,106 @@ func Test_PushValidation(t *testing.T) {
 
 	}
 }
+
+func Test_resolveImageDigests_PinsDeployedImage(t *testing.T) {
+	imageDigest = func(image string) (string, error) {
+		if image != "alexellis/figlet:latest" {
+			return "", fmt.Errorf("unexpected image %s", image)
+		}
+		return "alexellis/figlet@sha256:2222", nil
+	}
+	defer func() {
+		imageDigest = builder.ImageDigest
+		pinnedImages = map[string]string{}
+	}()
+
+	functions := map[string]stack.Function{
+		"figlet":   {Image: "alexellis/figlet"},
+		"nodeinfo": {Image: "alexellis/nodeinfo@sha256:1111"},
+		"prebuilt": {Image: "alexellis/prebuilt", SkipBuild: true},
+	}
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		err = resolveImageDigests(functions, schema.DefaultFormat)
+	})
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+	if stdOut != "figlet: alexellis/figlet@sha256:2222\n" {
+		t.Errorf("want the resolved digest printed, got %q", stdOut)
+	}
+
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  figlet:
+    image: alexellis/figlet
+  nodeinfo:
+    image: alexellis/nodeinfo@sha256:1111
+`)
+	stackFile.Close()
+
+	resetForTest()
+	defer func() {
+		deployFlags.dryRun = false
+		deployFlags.output = "yaml"
+	}()
+
+	stdOut = test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"deploy", "-f", stackFile.Name(), "--dry-run", "--output=json"})
+		faasCmd.Execute()
+	})
+
+	var requests []types.FunctionDeployment
+	if err := json.Unmarshal([]byte(stdOut), &requests); err != nil {
+		t.Fatalf("want JSON output, got %q: %s", stdOut, err)
+	}
+
+	images := map[string]string{}
+	for _, request := range requests {
+		images[request.Service] = request.Image
+	}
+	if images["figlet"] != "alexellis/figlet@sha256:2222" || images["nodeinfo"] != "alexellis/nodeinfo@sha256:1111" {
+		t.Errorf("want each function deployed by its digest, got %v", images)
+	}
+}
+
+func Test_pushStack_Errors(t *testing.T) {
+	previousPushImage := pushImage
+	pushImage = func(image string) error {
+		return fmt.Errorf("denied")
+	}
+	defer func() {
+		pushImage = previousPushImage
+		continueOnError = false
+	}()
+
+	services := stack.Services{
+		Functions: map[string]stack.Function{
+			"fn-a": {Image: "alexellis/fn-a"},
+			"fn-b": {Image: "alexellis/fn-b"},
+		},
+	}
+
+	var errors []error
+	test.CaptureStdout(func() {
+		errors = pushStack(&services, 1, schema.DefaultFormat)
+	})
+	if len(errors) != 1 {
+		t.Errorf("want only the first push tried with --fail-fast, got %v", errors)
+	}
+
+	continueOnError = true
+	test.CaptureStdout(func() {
+		errors = pushStack(&services, 1, schema.DefaultFormat)
+	})
+	if len(errors) != 2 || errors[0].Error() != "fn-a: unable to push alexellis/fn-a:latest: denied" {
+		t.Errorf("want an error for each function with --continue-on-error, got %v", errors)
+	}
+}
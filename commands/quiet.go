@@ -0,0 +1,23 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// quietOutput is set by --quiet to print only the errors and the result of a
+// command, such as the summary of a deploy or the JSON given by --output
+var quietOutput bool
+
+// infoOutput is where progress and other informational messages are
+// written, they are discarded with --quiet
+func infoOutput() io.Writer {
+	if quietOutput {
+		return ioutil.Discard
+	}
+	return os.Stdout
+}
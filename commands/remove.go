@@ -4,22 +4,33 @@
 package commands
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/openfaas/faas-cli/proxy"
 	"github.com/openfaas/faas-cli/stack"
 	"github.com/spf13/cobra"
 )
 
+var (
+	removeAll bool
+	removeYes bool
+)
+
 func init() {
 	// Setup flags that are used by multiple commands (variables defined in faas.go)
 	removeCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
-	removeCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
 	removeCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yml file")
 	removeCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
 	removeCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function")
+	removeCmd.Flags().BoolVar(&removeAll, "all", false, "Remove every function in the namespace")
+	removeCmd.Flags().BoolVarP(&removeYes, "yes", "y", false, "Remove every function for --all without asking for confirmation")
+	addFailFastFlags(removeCmd)
 
 	faasCmd.AddCommand(removeCmd)
 }
@@ -27,27 +38,42 @@ func init() {
 // removeCmd deletes/removes OpenFaaS function containers
 var removeCmd = &cobra.Command{
 	Use: `remove FUNCTION_NAME [--gateway GATEWAY_URL]
-  faas-cli remove -f YAML_FILE [--regex "REGEX"] [--filter "WILDCARD"]`,
+  faas-cli remove -f YAML_FILE [--regex "REGEX"] [--filter "WILDCARD"] [--continue-on-error]
+  faas-cli remove --all [--namespace NAMESPACE] [--yes] [--continue-on-error]`,
 	Aliases: []string{"rm"},
 	Short:   "Remove deployed OpenFaaS functions",
 	Long: `Removes/deletes deployed OpenFaaS functions either via the supplied YAML config
 using the "--yaml" flag (which may contain multiple function definitions), or by
-explicitly specifying a function name.`,
+explicitly specifying a function name. When no name is given and STDIN is a
+terminal, the deployed functions are listed to pick one from.
+
+The --all flag removes every function in the namespace. The functions are
+listed and must be confirmed before they are removed, unless --yes is given.
+The result is reported for each function and the command fails when any of
+them could not be removed.
+
+` + failFastNote,
 	Example: `  faas-cli remove -f https://domain/path/myfunctions.yml
   faas-cli remove -f ./stack.yml
   faas-cli remove -f ./stack.yml --filter "*gif*"
   faas-cli remove -f ./stack.yml --regex "fn[0-9]_.*"
   faas-cli remove url-ping
-  faas-cli remove img2ansi --gateway==http://remote-site.com:8080`,
+  faas-cli remove img2ansi --gateway==http://remote-site.com:8080
+  faas-cli remove --all --namespace staging-fn
+  faas-cli remove --all --yes`,
 	RunE: runDelete,
 }
 
 func runDelete(cmd *cobra.Command, args []string) error {
+	if removeAll && (len(args) > 0 || len(yamlFiles) > 0) {
+		return fmt.Errorf("--all removes every function in the namespace, so it can't be used with a function name or --yaml")
+	}
+
 	var services stack.Services
 	var gatewayAddress string
 	var yamlGateway string
-	if len(yamlFile) > 0 && len(args) == 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+	if len(yamlFile) > 0 && len(args) == 0 && !removeAll {
+		parsedServices, err := stack.ParseYAMLFiles(stackFiles(), regex, filter, envsubst)
 		if err != nil {
 			return err
 		}
@@ -65,29 +91,121 @@ func runDelete(cmd *cobra.Command, args []string) error {
 	proxyclient := proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
 	ctx := context.Background()
 
+	if removeAll {
+		return removeAllFunctions(ctx, proxyclient, cmd.InOrStdin())
+	}
+
 	if len(services.Functions) > 0 {
-		if len(services.Provider.Network) == 0 {
-			services.Provider.Network = defaultNetwork
+		var functions []deployedFunction
+		for k, function := range services.Functions {
+			namespace := functionNamespace
+			if len(function.Namespace) > 0 {
+				namespace = function.Namespace
+			}
+			functions = append(functions, deployedFunction{name: k, namespace: namespace})
 		}
+		sort.Slice(functions, func(i, j int) bool { return functions[i].name < functions[j].name })
 
-		for k, function := range services.Functions {
-			function.Name = k
-			fmt.Printf("Deleting: %s.\n", function.Name)
+		return removeFunctions(ctx, proxyclient, functions)
+	}
 
-			proxyclient.DeleteFunction(ctx, function.Name, functionNamespace)
-		}
-	} else {
-		if len(args) < 1 {
+	if len(args) < 1 {
+		if !isTerminal(cmd.InOrStdin()) {
 			return fmt.Errorf("please provide the name of a function to delete")
 		}
 
-		functionName = args[0]
-		fmt.Printf("Deleting: %s.\n", functionName)
-		err := proxyclient.DeleteFunction(ctx, functionName, functionNamespace)
+		name, err := selectDeployedFunction(ctx, proxyclient, cmd.InOrStdin())
 		if err != nil {
 			return err
 		}
+		args = []string{name}
+	}
+
+	functionName = args[0]
+	fmt.Printf("Deleting: %s.\n", functionName)
+	return proxyclient.DeleteFunction(ctx, functionName, functionNamespace)
+}
+
+// selectDeployedFunction asks which of the functions in the namespace to
+// remove, for when no name is given on a terminal
+func selectDeployedFunction(ctx context.Context, client *proxy.Client, in io.Reader) (string, error) {
+	statuses, err := client.ListFunctions(ctx, functionNamespace)
+	if err != nil {
+		return "", err
 	}
 
+	var options []selectOption
+	for _, status := range statuses {
+		options = append(options, selectOption{name: status.Name, description: status.Image})
+	}
+	sort.Slice(options, func(i, j int) bool { return options[i].name < options[j].name })
+
+	return selectName(in, os.Stdout, "function", options)
+}
+
+// removeAllFunctions removes every function in the namespace once the list
+// has been confirmed, or straight away with --yes
+func removeAllFunctions(ctx context.Context, client *proxy.Client, in io.Reader) error {
+	statuses, err := client.ListFunctions(ctx, functionNamespace)
+	if err != nil {
+		return err
+	}
+
+	if len(statuses) == 0 {
+		fmt.Println("No functions to remove.")
+		return nil
+	}
+
+	var functions []deployedFunction
+	for _, status := range statuses {
+		functions = append(functions, deployedFunction{name: status.Name, namespace: functionNamespace})
+	}
+	sort.Slice(functions, func(i, j int) bool { return functions[i].name < functions[j].name })
+
+	fmt.Printf("The following %d function(s) will be removed:\n", len(functions))
+	for _, function := range functions {
+		fmt.Printf("  %s\n", function.name)
+	}
+
+	if !removeYes {
+		fmt.Print("Remove them? [y/N] ")
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			return fmt.Errorf("no functions were removed, answer y to remove them or pass --yes")
+		}
+	}
+
+	return removeFunctions(ctx, client, functions)
+}
+
+// removeFunctions removes each of the functions, reporting the result for
+// each, until one fails with --fail-fast, and gives an error naming the functions which could not be removed
+func removeFunctions(ctx context.Context, client *proxy.Client, functions []deployedFunction) error {
+	var failed []string
+	removed := 0
+	gate := failureGate{}
+	for _, function := range functions {
+		if gate.skip(function.name) {
+			continue
+		}
+		fmt.Printf("Deleting: %s.\n", function.name)
+
+		if err := client.DeleteFunction(ctx, function.name, function.namespace); err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to remove %s: %s\n", function.name, err)
+			failed = append(failed, function.name)
+			gate.fail()
+		} else {
+			removed++
+		}
+	}
+
+	if len(functions) > 1 {
+		fmt.Printf("Removed %d of %d function(s).\n", removed, len(functions))
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to remove %d function(s): %s", len(failed), strings.Join(failed, ", "))
+	}
 	return nil
 }
@@ -4,13 +4,18 @@
 package commands
 
 import (
+	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
 )
 
 const testStack = `
@@ -66,3 +71,251 @@ func Test_remove(t *testing.T) {
 		t.Error("test-function should be deleted.")
 	}
 }
+
+// removeServer lists the functions and removes them, other than those in
+// missing which give a 404
+func removeServer(functions []types.FunctionStatus, missing string) (*httptest.Server, func() []string) {
+	var (
+		mu      sync.Mutex
+		removed []string
+	)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(functions)
+		case http.MethodDelete:
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["functionName"] == missing {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			mu.Lock()
+			removed = append(removed, req["functionName"])
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+
+	return s, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return removed
+	}
+}
+
+func Test_remove_StackReportsFailures(t *testing.T) {
+	s, removed := removeServer(nil, "fn2")
+	defer s.Close()
+
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  fn1:
+    image: fn1:latest
+  fn2:
+    image: fn2:latest
+  fn3:
+    image: fn3:latest
+`)
+	stackFile.Close()
+
+	resetForTest()
+
+	var runErr error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"remove",
+			"-f", stackFile.Name(),
+			"--gateway=" + s.URL,
+			"--continue-on-error",
+		})
+		runErr = faasCmd.Execute()
+	})
+
+	if runErr == nil || runErr.Error() != "unable to remove 1 function(s): fn2" {
+		t.Errorf("want an error naming fn2, got %v", runErr)
+	}
+	if !strings.Contains(stdOut, "Removed 2 of 3 function(s).") {
+		t.Errorf("want a summary, got:\n%s", stdOut)
+	}
+	if got := strings.Join(removed(), ","); got != "fn1,fn3" {
+		t.Errorf("want fn1 and fn3 removed, got %s", got)
+	}
+}
+
+func Test_remove_StackFailFast(t *testing.T) {
+	s, removed := removeServer(nil, "fn2")
+	defer s.Close()
+
+	stackFile, err := ioutil.TempFile("", "stack*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stackFile.Name())
+	stackFile.WriteString(`provider:
+  name: openfaas
+functions:
+  fn1:
+    image: fn1:latest
+  fn2:
+    image: fn2:latest
+  fn3:
+    image: fn3:latest
+`)
+	stackFile.Close()
+
+	resetForTest()
+
+	var runErr error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"remove",
+			"-f", stackFile.Name(),
+			"--gateway=" + s.URL,
+		})
+		runErr = faasCmd.Execute()
+	})
+
+	if runErr == nil || runErr.Error() != "unable to remove 1 function(s): fn2" {
+		t.Errorf("want an error naming fn2, got %v", runErr)
+	}
+	if !strings.Contains(stdOut, "Removed 1 of 3 function(s).") {
+		t.Errorf("want a summary, got:\n%s", stdOut)
+	}
+	if got := strings.Join(removed(), ","); got != "fn1" {
+		t.Errorf("want only fn1 removed before fn2 failed, got %s", got)
+	}
+}
+
+func Test_remove_FailFastAndContinueOnError(t *testing.T) {
+	resetForTest()
+	defer func() {
+		removeCmd.Flags().Lookup("fail-fast").Changed = false
+	}()
+
+	faasCmd.SetArgs([]string{"remove", "figlet", "--fail-fast", "--continue-on-error"})
+	err := faasCmd.Execute()
+
+	want := "--fail-fast and --continue-on-error are mutually exclusive"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+func Test_remove_All(t *testing.T) {
+	functions := []types.FunctionStatus{{Name: "nodeinfo"}, {Name: "figlet"}}
+
+	cases := []struct {
+		name        string
+		args        []string
+		input       string
+		wantErr     string
+		wantRemoved string
+	}{
+		{name: "with --yes", args: []string{"--yes"}, wantRemoved: "figlet,nodeinfo"},
+		{name: "confirmed", input: "y\n", wantRemoved: "figlet,nodeinfo"},
+		{name: "declined", input: "n\n", wantErr: "no functions were removed, answer y to remove them or pass --yes"},
+		{name: "no answer", wantErr: "no functions were removed, answer y to remove them or pass --yes"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, removed := removeServer(functions, "")
+			defer s.Close()
+
+			resetForTest()
+			faasCmd.SetIn(strings.NewReader(c.input))
+			defer func() {
+				removeAll = false
+				removeYes = false
+				faasCmd.SetIn(nil)
+			}()
+
+			var runErr error
+			stdOut := test.CaptureStdout(func() {
+				faasCmd.SetArgs(append([]string{"remove", "--all", "--gateway=" + s.URL}, c.args...))
+				runErr = faasCmd.Execute()
+			})
+
+			if !strings.Contains(stdOut, "The following 2 function(s) will be removed:\n  figlet\n  nodeinfo\n") {
+				t.Errorf("want the functions listed, got:\n%s", stdOut)
+			}
+
+			if len(c.wantErr) > 0 {
+				if runErr == nil || runErr.Error() != c.wantErr {
+					t.Errorf("want error %q, got %v", c.wantErr, runErr)
+				}
+			} else if runErr != nil {
+				t.Errorf("want no error, got %s", runErr)
+			}
+
+			if got := strings.Join(removed(), ","); got != c.wantRemoved {
+				t.Errorf("want removed %q, got %q", c.wantRemoved, got)
+			}
+		})
+	}
+}
+
+func Test_remove_AllWithFunctionName(t *testing.T) {
+	resetForTest()
+	defer func() { removeAll = false }()
+
+	faasCmd.SetArgs([]string{"remove", "--all", "figlet"})
+	err := faasCmd.Execute()
+
+	want := "--all removes every function in the namespace, so it can't be used with a function name or --yaml"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+func Test_remove_PickFunction(t *testing.T) {
+	s, removed := removeServer([]types.FunctionStatus{{Name: "nodeinfo"}, {Name: "figlet"}}, "")
+	defer s.Close()
+
+	resetForTest()
+	previousIsTerminal := isTerminal
+	isTerminal = func(io.Reader) bool { return true }
+	faasCmd.SetIn(strings.NewReader("fig\n\n"))
+	defer func() {
+		isTerminal = previousIsTerminal
+		faasCmd.SetIn(nil)
+	}()
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"remove", "--gateway=" + s.URL})
+		err = faasCmd.Execute()
+	})
+
+	if err != nil {
+		t.Fatalf("want the function picked, got %s", err)
+	}
+	if !strings.Contains(stdOut, "Press enter to pick figlet") {
+		t.Errorf("want the list narrowed to figlet, got:\n%s", stdOut)
+	}
+	if got := strings.Join(removed(), ","); got != "figlet" {
+		t.Errorf("want figlet removed, got %q", got)
+	}
+}
+
+func Test_remove_NoNameWithoutTerminal(t *testing.T) {
+	resetForTest()
+	faasCmd.SetIn(strings.NewReader(""))
+	defer faasCmd.SetIn(nil)
+
+	faasCmd.SetArgs([]string{"remove", "--gateway=http://127.0.0.1:1"})
+	err := faasCmd.Execute()
+
+	want := "please provide the name of a function to delete"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
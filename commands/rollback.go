@@ -0,0 +1,163 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/spf13/cobra"
+)
+
+// previousImageAnnotation is written on each deploy with the image being replaced
+const previousImageAnnotation = "com.openfaas.previous-image"
+
+var rollbackToImage string
+
+func init() {
+	rollbackCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	rollbackCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
+	rollbackCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function")
+	rollbackCmd.Flags().StringVar(&rollbackToImage, "to-image", "", "Image to roll back to instead of the previous image recorded on deploy")
+
+	faasCmd.AddCommand(rollbackCmd)
+}
+
+// rollbackCmd redeploys a function with the image it had before the last deploy
+var rollbackCmd = &cobra.Command{
+	Use:   `rollback FUNCTION_NAME [--to-image IMAGE] [--gateway GATEWAY_URL] [--namespace NAMESPACE]`,
+	Short: "Roll back a function to its previous image",
+	Long: `Redeploys a function with the image it was running before the last deploy, as
+recorded by faas-cli in the "` + previousImageAnnotation + `" annotation, or with the
+image given by --to-image.
+
+The function is redeployed from the spec reported by the gateway, with its
+labels, annotations, fprocess, environment variables, secrets, constraints and
+resources. A gateway which doesn't report the environment, secrets, constraints
+or resources of its functions is refused, so redeploy from your stack file with
+the previous image instead.`,
+	Example: `  faas-cli rollback figlet
+  faas-cli rollback figlet --to-image functions/figlet:0.13.0
+  faas-cli rollback figlet --namespace staging-fn`,
+	RunE: runRollback,
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("please provide the name of a function to roll back")
+	}
+	name := args[0]
+
+	gatewayAddress := getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
+
+	cliAuth := NewCLIAuth(token, gatewayAddress)
+	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
+	proxyClient := proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
+	ctx := context.Background()
+
+	status, err := proxyClient.GetFunctionStatus(ctx, name, functionNamespace)
+	if err != nil {
+		return err
+	}
+
+	if err := checkReportsSpec(name, status, "a rollback"); err != nil {
+		return err
+	}
+
+	deploySpec := statusSpec(name, status)
+	if deploySpec.Annotations == nil {
+		deploySpec.Annotations = map[string]string{}
+	}
+
+	targetImage := rollbackToImage
+	if len(targetImage) == 0 {
+		targetImage = deploySpec.Annotations[previousImageAnnotation]
+	}
+
+	if len(targetImage) == 0 {
+		return fmt.Errorf("no previous image is recorded for %s, pass the image to roll back to with --to-image", name)
+	}
+
+	// record the current image so that another rollback reverts this one
+	deploySpec.Annotations[previousImageAnnotation] = status.Image
+	deploySpec.Image = targetImage
+	deploySpec.TLSInsecure = tlsInsecure
+	deploySpec.Token = token
+	deploySpec.Namespace = functionNamespace
+
+	fmt.Printf("Rolling back %s from %s to %s.\n", name, status.Image, targetImage)
+
+	statusCode := proxyClient.DeployFunction(ctx, deploySpec)
+	if badStatusCode(statusCode) {
+		return &proxy.HTTPError{StatusCode: statusCode, Message: fmt.Sprintf("Function '%s' failed to roll back with status code: %d", name, statusCode)}
+	}
+
+	return nil
+}
+
+// statusSpec gives the spec to redeploy the function as the gateway reports
+// it, with its environment, secrets, constraints and resources
+func statusSpec(name string, status proxy.FunctionStatus) *proxy.DeployFunctionSpec {
+	spec := &proxy.DeployFunctionSpec{
+		FProcess:               status.EnvProcess,
+		FunctionName:           name,
+		Image:                  status.Image,
+		EnvVars:                status.EnvVars,
+		Constraints:            status.Constraints,
+		Secrets:                status.Secrets,
+		ReadOnlyRootFilesystem: status.ReadOnlyRootFilesystem,
+		Update:                 true,
+		FunctionResourceRequest: proxy.FunctionResourceRequest{
+			Limits:   stackResources(status.Limits),
+			Requests: stackResources(status.Requests),
+		},
+	}
+
+	if status.Labels != nil {
+		spec.Labels = *status.Labels
+	}
+	if status.Annotations != nil {
+		spec.Annotations = *status.Annotations
+	}
+	return spec
+}
+
+// checkReportsSpec gives an error when the gateway doesn't report the parts
+// of the spec which the vendored types leave out, as redeploying the function
+// from its status would then remove them
+func checkReportsSpec(name string, status proxy.FunctionStatus, action string) error {
+	if status.ReportsSpec() {
+		return nil
+	}
+	return fmt.Errorf("the gateway did not report the environment, secrets, constraints or resources of %s, so %s would remove them, redeploy it from your stack file instead", name, action)
+}
+
+// stampPreviousImage records the image which is currently deployed in the
+// previous-image annotation so that rollback can revert to it, the existing
+// annotation is kept when the image is unchanged.
+func stampPreviousImage(ctx context.Context, client *proxy.Client, spec *proxy.DeployFunctionSpec) {
+	if _, ok := spec.Annotations[previousImageAnnotation]; ok {
+		return
+	}
+
+	status, err := client.GetFunctionInfo(ctx, spec.FunctionName, spec.Namespace)
+	if err != nil {
+		// the function is not deployed yet
+		return
+	}
+
+	if spec.Annotations == nil {
+		spec.Annotations = map[string]string{}
+	}
+
+	if len(status.Image) > 0 && status.Image != spec.Image {
+		spec.Annotations[previousImageAnnotation] = status.Image
+	} else if status.Annotations != nil {
+		if previous, ok := (*status.Annotations)[previousImageAnnotation]; ok {
+			spec.Annotations[previousImageAnnotation] = previous
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_rollback_UsesPreviousImageAnnotation(t *testing.T) {
+	var deployed types.FunctionDeployment
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(proxy.FunctionStatus{
+				FunctionStatus: types.FunctionStatus{
+					Name:        "figlet",
+					Image:       "functions/figlet:0.2",
+					Annotations: &map[string]string{previousImageAnnotation: "functions/figlet:0.1"},
+				},
+				EnvVars: map[string]string{"LOG_LEVEL": "debug"},
+				Secrets: []string{"api-key"},
+				Limits:  &types.FunctionResources{Memory: "128Mi"},
+			})
+			return
+		}
+
+		json.NewDecoder(r.Body).Decode(&deployed)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"rollback",
+			"figlet",
+			"--gateway=" + s.URL,
+		})
+		faasCmd.Execute()
+	})
+
+	if !strings.Contains(stdOut, "Rolling back figlet from functions/figlet:0.2 to functions/figlet:0.1.") {
+		t.Fatalf("Output is not as expected:\n%s", stdOut)
+	}
+
+	if deployed.Image != "functions/figlet:0.1" {
+		t.Errorf("want image functions/figlet:0.1, got %s", deployed.Image)
+	}
+
+	if got := (*deployed.Annotations)[previousImageAnnotation]; got != "functions/figlet:0.2" {
+		t.Errorf("want previous image functions/figlet:0.2, got %s", got)
+	}
+
+	if got := deployed.EnvVars["LOG_LEVEL"]; got != "debug" {
+		t.Errorf("want the environment kept, got %v", deployed.EnvVars)
+	}
+	if len(deployed.Secrets) != 1 || deployed.Secrets[0] != "api-key" {
+		t.Errorf("want the secrets kept, got %v", deployed.Secrets)
+	}
+	if deployed.Limits == nil || deployed.Limits.Memory != "128Mi" {
+		t.Errorf("want the limits kept, got %v", deployed.Limits)
+	}
+}
+
+func Test_rollback_GatewayWithoutSpec(t *testing.T) {
+	deploys := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(types.FunctionStatus{
+				Name:        "figlet",
+				Image:       "functions/figlet:0.2",
+				Annotations: &map[string]string{previousImageAnnotation: "functions/figlet:0.1"},
+			})
+			return
+		}
+
+		deploys++
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	faasCmd.SetArgs([]string{
+		"rollback",
+		"figlet",
+		"--gateway=" + s.URL,
+	})
+	err := faasCmd.Execute()
+
+	if err == nil || !strings.Contains(err.Error(), "the gateway did not report the environment, secrets, constraints or resources of figlet") {
+		t.Errorf("want the rollback to be refused, got %v", err)
+	}
+	if deploys != 0 {
+		t.Errorf("want no deploy, got %d", deploys)
+	}
+}
+
+func Test_rollback_WithoutHistory(t *testing.T) {
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/figlet",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody: proxy.FunctionStatus{
+				FunctionStatus: types.FunctionStatus{Name: "figlet", Image: "functions/figlet:0.2"},
+				EnvVars:        map[string]string{"LOG_LEVEL": "debug"},
+			},
+		},
+	})
+	defer s.Close()
+
+	faasCmd.SetArgs([]string{
+		"rollback",
+		"figlet",
+		"--gateway=" + s.URL,
+	})
+	err := faasCmd.Execute()
+
+	want := "no previous image is recorded for figlet, pass the image to roll back to with --to-image"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+func Test_stampPreviousImage(t *testing.T) {
+	testCases := []struct {
+		title   string
+		current types.FunctionStatus
+		image   string
+		want    string
+	}{
+		{
+			title:   "image changed",
+			current: types.FunctionStatus{Image: "functions/figlet:0.1"},
+			image:   "functions/figlet:0.2",
+			want:    "functions/figlet:0.1",
+		},
+		{
+			title:   "image unchanged keeps history",
+			current: types.FunctionStatus{Image: "functions/figlet:0.2", Annotations: &map[string]string{previousImageAnnotation: "functions/figlet:0.1"}},
+			image:   "functions/figlet:0.2",
+			want:    "functions/figlet:0.1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.title, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(testCase.current)
+			}))
+			defer s.Close()
+
+			client := proxy.NewClient(NewCLIAuth("", s.URL), s.URL, nil, nil)
+			spec := &proxy.DeployFunctionSpec{FunctionName: "figlet", Image: testCase.image}
+
+			stampPreviousImage(context.Background(), client, spec)
+
+			if got := spec.Annotations[previousImageAnnotation]; got != testCase.want {
+				t.Errorf("want %q, got %q", testCase.want, got)
+			}
+		})
+	}
+}
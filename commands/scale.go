@@ -0,0 +1,132 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/stack"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// scaleMinLabel gives the minimum replicas for a function in the stack file
+	scaleMinLabel = "com.openfaas.scale.min"
+	// defaultMinReplicas is used by the gateway when no minimum is set
+	defaultMinReplicas = 1
+)
+
+var scaleReplicas uint64
+
+func init() {
+	scaleCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	scaleCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yml file")
+	scaleCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
+	scaleCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function")
+	scaleCmd.Flags().Uint64Var(&scaleReplicas, "replicas", 0, "Number of replicas to scale the function to")
+
+	faasCmd.AddCommand(scaleCmd)
+}
+
+// scaleCmd sets the replica count of deployed functions without a redeploy
+var scaleCmd = &cobra.Command{
+	Use: `scale FUNCTION_NAME --replicas REPLICAS [--gateway GATEWAY_URL] [--namespace NAMESPACE]
+  faas-cli scale -f YAML_FILE [--regex "REGEX"] [--filter "WILDCARD"]`,
+	Short: "Scale deployed OpenFaaS functions",
+	Long: `Sets the number of replicas for a deployed function without redeploying it. With
+the "--yaml" flag each function is scaled to its "` + scaleMinLabel + `" label,
+or to ` + strconv.Itoa(defaultMinReplicas) + ` when the label is not set.`,
+	Example: `  faas-cli scale figlet --replicas 5
+  faas-cli scale figlet --replicas 0 --namespace staging-fn
+  faas-cli scale -f ./stack.yml`,
+	RunE: runScale,
+}
+
+func runScale(cmd *cobra.Command, args []string) error {
+	var services stack.Services
+	var yamlGateway string
+	if len(yamlFile) > 0 && len(args) == 0 {
+		parsedServices, err := stack.ParseYAMLFiles(stackFiles(), regex, filter, envsubst)
+		if err != nil {
+			return err
+		}
+
+		if parsedServices != nil {
+			services = *parsedServices
+			yamlGateway = services.Provider.GatewayURL
+		}
+	}
+
+	gatewayAddress := getGatewayURL(gateway, defaultGateway, yamlGateway, os.Getenv(openFaaSURLEnvironment))
+
+	cliAuth := NewCLIAuth(token, gatewayAddress)
+	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
+	proxyClient := proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
+	ctx := context.Background()
+
+	if len(services.Functions) > 0 {
+		for k, function := range services.Functions {
+			replicas, err := getMinReplicas(function)
+			if err != nil {
+				return fmt.Errorf("function %s: %s", k, err.Error())
+			}
+
+			namespace := functionNamespace
+			if len(function.Namespace) > 0 {
+				namespace = function.Namespace
+			}
+
+			if err := scaleFunction(ctx, proxyClient, k, namespace, replicas); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(args) < 1 {
+		return fmt.Errorf("please provide the name of a function to scale")
+	}
+
+	if !cmd.Flags().Changed("replicas") {
+		return fmt.Errorf("--replicas is required when scaling a function by name")
+	}
+
+	return scaleFunction(ctx, proxyClient, args[0], functionNamespace, scaleReplicas)
+}
+
+// scaleFunction prints the previous replica count along with the new one
+func scaleFunction(ctx context.Context, client *proxy.Client, name, namespace string, replicas uint64) error {
+	status, err := client.GetFunctionInfo(ctx, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	if err := client.ScaleFunction(ctx, name, namespace, replicas); err != nil {
+		return err
+	}
+
+	fmt.Printf("Scaled %s from %d to %d replica(s).\n", name, status.Replicas, replicas)
+	return nil
+}
+
+func getMinReplicas(function stack.Function) (uint64, error) {
+	if function.Labels == nil {
+		return defaultMinReplicas, nil
+	}
+
+	value, ok := (*function.Labels)[scaleMinLabel]
+	if !ok {
+		return defaultMinReplicas, nil
+	}
+
+	replicas, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s label: %s", scaleMinLabel, value)
+	}
+	return replicas, nil
+}
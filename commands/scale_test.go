@@ -0,0 +1,76 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/stack"
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+func Test_scale(t *testing.T) {
+	resetForTest()
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/figlet",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       types.FunctionStatus{Name: "figlet", Replicas: 1},
+		},
+		{
+			Method:             http.MethodPost,
+			Uri:                "/system/scale-function/figlet",
+			ResponseStatusCode: http.StatusAccepted,
+		},
+	})
+	defer s.Close()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"scale",
+			"figlet",
+			"--gateway=" + s.URL,
+			"--replicas=5",
+		})
+		faasCmd.Execute()
+	})
+
+	if !strings.Contains(stdOut, "Scaled figlet from 1 to 5 replica(s).") {
+		t.Fatalf("Output is not as expected:\n%s", stdOut)
+	}
+}
+
+func Test_getMinReplicas(t *testing.T) {
+	testCases := []struct {
+		title   string
+		labels  *map[string]string
+		want    uint64
+		wantErr bool
+	}{
+		{title: "no labels", labels: nil, want: 1},
+		{title: "no min label", labels: &map[string]string{"canary": "true"}, want: 1},
+		{title: "min label", labels: &map[string]string{scaleMinLabel: "3"}, want: 3},
+		{title: "invalid min label", labels: &map[string]string{scaleMinLabel: "three"}, wantErr: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.title, func(t *testing.T) {
+			got, err := getMinReplicas(stack.Function{Labels: testCase.labels})
+			if testCase.wantErr {
+				if err == nil {
+					t.Errorf("want error, got none")
+				}
+				return
+			}
+
+			if err != nil || got != testCase.want {
+				t.Errorf("want %d, got %d (err: %v)", testCase.want, got, err)
+			}
+		})
+	}
+}
@@ -7,9 +7,12 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/openfaas/faas-cli/proxy"
 	types "github.com/openfaas/faas-provider/types"
@@ -19,20 +22,28 @@ import (
 var (
 	literalSecret string
 	secretFile    string
+	secretForce   bool
 )
 
 // secretCreateCmd represents the secretCreate command
 var secretCreateCmd = &cobra.Command{
 	Use: `create SECRET_NAME 
 			[--from-literal=SECRET_VALUE]
-			[--from-file=/path/to/secret/file]
+			[--from-file=[SECRET_NAME=]/path/to/secret/file]
 			[STDIN]
 			[--tls-no-verify]`,
 	Short: "Create a new secret",
-	Long:  `The create command creates a new secret from file, literal or STDIN`,
+	Long: `The create command creates a new secret from file, literal or STDIN. The
+contents of a file are used as they are, a literal or STDIN is trimmed of
+whitespace. The name defaults to the file name with --from-file.
+
+Creating a secret which already exists fails, pass --force to update it
+instead or use "faas-cli secret update".`,
 	Example: `faas-cli secret create secret-name --from-literal=secret-value
 faas-cli secret create secret-name --from-literal=secret-value --gateway=http://127.0.0.1:8080
 faas-cli secret create secret-name --from-file=/path/to/secret/file --gateway=http://127.0.0.1:8080
+faas-cli secret create --from-file=api-key=/path/to/key.txt
+faas-cli secret create secret-name --from-literal=new-value --force
 cat /path/to/secret/file | faas-cli secret create secret-name`,
 	RunE:    runSecretCreate,
 	PreRunE: preRunSecretCreate,
@@ -40,8 +51,8 @@ cat /path/to/secret/file | faas-cli secret create secret-name`,
 
 func init() {
 	secretCreateCmd.Flags().StringVar(&literalSecret, "from-literal", "", "Value of the secret")
-	secretCreateCmd.Flags().StringVar(&secretFile, "from-file", "", "Path to the secret file")
-	secretCreateCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
+	secretCreateCmd.Flags().StringVar(&secretFile, "from-file", "", "Path to the secret file, or NAME=path to give the secret a name")
+	secretCreateCmd.Flags().BoolVar(&secretForce, "force", false, "Update the secret if it already exists")
 	secretCreateCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
 	secretCreateCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
 	secretCreateCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function")
@@ -49,19 +60,16 @@ func init() {
 }
 
 func preRunSecretCreate(cmd *cobra.Command, args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("secret name required")
-	}
-
-	if len(args) > 1 {
-		return fmt.Errorf("too many values for secret name")
+	name, err := secretNameFromArgs(args)
+	if err != nil {
+		return err
 	}
 
 	if len(secretFile) > 0 && len(literalSecret) > 0 {
 		return fmt.Errorf("please provide secret using only one option from --from-literal, --from-file and STDIN")
 	}
 
-	isValid, err := validateSecretName(args[0])
+	isValid, err := validateSecretName(name)
 	if !isValid {
 		return err
 	}
@@ -70,18 +78,23 @@ func preRunSecretCreate(cmd *cobra.Command, args []string) error {
 }
 
 func runSecretCreate(cmd *cobra.Command, args []string) error {
+	name, err := secretNameFromArgs(args)
+	if err != nil {
+		return err
+	}
+
 	secret := types.Secret{
-		Name:      args[0],
+		Name:      name,
 		Namespace: functionNamespace,
 	}
 
 	switch {
 	case len(literalSecret) > 0:
-		secret.Value = literalSecret
+		secret.Value = strings.TrimSpace(literalSecret)
 
 	case len(secretFile) > 0:
-		var err error
-		secret.Value, err = readSecretFromFile(secretFile)
+		_, path := parseSecretFile(secretFile)
+		secret.Value, err = readSecretFromFile(path)
 		if err != nil {
 			return err
 		}
@@ -96,11 +109,9 @@ func runSecretCreate(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
-		secret.Value = string(secretStdin)
+		secret.Value = strings.TrimSpace(string(secretStdin))
 	}
 
-	secret.Value = strings.TrimSpace(secret.Value)
-
 	if len(secret.Value) == 0 {
 		return fmt.Errorf("must provide a non empty secret via --from-literal, --from-file or STDIN")
 	}
@@ -114,15 +125,82 @@ func runSecretCreate(cmd *cobra.Command, args []string) error {
 	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
 	client := proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
 	fmt.Println("Creating secret: " + secret.Name)
-	_, output := client.CreateSecret(context.Background(), secret)
-	fmt.Printf(output)
+	status, output := client.CreateSecret(context.Background(), secret)
+	if status == http.StatusConflict {
+		if !secretForce {
+			return fmt.Errorf("secret %s already exists, pass --force or use \"faas-cli secret update\" to update it", secret.Name)
+		}
+
+		fmt.Println("Secret exists, updating secret: " + secret.Name)
+		status, output = client.UpdateSecret(context.Background(), secret)
+	}
+
+	if !isSecretStatusOK(status) {
+		return &proxy.HTTPError{StatusCode: status, Message: strings.TrimSpace(output)}
+	}
+	fmt.Print(output)
 
 	return nil
 }
 
+// isSecretStatusOK is true when the gateway accepted a secret request
+func isSecretStatusOK(status int) bool {
+	return status >= http.StatusOK && status < http.StatusMultipleChoices
+}
+
+// readSecretFromFile gives the contents of the file as they are, without
+// trimming whitespace. The gateway's API takes the value as a string, so
+// binary files which are not valid UTF-8 are refused rather than corrupted.
 func readSecretFromFile(secretFile string) (string, error) {
 	fileData, err := ioutil.ReadFile(secretFile)
-	return string(fileData), err
+	if err != nil {
+		return "", fmt.Errorf("unable to read secret file: %s", err.Error())
+	}
+
+	if !utf8.Valid(fileData) {
+		return "", fmt.Errorf("the secret file %s is not valid UTF-8, binary secrets are not supported", secretFile)
+	}
+	return string(fileData), nil
+}
+
+// parseSecretFile splits the --from-file value into NAME=path, the name is
+// empty when only a path is given
+func parseSecretFile(value string) (string, string) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return "", value
+}
+
+// secretNameFromArgs gives the secret name from the argument, the NAME in
+// --from-file NAME=path or the file name given to --from-file, in that order
+func secretNameFromArgs(args []string) (string, error) {
+	if len(args) > 1 {
+		return "", fmt.Errorf("too many values for secret name")
+	}
+
+	var name string
+	if len(args) == 1 {
+		name = args[0]
+	}
+
+	if len(secretFile) > 0 {
+		fileName, path := parseSecretFile(secretFile)
+		switch {
+		case len(fileName) > 0 && len(name) > 0 && fileName != name:
+			return "", fmt.Errorf("the secret name %s does not match %s given in --from-file", name, fileName)
+		case len(fileName) > 0:
+			name = fileName
+		case len(name) == 0:
+			name = filepath.Base(path)
+		}
+	}
+
+	if len(name) == 0 {
+		return "", fmt.Errorf("secret name required")
+	}
+	return name, nil
 }
 
 // Kubernetes DNS-1123 Subdomain Regex
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"regexp"
+	"strings"
 	"testing"
 
 	"io/ioutil"
@@ -155,3 +156,175 @@ func Test_validateSecretName(t *testing.T) {
 		}
 	}
 }
+
+func Test_secretNameFromArgs(t *testing.T) {
+	defer func() { secretFile = "" }()
+
+	cases := []struct {
+		name    string
+		args    []string
+		file    string
+		want    string
+		wantErr string
+	}{
+		{name: "argument", args: []string{"api-key"}, want: "api-key"},
+		{name: "file name", file: "/tmp/api-key.txt", want: "api-key.txt"},
+		{name: "name from file flag", file: "api-key=/tmp/key.txt", want: "api-key"},
+		{name: "same name in both", args: []string{"api-key"}, file: "api-key=/tmp/key.txt", want: "api-key"},
+		{name: "argument wins over the file name", args: []string{"api-key"}, file: "/tmp/key.txt", want: "api-key"},
+		{name: "different names", args: []string{"db-password"}, file: "api-key=/tmp/key.txt", wantErr: "the secret name db-password does not match api-key given in --from-file"},
+		{name: "no name", wantErr: "secret name required"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			secretFile = c.file
+			got, err := secretNameFromArgs(c.args)
+			if len(c.wantErr) > 0 {
+				if err == nil || err.Error() != c.wantErr {
+					t.Errorf("want error %q, got %v", c.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("want %q, got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func Test_readSecretFromFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("  line one\nline two\n")
+	file.Close()
+
+	value, err := readSecretFromFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "  line one\nline two\n" {
+		t.Errorf("want the file contents unchanged, got %q", value)
+	}
+
+	ioutil.WriteFile(file.Name(), []byte{0xff, 0xfe, 0x00}, 0600)
+	if _, err := readSecretFromFile(file.Name()); err == nil {
+		t.Errorf("want an error for a binary file")
+	}
+}
+
+func Test_SecretCreateFromNamedFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("value")
+	file.Close()
+	literalSecret = ""
+	defer func() { secretFile = "" }()
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodPost,
+			Uri:                "/system/secrets",
+			ResponseStatusCode: http.StatusOK,
+		},
+	})
+	defer s.Close()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"secret",
+			"create",
+			"--gateway=" + s.URL,
+			"--from-file=api-key=" + file.Name(),
+		})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if found, _ := regexp.MatchString(`Creating secret: api-key`, stdOut); !found {
+		t.Errorf("want the secret named from --from-file, got %q", stdOut)
+	}
+}
+
+func Test_SecretCreateExisting(t *testing.T) {
+	defer func() {
+		literalSecret = ""
+		secretForce = false
+	}()
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodPost,
+			Uri:                "/system/secrets",
+			ResponseStatusCode: http.StatusConflict,
+		},
+	})
+	defer s.Close()
+
+	var err error
+	test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"secret",
+			"create",
+			"--gateway=" + s.URL,
+			"test-secret",
+			"--from-literal=value",
+		})
+		err = faasCmd.Execute()
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "secret test-secret already exists") {
+		t.Fatalf("want an error for an existing secret, got %v", err)
+	}
+}
+
+func Test_SecretCreateExistingForce(t *testing.T) {
+	defer func() {
+		literalSecret = ""
+		secretForce = false
+	}()
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodPost,
+			Uri:                "/system/secrets",
+			ResponseStatusCode: http.StatusConflict,
+		},
+		{
+			Method:             http.MethodPut,
+			Uri:                "/system/secrets",
+			ResponseStatusCode: http.StatusOK,
+		},
+	})
+	defer s.Close()
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"secret",
+			"create",
+			"--gateway=" + s.URL,
+			"test-secret",
+			"--from-literal=value",
+			"--force",
+		})
+		err = faasCmd.Execute()
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdOut, "Secret exists, updating secret: test-secret") || !strings.Contains(stdOut, "Updated: 200 OK") {
+		t.Errorf("want the secret to be updated, got %q", stdOut)
+	}
+}
@@ -19,10 +19,11 @@ var secretUpdateCmd = &cobra.Command{
 	Use:     "update [--tls-no-verify]",
 	Aliases: []string{"u"},
 	Short:   "Update a secret",
-	Long:    `Update a secret by name`,
+	Long:    `Update the value of an existing secret by name, fails if the secret does not exist`,
 	Example: `faas-cli secret update NAME
 faas-cli secret update NAME --from-literal=secret-value
 faas-cli secret update NAME --from-file=/path/to/secret/file
+faas-cli secret update --from-file=NAME=/path/to/secret/file
 faas-cli secret update NAME --from-literal=secret-value --gateway=http://127.0.0.1:8080
 cat /path/to/secret/file | faas-cli secret update NAME`,
 	RunE:    runSecretUpdate,
@@ -31,21 +32,16 @@ cat /path/to/secret/file | faas-cli secret update NAME`,
 
 func init() {
 	secretUpdateCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
-	secretUpdateCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
 	secretUpdateCmd.Flags().StringVar(&literalSecret, "from-literal", "", "Value of the secret")
-	secretUpdateCmd.Flags().StringVar(&secretFile, "from-file", "", "Path to the secret file")
+	secretUpdateCmd.Flags().StringVar(&secretFile, "from-file", "", "Path to the secret file, or NAME=path to give the secret a name")
 	secretUpdateCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
 	secretUpdateCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function")
 	secretCmd.AddCommand(secretUpdateCmd)
 }
 
 func preRunSecretUpdate(cmd *cobra.Command, args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("secret name required")
-	}
-
-	if len(args) > 1 {
-		return fmt.Errorf("too many values for secret name")
+	if _, err := secretNameFromArgs(args); err != nil {
+		return err
 	}
 
 	if len(secretFile) > 0 && len(literalSecret) > 0 {
@@ -62,21 +58,26 @@ func runSecretUpdate(cmd *cobra.Command, args []string) error {
 		fmt.Println(msg)
 	}
 
+	name, err := secretNameFromArgs(args)
+	if err != nil {
+		return err
+	}
+
 	secret := types.Secret{
-		Name:      args[0],
+		Name:      name,
 		Namespace: functionNamespace,
 	}
 
 	switch {
 	case len(literalSecret) > 0:
-		secret.Value = literalSecret
+		secret.Value = strings.TrimSpace(literalSecret)
 
 	case len(secretFile) > 0:
-		content, err := ioutil.ReadFile(secretFile)
+		_, path := parseSecretFile(secretFile)
+		secret.Value, err = readSecretFromFile(path)
 		if err != nil {
-			return fmt.Errorf("unable to read secret file: %s", err.Error())
+			return err
 		}
-		secret.Value = string(content)
 
 	default:
 		stat, _ := os.Stdin.Stat()
@@ -88,11 +89,9 @@ func runSecretUpdate(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("unable to read standard input: %s", err.Error())
 		}
-		secret.Value = string(secretStdin)
+		secret.Value = strings.TrimSpace(string(secretStdin))
 	}
 
-	secret.Value = strings.TrimSpace(secret.Value)
-
 	if len(secret.Value) == 0 {
 		return fmt.Errorf("must provide a non empty secret via --from-literal, --from-file or STDIN")
 	}
@@ -101,8 +100,11 @@ func runSecretUpdate(cmd *cobra.Command, args []string) error {
 	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
 	client := proxy.NewClient(cliAuth, gatewayAddress, transport, &commandTimeout)
 	fmt.Println("Updating secret: " + secret.Name)
-	_, output := client.UpdateSecret(context.Background(), secret)
-	fmt.Printf(output)
+	status, output := client.UpdateSecret(context.Background(), secret)
+	if !isSecretStatusOK(status) {
+		return &proxy.HTTPError{StatusCode: status, Message: strings.TrimSpace(output)}
+	}
+	fmt.Print(output)
 
 	return nil
 }
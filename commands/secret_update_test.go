@@ -106,3 +106,32 @@ func Test_SecretUpdateFromLiteral(t *testing.T) {
 		t.Fatalf("Output is not as expected:\nExpected:\n%s\n Got:\n%s", `(?m:`+secretName+`)`, stdOut)
 	}
 }
+
+func Test_SecretUpdateNotFound(t *testing.T) {
+	defer func() { literalSecret = "" }()
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodPut,
+			Uri:                "/system/secrets",
+			ResponseStatusCode: http.StatusNotFound,
+		},
+	})
+	defer s.Close()
+
+	var err error
+	test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"secret",
+			"update",
+			"--gateway=" + s.URL,
+			"test-secret",
+			"--from-literal=value",
+		})
+		err = faasCmd.Execute()
+	})
+
+	if err == nil || err.Error() != "unable to find secret: test-secret" {
+		t.Fatalf("want an error for a missing secret, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/docker/pkg/term"
+)
+
+// selectPageSize is how many options are listed at once, typing narrows the
+// list to find the others
+const selectPageSize = 15
+
+// selectOption is one of the choices listed by selectName, the description
+// is shown next to the name to help to pick it
+type selectOption struct {
+	name        string
+	description string
+}
+
+// isTerminal is true when the input can be used to pick from a list, it is
+// replaced in the tests
+var isTerminal = func(in io.Reader) bool {
+	_, ok := term.GetFdInfo(in)
+	return ok
+}
+
+// selectName lists the options and reads lines from in until one is picked.
+// A number picks the option listed with it, enter picks the only option left
+// and any other text narrows the list to the names which match it as a fuzzy
+// pattern, so that "fgt" matches figlet.
+func selectName(in io.Reader, out io.Writer, label string, options []selectOption) (string, error) {
+	if len(options) == 0 {
+		return "", fmt.Errorf("there is no %s to pick from", label)
+	}
+
+	reader := bufio.NewReader(in)
+	pattern := ""
+	for {
+		matches := fuzzyMatches(options, pattern)
+		if len(matches) == 0 {
+			fmt.Fprintf(out, "No %s matches %q.\n", label, pattern)
+			pattern = ""
+			continue
+		}
+
+		printSelectOptions(out, matches)
+		if len(matches) == 1 {
+			fmt.Fprintf(out, "Press enter to pick %s, or type to filter: ", matches[0].name)
+		} else {
+			fmt.Fprintf(out, "Pick a %s by number, or type to filter: ", label)
+		}
+
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if err != nil && len(line) == 0 {
+			fmt.Fprintln(out)
+			return "", fmt.Errorf("no %s was picked", label)
+		}
+
+		if len(line) == 0 {
+			if len(matches) == 1 {
+				return matches[0].name, nil
+			}
+			continue
+		}
+
+		if n, err := strconv.Atoi(line); err == nil && n > 0 && n <= len(matches) && n <= selectPageSize {
+			return matches[n-1].name, nil
+		}
+		pattern = line
+	}
+}
+
+func printSelectOptions(out io.Writer, options []selectOption) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for i, option := range options {
+		if i == selectPageSize {
+			fmt.Fprintf(w, "  ...\t%d more, type to filter\n", len(options)-selectPageSize)
+			break
+		}
+		fmt.Fprintf(w, "  %d)\t%s\t%s\n", i+1, option.name, option.description)
+	}
+	w.Flush()
+}
+
+// fuzzyMatches gives the options whose names contain the characters of the
+// pattern in order, ignoring case
+func fuzzyMatches(options []selectOption, pattern string) []selectOption {
+	var matches []selectOption
+	for _, option := range options {
+		if fuzzyMatch(strings.ToLower(option.name), strings.ToLower(pattern)) {
+			matches = append(matches, option)
+		}
+	}
+	return matches
+}
+
+func fuzzyMatch(name, pattern string) bool {
+	for _, r := range pattern {
+		i := strings.IndexRune(name, r)
+		if i == -1 {
+			return false
+		}
+		name = name[i+len(string(r)):]
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_selectName(t *testing.T) {
+	options := []selectOption{
+		{name: "figlet", description: "ASCII art"},
+		{name: "nodeinfo", description: "Node.js info"},
+		{name: "figlet-2", description: "ASCII art"},
+	}
+
+	cases := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "a number", input: "2\n", want: "nodeinfo"},
+		{name: "a number after filtering", input: "fg\n2\n", want: "figlet-2"},
+		{name: "enter for the only match", input: "NODE\n\n", want: "nodeinfo"},
+		{name: "enter with several matches", input: "\n3\n", want: "figlet-2"},
+		{name: "no match", input: "xyz\n1\n", want: "figlet"},
+		{name: "a number out of range filters", input: "9\n", wantErr: "no function was picked"},
+		{name: "no answer", input: "fig", wantErr: "no function was picked"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := selectName(strings.NewReader(c.input), &out, "function", options)
+
+			if len(c.wantErr) > 0 {
+				if err == nil || err.Error() != c.wantErr {
+					t.Errorf("want error %q, got %v", c.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("want %s picked, got %s\n%s", c.want, err, out.String())
+			}
+			if got != c.want {
+				t.Errorf("want %s picked, got %s\n%s", c.want, got, out.String())
+			}
+		})
+	}
+}
+
+func Test_selectName_ListsOnePage(t *testing.T) {
+	var options []selectOption
+	for _, name := range strings.Split("a b c d e f g h i j k l m n o p q", " ") {
+		options = append(options, selectOption{name: name})
+	}
+
+	var out bytes.Buffer
+	selectName(strings.NewReader(""), &out, "function", options)
+
+	if !strings.Contains(out.String(), "15)  o") || strings.Contains(out.String(), "16)") {
+		t.Errorf("want only the first page listed, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "2 more, type to filter") {
+		t.Errorf("want the rest counted, got:\n%s", out.String())
+	}
+}
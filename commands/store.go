@@ -32,7 +32,7 @@ const (
 var platformValue string
 
 func init() {
-	storeCmd.PersistentFlags().StringVarP(&storeAddress, "url", "u", defaultStore, "Alternative Store URL starting with http(s)://")
+	storeCmd.PersistentFlags().StringVarP(&storeAddress, "url", "u", defaultStore, "Alternative Store URL starting with http(s)://, or set "+storeURLEnvironment)
 	storeCmd.PersistentFlags().StringVarP(&platformValue, "platform", "p", Platform, "Target platform for store")
 
 	faasCmd.AddCommand(storeCmd)
@@ -41,7 +41,9 @@ func init() {
 var storeCmd = &cobra.Command{
 	Use:   `store`,
 	Short: "OpenFaaS store commands",
-	Long:  "Allows browsing and deploying OpenFaaS functions from a store",
+	Long: `Allows browsing and deploying OpenFaaS functions from a store. A store is a
+JSON manifest in the same format as the public OpenFaaS store, give another one
+with --url or the ` + storeURLEnvironment + ` environment variable.`,
 }
 
 func storeList(store string) ([]storeV2.StoreFunction, error) {
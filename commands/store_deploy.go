@@ -6,10 +6,13 @@ package commands
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"sort"
 
 	"github.com/openfaas/faas-cli/proxy"
+	storeV2 "github.com/openfaas/faas-cli/schema/store/v2"
 	"github.com/spf13/cobra"
 )
 
@@ -20,15 +23,14 @@ func init() {
 	storeDeployCmd.Flags().StringVar(&functionName, "name", "", "Name of the deployed function (overriding name from the store)")
 	storeDeployCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function")
 	// Setup flags that are used only by deploy command (variables defined above)
-	storeDeployCmd.Flags().StringArrayVarP(&storeDeployFlags.envvarOpts, "env", "e", []string{}, "Adds one or more environment variables to the defined ones by store (ENVVAR=VALUE)")
-	storeDeployCmd.Flags().StringArrayVarP(&storeDeployFlags.labelOpts, "label", "l", []string{}, "Set one or more label (LABEL=VALUE)")
+	storeDeployCmd.Flags().StringArrayVarP(&storeDeployFlags.envvarOpts, "env", "e", []string{}, "Set one or more environment variables, overriding the ones from the store (ENVVAR=VALUE)")
+	storeDeployCmd.Flags().StringArrayVarP(&storeDeployFlags.labelOpts, "label", "l", []string{}, "Set one or more label, overriding the ones from the store (LABEL=VALUE)")
 	storeDeployCmd.Flags().BoolVar(&storeDeployFlags.replace, "replace", false, "Replace any existing function")
 	storeDeployCmd.Flags().BoolVar(&storeDeployFlags.update, "update", true, "Update existing functions")
 	storeDeployCmd.Flags().StringArrayVar(&storeDeployFlags.constraints, "constraint", []string{}, "Apply a constraint to the function")
 	storeDeployCmd.Flags().StringArrayVar(&storeDeployFlags.secrets, "secret", []string{}, "Give the function access to a secure secret")
 	storeDeployCmd.Flags().BoolVarP(&storeDeployFlags.sendRegistryAuth, "send-registry-auth", "a", false, "send registryAuth from Docker credentials manager with the request")
-	storeDeployCmd.Flags().StringArrayVarP(&storeDeployFlags.annotationOpts, "annotation", "", []string{}, "Set one or more annotation (ANNOTATION=VALUE)")
-	storeDeployCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
+	storeDeployCmd.Flags().StringArrayVarP(&storeDeployFlags.annotationOpts, "annotation", "", []string{}, "Set one or more annotation, overriding the ones from the store (ANNOTATION=VALUE)")
 	storeDeployCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
 
 	// Set bash-completion.
@@ -53,8 +55,13 @@ var storeDeployCmd = &cobra.Command{
 			[--tls-no-verify=false]`,
 
 	Short: "Deploy OpenFaaS functions from a store",
-	Long:  `Same as faas-cli deploy except that function is pre-loaded with arguments from the store`,
+	Long: `Same as faas-cli deploy except that function is pre-loaded with arguments from the store.
+When no name is given and STDIN is a terminal, the functions in the store are
+listed to pick one from.
+The --env, --label and --annotation flags override the values from the store and
+--name deploys the function under another name, so it can be deployed twice.`,
 	Example: `  faas-cli store deploy figlet
+  faas-cli store deploy figlet --name figlet-2 --label team=web
   faas-cli store deploy figlet \
     --gateway=http://127.0.0.1:8080 \
     --env=MYVAR=myval`,
@@ -62,46 +69,37 @@ var storeDeployCmd = &cobra.Command{
 }
 
 func runStoreDeploy(cmd *cobra.Command, args []string) error {
-	if len(args) < 1 {
+	if len(args) < 1 && !isTerminal(cmd.InOrStdin()) {
 		return fmt.Errorf("please provide the function name")
 	}
 
+	if err := validateStoreDeployFlags(storeDeployFlags); err != nil {
+		return err
+	}
+
 	targetPlatform := getTargetPlatform(platformValue)
-	storeItems, err := storeList(storeAddress)
+	storeItems, err := storeList(getStoreURL(storeAddress, os.Getenv(storeURLEnvironment), defaultStore))
 	if err != nil {
 		return err
 	}
 
 	platformFunctions := filterStoreList(storeItems, targetPlatform)
 
+	if len(args) < 1 {
+		name, err := selectStoreFunction(cmd.InOrStdin(), platformFunctions)
+		if err != nil {
+			return err
+		}
+		args = []string{name}
+	}
+
 	requestedStoreFn := args[0]
 	item := storeFindFunction(requestedStoreFn, platformFunctions)
 	if item == nil {
 		return fmt.Errorf("function '%s' not found for platform '%s'", requestedStoreFn, targetPlatform)
 	}
 
-	// Add the store environment variables to the provided ones from cmd
-	if item.Environment != nil {
-		for k, v := range item.Environment {
-			env := fmt.Sprintf("%s=%s", k, v)
-			storeDeployFlags.envvarOpts = append(storeDeployFlags.envvarOpts, env)
-		}
-	}
-
-	// Add the store labels to the provided ones from cmd
-	if item.Labels != nil {
-		for k, v := range item.Labels {
-			label := fmt.Sprintf("%s=%s", k, v)
-			storeDeployFlags.labelOpts = append(storeDeployFlags.labelOpts, label)
-		}
-	}
-
-	if item.Annotations != nil {
-		for k, v := range item.Annotations {
-			annotation := fmt.Sprintf("%s=%s", k, v)
-			storeDeployFlags.annotationOpts = append(storeDeployFlags.annotationOpts, annotation)
-		}
-	}
+	itemDeployFlags := storeDeployOptions(item, storeDeployFlags)
 
 	// Use the network from manifest if not changed by user
 	if !cmd.Flag("network").Changed {
@@ -133,7 +131,7 @@ func runStoreDeploy(cmd *cobra.Command, args []string) error {
 	transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout)
 	proxyClient := proxy.NewClient(cliAuth, gateway, transport, &commandTimeout)
 
-	statusCode, err := deployImage(context.Background(), proxyClient, imageName, item.Fprocess, itemName, registryAuth, storeDeployFlags,
+	statusCode, err := deployImage(context.Background(), proxyClient, imageName, item.Fprocess, itemName, registryAuth, itemDeployFlags,
 		tlsInsecure, item.ReadOnlyRootFilesystem, token, functionNamespace)
 
 	if badStatusCode(statusCode) {
@@ -144,3 +142,53 @@ func runStoreDeploy(cmd *cobra.Command, args []string) error {
 
 	return err
 }
+
+// selectStoreFunction asks which of the functions in the store to deploy,
+// for when no name is given on a terminal
+func selectStoreFunction(in io.Reader, storeItems []storeV2.StoreFunction) (string, error) {
+	var options []selectOption
+	for _, item := range storeItems {
+		options = append(options, selectOption{name: item.Name, description: item.Title})
+	}
+	sort.Slice(options, func(i, j int) bool { return options[i].name < options[j].name })
+
+	return selectName(in, os.Stdout, "function", options)
+}
+
+// validateStoreDeployFlags checks the overrides parse as key=value before the
+// store is fetched
+func validateStoreDeployFlags(flags DeployFlags) error {
+	if _, err := parseMap(flags.envvarOpts, "env"); err != nil {
+		return fmt.Errorf("error parsing envvars: %v", err)
+	}
+
+	if _, err := parseMap(flags.labelOpts, "label"); err != nil {
+		return fmt.Errorf("error parsing labels: %v", err)
+	}
+
+	if _, err := parseAnnotations(flags.annotationOpts); err != nil {
+		return fmt.Errorf("error parsing annotations: %v", err)
+	}
+
+	return nil
+}
+
+// storeDeployOptions puts the environment, labels and annotations from the
+// store before the ones from the flags, so that the flags override them
+func storeDeployOptions(item *storeV2.StoreFunction, flags DeployFlags) DeployFlags {
+	flags.envvarOpts = append(storeMapOptions(item.Environment), flags.envvarOpts...)
+	flags.labelOpts = append(storeMapOptions(item.Labels), flags.labelOpts...)
+	flags.annotationOpts = append(storeMapOptions(item.Annotations), flags.annotationOpts...)
+
+	return flags
+}
+
+func storeMapOptions(values map[string]string) []string {
+	options := []string{}
+	for k, v := range values {
+		options = append(options, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(options)
+
+	return options
+}
@@ -2,9 +2,11 @@ package commands
 
 import (
 	"net/http"
+	"reflect"
 	"regexp"
 	"testing"
 
+	storeV2 "github.com/openfaas/faas-cli/schema/store/v2"
 	"github.com/openfaas/faas-cli/test"
 )
 
@@ -77,3 +79,48 @@ func Test_storeDeploy_withoutNameFlag(t *testing.T) {
 		t.Fatalf("Wrong function name (should not be `foo`):\n%s", stdOut)
 	}
 }
+
+func Test_storeDeployOptions_FlagsOverrideStore(t *testing.T) {
+	item := &storeV2.StoreFunction{
+		Environment: map[string]string{"write_debug": "false", "mode": "ascii"},
+		Labels:      map[string]string{"team": "store"},
+	}
+	flags := DeployFlags{
+		envvarOpts: []string{"write_debug=true"},
+		labelOpts:  []string{"team=web"},
+	}
+
+	got := storeDeployOptions(item, flags)
+
+	env, err := parseMap(got.envvarOpts, "env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"write_debug": "true", "mode": "ascii"}; !reflect.DeepEqual(env, want) {
+		t.Errorf("want env %v, got %v", want, env)
+	}
+
+	labels, err := parseMap(got.labelOpts, "label")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labels["team"] != "web" {
+		t.Errorf("want the label from the flag to win, got %v", labels)
+	}
+
+	if len(flags.envvarOpts) != 1 {
+		t.Errorf("want the flags left unchanged, got %v", flags.envvarOpts)
+	}
+}
+
+func Test_validateStoreDeployFlags(t *testing.T) {
+	err := validateStoreDeployFlags(DeployFlags{labelOpts: []string{"team"}})
+	want := "error parsing labels: label format is not correct, needs key=value: [team]"
+	if err == nil || err.Error() != want {
+		t.Errorf("want %q, got %v", want, err)
+	}
+
+	if err := validateStoreDeployFlags(DeployFlags{envvarOpts: []string{"a=b"}}); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+}
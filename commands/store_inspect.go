@@ -6,6 +6,7 @@ package commands
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"text/tabwriter"
 
 	storeV2 "github.com/openfaas/faas-cli/schema/store/v2"
@@ -33,7 +34,7 @@ func runStoreInspect(cmd *cobra.Command, args []string) error {
 	}
 
 	targetPlatform := getTargetPlatform(platformValue)
-	storeItems, err := storeList(storeAddress)
+	storeItems, err := storeList(getStoreURL(storeAddress, os.Getenv(storeURLEnvironment), defaultStore))
 	if err != nil {
 		return err
 	}
@@ -6,6 +6,7 @@ package commands
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"strings"
 	"text/tabwriter"
 
@@ -33,7 +34,7 @@ var storeListCmd = &cobra.Command{
 func runStoreList(cmd *cobra.Command, args []string) error {
 	targetPlatform := getTargetPlatform(platformValue)
 
-	storeList, err := storeList(storeAddress)
+	storeList, err := storeList(getStoreURL(storeAddress, os.Getenv(storeURLEnvironment), defaultStore))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,281 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/morikuni/aec"
+	storeV2 "github.com/openfaas/faas-cli/schema/store/v2"
+	"github.com/spf13/cobra"
+)
+
+var (
+	storeSearchCategory string
+	storeSearchOutput   string
+)
+
+func init() {
+	storeSearchCmd.Flags().StringVar(&storeSearchCategory, "category", "", "Only show functions in this category")
+	storeSearchCmd.Flags().StringVarP(&storeSearchOutput, "output", "o", "", "Output format, use json to print the matching functions as JSON")
+	storeSearchCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output to see the full description of each function in the store")
+
+	storeCmd.AddCommand(storeSearchCmd)
+}
+
+var storeSearchCmd = &cobra.Command{
+	Use:   `search [TERM] [--category CATEGORY] [--output json]`,
+	Short: "Search for OpenFaaS functions in a store",
+	Long: `Search the store for functions whose title, name or description contain the
+term, or whose title contains its letters in order. The best matches are
+printed first and the matched text is highlighted in a terminal.`,
+	Example: `  faas-cli store search figlet
+  faas-cli store search img --category images
+  faas-cli store search ascii --output json`,
+	PreRunE: preRunStoreSearch,
+	RunE:    runStoreSearch,
+}
+
+// storeSearchMatch is a function from the store which matches a search, the
+// rune positions of the matched text are kept to highlight it
+type storeSearchMatch struct {
+	function    storeV2.StoreFunction
+	rank        int
+	title       []int
+	description []int
+}
+
+func preRunStoreSearch(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("give a single search term, quote it if it contains spaces")
+	}
+
+	if len(args) == 0 && len(storeSearchCategory) == 0 {
+		return fmt.Errorf("give a search term or a --category")
+	}
+
+	if len(storeSearchOutput) > 0 && storeSearchOutput != "json" {
+		return fmt.Errorf("--output must be json, not: %s", storeSearchOutput)
+	}
+
+	return nil
+}
+
+func runStoreSearch(cmd *cobra.Command, args []string) error {
+	var term string
+	if len(args) == 1 {
+		term = args[0]
+	}
+
+	targetPlatform := getTargetPlatform(platformValue)
+
+	storeItems, err := storeList(getStoreURL(storeAddress, os.Getenv(storeURLEnvironment), defaultStore))
+	if err != nil {
+		return err
+	}
+
+	matches := searchStore(filterStoreList(storeItems, targetPlatform), term, storeSearchCategory)
+
+	if storeSearchOutput == "json" {
+		functions := []storeV2.StoreFunction{}
+		for _, match := range matches {
+			functions = append(functions, match.function)
+		}
+
+		out, err := json.MarshalIndent(functions, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(matches) == 0 {
+		fmt.Printf("No functions found in the store for platform '%s' matching your search\n", targetPlatform)
+		return nil
+	}
+
+	stat, _ := os.Stdout.Stat()
+	highlight := stat != nil && (stat.Mode()&os.ModeCharDevice) != 0
+
+	fmt.Print(storeRenderMatches(matches, highlight))
+
+	return nil
+}
+
+// searchStore gives the functions in the category which match the term, an
+// empty term or category matches every function
+func searchStore(functions []storeV2.StoreFunction, term string, category string) []storeSearchMatch {
+	var matches []storeSearchMatch
+
+	for _, function := range functions {
+		if len(category) > 0 && !hasStoreCategory(function, category) {
+			continue
+		}
+
+		match, ok := matchStoreFunction(function, term)
+		if ok {
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		return strings.ToLower(matches[i].function.Title) < strings.ToLower(matches[j].function.Title)
+	})
+
+	return matches
+}
+
+// matchStoreFunction ranks a match in the title first, then the name, then
+// the description and last the letters of the term in order in the title
+func matchStoreFunction(function storeV2.StoreFunction, term string) (storeSearchMatch, bool) {
+	match := storeSearchMatch{function: function}
+	if len(term) == 0 {
+		return match, true
+	}
+
+	if positions := matchSubstring(function.Title, term); positions != nil {
+		match.title = positions
+		return match, true
+	}
+
+	if positions := matchSubstring(function.Name, term); positions != nil {
+		match.rank = 1
+		return match, true
+	}
+
+	if positions := matchSubstring(function.Description, term); positions != nil {
+		match.rank = 2
+		match.description = positions
+		return match, true
+	}
+
+	if positions := matchSubsequence(function.Title, term); positions != nil {
+		match.rank = 3
+		match.title = positions
+		return match, true
+	}
+
+	return match, false
+}
+
+// matchSubstring gives the rune positions of the first case-insensitive
+// occurrence of the term in the text, or nil when there is none
+func matchSubstring(text string, term string) []int {
+	textRunes := []rune(strings.ToLower(text))
+	termRunes := []rune(strings.ToLower(term))
+
+	for i := 0; i+len(termRunes) <= len(textRunes); i++ {
+		if string(textRunes[i:i+len(termRunes)]) == string(termRunes) {
+			positions := make([]int, len(termRunes))
+			for j := range positions {
+				positions[j] = i + j
+			}
+			return positions
+		}
+	}
+
+	return nil
+}
+
+// matchSubsequence gives the rune positions of the letters of the term found
+// in order in the text, or nil when they are not all found
+func matchSubsequence(text string, term string) []int {
+	termRunes := []rune(term)
+	var positions []int
+
+	for i, r := range []rune(text) {
+		if len(positions) == len(termRunes) {
+			break
+		}
+		if unicode.ToLower(r) == unicode.ToLower(termRunes[len(positions)]) {
+			positions = append(positions, i)
+		}
+	}
+
+	if len(positions) < len(termRunes) {
+		return nil
+	}
+	return positions
+}
+
+func hasStoreCategory(function storeV2.StoreFunction, category string) bool {
+	for _, value := range function.Categories {
+		if strings.EqualFold(value, category) {
+			return true
+		}
+	}
+	return false
+}
+
+func storeRenderMatches(matches []storeSearchMatch, highlight bool) string {
+	width := len("FUNCTION")
+	for _, match := range matches {
+		if n := len([]rune(match.function.Title)); n > width {
+			width = n
+		}
+	}
+
+	// tabwriter would count the escape codes used to highlight as text, so the
+	// title column is padded by hand
+	var b bytes.Buffer
+	fmt.Fprintln(&b)
+	fmt.Fprintf(&b, "%-*s %s\n", width, "FUNCTION", "DESCRIPTION")
+
+	for _, match := range matches {
+		title := match.function.Title
+		padding := strings.Repeat(" ", width-len([]rune(title))+1)
+		description := storeRenderDescription(match.function.Description)
+
+		if highlight {
+			title = highlightRunes(title, match.title)
+			description = highlightRunes(description, match.description)
+		}
+
+		fmt.Fprintf(&b, "%s%s%s\n", title, padding, description)
+	}
+
+	fmt.Fprintln(&b)
+	return b.String()
+}
+
+// highlightRunes makes the runes at the given positions bold, positions past
+// the end of a truncated description are ignored
+func highlightRunes(text string, positions []int) string {
+	if len(positions) == 0 {
+		return text
+	}
+
+	matched := make(map[int]bool, len(positions))
+	for _, position := range positions {
+		matched[position] = true
+	}
+
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && matched[j] == matched[i] {
+			j++
+		}
+
+		if matched[i] {
+			b.WriteString(aec.Apply(string(runes[i:j]), aec.Bold))
+		} else {
+			b.WriteString(string(runes[i:j]))
+		}
+		i = j
+	}
+
+	return b.String()
+}
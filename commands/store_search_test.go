@@ -0,0 +1,80 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/morikuni/aec"
+	storeV2 "github.com/openfaas/faas-cli/schema/store/v2"
+)
+
+func searchTestFunctions() []storeV2.StoreFunction {
+	return []storeV2.StoreFunction{
+		{Title: "NodeInfo", Name: "nodeinfo", Description: "Get info about the machine"},
+		{Title: "Figlet", Name: "figlet", Description: "Generate ASCII logos", Categories: []string{"Text"}},
+		{Title: "SentimentAnalysis", Name: "sentimentanalysis", Description: "Python function provides a rating on sentiment"},
+		{Title: "Inception", Name: "inception", Description: "Identify objects in images", Categories: []string{"images", "ml"}},
+	}
+}
+
+func storeMatchTitles(matches []storeSearchMatch) []string {
+	titles := []string{}
+	for _, match := range matches {
+		titles = append(titles, match.function.Title)
+	}
+	return titles
+}
+
+func Test_searchStore(t *testing.T) {
+	cases := []struct {
+		name     string
+		term     string
+		category string
+		want     []string
+	}{
+		{name: "title before description", term: "in", want: []string{"Inception", "NodeInfo", "SentimentAnalysis"}},
+		{name: "description", term: "ascii", want: []string{"Figlet"}},
+		{name: "letters in order", term: "ndnf", want: []string{"NodeInfo"}},
+		{name: "category is case-insensitive", term: "", category: "text", want: []string{"Figlet"}},
+		{name: "term and category", term: "ion", category: "ml", want: []string{"Inception"}},
+		{name: "no match", term: "zzz", want: []string{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := storeMatchTitles(searchStore(searchTestFunctions(), c.term, c.category))
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("want %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
+func Test_matchSubsequence(t *testing.T) {
+	if got := matchSubsequence("NodeInfo", "nif"); !reflect.DeepEqual(got, []int{0, 4, 6}) {
+		t.Errorf("want [0 4 6], got %v", got)
+	}
+
+	if got := matchSubsequence("NodeInfo", "fin"); got != nil {
+		t.Errorf("want no match, got %v", got)
+	}
+}
+
+func Test_storeRenderMatches_Highlight(t *testing.T) {
+	matches := searchStore(searchTestFunctions(), "fig", "")
+
+	plain := storeRenderMatches(matches, false)
+	want := "\nFUNCTION DESCRIPTION\nFiglet   Generate ASCII logos\n\n"
+	if plain != want {
+		t.Errorf("want %q, got %q", want, plain)
+	}
+
+	highlighted := storeRenderMatches(matches, true)
+	if !strings.Contains(highlighted, aec.Apply("Fig", aec.Bold)+"let   Generate") {
+		t.Errorf("want the match to be highlighted and aligned, got %q", highlighted)
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	faasCmd.AddCommand(systemCmd)
+}
+
+var systemCmd = &cobra.Command{
+	Use:   `system`,
+	Short: "OpenFaaS system commands",
+	Long:  "Show what the gateway and its provider are running",
+}
@@ -0,0 +1,83 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var systemInfoOutput string
+
+// systemInfo is written by "faas-cli system info --output json"
+type systemInfo struct {
+	Gateway  gatewayInfo  `json:"gateway"`
+	Provider providerInfo `json:"provider"`
+}
+
+type gatewayInfo struct {
+	URL           string `json:"url"`
+	Version       string `json:"version,omitempty"`
+	SHA           string `json:"sha,omitempty"`
+	CommitMessage string `json:"commitMessage,omitempty"`
+}
+
+type providerInfo struct {
+	Name          string `json:"name"`
+	Orchestration string `json:"orchestration"`
+	Version       string `json:"version"`
+	SHA           string `json:"sha"`
+}
+
+var systemInfoCmd = &cobra.Command{
+	Use:   `info [--gateway GATEWAY_URL] [--output json]`,
+	Short: "Show the version of the gateway and its provider",
+	Long: `Shows the version of the gateway, and the name, orchestration and version of
+the provider it runs functions with, such as faas-netes on Kubernetes, to
+confirm which cluster is targeted. Gateways before 0.8.4 only give the
+provider.`,
+	Example: `  faas-cli system info
+  faas-cli system info --gateway https://openfaas.example.com
+  faas-cli system info --output json | jq -r .provider.orchestration`,
+	RunE: runSystemInfo,
+}
+
+func init() {
+	systemInfoCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	systemInfoCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
+	systemInfoCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yml file")
+	systemInfoCmd.Flags().StringVarP(&systemInfoOutput, "output", "o", "", "Output format, only json is supported, instead of text")
+
+	systemCmd.AddCommand(systemInfoCmd)
+}
+
+func runSystemInfo(cmd *cobra.Command, args []string) error {
+	if len(systemInfoOutput) > 0 && systemInfoOutput != "json" {
+		return fmt.Errorf("--output must be json, not: %s", systemInfoOutput)
+	}
+
+	gatewayAddress, info, err := getServerInfo(commandTimeout)
+	if err != nil {
+		return err
+	}
+
+	if len(systemInfoOutput) == 0 {
+		printSystemInfo(gatewayAddress, info)
+		return nil
+	}
+
+	details := systemInfo{Gateway: gatewayInfo{URL: gatewayAddress}}
+	details.Gateway.Version, details.Gateway.SHA, details.Gateway.CommitMessage = getGatewayDetails(info)
+	details.Provider.Name, details.Provider.Orchestration, details.Provider.SHA, details.Provider.Version = getProviderDetails(info)
+
+	out, err := json.MarshalIndent(details, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(out))
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_systemInfo(t *testing.T) {
+	resetForTest()
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/info",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       gateway_response_0_8_4_onwards,
+		},
+	})
+	defer s.Close()
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"system", "info", "--gateway=" + s.URL})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	for _, want := range []string{
+		"version: gateway-0.4.3",
+		"name:          faas-swarm",
+		"orchestration: swarm",
+		"version:       provider-0.3.3",
+	} {
+		if !strings.Contains(stdOut, want) {
+			t.Errorf("want %q in the output, got:\n%s", want, stdOut)
+		}
+	}
+}
+
+func Test_systemInfo_OutputJSON(t *testing.T) {
+	resetForTest()
+	defer func() {
+		systemInfoOutput = ""
+	}()
+
+	for _, responseBody := range []string{gateway_response_0_8_4_onwards, gateway_response_prior_to_0_8_4} {
+		s := test.MockHttpServer(t, []test.Request{
+			{
+				Method:             http.MethodGet,
+				Uri:                "/system/info",
+				ResponseStatusCode: http.StatusOK,
+				ResponseBody:       responseBody,
+			},
+		})
+
+		var err error
+		stdOut := test.CaptureStdout(func() {
+			faasCmd.SetArgs([]string{"system", "info", "--gateway=" + s.URL, "--output=json"})
+			err = faasCmd.Execute()
+		})
+		s.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var details systemInfo
+		if err := json.Unmarshal([]byte(stdOut), &details); err != nil {
+			t.Fatalf("want JSON output, got %q: %s", stdOut, err)
+		}
+
+		if details.Provider.Name != "faas-swarm" || details.Provider.Orchestration != "swarm" || details.Provider.Version != "provider-0.3.3" {
+			t.Errorf("want the provider, got %+v", details.Provider)
+		}
+		if responseBody == gateway_response_0_8_4_onwards && details.Gateway.Version != "gateway-0.4.3" {
+			t.Errorf("want the gateway version, got %+v", details.Gateway)
+		}
+		if len(details.Gateway.URL) == 0 {
+			t.Errorf("want the gateway URL, got %+v", details.Gateway)
+		}
+	}
+}
+
+func Test_systemInfo_Unreachable(t *testing.T) {
+	resetForTest()
+
+	faasCmd.SetArgs([]string{"system", "info", "--gateway=http://127.0.0.1:1"})
+	err := faasCmd.Execute()
+
+	if err == nil || exitCode(err) != exitCodeNetwork {
+		t.Errorf("want an error for an unreachable gateway, got %v", err)
+	}
+}
@@ -20,5 +20,7 @@ var templateCmd = &cobra.Command{
   faas-cli template store list
   faas-cli template store ls
   faas-cli template store pull ruby-http
-  faas-cli template store pull openfaas-incubator/ruby-http`,
+  faas-cli template store pull openfaas-incubator/ruby-http
+  faas-cli template lint ./template/ruby-http
+  faas-cli template lock`,
 }
@@ -0,0 +1,106 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/openfaas/faas-cli/stack"
+	"github.com/spf13/cobra"
+)
+
+var templateLintStrict bool
+
+func init() {
+	templateLintCmd.Flags().BoolVar(&templateLintStrict, "strict", false, "Treat warnings as errors")
+
+	templateCmd.AddCommand(templateLintCmd)
+}
+
+// templateLintCmd checks templates before they are published
+var templateLintCmd = &cobra.Command{
+	Use:   `lint PATH [--strict]`,
+	Short: "Check a template before publishing it",
+	Long: `Checks a language template for a template.yml which gives a language, a
+Dockerfile and the handler folder copied by "faas-cli new". PATH may be a single
+template, a folder of templates or a template repo with a template folder.
+Warnings, such as unknown keys in template.yml, fail the check with "--strict".`,
+	Example: `  faas-cli template lint ./template/node12
+  faas-cli template lint ./my-templates --strict`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTemplateLint,
+}
+
+func runTemplateLint(cmd *cobra.Command, args []string) error {
+	dirs, err := findLanguageTemplates(args[0])
+	if err != nil {
+		return err
+	}
+
+	return lintTemplates(os.Stdout, dirs, templateLintStrict)
+}
+
+// findLanguageTemplates gives the path when it is a template, or else each
+// folder in it or in its template folder
+func findLanguageTemplates(path string) ([]string, error) {
+	if _, err := os.Stat(filepath.Join(path, "template.yml")); err == nil {
+		return []string{path}, nil
+	}
+
+	parent := path
+	if info, err := os.Stat(filepath.Join(path, "template")); err == nil && info.IsDir() {
+		parent = filepath.Join(path, "template")
+	}
+
+	files, err := ioutil.ReadDir(parent)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read templates from %s: %s", path, err)
+	}
+
+	var dirs []string
+	for _, file := range files {
+		if file.IsDir() {
+			dirs = append(dirs, filepath.Join(parent, file.Name()))
+		}
+	}
+
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no templates found in %s", path)
+	}
+	return dirs, nil
+}
+
+// lintTemplates prints each problem found in the templates and gives an
+// error when there are errors, or warnings and strict is set
+func lintTemplates(w io.Writer, dirs []string, strict bool) error {
+	failed := 0
+
+	for _, dir := range dirs {
+		problems, err := stack.ValidateLanguageTemplate(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, problem := range problems {
+			if strict {
+				problem.Warning = false
+			}
+			if !problem.Warning {
+				failed++
+			}
+			fmt.Fprintln(w, problem.String())
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("template lint failed with %d error(s)", failed)
+	}
+
+	fmt.Fprintf(w, "%d template(s) passed lint.\n", len(dirs))
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_findLanguageTemplates(t *testing.T) {
+	repo := filepath.Join("testdata", "templates")
+	want := []string{
+		filepath.Join(repo, "template", "dockerfile"),
+		filepath.Join(repo, "template", "ruby"),
+	}
+
+	got, err := findLanguageTemplates(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	got, err = findLanguageTemplates(want[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("want %v, got %v", want[1:], got)
+	}
+}
+
+func Test_lintTemplates(t *testing.T) {
+	var buf bytes.Buffer
+	dirs := []string{filepath.Join("testdata", "templates", "template", "ruby")}
+	if err := lintTemplates(&buf, dirs, false); err != nil {
+		t.Fatalf("want the ruby template to pass, got %s\n%s", err, buf.String())
+	}
+
+	dir, err := ioutil.TempDir("", "template-lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	lang := filepath.Join(dir, "go")
+	os.MkdirAll(filepath.Join(lang, "function"), 0700)
+	ioutil.WriteFile(filepath.Join(lang, "template.yml"), []byte("language: golang\n"), 0600)
+	ioutil.WriteFile(filepath.Join(lang, "function", "Dockerfile"), []byte("FROM golang"), 0600)
+
+	buf.Reset()
+	if err := lintTemplates(&buf, []string{lang}, false); err != nil {
+		t.Errorf("want warnings to pass, got %s", err)
+	}
+
+	buf.Reset()
+	err = lintTemplates(&buf, []string{lang}, true)
+	if err == nil || err.Error() != "template lint failed with 1 error(s)" {
+		t.Errorf("want warnings to fail with --strict, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "error: language golang does not match the directory name go") {
+		t.Errorf("want the problem to be printed, got %q", buf.String())
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// templateLockFile pins the commit of each template repo, it is read and
+// written in the working directory alongside ./template, where build looks
+// for the templates
+const templateLockFile = "templates.lock"
+
+// noTemplateLock is set by build and up to leave the templates as they are
+var noTemplateLock bool
+
+// templateLock is the commit each template repo was pulled at
+type templateLock struct {
+	Repositories []lockedRepository `yaml:"repositories"`
+}
+
+type lockedRepository struct {
+	Repository string   `yaml:"repository"`
+	Ref        string   `yaml:"ref"`
+	Commit     string   `yaml:"commit"`
+	Templates  []string `yaml:"templates"`
+}
+
+func init() {
+	templateCmd.AddCommand(templateLockCmd)
+}
+
+// templateLockCmd records the commit of each pulled template repo
+var templateLockCmd = &cobra.Command{
+	Use:   `lock`,
+	Short: "Pin the templates to the commits they were pulled at",
+	Long: `Records the repository, ref and commit of each template in ./template into
+./templates.lock, from the sources recorded by "faas-cli template pull". Both
+are relative to the working directory rather than to the stack file, so run
+build and up from the same directory.
+
+Commit templates.lock with the stack file. When it is present, build and up
+check the templates out at the locked commits, warning about each template
+which was at another commit, pass --no-lock to build with the templates as
+they are.`,
+	Example: `  faas-cli template pull https://github.com/openfaas/templates
+  faas-cli template lock
+  faas-cli build --no-lock`,
+	Args: cobra.NoArgs,
+	RunE: runTemplateLock,
+}
+
+func runTemplateLock(cmd *cobra.Command, args []string) error {
+	lock, err := lockTemplateSources()
+	if err != nil {
+		return err
+	}
+
+	if err := writeTemplateLock(lock); err != nil {
+		return err
+	}
+
+	for _, repository := range lock.Repositories {
+		fmt.Printf("Locked %v from %s at %s (%s)\n", repository.Templates, repository.Repository, repository.Ref, shortCommit(repository.Commit))
+	}
+	fmt.Printf("Wrote %s\n", templateLockFile)
+	return nil
+}
+
+// lockTemplateSources groups the recorded sources of the templates by the
+// repo, ref and commit they were pulled from
+func lockTemplateSources() (templateLock, error) {
+	sources, err := readTemplateSources()
+	if err != nil {
+		return templateLock{}, err
+	}
+	if len(sources.Templates) == 0 {
+		return templateLock{}, fmt.Errorf("no template sources were found in %s, pull the templates with \"faas-cli template pull\" first", templateDirectory+templateSourcesFile)
+	}
+
+	languages := make([]string, 0, len(sources.Templates))
+	for language := range sources.Templates {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	lock := templateLock{}
+	index := map[templateSource]int{}
+	for _, language := range languages {
+		source := sources.Templates[language]
+		if len(source.Commit) == 0 {
+			return templateLock{}, fmt.Errorf("no commit was recorded for template %s, pull it again to lock it", language)
+		}
+
+		i, ok := index[source]
+		if !ok {
+			i = len(lock.Repositories)
+			index[source] = i
+			lock.Repositories = append(lock.Repositories, lockedRepository{
+				Repository: source.Repository,
+				Ref:        source.Ref,
+				Commit:     source.Commit,
+			})
+		}
+		lock.Repositories[i].Templates = append(lock.Repositories[i].Templates, language)
+	}
+
+	return lock, nil
+}
+
+// readTemplateLock gives the lock, and false when there is no lock file
+func readTemplateLock() (templateLock, bool, error) {
+	lock := templateLock{}
+
+	data, err := ioutil.ReadFile(templateLockFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, false, nil
+		}
+		return lock, false, err
+	}
+
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return lock, false, fmt.Errorf("unable to parse %s: %s", templateLockFile, err)
+	}
+	return lock, true, nil
+}
+
+func writeTemplateLock(lock templateLock) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(templateLockFile, data, 0644)
+}
+
+// applyTemplateLock warns about each template which is missing or at
+// another commit than the one locked, and fetches its repo at the locked
+// commit unless checkout is false
+func applyTemplateLock(w io.Writer, checkout bool) error {
+	lock, found, err := readTemplateLock()
+	if err != nil || !found {
+		return err
+	}
+
+	sources, err := readTemplateSources()
+	if err != nil {
+		return err
+	}
+
+	for _, repository := range lock.Repositories {
+		stale := false
+		for _, language := range repository.Templates {
+			recorded, ok := sources.Templates[language]
+			if !ok {
+				fmt.Fprintf(w, "Warning: template %s is locked to %s (%s) in %s, but was not pulled from it.\n",
+					language, repository.Repository, shortCommit(repository.Commit), templateLockFile)
+				stale = true
+			} else if recorded.Commit != repository.Commit {
+				fmt.Fprintf(w, "Warning: template %s is at %s, but %s locks it to %s from %s.\n",
+					language, shortCommit(recorded.Commit), templateLockFile, shortCommit(repository.Commit), repository.Repository)
+				stale = true
+			}
+		}
+
+		if !stale || !checkout {
+			continue
+		}
+
+		if err := fetchTemplates(repository.Repository, repository.Commit, true); err != nil {
+			return fmt.Errorf("unable to check out the templates locked in %s: %s", templateLockFile, err)
+		}
+
+		// Keep the locked ref rather than the commit, which is what the stack file gives
+		source := templateSource{Repository: repository.Repository, Ref: repository.Ref, Commit: repository.Commit}
+		if err := recordTemplateSources(repository.Templates, source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/versioncontrol"
+)
+
+func Test_lockTemplateSources(t *testing.T) {
+	tearDownFetchTemplates(t)
+	defer tearDownFetchTemplates(t)
+
+	if err := os.MkdirAll(templateDirectory, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	official := templateSource{Repository: "https://github.com/openfaas/templates.git", Ref: "master", Commit: "8f7f50ab3e09d4ea5f9e2c4ee5bd8fb8d73f2f4a"}
+	if err := recordTemplateSources([]string{"python3", "node12"}, official); err != nil {
+		t.Fatal(err)
+	}
+	rust := templateSource{Repository: "https://github.com/openfaas/rust.git", Ref: "1.0", Commit: "c8b0151f1fa8133799f3f8b0d5c8ee5558bc7911"}
+	if err := recordTemplateSources([]string{"rust"}, rust); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := lockTemplateSources()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []lockedRepository{
+		{Repository: official.Repository, Ref: "master", Commit: official.Commit, Templates: []string{"node12", "python3"}},
+		{Repository: rust.Repository, Ref: "1.0", Commit: rust.Commit, Templates: []string{"rust"}},
+	}
+	if !reflect.DeepEqual(lock.Repositories, want) {
+		t.Errorf("want %v, got %v", want, lock.Repositories)
+	}
+}
+
+func Test_lockTemplateSources_NothingPulled(t *testing.T) {
+	tearDownFetchTemplates(t)
+	defer tearDownFetchTemplates(t)
+
+	if _, err := lockTemplateSources(); err == nil {
+		t.Error("want an error when no templates were pulled")
+	}
+}
+
+func Test_applyTemplateLock(t *testing.T) {
+	tearDownFetchTemplates(t)
+	localTemplateRepository := setupLocalTemplateRepo(t)
+	defer os.RemoveAll(localTemplateRepository)
+	defer tearDownFetchTemplates(t)
+	defer os.Remove(templateLockFile)
+
+	commit, err := versioncontrol.GetGitCommit(localTemplateRepository)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(templateDirectory, 0700); err != nil {
+		t.Fatal(err)
+	}
+	moved := templateSource{Repository: localTemplateRepository, Ref: "master", Commit: "8f7f50ab3e09d4ea5f9e2c4ee5bd8fb8d73f2f4a"}
+	if err := recordTemplateSources([]string{"ruby"}, moved); err != nil {
+		t.Fatal(err)
+	}
+
+	lock := templateLock{Repositories: []lockedRepository{
+		{Repository: localTemplateRepository, Ref: "master", Commit: commit, Templates: []string{"dockerfile", "ruby"}},
+	}}
+	if err := writeTemplateLock(lock); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no-lock only warns", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := applyTemplateLock(&buf, false); err != nil {
+			t.Fatal(err)
+		}
+
+		out := buf.String()
+		if !strings.Contains(out, "template dockerfile is locked to") {
+			t.Errorf("want a warning for the missing template, got %q", out)
+		}
+		if !strings.Contains(out, "template ruby is at 8f7f50a, but templates.lock locks it to "+shortCommit(commit)) {
+			t.Errorf("want a warning for the template at another commit, got %q", out)
+		}
+		if _, err := os.Stat(templateDirectory + "dockerfile"); err == nil {
+			t.Error("want the templates to be left as they are")
+		}
+	})
+
+	t.Run("checks out the locked commit", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := applyTemplateLock(&buf, true); err != nil {
+			t.Fatal(err)
+		}
+
+		sources, err := readTemplateSources()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := templateSource{Repository: localTemplateRepository, Ref: "master", Commit: commit}
+		for _, language := range []string{"dockerfile", "ruby"} {
+			if got := sources.Templates[language]; got != want {
+				t.Errorf("%s: want %v, got %v", language, want, got)
+			}
+		}
+
+		buf.Reset()
+		if err := applyTemplateLock(&buf, true); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() > 0 {
+			t.Errorf("want no warnings once the templates are at the locked commit, got %q", buf.String())
+		}
+	})
+}
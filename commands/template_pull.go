@@ -10,41 +10,68 @@ import (
 )
 
 var (
-	repository string
-	overwrite  bool
-	pullDebug  bool
+	repository  string
+	overwrite   bool
+	pullDebug   bool
+	templateRef string
+
+	templateAuthToken string
+	templateRetries   int
 )
 
 func init() {
 	templatePullCmd.Flags().BoolVar(&overwrite, "overwrite", false, "Overwrite existing templates?")
 	templatePullCmd.Flags().BoolVar(&pullDebug, "debug", false, "Enable debug output")
+	templatePullCmd.Flags().StringVar(&templateRef, "ref", "", "Branch, tag or full commit SHA to pull, the same as adding #REF to the URL")
+	templatePullCmd.Flags().StringVar(&templateRef, "tag", "", "Alias of --ref")
+	templatePullCmd.Flags().StringVar(&templateAuthToken, "auth-token", "", "Token to pull from a private HTTPS git repo, or set "+gitTokenEnvironment)
+
+	templatePullCmd.Flags().IntVar(&templateRetries, "retries", 3, "Retry up to this many times on a temporary network error, a repo which is not found is not retried")
 
 	templateCmd.AddCommand(templatePullCmd)
 }
 
 // templatePullCmd allows the user to fetch a template from a repository
 var templatePullCmd = &cobra.Command{
-	Use:   `pull [REPOSITORY_URL]`,
+	Use:   `pull [REPOSITORY_URL] [--ref REF]`,
 	Short: `Downloads templates from the specified git repo`,
 	Long: `Downloads templates from the specified git repo specified by [REPOSITORY_URL], and copies the 'template'
 directory from the root of the repo, if it exists.
 
-[REPOSITORY_URL] may specify a specific branch or tag to copy by adding a URL fragment with the branch or tag name.
+[REPOSITORY_URL] may specify a specific branch or tag to copy by adding a URL fragment with the branch or tag name,
+or give it with --ref, which also accepts a full commit SHA. The commit which was pulled is recorded in
+template/.sources.yml and build warns when the stack file asks for a template from another ref.
+
+Private repos can be pulled over SSH with a git@host:org/repo.git URL and the local SSH agent, or over
+HTTPS with a token given by --auth-token or the GIT_TOKEN environment variable.
+
+A temporary network error is retried up to --retries times with a backoff, a repo which is not found or
+can't be read is not retried.
 	`,
 	Example: `
   faas-cli template pull https://github.com/openfaas/templates
   faas-cli template pull https://github.com/openfaas/templates#1.0
+  faas-cli template pull https://github.com/openfaas/templates --ref 1.0
+  faas-cli template pull https://github.com/openfaas/templates --ref 8f7f50ab3e09d4ea5f9e2c4ee5bd8fb8d73f2f4a
+  faas-cli template pull git@github.com:example/private-templates.git
+  GIT_TOKEN=$TOKEN faas-cli template pull https://gitlab.com/example/private-templates.git
+  faas-cli template pull https://github.com/openfaas/templates --retries 5
 `,
 	RunE: runTemplatePull,
 }
 
 func runTemplatePull(cmd *cobra.Command, args []string) error {
+	if templateRetries < 0 {
+		return fmt.Errorf("the --retries flag must be 0 or greater")
+	}
+
 	repository := ""
 	if len(args) > 0 {
 		repository = args[0]
 	}
 	repository = getTemplateURL(repository, os.Getenv(templateURLEnvironment), DefaultTemplateRepository)
-	return pullTemplate(repository)
+
+	return pullTemplate(repository, templateRef)
 }
 
 func pullDebugPrint(message string) {
@@ -18,6 +18,7 @@ var (
 func init() {
 	templatePullStackCmd.Flags().BoolVar(&overwrite, "overwrite", false, "Overwrite existing templates?")
 	templatePullStackCmd.Flags().BoolVar(&pullDebug, "debug", false, "Enable debug output")
+	templatePullStackCmd.Flags().StringVar(&templateAuthToken, "auth-token", "", "Token to pull from private HTTPS git repos, or set "+gitTokenEnvironment)
 
 	templatePullCmd.AddCommand(templatePullStackCmd)
 }
@@ -77,7 +78,7 @@ func pullStackTemplates(templateInfo []stack.TemplateSource, cmd *cobra.Command)
 				return pullErr
 			}
 		} else {
-			pullErr := pullTemplate(val.Source)
+			pullErr := pullTemplate(val.Source, "")
 			if pullErr != nil {
 				return pullErr
 			}
@@ -3,6 +3,7 @@ package commands
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/openfaas/faas-cli/stack"
 )
@@ -44,6 +45,9 @@ func Test_findTemplate(t *testing.T) {
 }
 
 func Test_pullAllTemplates(t *testing.T) {
+	templateRetryDelay = 0
+	defer func() { templateRetryDelay = 2 * time.Second }()
+
 	tests := []struct {
 		title             string
 		existingTemplates []stack.TemplateSource
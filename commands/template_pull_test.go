@@ -10,6 +10,8 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/openfaas/faas-cli/versioncontrol"
 )
 
 func Test_templatePull(t *testing.T) {
@@ -74,6 +76,45 @@ func Test_templatePull(t *testing.T) {
 		}
 	})
 
+	t.Run("AtCommit", func(t *testing.T) {
+		defer tearDownFetchTemplates(t)
+		defer func() { templateRef = "" }()
+
+		commit, err := versioncontrol.GetGitCommit(localTemplateRepository)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		faasCmd.SetArgs([]string{"template", "pull", localTemplateRepository, "--ref", commit})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatalf("unexpected error while pulling at a commit: %s", err.Error())
+		}
+
+		sources, err := readTemplateSources()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sources.Templates) == 0 {
+			t.Fatal("want the pulled templates to be recorded")
+		}
+		for language, source := range sources.Templates {
+			if source.Ref != commit || source.Commit != commit || source.Repository != localTemplateRepository {
+				t.Errorf("want %s recorded at %s, got %+v", language, commit, source)
+			}
+		}
+	})
+
+	t.Run("MissingRef", func(t *testing.T) {
+		defer tearDownFetchTemplates(t)
+		defer func() { templateRef = "" }()
+
+		faasCmd.SetArgs([]string{"template", "pull", localTemplateRepository, "--tag", "v0.0.0-missing"})
+		err := faasCmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), "the branch or tag v0.0.0-missing was not found") {
+			t.Errorf("want an error for a missing ref, got %v", err)
+		}
+	})
+
 	t.Run("InvalidUrlError", func(t *testing.T) {
 		faasCmd.SetArgs([]string{"template", "pull", "user@host.xz:openfaas/faas-cli.git"})
 		err := faasCmd.Execute()
@@ -0,0 +1,114 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/openfaas/faas-cli/stack"
+	"github.com/openfaas/faas-cli/versioncontrol"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// templateSourcesFile records where each template in the template directory
+// was pulled from
+const templateSourcesFile = ".sources.yml"
+
+// templateSource is the repository, ref and resolved commit a template was pulled from
+type templateSource struct {
+	Repository string `yaml:"repository"`
+	Ref        string `yaml:"ref"`
+	Commit     string `yaml:"commit"`
+}
+
+type templateSources struct {
+	Templates map[string]templateSource `yaml:"templates"`
+}
+
+// readTemplateSources gives the recorded sources, which are empty when
+// nothing has been recorded yet
+func readTemplateSources() (templateSources, error) {
+	sources := templateSources{Templates: map[string]templateSource{}}
+
+	data, err := ioutil.ReadFile(filepath.Join(templateDirectory, templateSourcesFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sources, nil
+		}
+		return sources, err
+	}
+
+	if err := yaml.Unmarshal(data, &sources); err != nil {
+		return sources, fmt.Errorf("unable to parse %s: %s", templateSourcesFile, err)
+	}
+	if sources.Templates == nil {
+		sources.Templates = map[string]templateSource{}
+	}
+	return sources, nil
+}
+
+// recordTemplateSources records the source for the templates which were
+// written to the template directory, other templates keep their record
+func recordTemplateSources(languages []string, source templateSource) error {
+	if len(languages) == 0 {
+		return nil
+	}
+
+	sources, err := readTemplateSources()
+	if err != nil {
+		return err
+	}
+
+	for _, language := range languages {
+		sources.Templates[language] = source
+	}
+
+	data, err := yaml.Marshal(sources)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(templateDirectory, templateSourcesFile), data, 0644)
+}
+
+// checkTemplateDrift warns about templates pinned in the stack file which
+// were pulled earlier from another repository or ref, as an existing
+// template is not overwritten when pulling the stack's templates
+func checkTemplateDrift(w io.Writer, configs []stack.TemplateSource) error {
+	sources, err := readTemplateSources()
+	if err != nil {
+		return err
+	}
+
+	for _, config := range configs {
+		if len(config.Source) == 0 {
+			continue
+		}
+
+		recorded, ok := sources.Templates[config.Name]
+		if !ok {
+			continue
+		}
+
+		repository, ref := versioncontrol.ParsePinnedRemote(config.Source)
+		if recorded.Repository == repository && recorded.Ref == ref {
+			continue
+		}
+
+		fmt.Fprintf(w, "Warning: template %s was pulled from %s at %s (%s), but the stack file gives %s at %s. Run \"faas-cli template pull stack --overwrite\" to update it.\n",
+			config.Name, recorded.Repository, recorded.Ref, shortCommit(recorded.Commit), repository, ref)
+	}
+
+	return nil
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
@@ -0,0 +1,57 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/stack"
+)
+
+func Test_checkTemplateDrift(t *testing.T) {
+	defer tearDownFetchTemplates(t)
+
+	if err := os.MkdirAll(templateDirectory, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	source := templateSource{Repository: "https://github.com/openfaas/templates.git", Ref: "1.0", Commit: "8f7f50ab3e09d4ea5f9e2c4ee5bd8fb8d73f2f4a"}
+	if err := recordTemplateSources([]string{"node12", "python3"}, source); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err := checkTemplateDrift(&buf, []stack.TemplateSource{
+		{Name: "node12", Source: "https://github.com/openfaas/templates.git#1.0"},
+		{Name: "python3", Source: "https://github.com/openfaas/templates.git#1.2"},
+		{Name: "golang-http"},
+		{Name: "rust", Source: "https://github.com/openfaas/rust.git#1.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if strings.Count(out, "Warning:") != 1 {
+		t.Fatalf("want a single warning, got %q", out)
+	}
+	if !strings.Contains(out, "template python3 was pulled from https://github.com/openfaas/templates.git at 1.0 (8f7f50a), but the stack file gives https://github.com/openfaas/templates.git at 1.2") {
+		t.Errorf("want a warning for python3, got %q", out)
+	}
+}
+
+func Test_readTemplateSources_Missing(t *testing.T) {
+	defer tearDownFetchTemplates(t)
+
+	sources, err := readTemplateSources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources.Templates) != 0 {
+		t.Errorf("want no sources, got %v", sources.Templates)
+	}
+}
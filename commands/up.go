@@ -5,12 +5,17 @@ package commands
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"os"
 
+	"github.com/openfaas/faas-cli/stack"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
 var (
+	skipBuild  bool
 	skipPush   bool
 	skipDeploy bool
 )
@@ -18,8 +23,12 @@ var (
 func init() {
 
 	upFlagset := pflag.NewFlagSet("up", pflag.ExitOnError)
+	upFlagset.BoolVar(&skipBuild, "skip-build", false, "Skip building the function image, to push or deploy an image which was built earlier")
 	upFlagset.BoolVar(&skipPush, "skip-push", false, "Skip pushing function to remote registry")
 	upFlagset.BoolVar(&skipDeploy, "skip-deploy", false, "Skip function deployment")
+	upFlagset.BoolVar(&watch, "watch", false, "Watch the handler folders and run up again for each function which changes, until interrupted")
+	upFlagset.StringArrayVar(&watchInclude, "watch-include", []string{}, "Glob of the files to watch in a handler folder, repeat for more than one, all files are watched when not given")
+	upFlagset.StringArrayVar(&watchExclude, "watch-exclude", []string{}, "Glob of the files or folders not to watch in a handler folder, repeat for more than one")
 	upCmd.Flags().AddFlagSet(upFlagset)
 
 	build, _, _ := faasCmd.Find([]string{"build"})
@@ -36,24 +45,80 @@ func init() {
 
 // upCmd is a wrapper to the build, push and deploy commands
 var upCmd = &cobra.Command{
-	Use:   `up -f [YAML_FILE] [--skip-push] [--skip-deploy] [flags from build, push, deploy]`,
+	Use:   `up -f [YAML_FILE] [FUNCTION_NAME ...] [--skip-build] [--skip-push] [--skip-deploy] [--watch] [flags from build, push, deploy]`,
 	Short: "Builds, pushes and deploys OpenFaaS function containers",
 	Long: `Build, Push, and Deploy OpenFaaS function containers either via the
 supplied YAML config using the "--yaml" flag (which may contain multiple function
-definitions), or directly via flags.
+definitions), or directly via flags. Give the names of functions in the YAML
+config, or globs such as "web-*", to run up for only those functions, along
+with those matching --regex or --filter.
 
-The push step may be skipped by setting the --skip-push flag
-and the deploy step with --skip-deploy.
+Use --platforms to build with docker buildx for each of the given platforms.
+An image for several platforms is pushed as it is built, so the push step is
+not run separately.
+
+The build step may be skipped by setting the --skip-build flag, the push
+step with --skip-push and the deploy step with --skip-deploy. Skip the push to
+deploy to a local cluster which uses the images built on this machine, a
+warning is printed when the gateway is not local.
+
+Use --scan to scan each image once it is pushed, the deploy step is not run
+when an image has findings at --scan-severity or above.
+
+Use --resolve-digests to deploy each function by the digest its image was
+pushed with, in place of its tag, which is printed after the push step.
+
+Use --watch to keep running after the first up and run it again for each
+function whose handler folder changes. The folders are checked every half a
+second and a function is rebuilt a second after its last change. Editor
+temporary files are not watched, use --watch-include and --watch-exclude to
+choose which files are.
+
+The --fail-fast and --continue-on-error flags apply within each of the build,
+push and deploy steps, the next step is not run when a function failed in one.
 
 Note: All flags from the build, push and deploy flags are valid and can be combined,
 see the --help text for those commands for details.`,
 	Example: `  faas-cli up -f myfn.yaml
-faas-cli up --filter "*gif*" --secret dockerhuborg`,
+  faas-cli up -f myfn.yaml figlet "web-*"
+  faas-cli up --filter "*gif*" --secret dockerhuborg
+  faas-cli up --regex "^api-" figlet
+  faas-cli up --skip-push
+  faas-cli up --skip-deploy
+  faas-cli up --resolve-digests
+  faas-cli up --scan --scan-severity CRITICAL
+  faas-cli up --platforms linux/amd64,linux/arm64
+  faas-cli up --watch --skip-push --watch-exclude "node_modules"`,
 	PreRunE: preRunUp,
-	RunE:    upHandler,
+	RunE:    runUp,
 }
 
 func preRunUp(cmd *cobra.Command, args []string) error {
+	if skipBuild && skipPush && skipDeploy {
+		return fmt.Errorf("--skip-build, --skip-push and --skip-deploy leave nothing to do")
+	}
+
+	if skipPush && len(parsePlatforms(platforms)) > 1 {
+		return fmt.Errorf("--skip-push can't be used with several --platforms, as docker buildx pushes the images as they are built")
+	}
+
+	if len(deployFlags.updateEnv) > 0 {
+		return fmt.Errorf("--update-env redeploys a function which is already deployed, use it with \"faas-cli deploy\"")
+	}
+
+	if pushScan {
+		if skipPush {
+			return fmt.Errorf("--scan scans the images once they are pushed, it can't be used with --skip-push")
+		}
+		if err := checkScanSeverity(scanSeverity); err != nil {
+			return err
+		}
+	}
+
+	if resolveDigests && (skipPush || len(parsePlatforms(platforms)) > 1) {
+		return fmt.Errorf("--resolve-digests needs the images to be pushed by the push step, it can't be used with --skip-push or several --platforms")
+	}
+
 	if err := preRunBuild(cmd, args); err != nil {
 		return err
 	}
@@ -63,16 +128,44 @@ func preRunUp(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runUp(cmd *cobra.Command, args []string) error {
+	if watch {
+		return runUpWatch(cmd, args)
+	}
+	return upHandler(cmd, args)
+}
+
 func upHandler(cmd *cobra.Command, args []string) error {
-	if err := runBuild(cmd, args); err != nil {
-		return err
+	if skipPush && !skipDeploy {
+		if gatewayURL := upGatewayURL(); !isLocalGateway(gatewayURL) {
+			fmt.Fprintf(os.Stderr, "Warning: --skip-push was given, but the gateway %s is not local, it may not be able to pull images which were not pushed to a registry.\n\n", gatewayURL)
+		}
+	}
+
+	// Images for several platforms are pushed by buildx during the build
+	multiPlatform := len(buildPlatforms) > 1
+	if multiPlatform {
+		buildxPush = true
+		defer func() { buildxPush = false }()
+	}
+
+	if !skipBuild {
+		if err := runBuild(cmd, args); err != nil {
+			return err
+		}
+		fmt.Fprintln(infoOutput())
 	}
-	fmt.Println()
-	if !skipPush {
+	if !skipPush && !multiPlatform {
 		if err := runPush(cmd, args); err != nil {
 			return err
 		}
-		fmt.Println()
+		fmt.Fprintln(infoOutput())
+	}
+	if multiPlatform && pushScan {
+		if err := runUpScan(args); err != nil {
+			return err
+		}
+		fmt.Fprintln(infoOutput())
 	}
 	if !skipDeploy {
 		if err := runDeploy(cmd, args); err != nil {
@@ -81,3 +174,46 @@ func upHandler(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+// runUpScan scans the images which buildx pushed during the build, as the
+// push step which would scan them is not run
+func runUpScan(args []string) error {
+	services, err := parseSelectedStack(args)
+	if err != nil {
+		return err
+	}
+	if services == nil {
+		return fmt.Errorf("you must supply a valid YAML file")
+	}
+	return runScan(services.Functions)
+}
+
+// upGatewayURL gives the gateway which deploy will use, the stack file is
+// parsed again by deploy which reports any error in it
+func upGatewayURL() string {
+	var yamlGateway string
+	if len(yamlFile) > 0 {
+		if services, err := stack.ParseYAMLFiles(stackFiles(), regex, filter, envsubst); err == nil {
+			yamlGateway = services.Provider.GatewayURL
+		}
+	}
+
+	return getGatewayURL(gateway, defaultGateway, yamlGateway, os.Getenv(openFaaSURLEnvironment))
+}
+
+// isLocalGateway is true when the gateway runs on this machine, and so
+// may use images which were built here without pushing them
+func isLocalGateway(gatewayURL string) bool {
+	u, err := url.Parse(gatewayURL)
+	if err != nil {
+		return false
+	}
+
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,60 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"testing"
+)
+
+func Test_isLocalGateway(t *testing.T) {
+	cases := []struct {
+		gateway string
+		want    bool
+	}{
+		{gateway: "http://127.0.0.1:8080", want: true},
+		{gateway: "http://localhost:8080", want: true},
+		{gateway: "http://[::1]:8080", want: true},
+		{gateway: "https://gw.example.com", want: false},
+		{gateway: "http://192.168.0.10:31112", want: false},
+	}
+
+	for _, c := range cases {
+		if got := isLocalGateway(c.gateway); got != c.want {
+			t.Errorf("%s: want %v, got %v", c.gateway, c.want, got)
+		}
+	}
+}
+
+func Test_upSkipEverything(t *testing.T) {
+	resetForTest()
+	defer func() {
+		skipBuild = false
+		skipPush = false
+		skipDeploy = false
+	}()
+
+	faasCmd.SetArgs([]string{"up", "--skip-build", "--skip-push", "--skip-deploy"})
+	err := faasCmd.Execute()
+
+	want := "--skip-build, --skip-push and --skip-deploy leave nothing to do"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+func Test_up_ResolveDigestsWithSkipPush(t *testing.T) {
+	resetForTest()
+	defer func() {
+		skipPush = false
+		resolveDigests = false
+	}()
+
+	faasCmd.SetArgs([]string{"up", "--skip-push", "--resolve-digests"})
+	err := faasCmd.Execute()
+
+	want := "--resolve-digests needs the images to be pushed by the push step, it can't be used with --skip-push or several --platforms"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
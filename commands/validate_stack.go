@@ -0,0 +1,73 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/openfaas/faas-cli/stack"
+	"github.com/spf13/cobra"
+)
+
+var validateStrict bool
+
+func init() {
+	validateCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yml file")
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "Treat unknown keys as errors")
+
+	faasCmd.AddCommand(validateCmd)
+}
+
+// validateCmd checks a stack file without building or deploying it
+var validateCmd = &cobra.Command{
+	Use:   `validate -f YAML_FILE [--strict]`,
+	Short: "Validate a stack file",
+	Long: `Checks the stack file for unknown keys, such as a misspelt "environment", and
+that every function gives a lang or an image. Unknown keys are reported as
+warnings unless "--strict" is passed. The same checks are run by build and deploy.`,
+	Example: `  faas-cli validate -f ./stack.yml
+  faas-cli validate -f ./stack.yml -f ./stack.prod.yml --strict`,
+	RunE: runValidate,
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	if len(yamlFile) == 0 {
+		return fmt.Errorf("give a stack file to validate with --yaml")
+	}
+
+	if err := checkStackFiles(os.Stdout, stackFiles(), validateStrict); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is valid.\n", strings.Join(stackFiles(), ", "))
+	return nil
+}
+
+// checkStackFiles prints each problem found in the stack files and gives an
+// error when there are errors, or warnings and strict is set.
+func checkStackFiles(w io.Writer, files []string, strict bool) error {
+	problems, err := stack.ValidateYAMLFiles(files, envsubst)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, problem := range problems {
+		if strict {
+			problem.Warning = false
+		}
+		if !problem.Warning {
+			failed++
+		}
+		fmt.Fprintln(w, problem.String())
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("stack file validation failed with %d error(s)", failed)
+	}
+	return nil
+}
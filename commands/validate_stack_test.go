@@ -0,0 +1,73 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+const typoStackFile = `provider:
+  name: openfaas
+functions:
+  figlet:
+    lang: dockerfile
+    enviroment:
+      write_debug: true
+`
+
+func writeTypoStack(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "faas-cli-validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "stack.yml")
+	if err := ioutil.WriteFile(file, []byte(typoStackFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func Test_validate_WarnsOnUnknownKey(t *testing.T) {
+	file := writeTypoStack(t)
+	defer os.RemoveAll(filepath.Dir(file))
+	defer resetForTest()
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"validate", "-f", file})
+		err = faasCmd.Execute()
+	})
+
+	if err != nil {
+		t.Fatalf("want no error without --strict, got %s", err)
+	}
+	if !strings.Contains(stdOut, file+`:6: warning: unknown key "enviroment"`) {
+		t.Errorf("want warning with line number, got %q", stdOut)
+	}
+}
+
+func Test_validate_StrictFails(t *testing.T) {
+	file := writeTypoStack(t)
+	defer os.RemoveAll(filepath.Dir(file))
+	defer func() {
+		resetForTest()
+		validateStrict = false
+	}()
+
+	var err error
+	test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"validate", "-f", file, "--strict"})
+		err = faasCmd.Execute()
+	})
+
+	if err == nil || err.Error() != "stack file validation failed with 1 error(s)" {
+		t.Errorf("want validation error, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"runtime"
 	"time"
@@ -20,17 +21,27 @@ import (
 
 // GitCommit injected at build-time
 var (
-	shortVersion bool
-	warnUpdate   bool
+	shortVersion  bool
+	warnUpdate    bool
+	versionOutput string
 )
 
+// versionDetails is written by "faas-cli version --output json", the
+// gateway version is left out when the gateway can't be reached
+type versionDetails struct {
+	Commit         string `json:"commit"`
+	Version        string `json:"version"`
+	GoVersion      string `json:"goVersion"`
+	GatewayVersion string `json:"gatewayVersion,omitempty"`
+}
+
 func init() {
 	versionCmd.Flags().BoolVar(&shortVersion, "short-version", false, "Just print Git SHA")
 	versionCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
-	versionCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
 	versionCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yml file")
 
-	versionCmd.Flags().BoolVar(&warnUpdate, "warn-update", true, "Check for new version and warn about updating")
+	versionCmd.Flags().BoolVar(&warnUpdate, "warn-update", true, "Check for new version and warn about updating, not done for --output")
+	versionCmd.Flags().StringVarP(&versionOutput, "output", "o", "", "Output format, only json is supported, instead of text")
 
 	versionCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
 	faasCmd.AddCommand(versionCmd)
@@ -38,20 +49,28 @@ func init() {
 
 // versionCmd displays version information
 var versionCmd = &cobra.Command{
-	Use:   "version [--short-version] [--gateway GATEWAY_URL]",
+	Use:   "version [--short-version] [--gateway GATEWAY_URL] [--output json]",
 	Short: "Display the clients version information",
 	Long: fmt.Sprintf(`The version command returns the current clients version information.
 
 This currently consists of the GitSHA from which the client was built.
 - https://github.com/openfaas/faas-cli/tree/%s`, version.GitCommit),
 	Example: `  faas-cli version
-  faas-cli version --short-version`,
+  faas-cli version --short-version
+  faas-cli version --output json | jq -r .gatewayVersion`,
 	RunE: runVersionE,
 }
 
 func runVersionE(cmd *cobra.Command, args []string) error {
 	releases := "https://github.com/openfaas/faas-cli/releases/latest"
 
+	if len(versionOutput) > 0 {
+		if versionOutput != "json" {
+			return fmt.Errorf("--output must be json, not: %s", versionOutput)
+		}
+		return printVersionJSON()
+	}
+
 	if shortVersion {
 		fmt.Println(version.BuildVersion())
 
@@ -79,13 +98,40 @@ func runVersionE(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func printServerVersions() {
+// printVersionJSON writes the version of the CLI and, when it can be
+// reached, the gateway as JSON
+func printVersionJSON() error {
+	details := versionDetails{
+		Commit:    version.GitCommit,
+		Version:   version.BuildVersion(),
+		GoVersion: runtime.Version(),
+	}
+
+	if _, info, err := getServerInfo(versionTimeout); err == nil {
+		details.GatewayVersion, _, _ = getGatewayDetails(info)
+	}
+
+	out, err := json.MarshalIndent(details, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(out))
+	return nil
+}
+
+// versionTimeout is short as the gateway doesn't have to be reachable to give
+// the version
+const versionTimeout = 5 * time.Second
 
+// getServerInfo gets the system info from the gateway given by the flags, the
+// stack file or the environment
+func getServerInfo(timeout time.Duration) (string, map[string]interface{}, error) {
 	var services stack.Services
 	var gatewayAddress string
 	var yamlGateway string
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		parsedServices, err := stack.ParseYAMLFiles(stackFiles(), regex, filter, envsubst)
 		if err == nil && parsedServices != nil {
 			services = *parsedServices
 			yamlGateway = services.Provider.GatewayURL
@@ -94,15 +140,26 @@ func printServerVersions() {
 
 	gatewayAddress = getGatewayURL(gateway, defaultGateway, yamlGateway, os.Getenv(openFaaSURLEnvironment))
 
-	versionTimeout := 5 * time.Second
 	cliAuth := NewCLIAuth(token, gatewayAddress)
-	transport := GetDefaultCLITransport(tlsInsecure, &versionTimeout)
-	cliClient := proxy.NewClient(cliAuth, gatewayAddress, transport, &versionTimeout)
+	transport := GetDefaultCLITransport(tlsInsecure, &timeout)
+	cliClient := proxy.NewClient(cliAuth, gatewayAddress, transport, &timeout)
 	info, err := cliClient.GetSystemInfo(context.Background())
+
+	return gatewayAddress, info, err
+}
+
+func printServerVersions() {
+	gatewayAddress, info, err := getServerInfo(versionTimeout)
 	if err != nil {
 		return
 	}
 
+	printSystemInfo(gatewayAddress, info)
+}
+
+// printSystemInfo prints the version of the gateway and the name,
+// orchestration and version of its provider
+func printSystemInfo(gatewayAddress string, info map[string]interface{}) {
 	version, sha, commit := getGatewayDetails(info)
 
 	printGatewayDetails(gatewayAddress, version, sha, commit)
@@ -144,10 +201,10 @@ func printLogo() {
 
 func getGatewayDetails(m map[string]interface{}) (version, sha, commit string) {
 	if _, ok := m["orchestration"]; !ok {
-		v := m["version"].(map[string]interface{})
-		version = v["release"].(string)
-		sha = v["sha"].(string)
-		commit = v["commit_message"].(string)
+		v, _ := m["version"].(map[string]interface{})
+		version, _ = v["release"].(string)
+		sha, _ = v["sha"].(string)
+		commit, _ = v["commit_message"].(string)
 	}
 
 	return
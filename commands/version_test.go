@@ -4,7 +4,9 @@
 package commands
 
 import (
+	"encoding/json"
 	"regexp"
+	"runtime"
 	"testing"
 
 	"fmt"
@@ -158,6 +160,75 @@ func Test_gateway_details_prior_to_0_8_4_should_not_be_displayed(t *testing.T) {
 	}
 }
 
+func Test_version_output_json(t *testing.T) {
+	version.GitCommit = "sha-test"
+	version.Version = "version.tag"
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/info",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       gateway_response_0_8_4_onwards,
+		},
+	})
+	defer s.Close()
+
+	cases := []struct {
+		name    string
+		gateway string
+		want    versionDetails
+	}{
+		{
+			name:    "reachable gateway",
+			gateway: s.URL,
+			want:    versionDetails{Commit: "sha-test", Version: "version.tag", GoVersion: runtime.Version(), GatewayVersion: "gateway-0.4.3"},
+		},
+		{
+			name:    "unreachable gateway",
+			gateway: "http://127.0.0.1:1",
+			want:    versionDetails{Commit: "sha-test", Version: "version.tag", GoVersion: runtime.Version()},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resetForTest()
+			version.Version = "version.tag"
+
+			var err error
+			stdOut := test.CaptureStdout(func() {
+				faasCmd.SetArgs([]string{"version", "--gateway=" + c.gateway, "--output=json"})
+				err = faasCmd.Execute()
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got versionDetails
+			if err := json.Unmarshal([]byte(stdOut), &got); err != nil {
+				t.Fatalf("want JSON, got %q: %s", stdOut, err)
+			}
+			if got != c.want {
+				t.Errorf("want %+v, got %+v", c.want, got)
+			}
+		})
+	}
+	resetForTest()
+}
+
+func Test_version_output_invalid(t *testing.T) {
+	resetForTest()
+	defer resetForTest()
+
+	faasCmd.SetArgs([]string{"version", "--output=yaml"})
+	err := faasCmd.Execute()
+
+	if err == nil || err.Error() != "--output must be json, not: yaml" {
+		t.Errorf("want an error for --output yaml, got %v", err)
+	}
+}
+
 func executeVersionCmd(t *testing.T, responseBody string) (versionInfo string, gatewayUri string) {
 	resetForTest()
 	s := test.MockHttpServer(t, []test.Request{
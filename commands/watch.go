@@ -0,0 +1,227 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	watchInterval = 500 * time.Millisecond
+	watchDebounce = time.Second
+)
+
+var (
+	watch        bool
+	watchInclude []string
+	watchExclude []string
+)
+
+// defaultWatchExcludes are the temporary files written by editors, which
+// would otherwise trigger a rebuild on every save
+var defaultWatchExcludes = []string{"*~", ".*.swp", ".*.swx", ".#*", "#*#", "4913", ".DS_Store"}
+
+// fileState is what is compared to find a changed file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// runUpWatch runs up for every function and then again for each function
+// whose handler changes, until it is interrupted
+func runUpWatch(cmd *cobra.Command, args []string) error {
+	handlers, err := watchedHandlers(args)
+	if err != nil {
+		return err
+	}
+
+	if err := upHandler(cmd, args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	done := make(chan struct{})
+	go func() {
+		<-stop
+		close(done)
+	}()
+
+	names := make([]string, 0, len(handlers))
+	for name := range handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Printf("\nWatching for changes to: %v, press Control+C to stop.\n", names)
+
+	watchHandlers(handlers, watchInterval, watchDebounce, done, func(changed []string) {
+		for _, name := range changed {
+			fmt.Printf("\nRebuilding %s...\n", name)
+			if err := upFunction(cmd, args, name); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			}
+		}
+	})
+
+	fmt.Println("Stopped watching.")
+	return nil
+}
+
+// watchedHandlers gives the handler folder of each function in the stack
+// file, or of those named by args, other than those which are not built
+func watchedHandlers(args []string) (map[string]string, error) {
+	if len(yamlFile) == 0 {
+		return nil, fmt.Errorf("--watch needs a stack file with the functions to watch")
+	}
+
+	services, err := parseSelectedStack(args)
+	if err != nil {
+		return nil, err
+	}
+
+	handlers := map[string]string{}
+	for name, function := range services.Functions {
+		if function.SkipBuild || len(function.Handler) == 0 {
+			continue
+		}
+		handlers[name] = function.Handler
+	}
+
+	if len(handlers) == 0 {
+		return nil, fmt.Errorf("no functions with a handler to watch in the stack file")
+	}
+	return handlers, nil
+}
+
+// upFunction runs up for one function of the stack file, in place of the
+// functions named by args
+func upFunction(cmd *cobra.Command, args []string, name string) error {
+	previousFilter, previousRegex := filter, regex
+	filter, regex = name, ""
+	defer func() { filter, regex = previousFilter, previousRegex }()
+
+	return upHandler(cmd, nil)
+}
+
+// watchHandlers polls the handler folders and calls onChange with the
+// functions whose files changed, once no more changes are seen within
+// debounce, until done is closed
+func watchHandlers(handlers map[string]string, interval, debounce time.Duration, done <-chan struct{}, onChange func([]string)) {
+	snapshots := map[string]map[string]fileState{}
+	for name, dir := range handlers {
+		snapshots[name] = snapshotDir(dir, watchInclude, watchExclude)
+	}
+
+	pending := map[string]bool{}
+	var lastChange time.Time
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		for name, dir := range handlers {
+			snapshot := snapshotDir(dir, watchInclude, watchExclude)
+			if !sameSnapshot(snapshots[name], snapshot) {
+				snapshots[name] = snapshot
+				pending[name] = true
+				lastChange = time.Now()
+			}
+		}
+
+		if len(pending) == 0 || time.Since(lastChange) < debounce {
+			continue
+		}
+
+		changed := make([]string, 0, len(pending))
+		for name := range pending {
+			changed = append(changed, name)
+		}
+		sort.Strings(changed)
+		pending = map[string]bool{}
+
+		onChange(changed)
+
+		// Files written by the build are not a change to rebuild for
+		for name, dir := range handlers {
+			snapshots[name] = snapshotDir(dir, watchInclude, watchExclude)
+		}
+	}
+}
+
+// snapshotDir records the files in dir which match include, when given, and
+// don't match exclude or defaultWatchExcludes. A folder which can't be read
+// gives an empty snapshot, so that it is seen as changed once it can be.
+func snapshotDir(dir string, include, exclude []string) map[string]fileState {
+	snapshot := map[string]fileState{}
+	excludes := append(append([]string{}, defaultWatchExcludes...), exclude...)
+
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(dir, path)
+		if info.IsDir() {
+			if info.Name() == ".git" || (rel != "." && matchesWatchGlob(excludes, rel, info.Name())) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if matchesWatchGlob(excludes, rel, info.Name()) {
+			return nil
+		}
+		if len(include) > 0 && !matchesWatchGlob(include, rel, info.Name()) {
+			return nil
+		}
+
+		snapshot[rel] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+
+	return snapshot
+}
+
+// matchesWatchGlob is true when a glob matches the path relative to the
+// handler folder or the file's name
+func matchesWatchGlob(globs []string, rel, name string) bool {
+	for _, glob := range globs {
+		if ok, _ := filepath.Match(glob, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func sameSnapshot(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		other, ok := b[path]
+		if !ok || !other.modTime.Equal(state.modTime) || other.size != state.size {
+			return false
+		}
+	}
+	return true
+}
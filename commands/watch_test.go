@@ -0,0 +1,102 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_snapshotDir_IncludeAndExclude(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "faas-cli-watch")
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"handler.go", ".handler.go.swp", "handler.go~", "README.md", ".git/HEAD", "node_modules/dep/index.js", "lib/util.go"} {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)
+		ioutil.WriteFile(filepath.Join(dir, name), []byte("x"), 0600)
+	}
+
+	cases := []struct {
+		name             string
+		include, exclude []string
+		want             []string
+	}{
+		{name: "editor files and .git are skipped", want: []string{"README.md", "handler.go", "lib/util.go", "node_modules/dep/index.js"}},
+		{name: "excluded folder", exclude: []string{"node_modules"}, want: []string{"README.md", "handler.go", "lib/util.go"}},
+		{name: "included files", include: []string{"*.go"}, exclude: []string{"lib"}, want: []string{"handler.go"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got []string
+			for path := range snapshotDir(dir, c.include, c.exclude) {
+				got = append(got, filepath.ToSlash(path))
+			}
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("want %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
+func Test_watchHandlers_DebouncesChanges(t *testing.T) {
+	root, _ := ioutil.TempDir("", "faas-cli-watch")
+	defer os.RemoveAll(root)
+
+	handlers := map[string]string{}
+	for _, name := range []string{"fn1", "fn2"} {
+		handlers[name] = filepath.Join(root, name)
+		os.MkdirAll(handlers[name], 0755)
+		ioutil.WriteFile(filepath.Join(handlers[name], "handler.go"), []byte("package function"), 0600)
+	}
+
+	done := make(chan struct{})
+	changes := make(chan []string, 10)
+	go func() {
+		watchHandlers(handlers, 10*time.Millisecond, 100*time.Millisecond, done, func(changed []string) {
+			changes <- changed
+		})
+	}()
+	defer close(done)
+
+	// Give the first snapshot time to be taken
+	time.Sleep(50 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		ioutil.WriteFile(filepath.Join(handlers["fn1"], "handler.go"), []byte("package function"+strings.Repeat(" ", i+1)), 0600)
+		time.Sleep(20 * time.Millisecond)
+	}
+	ioutil.WriteFile(filepath.Join(handlers["fn1"], ".handler.go.swp"), []byte("swap"), 0600)
+
+	select {
+	case changed := <-changes:
+		if !reflect.DeepEqual(changed, []string{"fn1"}) {
+			t.Errorf("want only fn1 to be rebuilt, got %v", changed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("want a change to be seen")
+	}
+
+	select {
+	case changed := <-changes:
+		t.Errorf("want the changes to fn1 to give one rebuild, got another for %v", changed)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func Test_watchedHandlers_NeedsStackFile(t *testing.T) {
+	resetForTest()
+
+	if _, err := watchedHandlers(nil); err == nil || err.Error() != "--watch needs a stack file with the functions to watch" {
+		t.Errorf("want an error without a stack file, got %v", err)
+	}
+}
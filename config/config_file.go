@@ -21,8 +21,16 @@ import (
 var (
 	DefaultDir  = "~/.openfaas"
 	DefaultFile = "config.yml"
+
+	// ConfigPath is used as the config file instead of the default when it is
+	// set, such as by the --config flag
+	ConfigPath string
 )
 
+// ConfigEnvironment is the environment variable which gives the path of the
+// config file when ConfigPath is not set
+const ConfigEnvironment = "OPENFAAS_CONFIG"
+
 //AuthType auth type
 type AuthType string
 
@@ -37,12 +45,20 @@ const (
 type ConfigFile struct {
 	AuthConfigs []AuthConfig `yaml:"auths"`
 	FilePath    string       `yaml:"-"`
+
+	// DefaultGateway is used when no gateway is given by a flag, the stack
+	// file or the environment
+	DefaultGateway string `yaml:"default_gateway,omitempty"`
 }
 
 type AuthConfig struct {
 	Gateway string   `yaml:"gateway,omitempty"`
 	Auth    AuthType `yaml:"auth,omitempty"`
 	Token   string   `yaml:"token,omitempty"`
+	// RefreshToken is used with the TokenURL and ClientID to renew an oauth2 Token
+	RefreshToken string `yaml:"refresh_token,omitempty"`
+	TokenURL     string `yaml:"token_url,omitempty"`
+	ClientID     string `yaml:"client_id,omitempty"`
 }
 
 // New initializes a config file for the given file path
@@ -58,14 +74,37 @@ func New(filePath string) (*ConfigFile, error) {
 	return conf, nil
 }
 
+// FilePath gives the path of the config file, which is ConfigPath, then the
+// OPENFAAS_CONFIG environment variable and then DefaultFile in DefaultDir
+func FilePath() (string, error) {
+	filePath := ConfigPath
+	if len(filePath) == 0 {
+		filePath = os.Getenv(ConfigEnvironment)
+	}
+
+	if len(filePath) > 0 {
+		expanded, err := homedir.Expand(filePath)
+		if err != nil {
+			return "", err
+		}
+		return path.Clean(expanded), nil
+	}
+
+	dirPath, err := homedir.Expand(DefaultDir)
+	if err != nil {
+		return "", err
+	}
+
+	return path.Clean(filepath.Join(dirPath, DefaultFile)), nil
+}
+
 // EnsureFile creates the root dir and config file
 func EnsureFile() (string, error) {
-	dirPath, err := homedir.Expand(DefaultDir)
+	filePath, err := FilePath()
 	if err != nil {
 		return "", err
 	}
 
-	filePath := path.Clean(filepath.Join(dirPath, DefaultFile))
 	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
 		return "", err
 	}
@@ -81,14 +120,13 @@ func EnsureFile() (string, error) {
 	return filePath, nil
 }
 
-// FileExists returns true if the config file is located at the default path
+// FileExists returns true if the config file is located at the path given by FilePath
 func fileExists() bool {
-	dirPath, err := homedir.Expand(DefaultDir)
+	filePath, err := FilePath()
 	if err != nil {
 		return false
 	}
 
-	filePath := path.Clean(filepath.Join(dirPath, DefaultFile))
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		return false
 	}
@@ -133,6 +171,7 @@ func (configFile *ConfigFile) load() error {
 	if len(conf.AuthConfigs) > 0 {
 		configFile.AuthConfigs = conf.AuthConfigs
 	}
+	configFile.DefaultGateway = conf.DefaultGateway
 	return nil
 }
 
@@ -206,6 +245,53 @@ func UpdateAuthConfig(gateway, token string, authType AuthType) error {
 	return nil
 }
 
+// UpdateRefreshToken stores the refresh token for a given gateway along with the
+// token URL and client ID needed to redeem it. The gateway must already have an auth config.
+func UpdateRefreshToken(gateway, refreshToken, tokenURL, clientID string) error {
+	if !fileExists() {
+		return fmt.Errorf("config file not found")
+	}
+
+	configPath, err := EnsureFile()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := New(configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.load(); err != nil {
+		return err
+	}
+
+	for i, v := range cfg.AuthConfigs {
+		if gateway == v.Gateway {
+			cfg.AuthConfigs[i].RefreshToken = refreshToken
+			cfg.AuthConfigs[i].TokenURL = tokenURL
+			cfg.AuthConfigs[i].ClientID = clientID
+			return cfg.save()
+		}
+	}
+
+	return fmt.Errorf("no auth config found for %s", gateway)
+}
+
+// GetRefreshToken returns the refresh token for a given gateway
+func GetRefreshToken(gateway string) (string, error) {
+	authConfig, err := LookupAuthConfig(gateway)
+	if err != nil {
+		return "", err
+	}
+
+	if len(authConfig.RefreshToken) == 0 {
+		return "", fmt.Errorf("no refresh token found for %s", gateway)
+	}
+
+	return authConfig.RefreshToken, nil
+}
+
 // LookupAuthConfig returns the username and password for a given gateway
 func LookupAuthConfig(gateway string) (AuthConfig, error) {
 	var authConfig AuthConfig
@@ -278,6 +364,123 @@ func RemoveAuthConfig(gateway string) error {
 	return nil
 }
 
+// HasAuthConfig reports whether credentials are stored for a given gateway, a
+// missing config file means nothing is stored.
+func HasAuthConfig(gateway string) (bool, error) {
+	if !fileExists() {
+		return false, nil
+	}
+
+	configPath, err := EnsureFile()
+	if err != nil {
+		return false, err
+	}
+
+	cfg, err := New(configPath)
+	if err != nil {
+		return false, err
+	}
+
+	if err := cfg.load(); err != nil {
+		return false, err
+	}
+
+	for _, v := range cfg.AuthConfigs {
+		if gateway == v.Gateway {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// RemoveAllAuthConfigs deletes the credentials for every gateway and returns
+// the gateways which were removed
+func RemoveAllAuthConfigs() ([]string, error) {
+	if !fileExists() {
+		return nil, nil
+	}
+
+	configPath, err := EnsureFile()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := New(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.load(); err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, v := range cfg.AuthConfigs {
+		removed = append(removed, v.Gateway)
+	}
+
+	if len(removed) > 0 {
+		cfg.AuthConfigs = []AuthConfig{}
+		if err := cfg.save(); err != nil {
+			return nil, err
+		}
+	}
+
+	return removed, nil
+}
+
+// SetDefaultGateway stores the gateway to use when none is given, an empty
+// gateway removes it
+func SetDefaultGateway(gateway string) error {
+	if len(gateway) > 0 {
+		if _, err := url.ParseRequestURI(gateway); err != nil {
+			return fmt.Errorf("invalid gateway URL")
+		}
+	}
+
+	configPath, err := EnsureFile()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := New(configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.load(); err != nil {
+		return err
+	}
+
+	cfg.DefaultGateway = gateway
+	return cfg.save()
+}
+
+// GetDefaultGateway returns the gateway stored by SetDefaultGateway, which is
+// empty when none was stored
+func GetDefaultGateway() (string, error) {
+	if !fileExists() {
+		return "", nil
+	}
+
+	configPath, err := EnsureFile()
+	if err != nil {
+		return "", err
+	}
+
+	cfg, err := New(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	if err := cfg.load(); err != nil {
+		return "", err
+	}
+
+	return cfg.DefaultGateway, nil
+}
+
 func removeAuthByIndex(s []AuthConfig, index int) []AuthConfig {
 	return append(s[:index], s[index+1:]...)
 }
@@ -253,3 +253,202 @@ func Test_UpdateAuthConfig_Oauth2Insert(t *testing.T) {
 		t.Errorf("got token %s, expected %s", authConfig.Token, token)
 	}
 }
+
+func Test_UpdateRefreshToken(t *testing.T) {
+	DefaultDir, _ = ioutil.TempDir("", "faas-cli-file-test")
+	DefaultFile = "test10.yml"
+	gatewayURL := "http://openfaas.test"
+	UpdateAuthConfig(gatewayURL, "access", Oauth2AuthType)
+	UpdateAuthConfig("http://openfaas.test2", "other", Oauth2AuthType)
+
+	err := UpdateRefreshToken(gatewayURL, "refresh", "http://idp/oauth/token", "my-id")
+	if err != nil {
+		t.Fatalf("got error %s", err.Error())
+	}
+
+	authConfig, err := LookupAuthConfig(gatewayURL)
+	if err != nil {
+		t.Fatalf("got error %s", err.Error())
+	}
+
+	if authConfig.Token != "access" {
+		t.Errorf("got token %s, expected %s", authConfig.Token, "access")
+	}
+	if authConfig.TokenURL != "http://idp/oauth/token" || authConfig.ClientID != "my-id" {
+		t.Errorf("got token URL %s and client ID %s", authConfig.TokenURL, authConfig.ClientID)
+	}
+
+	refreshToken, err := GetRefreshToken(gatewayURL)
+	if err != nil {
+		t.Fatalf("got error %s", err.Error())
+	}
+	if refreshToken != "refresh" {
+		t.Errorf("got refresh token %s, expected %s", refreshToken, "refresh")
+	}
+
+	if _, err := GetRefreshToken("http://openfaas.test2"); err == nil {
+		t.Errorf("Error was not returned for gateway without a refresh token")
+	}
+}
+
+func Test_UpdateRefreshToken_WithUnknownGateway(t *testing.T) {
+	DefaultDir, _ = ioutil.TempDir("", "faas-cli-file-test")
+	DefaultFile = "test11.yml"
+	UpdateAuthConfig("http://openfaas.test", "access", Oauth2AuthType)
+
+	err := UpdateRefreshToken("http://openfaas.test1", "refresh", "http://idp/oauth/token", "my-id")
+	if err == nil {
+		t.Fatal("Error was not returned")
+	}
+
+	r := regexp.MustCompile(`(?m:no auth config found for)`)
+	if !r.MatchString(err.Error()) {
+		t.Errorf("Error not matched: %s", err.Error())
+	}
+}
+
+func Test_HasAuthConfig(t *testing.T) {
+	DefaultDir, _ = ioutil.TempDir("", "faas-cli-file-test")
+	DefaultFile = "test12.yml"
+
+	found, err := HasAuthConfig("http://openfaas.test")
+	if err != nil {
+		t.Fatalf("got error %s", err.Error())
+	}
+	if found {
+		t.Errorf("want no auth config without a config file")
+	}
+
+	UpdateAuthConfig("http://openfaas.test", "somebase64encodedstring", Oauth2AuthType)
+
+	found, err = HasAuthConfig("http://openfaas.test")
+	if err != nil {
+		t.Fatalf("got error %s", err.Error())
+	}
+	if !found {
+		t.Errorf("want auth config to be found")
+	}
+
+	found, _ = HasAuthConfig("http://openfaas.test1")
+	if found {
+		t.Errorf("want no auth config for unknown gateway")
+	}
+}
+
+func Test_RemoveAllAuthConfigs(t *testing.T) {
+	DefaultDir, _ = ioutil.TempDir("", "faas-cli-file-test")
+	DefaultFile = "test13.yml"
+
+	UpdateAuthConfig("http://openfaas.test", "token1", Oauth2AuthType)
+	UpdateAuthConfig("http://openfaas.test2", "token2", Oauth2AuthType)
+
+	removed, err := RemoveAllAuthConfigs()
+	if err != nil {
+		t.Fatalf("got error %s", err.Error())
+	}
+
+	if len(removed) != 2 || removed[0] != "http://openfaas.test" || removed[1] != "http://openfaas.test2" {
+		t.Errorf("want both gateways removed, got %v", removed)
+	}
+
+	if _, err := LookupAuthConfig("http://openfaas.test2"); err == nil {
+		t.Errorf("want auth config to be removed")
+	}
+}
+
+func Test_RemoveAllAuthConfigs_WithNoConfigFile(t *testing.T) {
+	DefaultDir, _ = ioutil.TempDir("", "faas-cli-file-test")
+	DefaultFile = "test14.yml"
+
+	removed, err := RemoveAllAuthConfigs()
+	if err != nil {
+		t.Fatalf("got error %s", err.Error())
+	}
+	if len(removed) != 0 {
+		t.Errorf("want nothing removed, got %v", removed)
+	}
+}
+
+func Test_FilePath(t *testing.T) {
+	defer func() { ConfigPath = "" }()
+	defer os.Unsetenv(ConfigEnvironment)
+
+	DefaultDir, _ = ioutil.TempDir("", "faas-cli-file-test")
+	DefaultFile = "test15.yml"
+
+	cases := []struct {
+		name       string
+		configPath string
+		env        string
+		want       string
+	}{
+		{name: "default", want: DefaultDir + "/test15.yml"},
+		{name: "environment", env: "/tmp/env/config.yml", want: "/tmp/env/config.yml"},
+		{name: "flag over environment", configPath: "/tmp/flag/config.yml", env: "/tmp/env/config.yml", want: "/tmp/flag/config.yml"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ConfigPath = c.configPath
+			os.Setenv(ConfigEnvironment, c.env)
+
+			got, err := FilePath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("want %s, got %s", c.want, got)
+			}
+		})
+	}
+}
+
+func Test_UpdateAuthConfig_ConfigPath(t *testing.T) {
+	defer func() { ConfigPath = "" }()
+
+	DefaultDir, _ = ioutil.TempDir("", "faas-cli-file-test")
+	DefaultFile = "test16.yml"
+
+	dir, _ := ioutil.TempDir("", "faas-cli-file-test")
+	ConfigPath = dir + "/ci/config.yml"
+
+	gateway := "http://openfaas.test16"
+	if err := UpdateAuthConfig(gateway, EncodeAuth("admin", "pass"), BasicAuthType); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(ConfigPath); err != nil {
+		t.Errorf("want the config written to %s, got %s", ConfigPath, err)
+	}
+	if _, err := os.Stat(DefaultDir + "/" + DefaultFile); !os.IsNotExist(err) {
+		t.Errorf("want the default config file to be left alone, got %v", err)
+	}
+
+	authConfig, err := LookupAuthConfig(gateway)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authConfig.Gateway != gateway {
+		t.Errorf("want gateway %s, got %s", gateway, authConfig.Gateway)
+	}
+}
+
+func Test_SetDefaultGateway_KeptByAuthUpdates(t *testing.T) {
+	DefaultDir, _ = ioutil.TempDir("", "faas-cli-file-test")
+	DefaultFile = "test17.yml"
+
+	if err := SetDefaultGateway("https://openfaas.test17"); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateAuthConfig("http://openfaas.test17", EncodeAuth("admin", "pass"), BasicAuthType); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := GetDefaultGateway()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "https://openfaas.test17" {
+		t.Errorf("want the default gateway to be kept, got %q", got)
+	}
+}
@@ -14,17 +14,24 @@ import (
 
 // Command run a system command
 func Command(tempPath string, builder []string) {
+	if err := Run(tempPath, builder); err != nil {
+		errString := fmt.Sprintf("ERROR - Could not execute command: %s", builder)
+		log.Fatalf(aec.RedF.Apply(errString))
+	}
+}
+
+// Run runs a system command like Command, but gives an error when it fails
+// rather than exiting
+func Run(tempPath string, builder []string) error {
 	targetCmd := osexec.Command(builder[0], builder[1:]...)
 	targetCmd.Dir = tempPath
 	targetCmd.Stdout = os.Stdout
 	targetCmd.Stderr = os.Stderr
 
-	targetCmd.Start()
-	err := targetCmd.Wait()
-	if err != nil {
-		errString := fmt.Sprintf("ERROR - Could not execute command: %s", builder)
-		log.Fatalf(aec.RedF.Apply(errString))
+	if err := targetCmd.Start(); err != nil {
+		return err
 	}
+	return targetCmd.Wait()
 }
 
 // CommandWithOutput run a system command an return stdout
@@ -0,0 +1,62 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package flags
+
+import (
+	"fmt"
+	"time"
+)
+
+// SinceFlag implements the Value interface to accept either a relative duration
+// such as 10m or a RFC3339 timestamp as a flag
+type SinceFlag struct {
+	value     string
+	duration  time.Duration
+	timestamp time.Time
+}
+
+// Type implements pflag.Value
+func (s *SinceFlag) Type() string {
+	return "duration|timestamp"
+}
+
+// String implements Stringer
+func (s *SinceFlag) String() string {
+	if s == nil {
+		return ""
+	}
+	return s.value
+}
+
+// Set implements pflag.Value
+func (s *SinceFlag) Set(value string) error {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return fmt.Errorf("the duration must not be negative")
+		}
+		*s = SinceFlag{value: value, duration: d}
+		return nil
+	}
+
+	ts, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return fmt.Errorf("give a duration like 10m or a RFC3339 timestamp like 2019-01-01T00:00:00Z")
+	}
+	*s = SinceFlag{value: value, timestamp: ts}
+	return nil
+}
+
+// AsTime returns the timestamp, or now less the duration, and nil when
+// the flag was not set
+func (s SinceFlag) AsTime(now time.Time) *time.Time {
+	if !s.timestamp.IsZero() {
+		ts := s.timestamp
+		return &ts
+	}
+	if s.duration > 0 {
+		ts := now.Add(-1 * s.duration)
+		return &ts
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package flags
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSince(t *testing.T) {
+	now := time.Date(2019, time.January, 1, 1, 0, 0, 0, time.UTC)
+	fiveMinAgo := time.Date(2019, time.January, 1, 0, 55, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		value    string
+		expected *time.Time
+		wantErr  bool
+	}{
+		{"duration is relative to now", "5m", &fiveMinAgo, false},
+		{"rfc3339 timestamp parses", "2019-01-01T00:55:00Z", &fiveMinAgo, false},
+		{"zero duration is unset", "0s", nil, false},
+		{"negative duration is an error", "-5m", nil, true},
+		{"invalid value is an error", "yesterday", nil, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var since SinceFlag
+			err := since.Set(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+
+			got := since.AsTime(now)
+			if (got == nil) != (tc.expected == nil) || (got != nil && !got.Equal(*tc.expected)) {
+				t.Errorf("expected time %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -31,6 +31,13 @@ type ClientAuth interface {
 	Set(req *http.Request) error
 }
 
+// RefreshAuth is a ClientAuth which can renew its credentials, a request
+// which the gateway answers with 401 is sent once more after a refresh
+type RefreshAuth interface {
+	ClientAuth
+	Refresh() error
+}
+
 //NewClient initializes a new API client
 func NewClient(auth ClientAuth, gatewayURL string, transport http.RoundTripper, timeout *time.Duration) *Client {
 	gatewayURL = strings.TrimRight(gatewayURL, "/")
@@ -99,7 +106,20 @@ func (c *Client) doRequest(ctx context.Context, req *http.Request) (*http.Respon
 		}
 		fmt.Println(string(dump))
 	}
+
+	if err := waitRateLimit(ctx); err != nil {
+		return nil, err
+	}
 	resp, err := c.httpClient.Do(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		if retry := c.refreshRequest(req); retry != nil {
+			resp.Body.Close()
+			if err := waitRateLimit(ctx); err != nil {
+				return nil, err
+			}
+			resp, err = c.httpClient.Do(retry)
+		}
+	}
 
 	if err != nil {
 		select {
@@ -112,6 +132,37 @@ func (c *Client) doRequest(ctx context.Context, req *http.Request) (*http.Respon
 	return resp, err
 }
 
+// refreshRequest renews the credentials of the client and gives a copy of
+// the request with them, or nil when they can't be renewed or the body of
+// the request can't be sent again
+func (c *Client) refreshRequest(req *http.Request) *http.Request {
+	auth, ok := c.ClientAuth.(RefreshAuth)
+	if !ok || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return nil
+	}
+
+	if err := auth.Refresh(); err != nil {
+		return nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil
+		}
+		retry.Body = body
+	}
+
+	auth.Set(retry)
+	return retry
+}
+
+//newConnectError gives the error for a request which got no response from the gateway
+func (c *Client) newConnectError(err error) error {
+	return &connectError{gateway: c.GatewayURL.String(), err: err, timeout: c.httpClient.Timeout}
+}
+
 func addQueryParams(u string, params map[string]string) (string, error) {
 	parsedURL, err := url.Parse(u)
 	if err != nil {
@@ -1,7 +1,12 @@
 package proxy
 
 import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func Test_NewClient(t *testing.T) {
@@ -117,3 +122,92 @@ func Test_addQueryParams(t *testing.T) {
 		}
 	}
 }
+
+// slowServer gives a server which doesn't respond until release is closed
+func slowServer() (*httptest.Server, chan struct{}) {
+	release := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	return s, release
+}
+
+func Test_doRequest_Timeout(t *testing.T) {
+	s, release := slowServer()
+	defer s.Close()
+	defer close(release)
+
+	timeout := 50 * time.Millisecond
+	client := NewClient(NewTestAuth(nil), s.URL, nil, &timeout)
+
+	_, err := client.ListFunctions(context.Background(), "")
+	if err == nil || err.Error() != "gateway did not respond within 50ms" {
+		t.Errorf("want a timeout error, got %v", err)
+	}
+
+	if IsConnectionError(err) == false {
+		t.Errorf("want a timeout to be a connection error")
+	}
+}
+
+// refreshingAuth is a RefreshAuth which gives token "new" once refreshed
+type refreshingAuth struct {
+	token     string
+	refreshes int
+}
+
+func (a *refreshingAuth) Set(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+func (a *refreshingAuth) Refresh() error {
+	a.refreshes++
+	a.token = "new"
+	return nil
+}
+
+func Test_doRequest_RefreshesOnUnauthorized(t *testing.T) {
+	var bodies []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	auth := &refreshingAuth{token: "expired"}
+	client := NewClient(auth, s.URL, nil, &defaultCommandTimeout)
+
+	statusCode := client.DeployFunction(context.Background(), &DeployFunctionSpec{FunctionName: "figlet", Image: "functions/figlet"})
+	if statusCode != http.StatusAccepted {
+		t.Errorf("want status %d after the refresh, got %d", http.StatusAccepted, statusCode)
+	}
+	if auth.refreshes != 1 {
+		t.Errorf("want one refresh, got %d", auth.refreshes)
+	}
+	if len(bodies) != 2 || bodies[0] != bodies[1] || len(bodies[1]) == 0 {
+		t.Errorf("want the body sent again with the refreshed token, got %q", bodies)
+	}
+}
+
+func Test_doRequest_UnauthorizedWithoutRefresh(t *testing.T) {
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer s.Close()
+
+	client := NewClient(NewTestAuth(nil), s.URL, nil, &defaultCommandTimeout)
+	if _, err := client.ListFunctions(context.Background(), ""); err == nil {
+		t.Error("want an error for 401")
+	}
+	if requests != 1 {
+		t.Errorf("want the request sent once when the auth can't be refreshed, got %d", requests)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// GzipBody compresses a request body, which is to be sent with the
+// Content-Encoding: gzip header
+func GzipBody(body []byte) ([]byte, error) {
+	var out bytes.Buffer
+	writer := gzip.NewWriter(&out)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// decodeBody gives the body of the response, decompressed when it was sent
+// with Content-Encoding: gzip. The transport only decompresses the body
+// when it asked for gzip itself, not when Accept-Encoding was given with
+// --header or the function compressed it anyway.
+func decodeBody(res *http.Response) (io.Reader, error) {
+	encoding := strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding")))
+	if encoding != "gzip" && encoding != "x-gzip" {
+		return res.Body, nil
+	}
+	return gzip.NewReader(res.Body)
+}
@@ -0,0 +1,86 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// gzipEchoServer decompresses the request when it is sent with gzip and
+// echoes it back compressed with gzip
+func gzipEchoServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("want a gzip request, got %s", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = reader
+		}
+		payload, _ := ioutil.ReadAll(body)
+
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		writer.Write(payload)
+		writer.Close()
+	}))
+}
+
+func Test_InvokeFunction_GzipRequestAndResponse(t *testing.T) {
+	s := gzipEchoServer(t)
+	defer s.Close()
+
+	bytesIn, err := GzipBody([]byte("hello world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Accept-Encoding is given so that the transport leaves the response
+	// compressed, as with --header
+	headers := []string{"Content-Encoding=gzip", "Accept-Encoding=gzip"}
+	out, err := InvokeFunction(s.URL, "echo", &bytesIn, "text/plain", nil, headers, false, http.MethodPost, tlsNoVerify, "")
+	if err != nil {
+		t.Fatalf("Error returned: %s", err)
+	}
+
+	if string(*out) != "hello world" {
+		t.Errorf("want the decompressed echo, got %q", string(*out))
+	}
+}
+
+func Test_InvokeFunction_GzipResponseWithoutAcceptEncoding(t *testing.T) {
+	s := gzipEchoServer(t)
+	defer s.Close()
+
+	bytesIn := []byte("hello world")
+	out, err := InvokeFunction(s.URL, "echo", &bytesIn, "text/plain", nil, nil, false, http.MethodPost, tlsNoVerify, "")
+	if err != nil {
+		t.Fatalf("Error returned: %s", err)
+	}
+
+	if string(*out) != "hello world" {
+		t.Errorf("want the decompressed echo, got %q", string(*out))
+	}
+}
+
+func Test_InvokeFunction_InvalidGzipResponse(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte("not gzip"))
+	}))
+	defer s.Close()
+
+	bytesIn := []byte("")
+	headers := []string{"Accept-Encoding=gzip"}
+	if _, err := InvokeFunction(s.URL, "echo", &bytesIn, "text/plain", nil, headers, false, http.MethodPost, tlsNoVerify, ""); err == nil {
+		t.Errorf("want an error for a response which is not gzip")
+	}
+}
@@ -37,7 +37,7 @@ func (c *Client) DeleteFunction(ctx context.Context, functionName string, namesp
 
 	if delErr != nil {
 		fmt.Printf("Error removing existing function: %s, gateway=%s, functionName=%s\n", delErr.Error(), c.GatewayURL.String(), functionName)
-		return delErr
+		return c.newConnectError(delErr)
 	}
 
 	if delRes.Body != nil {
@@ -48,16 +48,16 @@ func (c *Client) DeleteFunction(ctx context.Context, functionName string, namesp
 	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
 		fmt.Println("Removing old function.")
 	case http.StatusNotFound:
-		err = fmt.Errorf("No existing function to remove")
+		err = newHTTPError(http.StatusNotFound, "No existing function to remove")
 	case http.StatusUnauthorized:
-		err = fmt.Errorf("unauthorized access, run \"faas-cli login\" to setup authentication for this server")
+		err = unauthorizedError()
 	default:
 		var bodyReadErr error
 		bytesOut, bodyReadErr := ioutil.ReadAll(delRes.Body)
 		if bodyReadErr != nil {
 			err = bodyReadErr
 		} else {
-			err = fmt.Errorf("Server returned unexpected status code %d %s", delRes.StatusCode, string(bytesOut))
+			err = newHTTPError(delRes.StatusCode, "Server returned unexpected status code %d %s", delRes.StatusCode, string(bytesOut))
 		}
 	}
 
@@ -60,7 +60,17 @@ func generateFuncStr(spec *DeployFunctionSpec) string {
 // DeployFunction first tries to deploy a function and if it exists will then attempt
 // a rolling update. Warnings are suppressed for the second API call (if required.)
 func (c *Client) DeployFunction(context context.Context, spec *DeployFunctionSpec) int {
+	statusCode, deployOutput := c.DeployFunctionOutput(context, spec)
+	fmt.Print(deployOutput)
+	return statusCode
+}
+
+// DeployFunctionOutput deploys the function in the same way as DeployFunction, but
+// returns the output instead of printing it so that concurrent deploys can be logged
+// without being interleaved.
+func (c *Client) DeployFunctionOutput(context context.Context, spec *DeployFunctionSpec) (int, string) {
 
+	var output string
 	rollingUpdateInfo := fmt.Sprintf("Function %s already exists, attempting rolling-update.", spec.FunctionName)
 	statusCode, deployOutput := c.deploy(context, spec, spec.Update)
 
@@ -69,27 +79,22 @@ func (c *Client) DeployFunction(context context.Context, spec *DeployFunctionSpe
 
 		statusCode, deployOutput = c.deploy(context, spec, false)
 	} else if statusCode == http.StatusOK {
-		fmt.Println(rollingUpdateInfo)
+		output += fmt.Sprintln(rollingUpdateInfo)
 	}
-	fmt.Println()
-	fmt.Println(deployOutput)
-	return statusCode
+	output += fmt.Sprintln()
+	output += fmt.Sprintln(deployOutput)
+	return statusCode, output
 }
 
-// deploy a function to an OpenFaaS gateway over REST
-func (c *Client) deploy(context context.Context, spec *DeployFunctionSpec, update bool) (int, string) {
-
-	var deployOutput string
+// GenerateFunctionDeployment builds the request sent to the gateway for the spec,
+// it is also used to print the request for a dry-run.
+func GenerateFunctionDeployment(spec *DeployFunctionSpec) types.FunctionDeployment {
 	// Need to alter Gateway to allow nil/empty string as fprocess, to avoid this repetition.
 	var fprocessTemplate string
 	if len(spec.FProcess) > 0 {
 		fprocessTemplate = spec.FProcess
 	}
 
-	if spec.Replace {
-		c.DeleteFunction(context, spec.FunctionName, spec.Namespace)
-	}
-
 	req := types.FunctionDeployment{
 		EnvProcess:             fprocessTemplate,
 		Image:                  spec.Image,
@@ -134,6 +139,19 @@ func (c *Client) deploy(context context.Context, spec *DeployFunctionSpec, updat
 		req.Requests = nil
 	}
 
+	return req
+}
+
+// deploy a function to an OpenFaaS gateway over REST
+func (c *Client) deploy(context context.Context, spec *DeployFunctionSpec, update bool) (int, string) {
+
+	var deployOutput string
+	if spec.Replace {
+		c.DeleteFunction(context, spec.FunctionName, spec.Namespace)
+	}
+
+	req := GenerateFunctionDeployment(spec)
+
 	reqBytes, _ := json.Marshal(&req)
 	reader := bytes.NewReader(reqBytes)
 	var request *http.Request
@@ -155,6 +173,10 @@ func (c *Client) deploy(context context.Context, spec *DeployFunctionSpec, updat
 	res, err := c.doRequest(context, request)
 
 	if err != nil {
+		if isTimeout(err) {
+			deployOutput += fmt.Sprintln(c.newConnectError(err))
+			return http.StatusInternalServerError, deployOutput
+		}
 		deployOutput += fmt.Sprintln("Is OpenFaaS deployed? Do you need to specify the --gateway flag?")
 		deployOutput += fmt.Sprintln(err)
 		return http.StatusInternalServerError, deployOutput
@@ -171,7 +193,7 @@ func (c *Client) deploy(context context.Context, spec *DeployFunctionSpec, updat
 		deployedURL := fmt.Sprintf("URL: %s/function/%s", c.GatewayURL.String(), generateFuncStr(spec))
 		deployOutput += fmt.Sprintln(deployedURL)
 	case http.StatusUnauthorized:
-		deployOutput += fmt.Sprintln("unauthorized access, run \"faas-cli login\" to setup authentication for this server")
+		deployOutput += fmt.Sprintln(unauthorizedMessage)
 
 	default:
 		bytesOut, err := ioutil.ReadAll(res.Body)
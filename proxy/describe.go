@@ -13,10 +13,45 @@ import (
 	types "github.com/openfaas/faas-provider/types"
 )
 
+// FunctionStatus is the status of a function with the fields which are
+// reported by providers newer than the types vendored here
+type FunctionStatus struct {
+	types.FunctionStatus
+
+	Constraints            []string                 `json:"constraints,omitempty"`
+	EnvVars                map[string]string        `json:"envVars,omitempty"`
+	Secrets                []string                 `json:"secrets,omitempty"`
+	Limits                 *types.FunctionResources `json:"limits,omitempty"`
+	Requests               *types.FunctionResources `json:"requests,omitempty"`
+	ReadOnlyRootFilesystem bool                     `json:"readOnlyRootFilesystem,omitempty"`
+
+	// reportsSpec is true when the provider returned any of the fields above
+	reportsSpec bool
+}
+
+// specFields are the keys of the fields which the vendored types don't have
+var specFields = []string{"constraints", "envVars", "secrets", "limits", "requests", "readOnlyRootFilesystem"}
+
+// ReportsSpec is true when the provider reported any of the fields which the
+// vendored types don't have, so that the function can be redeployed from its
+// status without losing its environment, secrets, constraints or resources.
+// These are left out when empty, so a provider which reports none of them is
+// taken not to report them at all.
+func (s FunctionStatus) ReportsSpec() bool {
+	return s.reportsSpec
+}
+
 //GetFunctionInfo get an OpenFaaS function information
 func (c *Client) GetFunctionInfo(ctx context.Context, functionName string, namespace string) (types.FunctionStatus, error) {
+	status, err := c.GetFunctionStatus(ctx, functionName, namespace)
+	return status.FunctionStatus, err
+}
+
+// GetFunctionStatus gets the status of a function, with its constraints,
+// environment, secrets and resources when the provider reports them
+func (c *Client) GetFunctionStatus(ctx context.Context, functionName string, namespace string) (FunctionStatus, error) {
 	var (
-		result types.FunctionStatus
+		result FunctionStatus
 		err    error
 	)
 
@@ -35,7 +70,7 @@ func (c *Client) GetFunctionInfo(ctx context.Context, functionName string, names
 
 	res, err := c.doRequest(ctx, getRequest)
 	if err != nil {
-		return result, fmt.Errorf("cannot connect to OpenFaaS on URL: %s", c.GatewayURL.String())
+		return result, c.newConnectError(err)
 
 	}
 
@@ -54,14 +89,23 @@ func (c *Client) GetFunctionInfo(ctx context.Context, functionName string, names
 		if jsonErr != nil {
 			return result, fmt.Errorf("cannot parse result from OpenFaaS on URL: %s\n%s", c.GatewayURL.String(), jsonErr.Error())
 		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(bytesOut, &fields); err == nil {
+			for _, field := range specFields {
+				if _, ok := fields[field]; ok {
+					result.reportsSpec = true
+				}
+			}
+		}
 	case http.StatusUnauthorized:
-		return result, fmt.Errorf("unauthorized access, run \"faas-cli login\" to setup authentication for this server")
+		return result, unauthorizedError()
 	case http.StatusNotFound:
-		return result, fmt.Errorf("No such function: %s", functionName)
+		return result, newHTTPError(http.StatusNotFound, "No such function: %s", functionName)
 	default:
 		bytesOut, err := ioutil.ReadAll(res.Body)
 		if err == nil {
-			return result, fmt.Errorf("server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
+			return result, newHTTPError(res.StatusCode, "server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
 		}
 	}
 	return result, nil
@@ -0,0 +1,57 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const unauthorizedMessage = "unauthorized access, run \"faas-cli login\" to setup authentication for this server"
+
+// HTTPError is returned when the gateway replies with a status which is not
+// a success, so that the failure can be told apart with StatusCode
+type HTTPError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *HTTPError) Error() string {
+	return e.Message
+}
+
+// newHTTPError formats the message for a status returned by the gateway
+func newHTTPError(statusCode int, format string, a ...interface{}) error {
+	return &HTTPError{StatusCode: statusCode, Message: fmt.Sprintf(format, a...)}
+}
+
+func unauthorizedError() error {
+	return &HTTPError{StatusCode: http.StatusUnauthorized, Message: unauthorizedMessage}
+}
+
+// StatusCode gives the status returned by the gateway for err, or 0 when the
+// gateway did not reply with a status
+func StatusCode(err error) int {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode
+	}
+	return 0
+}
+
+// ResponseBody gives the body of the response to an invoke which returned
+// a status that is not a success, or "" for any other error
+func ResponseBody(err error) string {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.body
+	}
+	return ""
+}
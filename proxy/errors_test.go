@@ -0,0 +1,39 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_StatusCode(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			}))
+			defer s.Close()
+
+			client := NewClient(NewTestAuth(nil), s.URL, nil, &defaultCommandTimeout)
+			_, err := client.GetFunctionInfo(context.Background(), "figlet", "")
+			if got := StatusCode(err); got != status {
+				t.Errorf("want status %d from describe, got %d: %v", status, got, err)
+			}
+
+			bytesIn := []byte("test data")
+			_, err = InvokeFunction(s.URL, "figlet", &bytesIn, "text/plain", []string{}, []string{}, false, http.MethodPost, tlsNoVerify, "")
+			if got := StatusCode(err); got != status {
+				t.Errorf("want status %d from invoke, got %d: %v", status, got, err)
+			}
+		})
+	}
+
+	if got := StatusCode(fmt.Errorf("no status")); got != 0 {
+		t.Errorf("want no status, got %d", got)
+	}
+}
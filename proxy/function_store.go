@@ -50,7 +50,7 @@ func FunctionStoreList(store string) ([]v2.StoreFunction, error) {
 	default:
 		bytesOut, err := ioutil.ReadAll(res.Body)
 		if err == nil {
-			return nil, fmt.Errorf("server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
+			return nil, newHTTPError(res.StatusCode, "server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
 		}
 	}
 	return storeResults.Functions, nil
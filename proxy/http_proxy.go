@@ -0,0 +1,49 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// httpProxyURL is the proxy set by SetHTTPProxy
+var httpProxyURL *url.URL
+
+// SetHTTPProxy sets the proxy used for every request the CLI makes instead of
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY, an empty URL goes back to those. It
+// is also set on http.DefaultTransport for clients which don't build their
+// own transport.
+func SetHTTPProxy(rawURL string) error {
+	httpProxyURL = nil
+
+	if len(rawURL) > 0 {
+		proxyURL, err := url.Parse(rawURL)
+		if err != nil || len(proxyURL.Host) == 0 {
+			return fmt.Errorf("invalid proxy URL %s, it must be given like http://proxy:3128", rawURL)
+		}
+
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("invalid proxy URL %s, the scheme must be http, https or socks5", rawURL)
+		}
+		httpProxyURL = proxyURL
+	}
+
+	if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport.Proxy = HTTPProxy
+	}
+	return nil
+}
+
+// HTTPProxy gives the proxy for a request, which is the one set by
+// SetHTTPProxy or else the one from HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+func HTTPProxy(req *http.Request) (*url.URL, error) {
+	if httpProxyURL != nil {
+		return httpProxyURL, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
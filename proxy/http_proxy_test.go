@@ -0,0 +1,63 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_SetHTTPProxy_Invalid(t *testing.T) {
+	defer SetHTTPProxy("")
+
+	cases := []struct {
+		proxyURL string
+		want     string
+	}{
+		{proxyURL: "proxy:3128", want: "invalid proxy URL proxy:3128"},
+		{proxyURL: "ftp://proxy:3128", want: "invalid proxy URL ftp://proxy:3128, the scheme must be http, https or socks5"},
+	}
+
+	for _, c := range cases {
+		if err := SetHTTPProxy(c.proxyURL); err == nil || !strings.HasPrefix(err.Error(), c.want) {
+			t.Errorf("want error starting %q, got %v", c.want, err)
+		}
+	}
+}
+
+func Test_MakeHTTPClient_UsesProxy(t *testing.T) {
+	var proxied []string
+	stubProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer stubProxy.Close()
+
+	if err := SetHTTPProxy(stubProxy.URL); err != nil {
+		t.Fatal(err)
+	}
+	defer SetHTTPProxy("")
+
+	timeout := 5 * time.Second
+	client := MakeHTTPClient(&timeout, false)
+	res, err := client.Get("http://gateway.example.com:8080/system/functions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	res, err = http.Get("http://store.example.com/store.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	want := []string{"http://gateway.example.com:8080/system/functions", "http://store.example.com/store.json"}
+	if strings.Join(proxied, " ") != strings.Join(want, " ") {
+		t.Errorf("want requests %v through the proxy, got %v", want, proxied)
+	}
+}
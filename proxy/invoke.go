@@ -4,35 +4,69 @@
 package proxy
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
 	"os"
+	"syscall"
 
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
 	"strings"
 	"time"
 )
 
-// InvokeFunction a function
+// invokeTimeout is the timeout for each invoke, there is none when it is 0
+var invokeTimeout time.Duration
+
+// invokeStatus is given the status code of each invoke, see SetInvokeStatus
+var invokeStatus func(statusCode int)
+
+// SetInvokeStatus calls f with the status code of the response to each
+// invoke, so that it can be checked, nil stops it being called
+func SetInvokeStatus(f func(statusCode int)) {
+	invokeStatus = f
+}
+
+// SetInvokeTimeout sets the timeout for each invoke, from connecting to the
+// last byte of the response, 0 lets a function run for as long as it needs
+func SetInvokeTimeout(timeout time.Duration) {
+	invokeTimeout = timeout
+}
+
+// InvokeFunction a function, for an async call the X-Call-Id header is returned
 func InvokeFunction(gateway string, name string, bytesIn *[]byte, contentType string, query []string, headers []string, async bool, httpMethod string, tlsInsecure bool, namespace string) (*[]byte, error) {
-	var resBytes []byte
+	var out bytes.Buffer
+	if err := InvokeFunctionStream(&out, false, gateway, name, bytesIn, contentType, query, headers, async, httpMethod, tlsInsecure, namespace); err != nil {
+		return nil, err
+	}
 
+	resBytes := out.Bytes()
+	return &resBytes, nil
+}
+
+// InvokeFunctionStream invokes a function and writes the result to out. Chunked
+// and text/event-stream responses are copied to out as they arrive, other
+// responses are read in full first unless stream is set.
+func InvokeFunctionStream(out io.Writer, stream bool, gateway string, name string, bytesIn *[]byte, contentType string, query []string, headers []string, async bool, httpMethod string, tlsInsecure bool, namespace string) error {
 	gateway = strings.TrimRight(gateway, "/")
 
 	reader := bytes.NewReader(*bytesIn)
 
-	var disableFunctionTimeout *time.Duration
-	client := MakeHTTPClient(disableFunctionTimeout, tlsInsecure)
-
-	qs, qsErr := buildQueryString(query)
-	if qsErr != nil {
-		return nil, qsErr
+	var timeout *time.Duration
+	if invokeTimeout > 0 {
+		timeout = &invokeTimeout
 	}
+	client := MakeHTTPClient(timeout, tlsInsecure)
 
 	headerMap, headerErr := parseHeaders(headers)
 	if headerErr != nil {
-		return nil, headerErr
+		return headerErr
 	}
 
 	functionEndpoint := "/function/"
@@ -42,20 +76,24 @@ func InvokeFunction(gateway string, name string, bytesIn *[]byte, contentType st
 
 	httpMethodErr := validateHTTPMethod(httpMethod)
 	if httpMethodErr != nil {
-		return nil, httpMethodErr
+		return httpMethodErr
 	}
 
-	gatewayURL := gateway + functionEndpoint + name
+	functionPath := functionEndpoint + name
 	if len(namespace) > 0 {
-		gatewayURL += "." + namespace
+		functionPath += "." + namespace
+	}
+
+	gatewayURL, urlErr := makeInvokeURL(gateway, functionPath, query)
+	if urlErr != nil {
+		return urlErr
 	}
-	gatewayURL += qs
 
 	req, err := http.NewRequest(httpMethod, gatewayURL, reader)
 	if err != nil {
 		fmt.Println()
 		fmt.Println(err)
-		return nil, fmt.Errorf("cannot connect to OpenFaaS on URL: %s", gateway)
+		return fmt.Errorf("cannot connect to OpenFaaS on URL: %s", gateway)
 	}
 
 	req.Header.Add("Content-Type", contentType)
@@ -68,57 +106,200 @@ func InvokeFunction(gateway string, name string, bytesIn *[]byte, contentType st
 	// to functions. Functions should implement their own auth.
 	// SetAuth(req, gateway)
 
+	var timing *invokeTiming
+	if invokeTimings != nil {
+		timing = newInvokeTiming()
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), timing.trace()))
+	}
+
+	if err := waitRateLimit(req.Context()); err != nil {
+		return err
+	}
 	res, err := client.Do(req)
 
 	if err != nil {
 		fmt.Println()
 		fmt.Println(err)
-		return nil, fmt.Errorf("cannot connect to OpenFaaS on URL: %s", gateway)
+		return &connectError{gateway: gateway, err: err, timeout: invokeTimeout}
 	}
 
 	if res.Body != nil {
 		defer res.Body.Close()
 	}
 
+	if invokeStatus != nil {
+		invokeStatus(res.StatusCode)
+	}
+
+	if timing != nil {
+		body := &countingReadCloser{ReadCloser: res.Body}
+		res.Body = body
+		defer func() { timing.write(invokeTimings, res.StatusCode, body.n) }()
+	}
+
 	switch res.StatusCode {
 	case http.StatusAccepted:
 		fmt.Fprintf(os.Stderr, "Function submitted asynchronously.\n")
+		// The call-id is the result of an async call so that it can be captured by scripts
+		if callID := res.Header.Get("X-Call-Id"); len(callID) > 0 {
+			fmt.Fprintln(out, callID)
+		}
 	case http.StatusOK:
-		var readErr error
-		resBytes, readErr = ioutil.ReadAll(res.Body)
-		if readErr != nil {
-			return nil, fmt.Errorf("cannot read result from OpenFaaS on URL: %s %s", gateway, readErr)
+		if readErr := copyResponse(out, res, stream); readErr != nil {
+			return fmt.Errorf("cannot read result from OpenFaaS on URL: %s %s", gateway, readErr)
 		}
 	case http.StatusUnauthorized:
-		return nil, fmt.Errorf("unauthorized access, run \"faas-cli login\" to setup authentication for this server")
+		return unauthorizedError()
 	default:
 		bytesOut, err := ioutil.ReadAll(res.Body)
 		if err == nil {
-			return nil, fmt.Errorf("server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
+			return &statusError{statusCode: res.StatusCode, body: string(bytesOut)}
 		}
 	}
 
-	return &resBytes, nil
+	return nil
 }
 
-func buildQueryString(query []string) (string, error) {
-	qs := ""
+// copyResponse copies the body to out, flushing after each line for
+// text/event-stream so that events are seen as they are sent. A gzip body
+// is decompressed.
+func copyResponse(out io.Writer, res *http.Response, stream bool) error {
+	body, err := decodeBody(res)
+	if err != nil {
+		return err
+	}
 
-	if len(query) > 0 {
-		qs = "?"
-		for _, queryValue := range query {
-			qs = qs + queryValue + "&"
-			if strings.Contains(queryValue, "=") == false {
-				return "", fmt.Errorf("the --query flags must take the form of key=value (= not found)")
+	if strings.HasPrefix(res.Header.Get("Content-Type"), "text/event-stream") {
+		reader := bufio.NewReader(body)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if _, writeErr := out.Write(line); writeErr != nil {
+					return writeErr
+				}
+				flush(out)
+			}
+			if err == io.EOF {
+				return nil
 			}
-			if strings.HasSuffix(queryValue, "=") {
-				return "", fmt.Errorf("the --query flag must take the form of: key=value (empty value given, or value ends in =)")
+			if err != nil {
+				return err
 			}
 		}
-		qs = strings.TrimRight(qs, "&")
 	}
 
-	return qs, nil
+	if stream || res.ContentLength < 0 {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := body.Read(buf)
+			if n > 0 {
+				if _, writeErr := out.Write(buf[:n]); writeErr != nil {
+					return writeErr
+				}
+				flush(out)
+			}
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	resBytes, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(resBytes)
+	return err
+}
+
+// flush writes out any buffered data when out supports it
+func flush(out io.Writer) {
+	if flusher, ok := out.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+}
+
+// connectError is returned when the gateway can't be reached, or did not
+// respond within the timeout of the client
+type connectError struct {
+	gateway string
+	err     error
+	timeout time.Duration
+}
+
+func (e *connectError) Error() string {
+	if e.timeout > 0 && isTimeout(e.err) {
+		return fmt.Sprintf("gateway did not respond within %s", e.timeout)
+	}
+	return fmt.Sprintf("cannot connect to OpenFaaS on URL: %s", e.gateway)
+}
+
+// statusError is returned for an unexpected status code
+type statusError struct {
+	statusCode int
+	body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("server returned unexpected status code: %d - %s", e.statusCode, e.body)
+}
+
+// IsConnectionError reports whether the gateway could not be reached
+func IsConnectionError(err error) bool {
+	var connectErr *connectError
+	return errors.As(err, &connectErr)
+}
+
+// isTimeout reports whether a request failed because it timed out
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsRetryable reports whether an error from InvokeFunction is likely to be
+// transient, i.e. a refused connection or a 429 or 503 status code.
+func IsRetryable(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode == http.StatusTooManyRequests ||
+			statusErr.statusCode == http.StatusServiceUnavailable
+	}
+
+	var connectErr *connectError
+	if errors.As(err, &connectErr) {
+		return errors.Is(connectErr.err, syscall.ECONNREFUSED)
+	}
+	return false
+}
+
+// makeInvokeURL appends the function path to the gateway and merges the
+// URL-encoded query values with any query already given on the gateway URL.
+func makeInvokeURL(gateway string, functionPath string, query []string) (string, error) {
+	invokeURL, err := url.Parse(gateway)
+	if err != nil {
+		return "", fmt.Errorf("cannot connect to OpenFaaS on URL: %s", gateway)
+	}
+
+	invokeURL.Path = strings.TrimRight(invokeURL.Path, "/") + functionPath
+
+	values := invokeURL.Query()
+	for _, queryValue := range query {
+		if strings.Contains(queryValue, "=") == false {
+			return "", fmt.Errorf("the --query flags must take the form of key=value (= not found)")
+		}
+		if strings.HasSuffix(queryValue, "=") {
+			return "", fmt.Errorf("the --query flag must take the form of: key=value (empty value given, or value ends in =)")
+		}
+
+		parts := strings.SplitN(queryValue, "=", 2)
+		values.Add(parts[0], parts[1])
+	}
+	invokeURL.RawQuery = values.Encode()
+
+	return invokeURL.String(), nil
 }
 
 // parseHeaders parses header values from command
@@ -6,10 +6,12 @@ package proxy
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 
 	"testing"
 
 	"regexp"
+	"time"
 
 	"github.com/openfaas/faas-cli/test"
 )
@@ -60,6 +62,43 @@ func Test_InvokeFunction_Async(t *testing.T) {
 	}
 }
 
+func Test_InvokeFunction_AsyncReturnsCallID(t *testing.T) {
+	var callbackURL string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/async-function/function" {
+			t.Errorf("want path /async-function/function, got %s", r.URL.Path)
+		}
+		callbackURL = r.Header.Get("X-Callback-Url")
+		w.Header().Set("X-Call-Id", "c8e4f8a1-6b1f-4d1e-9a0c-2f9c4b7e1d3a")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	bytesIn := []byte("test data")
+	response, err := InvokeFunction(
+		s.URL,
+		"function",
+		&bytesIn,
+		"text/plain",
+		[]string{},
+		[]string{"X-Callback-Url=http://gateway:8080/function/send2slack"},
+		true,
+		http.MethodPost,
+		tlsNoVerify,
+		"",
+	)
+
+	if err != nil {
+		t.Fatalf("Error returned: %s", err)
+	}
+	if callbackURL != "http://gateway:8080/function/send2slack" {
+		t.Errorf("want X-Callback-Url passed to the gateway, got %q", callbackURL)
+	}
+	if string(*response) != "c8e4f8a1-6b1f-4d1e-9a0c-2f9c4b7e1d3a\n" {
+		t.Errorf("want call-id returned, got %q", string(*response))
+	}
+}
+
 func Test_InvokeFunction_Not2xx(t *testing.T) {
 	s := test.MockHttpServerStatus(t, http.StatusNotFound)
 	defer s.Close()
@@ -166,3 +205,190 @@ func compareMaps(mapA map[string]string, mapB map[string]string) bool {
 	}
 	return true
 }
+
+func Test_IsRetryable(t *testing.T) {
+	cases := []struct {
+		statusCode int
+		want       bool
+	}{
+		{statusCode: http.StatusServiceUnavailable, want: true},
+		{statusCode: http.StatusTooManyRequests, want: true},
+		{statusCode: http.StatusNotFound, want: false},
+		{statusCode: http.StatusInternalServerError, want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(http.StatusText(c.statusCode), func(t *testing.T) {
+			s := test.MockHttpServerStatus(t, c.statusCode)
+			defer s.Close()
+
+			bytesIn := []byte("test data")
+			_, err := InvokeFunction(s.URL, "function", &bytesIn, "text/plain", []string{}, []string{}, false, http.MethodGet, tlsNoVerify, "")
+			if err == nil {
+				t.Fatalf("want error for status %d", c.statusCode)
+			}
+			if got := IsRetryable(err); got != c.want {
+				t.Errorf("want IsRetryable %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
+func Test_IsRetryable_ConnectionRefused(t *testing.T) {
+	s := httptest.NewServer(http.NotFoundHandler())
+	url := s.URL
+	s.Close()
+
+	bytesIn := []byte("test data")
+	_, err := InvokeFunction(url, "function", &bytesIn, "text/plain", []string{}, []string{}, false, http.MethodGet, tlsNoVerify, "")
+	if err == nil {
+		t.Fatalf("want error for a closed server")
+	}
+	if !IsRetryable(err) {
+		t.Errorf("want a refused connection to be retryable, got %s", err)
+	}
+}
+
+func Test_InvokeFunction_Timeout(t *testing.T) {
+	s, release := slowServer()
+	defer s.Close()
+	defer close(release)
+
+	SetInvokeTimeout(50 * time.Millisecond)
+	defer SetInvokeTimeout(0)
+
+	bytesIn := []byte("test data")
+	_, err := InvokeFunction(s.URL, "function", &bytesIn, "text/plain", []string{}, []string{}, false, http.MethodGet, tlsNoVerify, "")
+	if err == nil || err.Error() != "gateway did not respond within 50ms" {
+		t.Errorf("want a timeout error, got %v", err)
+	}
+	if IsRetryable(err) {
+		t.Errorf("want a timeout not to be retried, as the function may have run")
+	}
+}
+
+// lineRecorder records each write and signals the first one
+type lineRecorder struct {
+	writes  []string
+	flushes int
+	first   chan struct{}
+}
+
+func (l *lineRecorder) Write(p []byte) (int, error) {
+	if len(l.writes) == 0 {
+		close(l.first)
+	}
+	l.writes = append(l.writes, string(p))
+	return len(p), nil
+}
+
+func (l *lineRecorder) Flush() error {
+	l.flushes++
+	return nil
+}
+
+func Test_InvokeFunctionStream_EventStreamWritesPerLine(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: one\n\ndata: two\n")
+	}))
+	defer s.Close()
+
+	out := &lineRecorder{first: make(chan struct{})}
+	bytesIn := []byte("")
+	err := InvokeFunctionStream(out, false, s.URL, "function", &bytesIn, "text/plain", nil, nil, false, http.MethodGet, tlsNoVerify, "")
+	if err != nil {
+		t.Fatalf("Error returned: %s", err)
+	}
+
+	want := []string{"data: one\n", "\n", "data: two\n"}
+	if len(out.writes) != len(want) {
+		t.Fatalf("want writes %q, got %q", want, out.writes)
+	}
+	for i := range want {
+		if out.writes[i] != want[i] {
+			t.Errorf("write %d: want %q, got %q", i, want[i], out.writes[i])
+		}
+	}
+	if out.flushes != len(want) {
+		t.Errorf("want a flush per line, got %d", out.flushes)
+	}
+}
+
+func Test_InvokeFunctionStream_CopiesChunksAsTheyArrive(t *testing.T) {
+	out := &lineRecorder{first: make(chan struct{})}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "started\n")
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-out.first:
+		case <-time.After(5 * time.Second):
+			t.Error("want the first chunk written before the response ends")
+		}
+		fmt.Fprint(w, "finished\n")
+	}))
+	defer s.Close()
+
+	bytesIn := []byte("")
+	err := InvokeFunctionStream(out, false, s.URL, "function", &bytesIn, "text/plain", nil, nil, false, http.MethodGet, tlsNoVerify, "")
+	if err != nil {
+		t.Fatalf("Error returned: %s", err)
+	}
+
+	got := ""
+	for _, write := range out.writes {
+		got += write
+	}
+	if got != "started\nfinished\n" {
+		t.Errorf("want full response, got %q", got)
+	}
+}
+
+func Test_makeInvokeURL(t *testing.T) {
+	cases := []struct {
+		name    string
+		gateway string
+		query   []string
+		want    string
+	}{
+		{
+			name:    "no query",
+			gateway: "http://127.0.0.1:8080",
+			want:    "http://127.0.0.1:8080/function/figlet",
+		},
+		{
+			name:    "reserved characters are encoded",
+			gateway: "http://127.0.0.1:8080",
+			query:   []string{"q=a&b=c", "path=/tmp/x y", "sum=1+1"},
+			want:    "http://127.0.0.1:8080/function/figlet?path=%2Ftmp%2Fx+y&q=a%26b%3Dc&sum=1%2B1",
+		},
+		{
+			name:    "merged with the gateway query",
+			gateway: "https://gw.example.com/openfaas/?tenant=a",
+			query:   []string{"repo=faas-cli", "tenant=b"},
+			want:    "https://gw.example.com/openfaas/function/figlet?repo=faas-cli&tenant=a&tenant=b",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := makeInvokeURL(c.gateway, "/function/figlet", c.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("want %s, got %s", c.want, got)
+			}
+		})
+	}
+}
+
+func Test_makeInvokeURL_InvalidQuery(t *testing.T) {
+	for _, query := range []string{"noequals", "empty="} {
+		if _, err := makeInvokeURL("http://127.0.0.1:8080", "/function/figlet", []string{query}); err == nil {
+			t.Errorf("want error for --query %s", query)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
+)
+
+// invokeTimings is where the timings of each invoke are written, they are
+// not recorded when it is nil
+var invokeTimings io.Writer
+
+// SetInvokeTimings writes the timings of each invoke to w, from the DNS
+// lookup to the last byte of the response, nil stops them being written
+func SetInvokeTimings(w io.Writer) {
+	invokeTimings = w
+}
+
+// invokeTiming records when each step of a request starts and ends
+type invokeTiming struct {
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	firstByte    time.Time
+	reused       bool
+}
+
+func newInvokeTiming() *invokeTiming {
+	return &invokeTiming{start: time.Now()}
+}
+
+func (t *invokeTiming) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.reused = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { t.dnsStart = time.Now() },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.dnsDone = time.Now() },
+		ConnectStart: func(network, addr string) {
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err == nil {
+				t.connectDone = time.Now()
+			}
+		},
+		TLSHandshakeStart:    func() { t.tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.tlsDone = time.Now() },
+		GotFirstResponseByte: func() { t.firstByte = time.Now() },
+	}
+}
+
+// write prints the timings along with the status and the size of the body
+func (t *invokeTiming) write(w io.Writer, statusCode int, size int64) {
+	total := time.Since(t.start)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "DNS lookup:          %s\n", formatStep(t.dnsStart, t.dnsDone))
+	fmt.Fprintf(&b, "TCP connect:         %s\n", formatStep(t.connectStart, t.connectDone))
+	fmt.Fprintf(&b, "TLS handshake:       %s\n", formatStep(t.tlsStart, t.tlsDone))
+	fmt.Fprintf(&b, "Time to first byte:  %s\n", formatStep(t.start, t.firstByte))
+	fmt.Fprintf(&b, "Total:               %s\n", roundDuration(total))
+	if t.reused {
+		fmt.Fprintf(&b, "Connection:          reused\n")
+	}
+	fmt.Fprintf(&b, "Status:              %d %s\n", statusCode, http.StatusText(statusCode))
+	fmt.Fprintf(&b, "Response size:       %d bytes\n", size)
+
+	io.WriteString(w, b.String())
+}
+
+// formatStep gives the time between start and done, or - when the step
+// didn't happen, such as the DNS lookup for an IP address
+func formatStep(start, done time.Time) string {
+	if start.IsZero() || done.IsZero() {
+		return "-"
+	}
+	return roundDuration(done.Sub(start)).String()
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(100 * time.Microsecond)
+}
+
+// countingReadCloser counts the bytes read from the body of a response
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,89 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func Test_InvokeFunctionStream_Timings(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer s.Close()
+
+	var timings bytes.Buffer
+	SetInvokeTimings(&timings)
+	defer SetInvokeTimings(nil)
+
+	var out bytes.Buffer
+	bytesIn := []byte("")
+	if err := InvokeFunctionStream(&out, false, s.URL, "echo", &bytesIn, "text/plain", nil, nil, false, http.MethodPost, tlsNoVerify, ""); err != nil {
+		t.Fatalf("Error returned: %s", err)
+	}
+
+	if out.String() != "hello world" {
+		t.Errorf("want the response unchanged, got %q", out.String())
+	}
+
+	want := []string{
+		`(?m)^DNS lookup:\s+-$`,
+		`(?m)^TCP connect:\s+[0-9.]+[µm]?s$`,
+		`(?m)^TLS handshake:\s+[0-9.]+[µm]?s$`,
+		`(?m)^Time to first byte:\s+[0-9.]+[µm]?s$`,
+		`(?m)^Total:\s+[0-9.]+[µm]?s$`,
+		`(?m)^Status:\s+200 OK$`,
+		`(?m)^Response size:\s+11 bytes$`,
+	}
+	for _, pattern := range want {
+		if !regexp.MustCompile(pattern).MatchString(timings.String()) {
+			t.Errorf("want the timings to match %q, got:\n%s", pattern, timings.String())
+		}
+	}
+}
+
+func Test_InvokeFunctionStream_TimingsForError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	}))
+	defer s.Close()
+
+	var timings bytes.Buffer
+	SetInvokeTimings(&timings)
+	defer SetInvokeTimings(nil)
+
+	var out bytes.Buffer
+	bytesIn := []byte("")
+	if err := InvokeFunctionStream(&out, false, s.URL, "echo", &bytesIn, "text/plain", nil, nil, false, http.MethodPost, tlsNoVerify, ""); err == nil {
+		t.Fatalf("want an error for the status code")
+	}
+
+	if !regexp.MustCompile(`(?m)^Status:\s+500 Internal Server Error$`).MatchString(timings.String()) {
+		t.Errorf("want the status in the timings, got:\n%s", timings.String())
+	}
+	if !regexp.MustCompile(`(?m)^TLS handshake:\s+-$`).MatchString(timings.String()) {
+		t.Errorf("want no TLS handshake for http, got:\n%s", timings.String())
+	}
+}
+
+func Test_InvokeFunctionStream_NoTimingsByDefault(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer s.Close()
+
+	var out bytes.Buffer
+	bytesIn := []byte("")
+	if err := InvokeFunctionStream(&out, false, s.URL, "echo", &bytesIn, "text/plain", nil, nil, false, http.MethodPost, tlsNoVerify, ""); err != nil {
+		t.Fatalf("Error returned: %s", err)
+	}
+	if invokeTimings != nil || out.String() != "hello world" {
+		t.Errorf("want no timings and the response, got %q", out.String())
+	}
+}
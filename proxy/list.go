@@ -41,7 +41,7 @@ func (c *Client) ListFunctions(ctx context.Context, namespace string) ([]types.F
 
 	res, err := c.doRequest(ctx, getRequest)
 	if err != nil {
-		return nil, fmt.Errorf("cannot connect to OpenFaaS on URL: %s", c.GatewayURL.String())
+		return nil, c.newConnectError(err)
 	}
 
 	if res.Body != nil {
@@ -60,11 +60,11 @@ func (c *Client) ListFunctions(ctx context.Context, namespace string) ([]types.F
 			return nil, fmt.Errorf("cannot parse result from OpenFaaS on URL: %s\n%s", c.GatewayURL.String(), jsonErr.Error())
 		}
 	case http.StatusUnauthorized:
-		return nil, fmt.Errorf("unauthorized access, run \"faas-cli login\" to setup authentication for this server")
+		return nil, unauthorizedError()
 	default:
 		bytesOut, err := ioutil.ReadAll(res.Body)
 		if err == nil {
-			return nil, fmt.Errorf("server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
+			return nil, newHTTPError(res.StatusCode, "server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
 		}
 	}
 	return results, nil
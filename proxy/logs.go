@@ -2,7 +2,6 @@ package proxy
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -27,7 +26,7 @@ func (c *Client) GetLogs(ctx context.Context, params logs.Request) (<-chan logs.
 
 	res, err := c.doRequest(ctx, logRequest)
 	if err != nil {
-		return nil, fmt.Errorf("cannot connect to OpenFaaS on URL: %s", c.GatewayURL.String())
+		return nil, c.newConnectError(err)
 	}
 
 	logStream := make(chan logs.Message, 1000)
@@ -42,18 +41,21 @@ func (c *Client) GetLogs(ctx context.Context, params logs.Request) (<-chan logs.
 				msg := logs.Message{}
 				err := decoder.Decode(&msg)
 				if err != nil {
-					log.Printf("cannot parse log results: %s\n", err.Error())
+					// the stream is closed when the request is cancelled
+					if ctx.Err() == nil {
+						log.Printf("cannot parse log results: %s\n", err.Error())
+					}
 					return
 				}
 				logStream <- msg
 			}
 		}()
 	case http.StatusUnauthorized:
-		return nil, fmt.Errorf("unauthorized access, run \"faas-cli login\" to setup authentication for this server")
+		return nil, unauthorizedError()
 	default:
 		bytesOut, err := ioutil.ReadAll(res.Body)
 		if err == nil {
-			return nil, fmt.Errorf("server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
+			return nil, &statusError{statusCode: res.StatusCode, body: string(bytesOut)}
 		}
 	}
 	return logStream, nil
@@ -84,14 +86,11 @@ func reqAsQueryValues(r logs.Request) url.Values {
 func makeStreamingHTTPClient(tlsInsecure bool) http.Client {
 	client := http.Client{}
 
-	if tlsInsecure {
+	if tlsConfig := TLSClientConfig(tlsInsecure); tlsConfig != nil {
 		tr := &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-		}
-
-		if tlsInsecure {
-			tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: tlsInsecure}
+			Proxy: HTTPProxy,
 		}
+		tr.TLSClientConfig = tlsConfig
 
 		client.Transport = tr
 	}
@@ -1,14 +1,21 @@
 package proxy
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"path"
 )
 
+// FunctionNamespace is the request to create a namespace
+type FunctionNamespace struct {
+	Name string `json:"name"`
+}
+
 // ListNamespaces lists available function namespaces
 func (c *Client) ListNamespaces(ctx context.Context) ([]string, error) {
 	var namespaces []string
@@ -24,7 +31,7 @@ func (c *Client) ListNamespaces(ctx context.Context) ([]string, error) {
 
 	res, err := c.doRequest(ctx, getRequest)
 	if err != nil {
-		return nil, fmt.Errorf("cannot connect to OpenFaaS on URL: %s", c.GatewayURL.String())
+		return nil, c.newConnectError(err)
 	}
 
 	if res.Body != nil {
@@ -43,12 +50,71 @@ func (c *Client) ListNamespaces(ctx context.Context) ([]string, error) {
 			return nil, fmt.Errorf("cannot parse namespaces from OpenFaaS on URL: %s\n%s", c.GatewayURL.String(), jsonErr.Error())
 		}
 	case http.StatusUnauthorized:
-		return nil, fmt.Errorf("unauthorized access, run \"faas-cli login\" to setup authentication for this server")
+		return nil, unauthorizedError()
 	default:
 		bytesOut, err := ioutil.ReadAll(res.Body)
 		if err == nil {
-			return nil, fmt.Errorf("server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
+			return nil, newHTTPError(res.StatusCode, "server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
 		}
 	}
 	return namespaces, nil
 }
+
+// CreateNamespace creates a function namespace
+func (c *Client) CreateNamespace(ctx context.Context, name string) error {
+	body, _ := json.Marshal(FunctionNamespace{Name: name})
+	req, err := c.newRequest(http.MethodPost, namespacePath, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("cannot connect to OpenFaaS on URL: %s", c.GatewayURL.String())
+	}
+
+	res, err := c.doRequest(ctx, req)
+	if err != nil {
+		return c.newConnectError(err)
+	}
+
+	if res.Body != nil {
+		defer res.Body.Close()
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+		return nil
+	case http.StatusConflict:
+		return newHTTPError(http.StatusConflict, "namespace %s already exists", name)
+	case http.StatusUnauthorized:
+		return unauthorizedError()
+	default:
+		bytesOut, _ := ioutil.ReadAll(res.Body)
+		return newHTTPError(res.StatusCode, "server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
+	}
+}
+
+// DeleteNamespace deletes a function namespace, along with any functions in it
+func (c *Client) DeleteNamespace(ctx context.Context, name string) error {
+	req, err := c.newRequest(http.MethodDelete, path.Join(namespacePath, name), nil)
+	if err != nil {
+		return fmt.Errorf("cannot connect to OpenFaaS on URL: %s", c.GatewayURL.String())
+	}
+
+	res, err := c.doRequest(ctx, req)
+	if err != nil {
+		return c.newConnectError(err)
+	}
+
+	if res.Body != nil {
+		defer res.Body.Close()
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return newHTTPError(http.StatusNotFound, "namespace %s not found", name)
+	case http.StatusUnauthorized:
+		return unauthorizedError()
+	default:
+		bytesOut, _ := ioutil.ReadAll(res.Body)
+		return newHTTPError(res.StatusCode, "server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
+	}
+}
@@ -0,0 +1,70 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_CreateNamespace(t *testing.T) {
+	cases := []struct {
+		name       string
+		statusCode int
+		want       string
+	}{
+		{name: "created", statusCode: http.StatusCreated},
+		{name: "already exists", statusCode: http.StatusConflict, want: "namespace dev already exists"},
+		{name: "unauthorized", statusCode: http.StatusUnauthorized, want: "unauthorized access, run \"faas-cli login\" to setup authentication for this server"},
+		{name: "unexpected", statusCode: http.StatusBadRequest, want: "server returned unexpected status code: 400 - "},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := test.MockHttpServer(t, []test.Request{
+				{Method: http.MethodPost, Uri: "/system/namespace", ResponseStatusCode: c.statusCode},
+			})
+			defer s.Close()
+
+			client := NewClient(NewTestAuth(nil), s.URL, nil, nil)
+			err := client.CreateNamespace(context.Background(), "dev")
+			if len(c.want) == 0 && err != nil {
+				t.Fatalf("want no error, got %s", err)
+			}
+			if len(c.want) > 0 && (err == nil || err.Error() != c.want) {
+				t.Fatalf("want error %q, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func Test_DeleteNamespace(t *testing.T) {
+	cases := []struct {
+		name       string
+		statusCode int
+		want       string
+	}{
+		{name: "deleted", statusCode: http.StatusAccepted},
+		{name: "not found", statusCode: http.StatusNotFound, want: "namespace dev not found"},
+		{name: "unexpected", statusCode: http.StatusInternalServerError, want: "server returned unexpected status code: 500 - "},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := test.MockHttpServer(t, []test.Request{
+				{Method: http.MethodDelete, Uri: "/system/namespace/dev", ResponseStatusCode: c.statusCode},
+			})
+			defer s.Close()
+
+			client := NewClient(NewTestAuth(nil), s.URL, nil, nil)
+			err := client.DeleteNamespace(context.Background(), "dev")
+			if len(c.want) == 0 && err != nil {
+				t.Fatalf("want no error, got %s", err)
+			}
+			if len(c.want) > 0 && (err == nil || err.Error() != c.want) {
+				t.Fatalf("want error %q, got %v", c.want, err)
+			}
+		})
+	}
+}
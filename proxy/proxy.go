@@ -4,7 +4,6 @@
 package proxy
 
 import (
-	"crypto/tls"
 	"net"
 	"net/http"
 	"time"
@@ -18,10 +17,11 @@ func MakeHTTPClient(timeout *time.Duration, tlsInsecure bool) http.Client {
 // makeHTTPClientWithDisableKeepAlives makes a HTTP client with good defaults for timeouts.
 func makeHTTPClientWithDisableKeepAlives(timeout *time.Duration, tlsInsecure bool, disableKeepAlives bool) http.Client {
 	client := http.Client{}
+	tlsConfig := TLSClientConfig(tlsInsecure)
 
-	if timeout != nil || tlsInsecure {
+	if timeout != nil || tlsConfig != nil {
 		tr := &http.Transport{
-			Proxy:             http.ProxyFromEnvironment,
+			Proxy:             HTTPProxy,
 			DisableKeepAlives: disableKeepAlives,
 		}
 
@@ -35,8 +35,8 @@ func makeHTTPClientWithDisableKeepAlives(timeout *time.Duration, tlsInsecure boo
 			tr.ExpectContinueTimeout = 1500 * time.Millisecond
 		}
 
-		if tlsInsecure {
-			tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: tlsInsecure}
+		if tlsConfig != nil {
+			tr.TLSClientConfig = tlsConfig
 		}
 
 		tr.DisableKeepAlives = disableKeepAlives
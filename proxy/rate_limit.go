@@ -0,0 +1,84 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimit is the token bucket set by SetRateLimit, there is no limit when
+// it is nil
+var rateLimit *tokenBucket
+
+// SetRateLimit limits the requests to the gateway, including invokes, to
+// perSecond across every goroutine, 0 removes the limit
+func SetRateLimit(perSecond float64) {
+	if perSecond <= 0 {
+		rateLimit = nil
+		return
+	}
+	rateLimit = newTokenBucket(perSecond, time.Now)
+}
+
+// waitRateLimit blocks until the request may be sent, or ctx is done
+func waitRateLimit(ctx context.Context) error {
+	if rateLimit == nil {
+		return nil
+	}
+	return rateLimit.wait(ctx)
+}
+
+// tokenBucket gives a token for each request at rate per second. It holds
+// at most one token, so that requests are spread evenly rather than sent in
+// a burst after an idle period.
+type tokenBucket struct {
+	lock   sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newTokenBucket(rate float64, now func() time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: 1, last: now(), now: now}
+}
+
+// reserve takes a token and gives how long to wait for it, the token
+// count goes below zero for the requests which are waiting
+func (b *tokenBucket) reserve() time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > 1 {
+		b.tokens = 1
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	delay := b.reserve()
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_tokenBucket_reserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	bucket := newTokenBucket(2, func() time.Time { return now })
+
+	want := []time.Duration{0, 500 * time.Millisecond, time.Second}
+	for i, delay := range want {
+		if got := bucket.reserve(); got != delay {
+			t.Errorf("request %d: want a delay of %s, got %s", i, delay, got)
+		}
+	}
+
+	// After an idle period the bucket holds a single token
+	now = now.Add(10 * time.Second)
+	if got := bucket.reserve(); got != 0 {
+		t.Errorf("want no delay after an idle period, got %s", got)
+	}
+	if got := bucket.reserve(); got != 500*time.Millisecond {
+		t.Errorf("want the next request after an idle period to wait, got %s", got)
+	}
+}
+
+func Test_tokenBucket_waitCancelled(t *testing.T) {
+	now := time.Unix(0, 0)
+	bucket := newTokenBucket(0.1, func() time.Time { return now })
+	bucket.reserve()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := bucket.wait(ctx); err != context.Canceled {
+		t.Errorf("want the wait to end with the context, got %v", err)
+	}
+}
+
+func Test_InvokeFunction_RateLimit(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	SetRateLimit(50)
+	defer SetRateLimit(0)
+
+	start := time.Now()
+	bytesIn := []byte("test data")
+	for i := 0; i < 3; i++ {
+		if _, err := InvokeFunction(s.URL, "function", &bytesIn, "text/plain", []string{}, []string{}, false, http.MethodPost, tlsNoVerify, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("want 3 requests at 50 per second to take at least 40ms, took %s", elapsed)
+	}
+}
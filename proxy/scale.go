@@ -0,0 +1,65 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	types "github.com/openfaas/faas-provider/types"
+)
+
+const scalePath = "/system/scale-function"
+
+// ScaleFunction sets the number of replicas for a function
+func (c *Client) ScaleFunction(ctx context.Context, functionName string, namespace string, replicas uint64) error {
+	var err error
+
+	scaleReq := types.ScaleServiceRequest{
+		ServiceName: functionName,
+		Replicas:    replicas,
+	}
+	reqBytes, _ := json.Marshal(&scaleReq)
+
+	scaleEndpoint := fmt.Sprintf("%s/%s", scalePath, functionName)
+	if len(namespace) > 0 {
+		scaleEndpoint, err = addQueryParams(scaleEndpoint, map[string]string{namespaceKey: namespace})
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := c.newRequest(http.MethodPost, scaleEndpoint, bytes.NewReader(reqBytes))
+	if err != nil {
+		return fmt.Errorf("cannot connect to OpenFaaS on URL: %s", c.GatewayURL.String())
+	}
+
+	res, err := c.doRequest(ctx, req)
+	if err != nil {
+		return c.newConnectError(err)
+	}
+
+	if res.Body != nil {
+		defer res.Body.Close()
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusAccepted:
+		return nil
+	case http.StatusNotFound:
+		return newHTTPError(http.StatusNotFound, "No such function: %s", functionName)
+	case http.StatusUnauthorized:
+		return unauthorizedError()
+	default:
+		bytesOut, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		return newHTTPError(res.StatusCode, "server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
+	}
+}
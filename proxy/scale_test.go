@@ -0,0 +1,46 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_ScaleFunction(t *testing.T) {
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodPost,
+			Uri:                "/system/scale-function/figlet?namespace=openfaas-fn",
+			ResponseStatusCode: http.StatusAccepted,
+		},
+	})
+	defer s.Close()
+
+	cliAuth := NewTestAuth(nil)
+	proxyClient := NewClient(cliAuth, s.URL, nil, &defaultCommandTimeout)
+
+	if err := proxyClient.ScaleFunction(context.Background(), "figlet", "openfaas-fn", 3); err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+}
+
+func Test_ScaleFunction_404(t *testing.T) {
+	s := test.MockHttpServerStatus(t, http.StatusNotFound)
+	defer s.Close()
+
+	cliAuth := NewTestAuth(nil)
+	proxyClient := NewClient(cliAuth, s.URL, nil, &defaultCommandTimeout)
+
+	err := proxyClient.ScaleFunction(context.Background(), "figlet", "", 3)
+
+	r := regexp.MustCompile(`(?m:No such function: figlet)`)
+	if err == nil || !r.MatchString(err.Error()) {
+		t.Fatalf("Want: %s, got: %v", "No such function: figlet", err)
+	}
+}
@@ -35,7 +35,7 @@ func (c *Client) GetSecretList(ctx context.Context, namespace string) ([]types.S
 
 	res, err := c.doRequest(ctx, getRequest)
 	if err != nil {
-		return nil, fmt.Errorf("cannot connect to OpenFaaS on URL: %s", c.GatewayURL.String())
+		return nil, c.newConnectError(err)
 	}
 
 	if res.Body != nil {
@@ -56,12 +56,12 @@ func (c *Client) GetSecretList(ctx context.Context, namespace string) ([]types.S
 		}
 
 	case http.StatusUnauthorized:
-		return nil, fmt.Errorf("unauthorized access, run \"faas-cli login\" to setup authentication for this server")
+		return nil, unauthorizedError()
 
 	default:
 		bytesOut, err := ioutil.ReadAll(res.Body)
 		if err == nil {
-			return nil, fmt.Errorf("server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
+			return nil, newHTTPError(res.StatusCode, "server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
 		}
 	}
 
@@ -82,7 +82,7 @@ func (c *Client) UpdateSecret(ctx context.Context, secret types.Secret) (int, st
 
 	res, err := c.doRequest(ctx, putRequest)
 	if err != nil {
-		output += fmt.Sprintf("cannot connect to OpenFaaS on URL: %s", c.GatewayURL.String())
+		output += c.newConnectError(err).Error()
 		return http.StatusInternalServerError, output
 	}
 
@@ -99,7 +99,7 @@ func (c *Client) UpdateSecret(ctx context.Context, secret types.Secret) (int, st
 		output += fmt.Sprintf("unable to find secret: %s", secret.Name)
 
 	case http.StatusUnauthorized:
-		output += fmt.Sprintf("unauthorized access, run \"faas-cli login\" to setup authentication for this server")
+		output += unauthorizedMessage
 
 	default:
 		bytesOut, err := ioutil.ReadAll(res.Body)
@@ -121,7 +121,7 @@ func (c *Client) RemoveSecret(ctx context.Context, secret types.Secret) error {
 
 	res, err := c.doRequest(ctx, req)
 	if err != nil {
-		return fmt.Errorf("cannot connect to OpenFaaS on URL: %s", c.GatewayURL.String())
+		return c.newConnectError(err)
 	}
 
 	if res.Body != nil {
@@ -132,14 +132,14 @@ func (c *Client) RemoveSecret(ctx context.Context, secret types.Secret) error {
 	case http.StatusOK, http.StatusAccepted:
 		break
 	case http.StatusNotFound:
-		return fmt.Errorf("unable to find secret: %s", secret.Name)
+		return newHTTPError(http.StatusNotFound, "unable to find secret: %s", secret.Name)
 	case http.StatusUnauthorized:
-		return fmt.Errorf("unauthorized access, run \"faas-cli login\" to setup authentication for this server")
+		return unauthorizedError()
 
 	default:
 		bytesOut, err := ioutil.ReadAll(res.Body)
 		if err == nil {
-			return fmt.Errorf("server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
+			return newHTTPError(res.StatusCode, "server returned unexpected status code: %d - %s", res.StatusCode, string(bytesOut))
 		}
 	}
 
@@ -161,7 +161,7 @@ func (c *Client) CreateSecret(ctx context.Context, secret types.Secret) (int, st
 
 	res, err := c.doRequest(ctx, request)
 	if err != nil {
-		output += fmt.Sprintf("cannot connect to OpenFaaS on URL: %s\n", c.GatewayURL.String())
+		output += fmt.Sprintln(c.newConnectError(err))
 		return http.StatusInternalServerError, output
 	}
 
@@ -174,7 +174,7 @@ func (c *Client) CreateSecret(ctx context.Context, secret types.Secret) (int, st
 		output += fmt.Sprintf("Created: %s\n", res.Status)
 
 	case http.StatusUnauthorized:
-		output += fmt.Sprintln("unauthorized access, run \"faas-cli login\" to setup authentication for this server")
+		output += fmt.Sprintln(unauthorizedMessage)
 
 	case http.StatusConflict:
 		output += fmt.Sprintf("secret with the name %q already exists\n", secret.Name)
@@ -0,0 +1,97 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// clientTLSConfig holds the client certificate, CAs and verification set by
+// SetClientTLS
+var clientTLSConfig *tls.Config
+
+// LoadClientTLS loads the client certificate and key used for mutual TLS and
+// the CAs used, along with the system's, to verify the gateway. Each file is optional, but the
+// certificate and key must be given together.
+func LoadClientTLS(certFile, keyFile string, caFiles ...string) (*tls.Config, error) {
+	var cas []string
+	for _, caFile := range caFiles {
+		if len(caFile) > 0 {
+			cas = append(cas, caFile)
+		}
+	}
+
+	if len(certFile) == 0 && len(keyFile) == 0 && len(cas) == 0 {
+		return nil, nil
+	}
+
+	if (len(certFile) == 0) != (len(keyFile) == 0) {
+		return nil, fmt.Errorf("--tls-cert and --tls-key must be given together")
+	}
+
+	tlsConfig := &tls.Config{}
+
+	if len(certFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the TLS client certificate %s and key %s: %s", certFile, keyFile, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(cas) > 0 {
+		// The CAs are added to the system's, as the same clients are used
+		// for the store and templates
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		for _, caFile := range cas {
+			caData, err := ioutil.ReadFile(caFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read the TLS CA %s: %s", caFile, err)
+			}
+			if !pool.AppendCertsFromPEM(caData) {
+				return nil, fmt.Errorf("no PEM certificates found in the TLS CA %s", caFile)
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// SetClientTLS sets the TLS config used for every request the CLI makes. It is
+// also set on http.DefaultTransport, so that clients which don't build their
+// own transport, such as for the store, use it too.
+func SetClientTLS(tlsConfig *tls.Config) {
+	clientTLSConfig = tlsConfig
+
+	if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport.TLSClientConfig = TLSClientConfig(false)
+	}
+}
+
+// TLSClientConfig gives the TLS config for requests to the gateway, which is
+// nil unless a client certificate or CA was set or tlsInsecure is true.
+// Verification is skipped when tlsInsecure is true or SetClientTLS was given
+// InsecureSkipVerify.
+func TLSClientConfig(tlsInsecure bool) *tls.Config {
+	if clientTLSConfig == nil && !tlsInsecure {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if clientTLSConfig != nil {
+		tlsConfig = clientTLSConfig.Clone()
+	}
+	tlsConfig.InsecureSkipVerify = tlsConfig.InsecureSkipVerify || tlsInsecure
+
+	return tlsConfig
+}
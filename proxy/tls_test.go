@@ -0,0 +1,190 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeClientCert writes a self-signed client certificate and its key
+func writeClientCert(t *testing.T, dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "faas-cli"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ = x509.ParseCertificate(der)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "client.crt")
+	keyFile = filepath.Join(dir, "client.key")
+	ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+
+	return certFile, keyFile, cert
+}
+
+func Test_LoadClientTLS_Errors(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "faas-cli-tls")
+	defer os.RemoveAll(dir)
+
+	notPEM := filepath.Join(dir, "ca.crt")
+	ioutil.WriteFile(notPEM, []byte("not a certificate"), 0600)
+
+	cases := []struct {
+		name                      string
+		certFile, keyFile, caFile string
+		want                      string
+	}{
+		{name: "cert without key", certFile: "client.crt", want: "--tls-cert and --tls-key must be given together"},
+		{name: "key without cert", keyFile: "client.key", want: "--tls-cert and --tls-key must be given together"},
+		{name: "missing cert", certFile: filepath.Join(dir, "missing.crt"), keyFile: filepath.Join(dir, "missing.key"), want: "unable to load the TLS client certificate"},
+		{name: "CA without certificates", caFile: notPEM, want: "no PEM certificates found in the TLS CA"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := LoadClientTLS(c.certFile, c.keyFile, c.caFile)
+			if err == nil || !strings.HasPrefix(err.Error(), c.want) {
+				t.Errorf("want error starting %q, got %v", c.want, err)
+			}
+		})
+	}
+
+	if tlsConfig, err := LoadClientTLS("", "", ""); tlsConfig != nil || err != nil {
+		t.Errorf("want no config without files, got %v %v", tlsConfig, err)
+	}
+}
+
+func Test_MakeHTTPClient_MutualTLS(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "faas-cli-tls")
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile, clientCert := writeClientCert(t, dir)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(dir, "ca.crt")
+	ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+
+	tlsConfig, err := LoadClientTLS(certFile, keyFile, caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetClientTLS(tlsConfig)
+	defer SetClientTLS(nil)
+
+	timeout := 5 * time.Second
+	client := MakeHTTPClient(&timeout, false)
+
+	res, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("want the request to pass mutual TLS, got %s", err)
+	}
+	res.Body.Close()
+
+	res, err = http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("want the default client to use the client certificate and CA, got %s", err)
+	}
+	res.Body.Close()
+
+	SetClientTLS(nil)
+	client = MakeHTTPClient(&timeout, true)
+	if _, err := client.Get(server.URL); err == nil {
+		t.Errorf("want the request without a client certificate to fail")
+	}
+}
+
+func Test_LoadClientTLS_SeveralCAs(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "faas-cli-tls")
+	defer os.RemoveAll(dir)
+
+	_, _, first := writeClientCert(t, dir)
+	firstFile := filepath.Join(dir, "first.crt")
+	ioutil.WriteFile(firstFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: first.Raw}), 0600)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	bundleFile := filepath.Join(dir, "bundle.pem")
+	ioutil.WriteFile(bundleFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+
+	tlsConfig, err := LoadClientTLS("", "", firstFile, "", bundleFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetClientTLS(tlsConfig)
+	defer SetClientTLS(nil)
+
+	timeout := 5 * time.Second
+	client := MakeHTTPClient(&timeout, false)
+	res, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("want the gateway to be verified with the CA bundle, got %s", err)
+	}
+	res.Body.Close()
+}
+
+func Test_TLSClientConfig_Insecure(t *testing.T) {
+	SetClientTLS(&tls.Config{InsecureSkipVerify: true})
+	defer SetClientTLS(nil)
+
+	if tlsConfig := TLSClientConfig(false); !tlsConfig.InsecureSkipVerify {
+		t.Errorf("want verification to stay disabled when set by SetClientTLS")
+	}
+
+	SetClientTLS(nil)
+	if tlsConfig := TLSClientConfig(false); tlsConfig != nil {
+		t.Errorf("want no config when nothing is set, got %v", tlsConfig)
+	}
+	if tlsConfig := TLSClientConfig(true); !tlsConfig.InsecureSkipVerify {
+		t.Errorf("want verification to be disabled by tlsInsecure")
+	}
+}
@@ -10,6 +10,7 @@ const (
 	systemPath     = "/system/functions"
 	functionPath   = "/system/function"
 	namespacesPath = "/system/namespaces"
+	namespacePath  = "/system/namespace"
 	namespaceKey   = "namespace"
 )
 
@@ -21,7 +21,7 @@ func (c *Client) GetSystemInfo(ctx context.Context) (map[string]interface{}, err
 
 	response, err := c.doRequest(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("cannot connect to OpenFaaS on URL: %s", c.GatewayURL.String())
+		return nil, c.newConnectError(err)
 	}
 
 	if response.Body != nil {
@@ -41,11 +41,11 @@ func (c *Client) GetSystemInfo(ctx context.Context) (map[string]interface{}, err
 		}
 
 	case http.StatusUnauthorized:
-		return nil, fmt.Errorf("unauthorized access, run \"faas-cli login\" to setup authentication for this server")
+		return nil, unauthorizedError()
 	default:
 		bytesOut, err := ioutil.ReadAll(response.Body)
 		if err == nil {
-			return nil, fmt.Errorf("server returned unexpected status code: %d - %s", response.StatusCode, string(bytesOut))
+			return nil, newHTTPError(response.StatusCode, "server returned unexpected status code: %d - %s", response.StatusCode, string(bytesOut))
 		}
 	}
 
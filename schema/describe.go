@@ -3,17 +3,28 @@
 
 package schema
 
+import "github.com/openfaas/faas-cli/stack"
+
 //FunctionDescription information related to a function
 type FunctionDescription struct {
-	Name              string
-	Status            string
-	Replicas          int
-	AvailableReplicas int
-	InvocationCount   int
-	Image             string
-	EnvProcess        string
-	URL               string
-	AsyncURL          string
-	Labels            *map[string]string
-	Annotations       *map[string]string
+	Name              string             `json:"name" yaml:"name"`
+	Namespace         string             `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Status            string             `json:"status" yaml:"status"`
+	Replicas          int                `json:"replicas" yaml:"replicas"`
+	AvailableReplicas int                `json:"availableReplicas" yaml:"availableReplicas"`
+	InvocationCount   int                `json:"invocationCount" yaml:"invocationCount"`
+	Image             string             `json:"image" yaml:"image"`
+	EnvProcess        string             `json:"envProcess" yaml:"envProcess"`
+	URL               string             `json:"url" yaml:"url"`
+	AsyncURL          string             `json:"asyncUrl" yaml:"asyncUrl"`
+	Labels            *map[string]string `json:"labels" yaml:"labels"`
+	Annotations       *map[string]string `json:"annotations" yaml:"annotations"`
+	// HealthCheck is read back from the annotations the function was deployed with
+	HealthCheck *stack.HealthCheck `json:"healthcheck,omitempty" yaml:"healthcheck,omitempty"`
+	// Topics are read back from the topic annotation for the event connectors
+	Topics []string `json:"topics,omitempty" yaml:"topics,omitempty"`
+	// Environment is only known when the function is in the stack file, as are
+	// Constraints when the provider doesn't report them
+	Environment map[string]string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	Constraints []string          `json:"constraints,omitempty" yaml:"constraints,omitempty"`
 }
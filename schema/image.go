@@ -62,8 +62,13 @@ func (i *BuildFormat) Set(value string) error {
 	return nil
 }
 
-// BuildImageName builds a Docker image tag for build, push or deploy
+// BuildImageName builds a Docker image tag for build, push or deploy, an
+// image pinned to a digest such as repo@sha256:... is given unchanged
 func BuildImageName(format BuildFormat, image string, version string, branch string) string {
+	if IsPinnedImage(image) {
+		return image
+	}
+
 	imageVal := image
 	if strings.Contains(image, ":") == false {
 		imageVal += ":latest"
@@ -82,3 +87,9 @@ func BuildImageName(format BuildFormat, image string, version string, branch str
 		return imageVal
 	}
 }
+
+// IsPinnedImage is true for an image given by its digest, such as
+// repo@sha256:...
+func IsPinnedImage(image string) bool {
+	return strings.Contains(image, "@")
+}
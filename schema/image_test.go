@@ -37,3 +37,12 @@ func Test_BuildImageName_BranchAndSHAFormat(t *testing.T) {
 		t.Errorf("BuildImageName want: \"%s\", got: \"%s\"", want, got)
 	}
 }
+
+func Test_BuildImageName_PinnedToDigest(t *testing.T) {
+	want := "img@sha256:4b1b2f3c"
+	got := BuildImageName(SHAFormat, "img@sha256:4b1b2f3c", "ef384", "master")
+
+	if got != want {
+		t.Errorf("BuildImageName want: \"%s\", got: \"%s\"", want, got)
+	}
+}
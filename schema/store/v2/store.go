@@ -17,6 +17,7 @@ type StoreFunction struct {
 	Labels                 map[string]string `json:"labels"`
 	Annotations            map[string]string `json:"annotations"`
 	Images                 map[string]string `json:"images"`
+	Categories             []string          `json:"categories,omitempty"`
 }
 
 //GetImageName get image name of function for a platform
@@ -0,0 +1,54 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	annotationPrefixPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	annotationNamePattern   = regexp.MustCompile(`^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`)
+)
+
+// ValidateAnnotationKey checks the key is a qualified name as accepted by
+// Kubernetes, a name of up to 63 characters with an optional DNS subdomain
+// prefix, i.e. topic or com.openfaas.scale.min
+func ValidateAnnotationKey(key string) error {
+	prefix, name := "", key
+	if i := strings.LastIndex(key, "/"); i > -1 {
+		prefix, name = key[:i], key[i+1:]
+		if len(prefix) == 0 || len(prefix) > 253 || !annotationPrefixPattern.MatchString(prefix) {
+			return fmt.Errorf("annotation %q must have a DNS subdomain before the /", key)
+		}
+	}
+
+	if len(name) == 0 || len(name) > 63 || !annotationNamePattern.MatchString(name) {
+		return fmt.Errorf("annotation %q must be up to 63 letters, digits, '-', '_' or '.' and start and end with a letter or digit", key)
+	}
+	return nil
+}
+
+// validateAnnotations checks the key of each annotation of the function
+func validateAnnotations(function Function) error {
+	if function.Annotations == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(*function.Annotations))
+	for key := range *function.Annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := ValidateAnnotationKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_ValidateAnnotationKey(t *testing.T) {
+	cases := []struct {
+		key   string
+		valid bool
+	}{
+		{"topic", true},
+		{"com.openfaas.scale.min", true},
+		{"prometheus.io/scrape", true},
+		{"nginx.ingress.kubernetes.io/rewrite-target", true},
+		{"a_b-c.d", true},
+		{strings.Repeat("a", 63), true},
+		{strings.Repeat("a", 64), false},
+		{"", false},
+		{"-topic", false},
+		{"topic.", false},
+		{"has space", false},
+		{"/scrape", false},
+		{"Prometheus.io/scrape", false},
+		{"prometheus.io/", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.key, func(t *testing.T) {
+			err := ValidateAnnotationKey(c.key)
+			if c.valid && err != nil {
+				t.Errorf("want %q to be valid, got %s", c.key, err)
+			}
+			if !c.valid && err == nil {
+				t.Errorf("want %q to be refused", c.key)
+			}
+		})
+	}
+}
+
+func Test_ParseYAMLData_InvalidAnnotation(t *testing.T) {
+	stack := `provider:
+  name: openfaas
+functions:
+  figlet:
+    image: functions/figlet:latest
+    annotations:
+      topic: cron
+      "bad key": "true"
+`
+	_, err := ParseYAMLData([]byte(stack), "", "", false)
+
+	want := `function figlet: annotation "bad key" must be up to 63 letters, digits, '-', '_' or '.' and start and end with a letter or digit`
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
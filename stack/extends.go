@@ -0,0 +1,53 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resolveExtends replaces each function which sets "extends" with the named
+// function merged with its own fields, using the same precedence as merging
+// stack files. Chains of extends are resolved from the root base down.
+func resolveExtends(functions map[string]Function) error {
+	resolved := map[string]bool{}
+
+	for name := range functions {
+		if err := resolveFunction(functions, name, resolved, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resolveFunction(functions map[string]Function, name string, resolved map[string]bool, path []string) error {
+	if resolved[name] {
+		return nil
+	}
+
+	for _, visited := range path {
+		if visited == name {
+			return fmt.Errorf("cycle found in extends: %s", strings.Join(append(path, name), " -> "))
+		}
+	}
+
+	function := functions[name]
+	if len(function.Extends) == 0 {
+		resolved[name] = true
+		return nil
+	}
+
+	if _, ok := functions[function.Extends]; !ok {
+		return fmt.Errorf("function %s extends %s, which was not found in the YAML file", name, function.Extends)
+	}
+
+	if err := resolveFunction(functions, function.Extends, resolved, append(path, name)); err != nil {
+		return err
+	}
+
+	functions[name] = mergeFunction(functions[function.Extends], function)
+	resolved[name] = true
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+import (
+	"strings"
+	"testing"
+)
+
+const extendsStack = `provider:
+  name: openfaas
+functions:
+  base-go:
+    lang: go
+    environment:
+      write_timeout: 10s
+      read_timeout: 10s
+    labels:
+      team: payments
+  checkout:
+    extends: base-go
+    image: payments/checkout:latest
+    environment:
+      write_timeout: 30s
+  refund:
+    extends: checkout
+    image: payments/refund:latest
+    lang: golang-middleware
+`
+
+func Test_ParseYAMLData_Extends(t *testing.T) {
+	services, err := ParseYAMLData([]byte(extendsStack), "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkout := services.Functions["checkout"]
+	if checkout.Language != "go" {
+		t.Errorf("want lang inherited from base-go, got %s", checkout.Language)
+	}
+	if checkout.Environment["write_timeout"] != "30s" || checkout.Environment["read_timeout"] != "10s" {
+		t.Errorf("want environment merged with base-go, got %v", checkout.Environment)
+	}
+	if checkout.Labels == nil || (*checkout.Labels)["team"] != "payments" {
+		t.Errorf("want labels inherited from base-go, got %v", checkout.Labels)
+	}
+
+	refund := services.Functions["refund"]
+	if refund.Language != "golang-middleware" {
+		t.Errorf("want lang overridden, got %s", refund.Language)
+	}
+	if refund.Image != "payments/refund:latest" {
+		t.Errorf("want image overridden, got %s", refund.Image)
+	}
+	if refund.Environment["write_timeout"] != "30s" {
+		t.Errorf("want environment inherited through checkout, got %v", refund.Environment)
+	}
+
+	base := services.Functions["base-go"]
+	if base.Environment["write_timeout"] != "10s" {
+		t.Errorf("want base-go left unchanged, got %v", base.Environment)
+	}
+}
+
+func Test_ParseYAMLData_ExtendsErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "unknown base",
+			yaml: `provider:
+  name: openfaas
+functions:
+  checkout:
+    extends: base-go
+`,
+			wantErr: "function checkout extends base-go, which was not found in the YAML file",
+		},
+		{
+			name: "cycle",
+			yaml: `provider:
+  name: openfaas
+functions:
+  a:
+    extends: b
+  b:
+    extends: a
+`,
+			wantErr: "cycle found in extends:",
+		},
+		{
+			name: "self",
+			yaml: `provider:
+  name: openfaas
+functions:
+  a:
+    extends: a
+`,
+			wantErr: "cycle found in extends: a -> a",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseYAMLData([]byte(c.yaml), "", "", false)
+			if err == nil {
+				t.Fatalf("want error containing %q, got nil", c.wantErr)
+			}
+			if !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("want error containing %q, got %q", c.wantErr, err.Error())
+			}
+		})
+	}
+}
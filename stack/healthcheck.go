@@ -0,0 +1,117 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The annotations read by faas-netes to set the HTTP readiness and liveness
+// probes of a function. Providers which don't support them, such as
+// faas-swarm and faasd, ignore them.
+const (
+	HealthCheckPathAnnotation         = "com.openfaas.health.http.path"
+	HealthCheckInitialDelayAnnotation = "com.openfaas.health.http.initialDelay"
+	HealthCheckPeriodAnnotation       = "com.openfaas.health.http.periodSeconds"
+	HealthCheckTimeoutAnnotation      = "com.openfaas.health.http.timeoutSeconds"
+)
+
+// HealthCheck configures the HTTP readiness and liveness probes of a
+// function, the delay, period and timeout are durations such as 10s
+type HealthCheck struct {
+	Path         string `yaml:"path,omitempty" json:"path,omitempty"`
+	InitialDelay string `yaml:"initial_delay,omitempty" json:"initialDelay,omitempty"`
+	Period       string `yaml:"period,omitempty" json:"period,omitempty"`
+	Timeout      string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// Validate checks the path is absolute and the durations can be given to
+// the provider, the period and timeout as a whole number of seconds
+func (h HealthCheck) Validate() error {
+	if len(h.Path) > 0 && !strings.HasPrefix(h.Path, "/") {
+		return fmt.Errorf("healthcheck path must start with /, but you gave: %s", h.Path)
+	}
+
+	if _, err := parseHealthCheckDuration("initial_delay", h.InitialDelay, 0); err != nil {
+		return err
+	}
+	if _, err := parseHealthCheckSeconds("period", h.Period); err != nil {
+		return err
+	}
+	if _, err := parseHealthCheckSeconds("timeout", h.Timeout); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Annotations gives the annotations for the fields which are set, Validate
+// should be called first
+func (h HealthCheck) Annotations() map[string]string {
+	annotations := map[string]string{}
+
+	if len(h.Path) > 0 {
+		annotations[HealthCheckPathAnnotation] = h.Path
+	}
+	if len(h.InitialDelay) > 0 {
+		d, _ := parseHealthCheckDuration("initial_delay", h.InitialDelay, 0)
+		annotations[HealthCheckInitialDelayAnnotation] = d.String()
+	}
+	if seconds, _ := parseHealthCheckSeconds("period", h.Period); seconds > 0 {
+		annotations[HealthCheckPeriodAnnotation] = strconv.Itoa(seconds)
+	}
+	if seconds, _ := parseHealthCheckSeconds("timeout", h.Timeout); seconds > 0 {
+		annotations[HealthCheckTimeoutAnnotation] = strconv.Itoa(seconds)
+	}
+
+	return annotations
+}
+
+// HealthCheckFromAnnotations reads the health check back from the
+// annotations of a deployed function, nil is returned when none are set
+func HealthCheckFromAnnotations(annotations map[string]string) *HealthCheck {
+	h := HealthCheck{
+		Path:         annotations[HealthCheckPathAnnotation],
+		InitialDelay: annotations[HealthCheckInitialDelayAnnotation],
+	}
+	if seconds, ok := annotations[HealthCheckPeriodAnnotation]; ok {
+		h.Period = seconds + "s"
+	}
+	if seconds, ok := annotations[HealthCheckTimeoutAnnotation]; ok {
+		h.Timeout = seconds + "s"
+	}
+
+	if h == (HealthCheck{}) {
+		return nil
+	}
+	return &h
+}
+
+func parseHealthCheckDuration(field, value string, min time.Duration) (time.Duration, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("healthcheck %s must be a duration such as 10s, but you gave: %s", field, value)
+	}
+	if d < min {
+		return 0, fmt.Errorf("healthcheck %s must be at least %s, but you gave: %s", field, min, value)
+	}
+	return d, nil
+}
+
+func parseHealthCheckSeconds(field, value string) (int, error) {
+	d, err := parseHealthCheckDuration(field, value, time.Second)
+	if err != nil {
+		return 0, err
+	}
+	if d%time.Second != 0 {
+		return 0, fmt.Errorf("healthcheck %s must be a whole number of seconds, but you gave: %s", field, value)
+	}
+	return int(d / time.Second), nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_HealthCheck_Validate(t *testing.T) {
+	cases := []struct {
+		name        string
+		healthCheck HealthCheck
+		want        string
+	}{
+		{name: "valid", healthCheck: HealthCheck{Path: "/_/health", InitialDelay: "1500ms", Period: "10s", Timeout: "1m"}},
+		{name: "relative path", healthCheck: HealthCheck{Path: "health"}, want: "healthcheck path must start with /, but you gave: health"},
+		{name: "not a duration", healthCheck: HealthCheck{InitialDelay: "5"}, want: "healthcheck initial_delay must be a duration such as 10s, but you gave: 5"},
+		{name: "negative delay", healthCheck: HealthCheck{InitialDelay: "-1s"}, want: "healthcheck initial_delay must be at least 0s, but you gave: -1s"},
+		{name: "period under a second", healthCheck: HealthCheck{Period: "500ms"}, want: "healthcheck period must be at least 1s, but you gave: 500ms"},
+		{name: "timeout in part seconds", healthCheck: HealthCheck{Timeout: "1500ms"}, want: "healthcheck timeout must be a whole number of seconds, but you gave: 1500ms"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.healthCheck.Validate()
+			if len(c.want) == 0 && err != nil {
+				t.Fatalf("want no error, got %s", err)
+			}
+			if len(c.want) > 0 && (err == nil || err.Error() != c.want) {
+				t.Fatalf("want error %q, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func Test_HealthCheck_AnnotationsRoundTrip(t *testing.T) {
+	healthCheck := HealthCheck{Path: "/_/health", InitialDelay: "30s", Period: "1m", Timeout: "5s"}
+
+	annotations := healthCheck.Annotations()
+	want := map[string]string{
+		HealthCheckPathAnnotation:         "/_/health",
+		HealthCheckInitialDelayAnnotation: "30s",
+		HealthCheckPeriodAnnotation:       "60",
+		HealthCheckTimeoutAnnotation:      "5",
+	}
+	if !reflect.DeepEqual(annotations, want) {
+		t.Fatalf("want %v, got %v", want, annotations)
+	}
+
+	got := HealthCheckFromAnnotations(annotations)
+	if got == nil || *got != (HealthCheck{Path: "/_/health", InitialDelay: "30s", Period: "60s", Timeout: "5s"}) {
+		t.Errorf("want the health check back from the annotations, got %+v", got)
+	}
+
+	if got := HealthCheckFromAnnotations(map[string]string{"topic": "payments"}); got != nil {
+		t.Errorf("want no health check without its annotations, got %+v", got)
+	}
+}
+
+func Test_ParseYAMLData_InvalidHealthCheck(t *testing.T) {
+	data := []byte(`provider:
+  name: openfaas
+functions:
+  fn1:
+    image: fn1:latest
+    healthcheck:
+      path: health
+`)
+
+	_, err := ParseYAMLData(data, "", "", false)
+	want := "function fn1: healthcheck path must start with /, but you gave: health"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
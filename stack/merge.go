@@ -0,0 +1,160 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+// mergeServices merges the overlay stack file on top of base. Scalar fields set in
+// the overlay take precedence, maps such as environment, labels and annotations
+// are merged key-by-key and functions are unioned by name. Lists such as secrets
+// and constraints are replaced when set in the overlay.
+func mergeServices(base, overlay Services) Services {
+	merged := base
+
+	merged.Version = mergeString(base.Version, overlay.Version)
+
+	merged.Provider = Provider{
+		Name:       mergeString(base.Provider.Name, overlay.Provider.Name),
+		GatewayURL: mergeString(base.Provider.GatewayURL, overlay.Provider.GatewayURL),
+		Network:    mergeString(base.Provider.Network, overlay.Provider.Network),
+	}
+
+	if len(overlay.StackConfiguration.TemplateConfigs) > 0 {
+		merged.StackConfiguration.TemplateConfigs = overlay.StackConfiguration.TemplateConfigs
+	}
+	if len(overlay.StackConfiguration.CopyExtraPaths) > 0 {
+		merged.StackConfiguration.CopyExtraPaths = overlay.StackConfiguration.CopyExtraPaths
+	}
+
+	merged.Functions = map[string]Function{}
+	for name, function := range base.Functions {
+		merged.Functions[name] = function
+	}
+	for name, function := range overlay.Functions {
+		if existing, ok := merged.Functions[name]; ok {
+			merged.Functions[name] = mergeFunction(existing, function)
+		} else {
+			merged.Functions[name] = function
+		}
+	}
+
+	return merged
+}
+
+func mergeFunction(base, overlay Function) Function {
+	merged := base
+
+	merged.Language = mergeString(base.Language, overlay.Language)
+	merged.Handler = mergeString(base.Handler, overlay.Handler)
+	merged.Image = mergeString(base.Image, overlay.Image)
+	merged.RegistryAuth = mergeString(base.RegistryAuth, overlay.RegistryAuth)
+	merged.FProcess = mergeString(base.FProcess, overlay.FProcess)
+	merged.Namespace = mergeString(base.Namespace, overlay.Namespace)
+	merged.BuildContext = mergeString(base.BuildContext, overlay.BuildContext)
+	merged.Extends = mergeString(base.Extends, overlay.Extends)
+
+	merged.SkipBuild = base.SkipBuild || overlay.SkipBuild
+	merged.ReadOnlyRootFilesystem = base.ReadOnlyRootFilesystem || overlay.ReadOnlyRootFilesystem
+
+	merged.Environment = mergeStringMap(base.Environment, overlay.Environment)
+	merged.BuildArgs = mergeStringMap(base.BuildArgs, overlay.BuildArgs)
+	merged.Labels = mergeStringMapPtr(base.Labels, overlay.Labels)
+	merged.Annotations = mergeStringMapPtr(base.Annotations, overlay.Annotations)
+
+	if overlay.Secrets != nil {
+		merged.Secrets = overlay.Secrets
+	}
+	if overlay.Constraints != nil {
+		merged.Constraints = overlay.Constraints
+	}
+	if overlay.EnvironmentFile != nil {
+		merged.EnvironmentFile = overlay.EnvironmentFile
+	}
+	if overlay.BuildOptions != nil {
+		merged.BuildOptions = overlay.BuildOptions
+	}
+	if overlay.CacheFrom != nil {
+		merged.CacheFrom = overlay.CacheFrom
+	}
+	if overlay.Topics != nil {
+		merged.Topics = overlay.Topics
+	}
+
+	merged.HealthCheck = mergeHealthCheck(base.HealthCheck, overlay.HealthCheck)
+
+	merged.Limits = mergeResources(base.Limits, overlay.Limits)
+	merged.Requests = mergeResources(base.Requests, overlay.Requests)
+
+	return merged
+}
+
+func mergeString(base, overlay string) string {
+	if len(overlay) > 0 {
+		return overlay
+	}
+	return base
+}
+
+func mergeStringMap(base, overlay map[string]string) map[string]string {
+	if base == nil && overlay == nil {
+		return nil
+	}
+
+	merged := map[string]string{}
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overlay {
+		merged[k] = v
+	}
+	return merged
+}
+
+func mergeStringMapPtr(base, overlay *map[string]string) *map[string]string {
+	if base == nil && overlay == nil {
+		return nil
+	}
+
+	var baseMap, overlayMap map[string]string
+	if base != nil {
+		baseMap = *base
+	}
+	if overlay != nil {
+		overlayMap = *overlay
+	}
+
+	merged := mergeStringMap(baseMap, overlayMap)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	return &merged
+}
+
+func mergeResources(base, overlay *FunctionResources) *FunctionResources {
+	if overlay == nil {
+		return base
+	}
+	if base == nil {
+		return overlay
+	}
+
+	return &FunctionResources{
+		Memory: mergeString(base.Memory, overlay.Memory),
+		CPU:    mergeString(base.CPU, overlay.CPU),
+	}
+}
+
+func mergeHealthCheck(base, overlay *HealthCheck) *HealthCheck {
+	if overlay == nil {
+		return base
+	}
+	if base == nil {
+		return overlay
+	}
+
+	return &HealthCheck{
+		Path:         mergeString(base.Path, overlay.Path),
+		InitialDelay: mergeString(base.InitialDelay, overlay.InitialDelay),
+		Period:       mergeString(base.Period, overlay.Period),
+		Timeout:      mergeString(base.Timeout, overlay.Timeout),
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const baseStack = `provider:
+  name: openfaas
+  gateway: http://127.0.0.1:8080
+functions:
+  figlet:
+    lang: dockerfile
+    image: functions/figlet:latest
+    environment:
+      write_debug: true
+      read_timeout: 10s
+    labels:
+      team: cli
+    secrets:
+      - api-key
+`
+
+const overrideStack = `provider:
+  name: openfaas
+  gateway: https://openfaas.example.com
+functions:
+  figlet:
+    image: functions/figlet:0.13.0
+    environment:
+      write_debug: false
+    labels:
+      canary: "true"
+  nodeinfo:
+    lang: node
+    image: functions/nodeinfo:latest
+`
+
+func writeStackFiles(t *testing.T, contents ...string) []string {
+	dir, err := ioutil.TempDir("", "faas-cli-merge-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var files []string
+	for i, content := range contents {
+		file := filepath.Join(dir, string(rune('a'+i))+".yml")
+		if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+func Test_ParseYAMLFiles_MergesInOrder(t *testing.T) {
+	files := writeStackFiles(t, baseStack, overrideStack)
+	defer os.RemoveAll(filepath.Dir(files[0]))
+
+	services, err := ParseYAMLFiles(files, "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if services.Provider.GatewayURL != "https://openfaas.example.com" {
+		t.Errorf("want gateway from the last file, got %s", services.Provider.GatewayURL)
+	}
+
+	figlet := services.Functions["figlet"]
+	if figlet.Image != "functions/figlet:0.13.0" {
+		t.Errorf("want image from the last file, got %s", figlet.Image)
+	}
+	if figlet.Language != "dockerfile" {
+		t.Errorf("want lang kept from the first file, got %s", figlet.Language)
+	}
+
+	wantEnv := map[string]string{"write_debug": "false", "read_timeout": "10s"}
+	for k, v := range wantEnv {
+		if figlet.Environment[k] != v {
+			t.Errorf("environment %s: want %s, got %s", k, v, figlet.Environment[k])
+		}
+	}
+
+	wantLabels := map[string]string{"team": "cli", "canary": "true"}
+	for k, v := range wantLabels {
+		if (*figlet.Labels)[k] != v {
+			t.Errorf("label %s: want %s, got %s", k, v, (*figlet.Labels)[k])
+		}
+	}
+
+	if len(figlet.Secrets) != 1 || figlet.Secrets[0] != "api-key" {
+		t.Errorf("want secrets kept from the first file, got %v", figlet.Secrets)
+	}
+
+	if _, ok := services.Functions["nodeinfo"]; !ok {
+		t.Errorf("want nodeinfo added from the last file")
+	}
+}
+
+func Test_ParseYAMLFiles_FilterAppliedAfterMerge(t *testing.T) {
+	files := writeStackFiles(t, baseStack, overrideStack)
+	defer os.RemoveAll(filepath.Dir(files[0]))
+
+	services, err := ParseYAMLFiles(files, "", "node*", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(services.Functions) != 1 {
+		t.Errorf("want only nodeinfo after filtering, got %d functions", len(services.Functions))
+	}
+}
+
+func Test_mergeResources(t *testing.T) {
+	merged := mergeResources(&FunctionResources{Memory: "128Mi", CPU: "100m"}, &FunctionResources{Memory: "256Mi"})
+
+	if merged.Memory != "256Mi" || merged.CPU != "100m" {
+		t.Errorf("want memory 256Mi and cpu 100m, got %+v", merged)
+	}
+}
@@ -58,11 +58,28 @@ type Function struct {
 	// Annotations
 	Annotations *map[string]string `yaml:"annotations,omitempty"`
 
+	// HealthCheck sets the readiness and liveness probes through annotations
+	HealthCheck *HealthCheck `yaml:"healthcheck,omitempty"`
+
+	// Topics which invoke the function through an event connector, given to
+	// the connector by the topic annotation
+	Topics []string `yaml:"topics,omitempty"`
+
 	// Namespace of the function
 	Namespace string `yaml:"namespace,omitempty"`
 
 	// BuildArgs for providing build-args
 	BuildArgs map[string]string `yaml:"build_args,omitempty"`
+
+	// CacheFrom images to seed the Docker build cache from
+	CacheFrom []string `yaml:"cache_from,omitempty"`
+
+	// BuildContext is a folder holding the handler whose files are copied into
+	// the build along with the handler, such as packages shared by functions
+	BuildContext string `yaml:"build_context,omitempty"`
+
+	// Extends the name of another function in the stack to inherit fields from
+	Extends string `yaml:"extends,omitempty"`
 }
 
 // Configuration for the stack.yml file
@@ -90,8 +107,8 @@ type TemplateSource struct {
 
 // FunctionResources Memory and CPU
 type FunctionResources struct {
-	Memory string `yaml:"memory"`
-	CPU    string `yaml:"cpu"`
+	Memory string `yaml:"memory,omitempty"`
+	CPU    string `yaml:"cpu,omitempty"`
 }
 
 // EnvironmentFile represents external file for environment data
@@ -4,6 +4,7 @@
 package stack
 
 import (
+	"crypto/tls"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -11,9 +12,12 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	envsubst "github.com/drone/envsubst"
+	"github.com/drone/envsubst/parse"
 	glob "github.com/ryanuber/go-glob"
 	yaml "gopkg.in/yaml.v2"
 )
@@ -29,26 +33,101 @@ var ValidSchemaVersions = []string{
 
 // ParseYAMLFile parse YAML file into a stack of "services".
 func ParseYAMLFile(yamlFile, regex, filter string, envsubst bool) (*Services, error) {
-	var err error
-	var fileData []byte
-	urlParsed, err := url.Parse(yamlFile)
-	if err == nil && len(urlParsed.Scheme) > 0 {
-		fmt.Println("Parsed: " + urlParsed.String())
-		fileData, err = fetchYAML(urlParsed)
+	fileData, err := readYAMLFile(yamlFile)
+	if err != nil {
+		return nil, err
+	}
+	return ParseYAMLData(fileData, regex, filter, envsubst)
+}
+
+// ParseYAMLFiles parses each YAML file and merges them in order into a single
+// stack of "services", see mergeServices for the precedence.
+func ParseYAMLFiles(yamlFiles []string, regex, filter string, envsubst bool) (*Services, error) {
+	if len(yamlFiles) == 1 {
+		return ParseYAMLFile(yamlFiles[0], regex, filter, envsubst)
+	}
+
+	var merged Services
+	for _, yamlFile := range yamlFiles {
+		fileData, err := readYAMLFile(yamlFile)
 		if err != nil {
 			return nil, err
 		}
+
+		services, err := unmarshalServices(fileData, envsubst)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", yamlFile, err.Error())
+		}
+
+		merged = mergeServices(merged, *services)
+	}
+
+	return validateServices(&merged, regex, filter)
+}
+
+// StdinFile is given in place of a stack file to read it from STDIN
+const StdinFile = "-"
+
+// readStackFiles holds the stack files read from STDIN or fetched from a URL,
+// which are parsed again by each step of a command such as up
+var (
+	readStackFiles     = map[string][]byte{}
+	readStackFilesLock sync.Mutex
+)
+
+// IsRemoteFile is true for a stack file given by an http or https URL
+func IsRemoteFile(yamlFile string) bool {
+	return strings.HasPrefix(yamlFile, "http://") || strings.HasPrefix(yamlFile, "https://")
+}
+
+// readYAMLFile reads a stack file from disk, from STDIN or from a URL, the
+// last two are only read once
+func readYAMLFile(yamlFile string) ([]byte, error) {
+	if yamlFile != StdinFile && !IsRemoteFile(yamlFile) {
+		return ioutil.ReadFile(yamlFile)
+	}
+
+	readStackFilesLock.Lock()
+	defer readStackFilesLock.Unlock()
+
+	if fileData, ok := readStackFiles[yamlFile]; ok {
+		return fileData, nil
+	}
+
+	var fileData []byte
+	if yamlFile == StdinFile {
+		var err error
+		if fileData, err = ioutil.ReadAll(os.Stdin); err != nil {
+			return nil, fmt.Errorf("unable to read the stack file from STDIN: %s", err)
+		}
 	} else {
-		fileData, err = ioutil.ReadFile(yamlFile)
+		urlParsed, err := url.Parse(yamlFile)
 		if err != nil {
 			return nil, err
 		}
+		fmt.Fprintln(os.Stderr, "Parsed: "+urlParsed.String())
+		if fileData, err = fetchYAML(urlParsed); err != nil {
+			return nil, err
+		}
 	}
-	return ParseYAMLData(fileData, regex, filter, envsubst)
+
+	readStackFiles[yamlFile] = fileData
+	return fileData, nil
 }
 
+// substituteEnvironment expands ${VAR} and ${VAR:-default} from the environment,
+// $$ gives a literal $. A variable which is unset and has no default is an error.
 func substituteEnvironment(data []byte) ([]byte, error) {
 
+	tree, err := parse.Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if missing := findUnsetVariables(tree.Root, nil); len(missing) > 0 {
+		return nil, fmt.Errorf("environment variable(s) %s not set and no default given in the YAML file", strings.Join(missing, ", "))
+	}
+
 	ret, err := envsubst.Parse(string(data))
 	if err != nil {
 		return nil, err
@@ -64,11 +143,60 @@ func substituteEnvironment(data []byte) ([]byte, error) {
 	return []byte(res), resErr
 }
 
+// defaultFuncs are the substitutions which give a value when the variable is unset
+var defaultFuncs = map[string]bool{
+	"-":  true,
+	":-": true,
+	"=":  true,
+	":=": true,
+	"+":  true,
+	":+": true,
+}
+
+func findUnsetVariables(node parse.Node, missing []string) []string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, child := range n.Nodes {
+			missing = findUnsetVariables(child, missing)
+		}
+	case *parse.FuncNode:
+		if _, ok := os.LookupEnv(n.Param); ok {
+			return missing
+		}
+
+		if !defaultFuncs[n.Name] {
+			return appendUnique(missing, n.Param)
+		}
+
+		// the default is only expanded when the variable is unset
+		for _, arg := range n.Args {
+			missing = findUnsetVariables(arg, missing)
+		}
+	}
+	return missing
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
 // ParseYAMLData parse YAML data into a stack of "services".
 func ParseYAMLData(fileData []byte, regex string, filter string, envsubst bool) (*Services, error) {
+	services, err := unmarshalServices(fileData, envsubst)
+	if err != nil {
+		return services, err
+	}
+
+	return validateServices(services, regex, filter)
+}
+
+func unmarshalServices(fileData []byte, envsubst bool) (*Services, error) {
 	var services Services
-	regexExists := len(regex) > 0
-	filterExists := len(filter) > 0
 
 	var source []byte
 	if envsubst {
@@ -88,6 +216,11 @@ func ParseYAMLData(fileData []byte, regex string, filter string, envsubst bool)
 		return nil, err
 	}
 
+	return &services, nil
+}
+
+// validateServices checks the provider and schema version, then applies the regex or filter
+func validateServices(services *Services, regex string, filter string) (*Services, error) {
 	for _, f := range services.Functions {
 		if f.Language == "Dockerfile" {
 			f.Language = "dockerfile"
@@ -102,37 +235,78 @@ func ParseYAMLData(fileData []byte, regex string, filter string, envsubst bool)
 		return nil, fmt.Errorf("%s are the only valid versions for the stack file - found: %s", ValidSchemaVersions, services.Version)
 	}
 
-	if regexExists && filterExists {
-		return nil, fmt.Errorf("pass in a regex or a filter, not both")
+	if err := resolveExtends(services.Functions); err != nil {
+		return nil, err
 	}
 
-	if regexExists || filterExists {
-		for k, function := range services.Functions {
-			var match bool
-			var err error
-			function.Name = k
-
-			if regexExists {
-				match, err = regexp.MatchString(regex, function.Name)
-				if err != nil {
-					return nil, err
-				}
-			} else {
-				match = glob.Glob(filter, function.Name)
-			}
+	for name, function := range services.Functions {
+		if err := validateAnnotations(function); err != nil {
+			return nil, fmt.Errorf("function %s: %s", name, err)
+		}
+		if err := ValidateTopics(function.Topics); err != nil {
+			return nil, fmt.Errorf("function %s: %s", name, err)
+		}
+		if function.HealthCheck == nil {
+			continue
+		}
+		if err := function.HealthCheck.Validate(); err != nil {
+			return nil, fmt.Errorf("function %s: %s", name, err)
+		}
+	}
 
-			if !match {
-				delete(services.Functions, function.Name)
-			}
+	if err := SelectFunctions(services, regex, filter, nil); err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// SelectFunctions keeps the functions whose names match the regex or the
+// filter, a glob, or which are given by names, each of which is a name or a
+// glob such as web-*. Every function is kept when none of them are given. A
+// name which matches no function in the stack file is an error.
+func SelectFunctions(services *Services, regex, filter string, names []string) error {
+	if len(regex) > 0 && len(filter) > 0 {
+		return fmt.Errorf("pass in a regex or a filter, not both")
+	}
+	if len(regex) == 0 && len(filter) == 0 && len(names) == 0 {
+		return nil
+	}
+
+	var pattern *regexp.Regexp
+	if len(regex) > 0 {
+		var err error
+		if pattern, err = regexp.Compile(regex); err != nil {
+			return fmt.Errorf("invalid --regex %q: %s", regex, err)
+		}
+	}
+
+	selected := map[string]Function{}
+	for k, function := range services.Functions {
+		if (pattern != nil && pattern.MatchString(k)) || (len(filter) > 0 && glob.Glob(filter, k)) {
+			selected[k] = function
 		}
+	}
 
-		if len(services.Functions) == 0 {
-			return nil, fmt.Errorf("no functions matching --filter/--regex were found in the YAML file")
+	for _, name := range names {
+		found := false
+		for k, function := range services.Functions {
+			if glob.Glob(name, k) {
+				selected[k] = function
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("no function matching %s was found in the stack file", name)
 		}
+	}
 
+	if len(selected) == 0 {
+		return fmt.Errorf("no functions matching --filter/--regex were found in the YAML file")
 	}
 
-	return &services, nil
+	services.Functions = selected
+	return nil
 }
 
 func makeHTTPClient(timeout *time.Duration) http.Client {
@@ -140,7 +314,7 @@ func makeHTTPClient(timeout *time.Duration) http.Client {
 		return http.Client{
 			Timeout: *timeout,
 			Transport: &http.Transport{
-				Proxy: http.ProxyFromEnvironment,
+				Proxy: defaultProxy(),
 				DialContext: (&net.Dialer{
 					Timeout: *timeout,
 					// KeepAlive: 0,
@@ -149,6 +323,7 @@ func makeHTTPClient(timeout *time.Duration) http.Client {
 				// DisableKeepAlives:     true,
 				IdleConnTimeout:       120 * time.Millisecond,
 				ExpectContinueTimeout: 1500 * time.Millisecond,
+				TLSClientConfig:       defaultTLSClientConfig(),
 			},
 		}
 	}
@@ -157,6 +332,24 @@ func makeHTTPClient(timeout *time.Duration) http.Client {
 	return http.Client{}
 }
 
+// defaultTLSClientConfig gives the TLS config of http.DefaultTransport, which
+// carries the CAs and verification set by the global TLS flags
+func defaultTLSClientConfig() *tls.Config {
+	if transport, ok := http.DefaultTransport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+		return transport.TLSClientConfig.Clone()
+	}
+	return nil
+}
+
+// defaultProxy gives the proxy of http.DefaultTransport, which is set by the
+// global --proxy flag
+func defaultProxy() func(*http.Request) (*url.URL, error) {
+	if transport, ok := http.DefaultTransport.(*http.Transport); ok && transport.Proxy != nil {
+		return transport.Proxy
+	}
+	return http.ProxyFromEnvironment
+}
+
 // fetchYAML pulls in file from remote location such as GitHub raw file-view
 func fetchYAML(address *url.URL) ([]byte, error) {
 	req, err := http.NewRequest(http.MethodGet, address.String(), nil)
@@ -173,6 +366,10 @@ func fetchYAML(address *url.URL) ([]byte, error) {
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch the stack file %s, the server gave: %s", address.String(), res.Status)
+	}
+
 	resBytes, err := ioutil.ReadAll(res.Body)
 
 	return resBytes, err
@@ -4,6 +4,10 @@
 package stack
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
 	"regexp"
@@ -327,6 +331,57 @@ func Test_ParseYAMLDataFilterAndRegex(t *testing.T) {
 	}
 }
 
+func Test_SelectFunctions(t *testing.T) {
+	cases := []struct {
+		title     string
+		regex     string
+		filter    string
+		names     []string
+		functions []string
+		err       string
+	}{
+		{title: "no names keeps every function", functions: []string{"abcd-eeee", "imagemagick", "nodejs-echo", "ruby-echo", "url-ping"}},
+		{title: "names", names: []string{"url-ping", "imagemagick"}, functions: []string{"imagemagick", "url-ping"}},
+		{title: "glob", names: []string{"*-echo"}, functions: []string{"nodejs-echo", "ruby-echo"}},
+		{title: "unknown name", names: []string{"url-ping", "figlet"}, err: "no function matching figlet was found in the stack file"},
+		{title: "regex", regex: "^(url|ruby)-", functions: []string{"ruby-echo", "url-ping"}},
+		{title: "regex and names", regex: "^url-", names: []string{"imagemagick"}, functions: []string{"imagemagick", "url-ping"}},
+		{title: "filter and names", filter: "*-echo", names: []string{"url-ping"}, functions: []string{"nodejs-echo", "ruby-echo", "url-ping"}},
+		{title: "invalid regex", regex: "[", err: "invalid --regex \"[\": error parsing regexp: missing closing ]: `[`"},
+		{title: "regex and filter", regex: "url", filter: "url*", err: "pass in a regex or a filter, not both"},
+		{title: "regex without matches", regex: "^figlet$", err: "no functions matching --filter/--regex were found in the YAML file"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			services, err := ParseYAMLData([]byte(TestData_1), "", "", true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = SelectFunctions(services, c.regex, c.filter, c.names)
+			if len(c.err) > 0 {
+				if err == nil || err.Error() != c.err {
+					t.Fatalf("want error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("want no error, got %s", err)
+			}
+
+			var functions []string
+			for name := range services.Functions {
+				functions = append(functions, name)
+			}
+			sort.Strings(functions)
+			if !reflect.DeepEqual(functions, c.functions) {
+				t.Errorf("want %v, got %v", c.functions, functions)
+			}
+		})
+	}
+}
+
 func Test_ParseYAMLData_ProviderValues(t *testing.T) {
 	testCases := []struct {
 		title         string
@@ -500,3 +555,118 @@ func Test_substituteEnvironment_DefaultLeftWhenNil(t *testing.T) {
 		t.Errorf("subst, want: %s, got: %s", want, string(res))
 	}
 }
+
+func Test_substituteEnvironment_UnsetWithoutDefaultErrors(t *testing.T) {
+
+	os.Unsetenv("FAAS_TEST_REGISTRY")
+	template := "${FAAS_TEST_REGISTRY}/image:latest"
+	_, err := substituteEnvironment([]byte(template))
+
+	if err == nil {
+		t.Fatalf("want error for unset variable")
+	}
+
+	want := "environment variable(s) FAAS_TEST_REGISTRY not set and no default given in the YAML file"
+	if err.Error() != want {
+		t.Errorf("want error: %q, got: %q", want, err.Error())
+	}
+}
+
+func Test_substituteEnvironment_SetButEmptyIsAllowed(t *testing.T) {
+
+	os.Setenv("FAAS_TEST_TAG", "")
+	defer os.Unsetenv("FAAS_TEST_TAG")
+	want := "image:"
+	res, err := substituteEnvironment([]byte("image:${FAAS_TEST_TAG}"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want != string(res) {
+		t.Errorf("subst, want: %s, got: %s", want, string(res))
+	}
+}
+
+func Test_substituteEnvironment_NestedDefault(t *testing.T) {
+
+	os.Unsetenv("FAAS_TEST_REGISTRY")
+	os.Setenv("FAAS_TEST_USER", "openfaas")
+	defer os.Unsetenv("FAAS_TEST_USER")
+	want := "openfaas/image:latest"
+	res, err := substituteEnvironment([]byte("${FAAS_TEST_REGISTRY:-${FAAS_TEST_USER}}/image:latest"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want != string(res) {
+		t.Errorf("subst, want: %s, got: %s", want, string(res))
+	}
+}
+
+func Test_substituteEnvironment_EscapedDollar(t *testing.T) {
+
+	os.Unsetenv("FAAS_TEST_PRICE")
+	want := "price: $FAAS_TEST_PRICE"
+	res, err := substituteEnvironment([]byte("price: $$FAAS_TEST_PRICE"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want != string(res) {
+		t.Errorf("subst, want: %s, got: %s", want, string(res))
+	}
+}
+
+func Test_readYAMLFile_StdinReadOnce(t *testing.T) {
+	stdin := os.Stdin
+	os.Stdin, _ = ioutil.TempFile("", "stdin")
+	os.Stdin.WriteString(TestData_1)
+	os.Stdin.Seek(0, 0)
+	defer func() {
+		os.Remove(os.Stdin.Name())
+		os.Stdin = stdin
+		delete(readStackFiles, StdinFile)
+	}()
+
+	for i := 0; i < 2; i++ {
+		services, err := ParseYAMLFile(StdinFile, "", "", true)
+		if err != nil {
+			t.Fatalf("want no error, got %s", err)
+		}
+		if _, ok := services.Functions["url-ping"]; !ok {
+			t.Errorf("want the stack file from STDIN each time it is parsed, got %v", services.Functions)
+		}
+	}
+}
+
+func Test_readYAMLFile_URLFetchedOnce(t *testing.T) {
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/stack.yml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, TestData_1)
+	}))
+	defer s.Close()
+	defer func() { readStackFiles = map[string][]byte{} }()
+
+	for i := 0; i < 2; i++ {
+		if _, err := ParseYAMLFile(s.URL+"/stack.yml", "", "", true); err != nil {
+			t.Fatalf("want no error, got %s", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("want the stack file fetched once, got %d requests", requests)
+	}
+
+	_, err := ParseYAMLFile(s.URL+"/missing.yml", "", "", true)
+	want := fmt.Sprintf("unable to fetch the stack file %s/missing.yml, the server gave: 404 Not Found", s.URL)
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
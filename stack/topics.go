@@ -0,0 +1,53 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TopicAnnotation is read by the event connectors, such as the Kafka and
+// NATS connectors, for the comma-separated topics which invoke the function
+const TopicAnnotation = "topic"
+
+// ValidateTopics checks each topic can be given in the annotation, it must
+// not be empty or contain a comma
+func ValidateTopics(topics []string) error {
+	for _, topic := range topics {
+		if len(strings.TrimSpace(topic)) == 0 {
+			return fmt.Errorf("topics must not be empty")
+		}
+		if strings.Contains(topic, ",") {
+			return fmt.Errorf("topic %q must not contain a comma, give each topic separately", topic)
+		}
+	}
+	return nil
+}
+
+// TopicsAnnotations gives the annotation for the topics, none are given when
+// there are no topics
+func TopicsAnnotations(topics []string) map[string]string {
+	if len(topics) == 0 {
+		return map[string]string{}
+	}
+	return map[string]string{TopicAnnotation: strings.Join(topics, ",")}
+}
+
+// TopicsFromAnnotations reads the topics back from the annotations of a
+// deployed function, nil is returned when none are set
+func TopicsFromAnnotations(annotations map[string]string) []string {
+	value, ok := annotations[TopicAnnotation]
+	if !ok || len(value) == 0 {
+		return nil
+	}
+
+	var topics []string
+	for _, topic := range strings.Split(value, ",") {
+		if topic = strings.TrimSpace(topic); len(topic) > 0 {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
@@ -0,0 +1,73 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_ValidateTopics(t *testing.T) {
+	cases := []struct {
+		name   string
+		topics []string
+		want   string
+	}{
+		{name: "none"},
+		{name: "valid", topics: []string{"payments", "orders.created"}},
+		{name: "empty", topics: []string{"payments", ""}, want: "topics must not be empty"},
+		{name: "whitespace", topics: []string{" "}, want: "topics must not be empty"},
+		{name: "comma", topics: []string{"payments,orders"}, want: `topic "payments,orders" must not contain a comma, give each topic separately`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateTopics(c.topics)
+			if len(c.want) == 0 && err != nil {
+				t.Fatalf("want no error, got %s", err)
+			}
+			if len(c.want) > 0 && (err == nil || err.Error() != c.want) {
+				t.Fatalf("want error %q, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func Test_Topics_AnnotationsRoundTrip(t *testing.T) {
+	topics := []string{"payments", "orders"}
+
+	annotations := TopicsAnnotations(topics)
+	if want := map[string]string{TopicAnnotation: "payments,orders"}; !reflect.DeepEqual(annotations, want) {
+		t.Fatalf("want %v, got %v", want, annotations)
+	}
+
+	if got := TopicsFromAnnotations(annotations); !reflect.DeepEqual(got, topics) {
+		t.Errorf("want the topics back from the annotation, got %v", got)
+	}
+
+	if got := TopicsAnnotations(nil); len(got) != 0 {
+		t.Errorf("want no annotation without topics, got %v", got)
+	}
+	if got := TopicsFromAnnotations(map[string]string{HealthCheckPathAnnotation: "/_/ready"}); got != nil {
+		t.Errorf("want no topics without the annotation, got %v", got)
+	}
+}
+
+func Test_ParseYAMLData_EmptyTopic(t *testing.T) {
+	data := []byte(`provider:
+  name: openfaas
+functions:
+  fn1:
+    image: fn1:latest
+    topics:
+      - payments
+      - ""
+`)
+
+	_, err := ParseYAMLData(data, "", "", false)
+	want := "function fn1: topics must not be empty"
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
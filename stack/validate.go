@@ -0,0 +1,139 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// unknownFieldError matches the errors given by yaml.UnmarshalStrict for a key
+// with no corresponding struct field
+var unknownFieldError = regexp.MustCompile(`^line (\d+): field (.+) not found in type (.+)$`)
+
+// Problem found while validating a stack file
+type Problem struct {
+	File string
+	// Line is 0 when the problem can't be tied to a single line
+	Line    int
+	Message string
+	// Warning is set for unknown keys, which are ignored when parsing the file
+	Warning bool
+}
+
+func (p Problem) String() string {
+	level := "error"
+	if p.Warning {
+		level = "warning"
+	}
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", p.File, p.Line, level, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.File, level, p.Message)
+}
+
+// ValidateYAMLFiles checks the stack files for unknown keys, then merges them
+// and checks every function gives a lang or an image. An error is returned
+// when the files can't be parsed at all.
+func ValidateYAMLFiles(yamlFiles []string, envsubst bool) ([]Problem, error) {
+	var problems []Problem
+	var merged Services
+	definedIn := map[string]string{}
+
+	for _, yamlFile := range yamlFiles {
+		fileData, err := readYAMLFile(yamlFile)
+		if err != nil {
+			return nil, err
+		}
+
+		services, err := unmarshalServices(fileData, envsubst)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", yamlFile, err.Error())
+		}
+
+		unknown, err := findUnknownKeys(yamlFile, fileData, envsubst)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, unknown...)
+
+		for name := range services.Functions {
+			if _, ok := definedIn[name]; !ok {
+				definedIn[name] = yamlFile
+			}
+		}
+		merged = mergeServices(merged, *services)
+	}
+
+	if _, err := validateServices(&merged, "", ""); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range merged.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		function := merged.Functions[name]
+		if len(function.Language) == 0 && len(function.Image) == 0 {
+			problems = append(problems, Problem{
+				File:    definedIn[name],
+				Message: fmt.Sprintf("function %s must give a lang or an image", name),
+			})
+		}
+	}
+
+	return problems, nil
+}
+
+func findUnknownKeys(yamlFile string, fileData []byte, envsubst bool) ([]Problem, error) {
+	if envsubst {
+		substData, err := substituteEnvironment(fileData)
+		if err != nil {
+			return nil, err
+		}
+		fileData = substData
+	}
+
+	var services Services
+	return unknownKeyProblems(yamlFile, fileData, &services), nil
+}
+
+// unknownKeyProblems gives a warning for each key in the YAML with no
+// corresponding field in out, and an error for any other problem
+func unknownKeyProblems(yamlFile string, fileData []byte, out interface{}) []Problem {
+	err := yaml.UnmarshalStrict(fileData, out)
+	if err == nil {
+		return nil
+	}
+
+	typeErr, ok := err.(*yaml.TypeError)
+	if !ok {
+		return []Problem{{File: yamlFile, Message: err.Error()}}
+	}
+
+	var problems []Problem
+	for _, msg := range typeErr.Errors {
+		match := unknownFieldError.FindStringSubmatch(msg)
+		if match == nil {
+			problems = append(problems, Problem{File: yamlFile, Message: msg})
+			continue
+		}
+
+		line, _ := strconv.Atoi(match[1])
+		problems = append(problems, Problem{
+			File:    yamlFile,
+			Line:    line,
+			Message: fmt.Sprintf("unknown key %q", match[2]),
+			Warning: true,
+		})
+	}
+	return problems
+}
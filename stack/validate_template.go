@@ -0,0 +1,103 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// defaultHandlerFolder is copied into a new function when the template does
+// not give a handler_folder
+const defaultHandlerFolder = "function"
+
+// ValidateLanguageTemplate checks a language template directory has a
+// template.yml with a language, a Dockerfile and the handler folder which is
+// copied by "faas-cli new". An error is returned when the directory can't
+// be read.
+func ValidateLanguageTemplate(dir string) ([]Problem, error) {
+	templateYAML := filepath.Join(dir, "template.yml")
+
+	fileData, err := ioutil.ReadFile(templateYAML)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Problem{{File: dir, Message: "template.yml not found"}}, nil
+		}
+		return nil, err
+	}
+
+	var problems []Problem
+	var strict LanguageTemplate
+	problems = append(problems, unknownKeyProblems(templateYAML, fileData, &strict)...)
+
+	var langTemplate LanguageTemplate
+	if err := yaml.Unmarshal(fileData, &langTemplate); err != nil {
+		return problems, nil
+	}
+
+	name := filepath.Base(dir)
+	switch {
+	case len(langTemplate.Language) == 0:
+		problems = append(problems, Problem{File: templateYAML, Message: "language must be given"})
+	case langTemplate.Language != name:
+		problems = append(problems, Problem{
+			File:    templateYAML,
+			Message: fmt.Sprintf("language %s does not match the directory name %s, which is given to --lang", langTemplate.Language, name),
+			Warning: true,
+		})
+	}
+
+	for i, option := range langTemplate.BuildOptions {
+		if len(option.Name) == 0 {
+			problems = append(problems, Problem{File: templateYAML, Message: fmt.Sprintf("build option %d must give a name", i+1)})
+		}
+	}
+
+	handlerFolder := defaultHandlerFolder
+	if len(langTemplate.HandlerFolder) > 0 {
+		handlerFolder = langTemplate.HandlerFolder
+	}
+	handlerDir := filepath.Join(dir, handlerFolder)
+
+	handlerFiles, err := ioutil.ReadDir(handlerDir)
+	switch {
+	case os.IsNotExist(err):
+		problems = append(problems, Problem{File: dir, Message: fmt.Sprintf("handler folder %s not found", handlerFolder)})
+	case err != nil:
+		problems = append(problems, Problem{File: dir, Message: fmt.Sprintf("handler folder %s can't be read: %s", handlerFolder, err)})
+	case len(handlerFiles) == 0:
+		problems = append(problems, Problem{
+			File:    dir,
+			Message: fmt.Sprintf("handler folder %s is empty, new functions will have no files", handlerFolder),
+			Warning: true,
+		})
+	}
+
+	// A dockerfile template has its Dockerfile in the handler, so that each
+	// function gives its own
+	switch {
+	case isFile(filepath.Join(dir, "Dockerfile")):
+		if len(langTemplate.FProcess) == 0 {
+			problems = append(problems, Problem{
+				File:    templateYAML,
+				Message: "fprocess is not given, deploy will not set one for functions",
+				Warning: true,
+			})
+		}
+	case isFile(filepath.Join(handlerDir, "Dockerfile")):
+	default:
+		problems = append(problems, Problem{File: dir, Message: fmt.Sprintf("Dockerfile not found in the template or its %s folder", handlerFolder)})
+	}
+
+	return problems, nil
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,129 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTemplate writes the files, a name ending in / is made a directory
+func writeTemplate(t *testing.T, name string, files map[string]string) string {
+	root, err := ioutil.TempDir("", "template-lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(root, name)
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(path, 0700); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func problemMessages(problems []Problem) []string {
+	messages := []string{}
+	for _, problem := range problems {
+		level := "error"
+		if problem.Warning {
+			level = "warning"
+		}
+		messages = append(messages, level+": "+problem.Message)
+	}
+	return messages
+}
+
+func Test_ValidateLanguageTemplate(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		files    map[string]string
+		want     []string
+	}{
+		{
+			name:     "valid",
+			template: "node12",
+			files: map[string]string{
+				"template.yml":          "language: node12\nfprocess: node index.js\n",
+				"Dockerfile":            "FROM node:12",
+				"function/handler.js":   "module.exports = {}",
+				"function/package.json": "{}",
+			},
+			want: []string{},
+		},
+		{
+			name:     "dockerfile in the handler",
+			template: "dockerfile",
+			files: map[string]string{
+				"template.yml":        "language: dockerfile\n",
+				"function/Dockerfile": "FROM alpine",
+			},
+			want: []string{},
+		},
+		{
+			name:     "no template.yml",
+			template: "node12",
+			files:    map[string]string{"Dockerfile": "FROM node:12"},
+			want:     []string{"error: template.yml not found"},
+		},
+		{
+			name:     "missing language, Dockerfile and handler",
+			template: "node12",
+			files:    map[string]string{"template.yml": "fprocess: node index.js\n"},
+			want: []string{
+				"error: language must be given",
+				"error: handler folder function not found",
+				"error: Dockerfile not found in the template or its function folder",
+			},
+		},
+		{
+			name:     "warnings",
+			template: "node12",
+			files: map[string]string{
+				"template.yml": "language: node\nhandler_folder: src\nwelcome: hi\n",
+				"Dockerfile":   "FROM node:12",
+				"src/":         "",
+			},
+			want: []string{
+				`warning: unknown key "welcome"`,
+				"warning: language node does not match the directory name node12, which is given to --lang",
+				"warning: handler folder src is empty, new functions will have no files",
+				"warning: fprocess is not given, deploy will not set one for functions",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := writeTemplate(t, c.template, c.files)
+			defer os.RemoveAll(filepath.Dir(dir))
+
+			problems, err := ValidateLanguageTemplate(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := problemMessages(problems); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("want %q, got %q", c.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) OpenFaaS Author(s) 2020. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package stack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const typoStack = `provider:
+  name: openfaas
+functions:
+  figlet:
+    lang: dockerfile
+    enviroment:
+      write_debug: true
+  nodeinfo:
+    handler: ./nodeinfo
+`
+
+func Test_ValidateYAMLFiles_ReportsProblems(t *testing.T) {
+	files := writeStackFiles(t, typoStack)
+	defer os.RemoveAll(filepath.Dir(files[0]))
+
+	problems, err := ValidateYAMLFiles(files, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Problem{
+		{File: files[0], Line: 6, Message: `unknown key "enviroment"`, Warning: true},
+		{File: files[0], Message: "function nodeinfo must give a lang or an image"},
+	}
+
+	if len(problems) != len(want) {
+		t.Fatalf("want %d problems, got %d: %v", len(want), len(problems), problems)
+	}
+	for i := range want {
+		if problems[i] != want[i] {
+			t.Errorf("problem %d: want %v, got %v", i, want[i], problems[i])
+		}
+	}
+}
+
+func Test_ValidateYAMLFiles_ValidStack(t *testing.T) {
+	files := writeStackFiles(t, baseStack, overrideStack)
+	defer os.RemoveAll(filepath.Dir(files[0]))
+
+	problems, err := ValidateYAMLFiles(files, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("want no problems, got %v", problems)
+	}
+}
+
+func Test_ValidateYAMLFiles_InvalidProvider(t *testing.T) {
+	files := writeStackFiles(t, "provider:\n  name: faas\n")
+	defer os.RemoveAll(filepath.Dir(files[0]))
+
+	if _, err := ValidateYAMLFiles(files, false); err == nil {
+		t.Errorf("want error for an invalid provider")
+	}
+}
+
+func Test_Problem_String(t *testing.T) {
+	p := Problem{File: "stack.yml", Line: 6, Message: `unknown key "enviroment"`, Warning: true}
+	want := `stack.yml:6: warning: unknown key "enviroment"`
+	if p.String() != want {
+		t.Errorf("want %q, got %q", want, p.String())
+	}
+}
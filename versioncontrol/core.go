@@ -26,16 +26,39 @@ type vcsCmd struct {
 // Invoke executes the vcsCmd replacing varibables in the cmds with the keyval
 // variables passed.
 func (v *vcsCmd) Invoke(dir string, args map[string]string) error {
+	return v.InvokeWithEnv(dir, args, nil)
+}
+
+// InvokeWithEnv is the same as Invoke, with extra environment variables
+// for the commands. Values such as credentials passed this way are kept out
+// of the command line.
+func (v *vcsCmd) InvokeWithEnv(dir string, args map[string]string, env []string) error {
 	for _, cmd := range v.cmds {
-		if _, err := v.run(dir, cmd, args, true); err != nil {
-			return err
+		if out, err := v.run(dir, cmd, args, env, true); err != nil {
+			return &CommandError{Output: string(out), Err: err}
 		}
 	}
 	return nil
 }
 
+// CommandError is returned by Invoke when a command fails, with the output
+// of the command so that the cause can be told apart
+type CommandError struct {
+	Output string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap gives the error from running the command
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
 // run is the generalized implementation of executing our commands.
-func (v *vcsCmd) run(dir string, cmdline string, keyval map[string]string, verbose bool) ([]byte, error) {
+func (v *vcsCmd) run(dir string, cmdline string, keyval map[string]string, env []string, verbose bool) ([]byte, error) {
 	args := strings.Fields(cmdline)
 	for i, arg := range args {
 		args[i] = replaceVars(keyval, arg)
@@ -50,7 +73,7 @@ func (v *vcsCmd) run(dir string, cmdline string, keyval map[string]string, verbo
 
 	cmd := exec.Command(v.cmd, args...)
 	cmd.Dir = dir
-	cmd.Env = envWithPWD(cmd.Dir)
+	cmd.Env = append(envWithPWD(cmd.Dir), env...)
 
 	var buf bytes.Buffer
 	cmd.Stdout = &buf
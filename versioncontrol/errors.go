@@ -0,0 +1,91 @@
+package versioncontrol
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+// temporaryGitErrors are the messages git gives for a network error which
+// may not happen again, such as a dropped connection. A host which could not
+// be resolved is left out, as it won't resolve on the next attempt either.
+var temporaryGitErrors = []string{
+	"temporary failure in name resolution",
+	"connection timed out",
+	"operation timed out",
+	"connection refused",
+	"connection reset",
+	"failed to connect",
+	"the remote end hung up unexpectedly",
+	"early eof",
+	"rpc failed",
+	"unexpected disconnect",
+	"tls connection was non-properly terminated",
+	"gnutls_handshake() failed",
+	"ssl_connect",
+	"the requested url returned error: 429",
+	"the requested url returned error: 500",
+	"the requested url returned error: 502",
+	"the requested url returned error: 503",
+	"the requested url returned error: 504",
+}
+
+// notFoundGitErrors are the messages git gives for a repo which does not
+// exist, or which can't be read without credentials
+var notFoundGitErrors = []string{
+	"repository not found",
+	"does not appear to be a git repository",
+	"not found",
+	"the requested url returned error: 403",
+	"the requested url returned error: 401",
+	"authentication failed",
+	"could not read username",
+}
+
+// IsTemporaryError is true when a command failed from a network error which
+// is worth retrying, a repo or host which was not found is never retried
+func IsTemporaryError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound && (dnsErr.IsTemporary || dnsErr.IsTimeout)
+	}
+
+	output := strings.ToLower(commandOutput(err))
+	if IsNotFoundError(err) {
+		return false
+	}
+	return containsAny(output, temporaryGitErrors)
+}
+
+// IsNotFoundError is true when a command failed as the repo does not exist,
+// or can't be read without credentials
+func IsNotFoundError(err error) bool {
+	return containsAny(strings.ToLower(commandOutput(err)), notFoundGitErrors)
+}
+
+// ErrorCause gives the last line git printed for a failed command, which
+// names the cause, or the error itself when there is no output
+func ErrorCause(err error) string {
+	lines := strings.Split(strings.TrimSpace(commandOutput(err)), "\n")
+	if cause := strings.TrimSpace(lines[len(lines)-1]); len(cause) > 0 {
+		return cause
+	}
+	return err.Error()
+}
+
+func commandOutput(err error) string {
+	var commandErr *CommandError
+	if errors.As(err, &commandErr) {
+		return commandErr.Output
+	}
+	return ""
+}
+
+func containsAny(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package versioncontrol
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func Test_IsTemporaryError(t *testing.T) {
+	cases := []struct {
+		output string
+		want   bool
+	}{
+		{"Cloning into '/tmp/x'...\nfatal: unable to access 'https://github.com/openfaas/templates/': Could not resolve host: github.com\n", false},
+		{"fatal: unable to access 'https://github.com/openfaas/templates/': Failed to connect to github.com port 443: Connection timed out\n", true},
+		{"error: RPC failed; curl 56 GnuTLS recv error (-54)\nfatal: early EOF\n", true},
+		{"remote: Repository not found.\nfatal: repository 'https://github.com/openfaas/missing/' not found\n", false},
+		{"fatal: invalid refspec\n", false},
+	}
+
+	for _, c := range cases {
+		err := &CommandError{Output: c.output, Err: errors.New("exit status 128")}
+		if got := IsTemporaryError(err); got != c.want {
+			t.Errorf("%q: want temporary %t, got %t", c.output, c.want, got)
+		}
+	}
+
+	if IsTemporaryError(errors.New("connection refused")) {
+		t.Error("want an error without command output not to be temporary")
+	}
+
+	if IsTemporaryError(&net.DNSError{Err: "no such host", Name: "github.com", IsNotFound: true}) {
+		t.Error("want a host which was not found not to be temporary")
+	}
+	if !IsTemporaryError(fmt.Errorf("lookup: %w", &net.DNSError{Err: "server misbehaving", Name: "github.com", IsTemporary: true})) {
+		t.Error("want a DNS lookup which failed for now to be temporary")
+	}
+}
+
+func Test_IsNotFoundError(t *testing.T) {
+	err := fmt.Errorf("clone: %w", &CommandError{
+		Output: "fatal: could not read Username for 'https://github.com': terminal prompts disabled\n",
+		Err:    errors.New("exit status 128"),
+	})
+	if !IsNotFoundError(err) {
+		t.Error("want a wrapped error which needs credentials to be not found")
+	}
+}
+
+func Test_ErrorCause(t *testing.T) {
+	err := &CommandError{
+		Output: "Cloning into '/tmp/x'...\nfatal: unable to access 'https://github.com/openfaas/templates/': Could not resolve host: github.com\n",
+		Err:    errors.New("exit status 128"),
+	}
+	want := "fatal: unable to access 'https://github.com/openfaas/templates/': Could not resolve host: github.com"
+	if got := ErrorCause(err); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	if got := ErrorCause(errors.New("exit status 1")); got != "exit status 1" {
+		t.Errorf("want the error without output, got %q", got)
+	}
+}
@@ -1,9 +1,9 @@
 package versioncontrol
 
 import (
+	"encoding/base64"
+	"regexp"
 	"strings"
-
-	"github.com/openfaas/faas-cli/exec"
 )
 
 // GitClone defines the command to clone a repo into a directory
@@ -45,44 +45,113 @@ var GitInitRepo = &vcsCmd{
 	scheme: []string{"git", "https", "http", "git+ssh", "ssh"},
 }
 
-// GetGitDescribe returns the human readable name for the current commit using `git-describe`
-func GetGitDescribe() string {
-	// git-describe - Give an object a human readable name based on an available ref
-	// --tags                use any tag, even unannotated
-	// --always              show abbreviated commit object as fallback
+// gitTagInfo defines the commands which give the values used to tag images
+var gitTagInfo = &vcsCmd{
+	name: "Git",
+	cmd:  "git",
+	cmds: []string{
+		"rev-parse --short HEAD",
+		"rev-parse --symbolic-full-name --abbrev-ref HEAD",
+		// --tags uses any tag, even unannotated, so the output looks like
+		// v1.2.2-1-g3443110 or <tag>-<commits-since-tag>-g<short-sha>.
+		// --always gives the short SHA when the repo has no tags.
+		"describe --tags --always",
+	},
+	scheme: []string{"git", "https", "http", "git+ssh", "ssh"},
+}
+
+// GetGitDescribe returns the human readable name for the current commit of
+// the repo holding dir using `git-describe`, or "" when dir is not in a repo
+func GetGitDescribe(dir string) string {
+	return gitTagValue(dir, gitTagInfo.cmds[2])
+}
+
+// GetGitSHA returns the short Git commit SHA of the repo holding dir, or ""
+// when dir is not in a repo
+func GetGitSHA(dir string) string {
+	return gitTagValue(dir, gitTagInfo.cmds[0])
+}
+
+// GetGitBranch returns the branch checked out in the repo holding dir, or ""
+// when dir is not in a repo
+func GetGitBranch(dir string) string {
+	return gitTagValue(dir, gitTagInfo.cmds[1])
+}
 
-	// using --tags, means that the output should look like v1.2.2-1-g3443110 where the last
-	// <most-recent-parent-tag>-<number-of-commits-to-that-tag>-g<short-sha>
-	// using --always, means that if the repo does not use tags, then we will still get the <short-sha>
-	// as output, similar to GetGitSHA
-	getDescribeCommand := []string{"git", "describe", "--tags", "--always"}
-	sha := exec.CommandWithOutput(getDescribeCommand, true)
-	if strings.Contains(sha, "Not a git repository") {
+func gitTagValue(dir string, cmdline string) string {
+	out, err := gitTagInfo.run(dir, cmdline, nil, nil, false)
+	if err != nil {
 		return ""
 	}
-	sha = strings.TrimSuffix(sha, "\n")
+	return strings.TrimSpace(string(out))
+}
 
-	return sha
+// GitFetchCommit defines the commands to fetch a single commit into a new repo in a directory
+var GitFetchCommit = &vcsCmd{
+	name: "Git",
+	cmd:  "git",
+	cmds: []string{
+		"init {dir}",
+		"-C {dir} config core.autocrlf false",
+		"-C {dir} fetch --depth=1 {repo} {refname}",
+		"-C {dir} checkout FETCH_HEAD",
+	},
+	scheme: []string{"git", "https", "http", "git+ssh", "ssh"},
 }
 
-// GetGitSHA returns the short Git commit SHA from local repo
-func GetGitSHA() string {
-	getShaCommand := []string{"git", "rev-parse", "--short", "HEAD"}
-	sha := exec.CommandWithOutput(getShaCommand, true)
-	if strings.Contains(sha, "Not a git repository") {
-		return ""
+// gitLsRemote defines the command to check a ref exists in a remote repo
+var gitLsRemote = &vcsCmd{
+	name:   "Git",
+	cmd:    "git",
+	cmds:   []string{"ls-remote --exit-code {repo} {refname}"},
+	scheme: []string{"git", "https", "http", "git+ssh", "ssh"},
+}
+
+// gitRevParse defines the command to resolve the commit checked out in a directory
+var gitRevParse = &vcsCmd{
+	name:   "Git",
+	cmd:    "git",
+	cmds:   []string{"-C {dir} rev-parse HEAD"},
+	scheme: []string{"git", "https", "http", "git+ssh", "ssh"},
+}
+
+var commitSHARegexp = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// IsCommitSHA returns true when the ref is a full commit SHA rather than a branch or tag
+func IsCommitSHA(refName string) bool {
+	return commitSHARegexp.MatchString(refName)
+}
+
+// GitRefExists returns true when the branch or tag is found in the remote repo
+func GitRefExists(repo string, refName string, env []string) (bool, error) {
+	_, err := gitLsRemote.run(".", gitLsRemote.cmds[0], map[string]string{"repo": repo, "refname": refName}, env, false)
+	if err == nil {
+		return true, nil
 	}
-	sha = strings.TrimSuffix(sha, "\n")
 
-	return sha
+	// ls-remote exits with 2 when the remote has no matching refs
+	if exitErr, ok := err.(interface{ ExitCode() int }); ok && exitErr.ExitCode() == 2 {
+		return false, nil
+	}
+	return false, err
 }
 
-func GetGitBranch() string {
-	getBranchCommand := []string{"git", "rev-parse", "--symbolic-full-name", "--abbrev-ref", "HEAD"}
-	branch := exec.CommandWithOutput(getBranchCommand, true)
-	if strings.Contains(branch, "Not a git repository") {
-		return ""
+// GetGitCommit returns the full SHA of the commit checked out in the directory
+func GetGitCommit(dir string) (string, error) {
+	out, err := gitRevParse.run(".", gitRevParse.cmds[0], map[string]string{"dir": dir}, nil, false)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitTokenEnv gives the environment for git to send the token as a basic-auth
+// credential to HTTPS remotes. Git reads the header from GIT_CONFIG_PARAMETERS,
+// the same as "git -c", so it is not in the command line of the process.
+func GitTokenEnv(token string) []string {
+	credential := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+	return []string{
+		"GIT_CONFIG_PARAMETERS='http.extraheader=Authorization: Basic " + credential + "'",
+		"GIT_TERMINAL_PROMPT=0",
 	}
-	branch = strings.TrimSuffix(branch, "\n")
-	return branch
 }